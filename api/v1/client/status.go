@@ -22,6 +22,7 @@ package client
 
 import (
 	"context"
+	"encoding/json"
 	"net/url"
 
 	v1 "github.com/loadimpact/k6/api/v1"
@@ -37,3 +38,18 @@ func (c *Client) Status(ctx context.Context) (ret v1.Status, err error) {
 func (c *Client) SetStatus(ctx context.Context, patch v1.Status) (ret v1.Status, err error) {
 	return ret, c.Call(ctx, "PATCH", &url.URL{Path: "/v1/status"}, patch, &ret)
 }
+
+// Stop gracefully stops the test run and returns the new status.
+func (c *Client) Stop(ctx context.Context) (ret v1.Status, err error) {
+	return ret, c.Call(ctx, "POST", &url.URL{Path: "/v1/stop"}, nil, &ret)
+}
+
+// UpdateConfig changes the options of the running test that are set in update
+// and returns the new status if it was successful.
+func (c *Client) UpdateConfig(ctx context.Context, update v1.ConfigUpdate) (ret v1.Status, err error) {
+	body, err := json.Marshal(update)
+	if err != nil {
+		return ret, err
+	}
+	return ret, c.Call(ctx, "PATCH", &url.URL{Path: "/v1/config"}, body, &ret)
+}
@@ -31,6 +31,8 @@ func NewHandler() http.Handler {
 
 	router.GET("/v1/status", HandleGetStatus)
 	router.PATCH("/v1/status", HandlePatchStatus)
+	router.POST("/v1/stop", HandlePostStop)
+	router.PATCH("/v1/config", HandlePatchConfig)
 
 	router.GET("/v1/metrics", HandleGetMetrics)
 	router.GET("/v1/metrics/:id", HandleGetMetric)
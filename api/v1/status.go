@@ -21,6 +21,8 @@
 package v1
 
 import (
+	"time"
+
 	"gopkg.in/guregu/null.v3"
 
 	"github.com/loadimpact/k6/core"
@@ -36,21 +38,36 @@ type Status struct {
 	Stopped bool      `json:"stopped" yaml:"stopped"`
 	Running bool      `json:"running" yaml:"running"`
 	Tainted bool      `json:"tainted" yaml:"tainted"`
+
+	// Whether the test run was gracefully stopped, e.g. with POST /v1/stop
+	Interrupted bool `json:"interrupted" yaml:"interrupted"`
+
+	// The time since the test run started, in milliseconds
+	Elapsed int64 `json:"elapsed" yaml:"elapsed"`
 }
 
 func NewStatus(engine *core.Engine) Status {
 	executionState := engine.ExecutionScheduler.GetState()
 	return Status{
-		Status:  executionState.GetCurrentExecutionStatus(),
-		Running: executionState.HasStarted() && !executionState.HasEnded(),
-		Paused:  null.BoolFrom(executionState.IsPaused()),
-		Stopped: engine.IsStopped(),
-		VUs:     null.IntFrom(executionState.GetCurrentlyActiveVUsCount()),
-		VUsMax:  null.IntFrom(executionState.GetInitializedVUsCount()),
-		Tainted: engine.IsTainted(),
+		Status:      executionState.GetCurrentExecutionStatus(),
+		Running:     executionState.HasStarted() && !executionState.HasEnded(),
+		Paused:      null.BoolFrom(executionState.IsPaused()),
+		Stopped:     engine.IsStopped(),
+		Interrupted: engine.IsInterrupted(),
+		VUs:         null.IntFrom(executionState.GetCurrentlyActiveVUsCount()),
+		VUsMax:      null.IntFrom(executionState.GetInitializedVUsCount()),
+		Tainted:     engine.IsTainted(),
+		Elapsed:     int64(executionState.GetCurrentTestRunDuration() / time.Millisecond),
 	}
 }
 
+// ConfigUpdate contains the options that can be changed while the test is
+// running, with PATCH /v1/config. Only the ones that are set are changed.
+type ConfigUpdate struct {
+	VUs    null.Int `json:"vus" yaml:"vus"`
+	VUsMax null.Int `json:"vus-max" yaml:"vus-max"`
+}
+
 func (s Status) GetName() string {
 	return "status"
 }
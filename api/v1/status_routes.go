@@ -21,14 +21,17 @@
 package v1
 
 import (
+	"encoding/json"
 	"errors"
 	"io/ioutil"
 	"net/http"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/manyminds/api2go/jsonapi"
+	"gopkg.in/guregu/null.v3"
 
 	"github.com/loadimpact/k6/api/common"
+	"github.com/loadimpact/k6/core"
 	"github.com/loadimpact/k6/lib"
 	"github.com/loadimpact/k6/lib/executor"
 )
@@ -82,26 +85,8 @@ func HandlePatchStatus(rw http.ResponseWriter, r *http.Request, p httprouter.Par
 			}
 		}
 
-		if status.VUsMax.Valid || status.VUs.Valid {
-			//TODO: add ability to specify the actual executor id? Though this should
-			//likely be in the v2 REST API, where we could implement it in a way that
-			//may allow us to eventually support other executor types.
-			executor, updateErr := getFirstExternallyControlledExecutor(engine.ExecutionScheduler)
-			if updateErr != nil {
-				apiError(rw, "Execution config error", updateErr.Error(), http.StatusInternalServerError)
-				return
-			}
-			newConfig := executor.GetCurrentConfig().ExternallyControlledConfigParams
-			if status.VUsMax.Valid {
-				newConfig.MaxVUs = status.VUsMax
-			}
-			if status.VUs.Valid {
-				newConfig.VUs = status.VUs
-			}
-			if updateErr := executor.UpdateConfig(r.Context(), newConfig); updateErr != nil {
-				apiError(rw, "Config update error", updateErr.Error(), http.StatusBadRequest)
-				return
-			}
+		if !updateVUs(rw, r, engine, status.VUs, status.VUsMax) {
+			return
 		}
 	}
 
@@ -112,3 +97,73 @@ func HandlePatchStatus(rw http.ResponseWriter, r *http.Request, p httprouter.Par
 	}
 	_, _ = rw.Write(data)
 }
+
+// updateVUs changes the VUs and max VUs of the externally-controlled executor,
+// if they are set. It writes the error response and returns false on failure.
+func updateVUs(rw http.ResponseWriter, r *http.Request, engine *core.Engine, vus, vusMax null.Int) bool {
+	if !vusMax.Valid && !vus.Valid {
+		return true
+	}
+	//TODO: add ability to specify the actual executor id? Though this should
+	//likely be in the v2 REST API, where we could implement it in a way that
+	//may allow us to eventually support other executor types.
+	executor, err := getFirstExternallyControlledExecutor(engine.ExecutionScheduler)
+	if err != nil {
+		apiError(rw, "Execution config error", err.Error(), http.StatusInternalServerError)
+		return false
+	}
+	newConfig := executor.GetCurrentConfig().ExternallyControlledConfigParams
+	if vusMax.Valid {
+		newConfig.MaxVUs = vusMax
+	}
+	if vus.Valid {
+		newConfig.VUs = vus
+	}
+	if err := executor.UpdateConfig(r.Context(), newConfig); err != nil {
+		apiError(rw, "Config update error", err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// HandlePostStop gracefully stops the test run and returns the new status.
+func HandlePostStop(rw http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	engine := common.GetEngine(r.Context())
+	engine.Interrupt()
+
+	data, err := jsonapi.Marshal(NewStatus(engine))
+	if err != nil {
+		apiError(rw, "Encoding error", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	_, _ = rw.Write(data)
+}
+
+// HandlePatchConfig changes the options of the running test that are set in
+// the request body, a plain JSON ConfigUpdate, and returns the new status.
+func HandlePatchConfig(rw http.ResponseWriter, r *http.Request, p httprouter.Params) {
+	engine := common.GetEngine(r.Context())
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		apiError(rw, "Couldn't read request", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var update ConfigUpdate
+	if err := json.Unmarshal(body, &update); err != nil {
+		apiError(rw, "Invalid data", err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !updateVUs(rw, r, engine, update.VUs, update.VUsMax) {
+		return
+	}
+
+	data, err := jsonapi.Marshal(NewStatus(engine))
+	if err != nil {
+		apiError(rw, "Encoding error", err.Error(), http.StatusInternalServerError)
+		return
+	}
+	_, _ = rw.Write(data)
+}
@@ -141,3 +141,86 @@ func TestPatchStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestPostStop(t *testing.T) {
+	logger := logrus.New()
+	logger.SetOutput(testutils.NewTestOutput(t))
+	execScheduler, err := local.NewExecutionScheduler(&minirunner.MiniRunner{}, logger)
+	require.NoError(t, err)
+	engine, err := core.NewEngine(execScheduler, lib.Options{}, logger)
+	require.NoError(t, err)
+
+	rw := httptest.NewRecorder()
+	NewHandler().ServeHTTP(rw, newRequestWithEngine(engine, "POST", "/v1/stop", nil))
+	res := rw.Result()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	var status Status
+	require.NoError(t, jsonapi.Unmarshal(rw.Body.Bytes(), &status))
+	// The test run is stopped gracefully, like with Ctrl+C
+	assert.True(t, status.Interrupted)
+	assert.True(t, engine.IsInterrupted())
+	assert.False(t, status.Stopped)
+	assert.False(t, engine.IsStopped())
+}
+
+func TestPatchConfig(t *testing.T) {
+	testdata := map[string]struct {
+		StatusCode int
+		Body       string
+		VUs        int64
+		VUsMax     int64
+	}{
+		"nothing":      {200, `{}`, 0, 10},
+		"vus":          {200, `{"vus": 5}`, 5, 10},
+		"max vus":      {200, `{"vus-max": 20}`, 0, 20},
+		"too many vus": {400, `{"vus": 20}`, 0, 0},
+		"invalid":      {400, `{"vus": "many"}`, 0, 0},
+	}
+	logger := logrus.New()
+	logger.SetOutput(testutils.NewTestOutput(t))
+
+	scenarios := lib.ScenarioConfigs{}
+	err := json.Unmarshal([]byte(`
+			{"external": {"executor": "externally-controlled",
+			"vus": 0, "maxVUs": 10, "duration": "1s"}}`), &scenarios)
+	require.NoError(t, err)
+	options := lib.Options{Scenarios: scenarios}
+
+	for name, indata := range testdata {
+		indata := indata
+		t.Run(name, func(t *testing.T) {
+			execScheduler, err := local.NewExecutionScheduler(&minirunner.MiniRunner{Options: options}, logger)
+			require.NoError(t, err)
+			engine, err := core.NewEngine(execScheduler, options, logger)
+			require.NoError(t, err)
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			run, _, err := engine.Init(ctx, ctx)
+			require.NoError(t, err)
+
+			go func() { _ = run() }()
+			// wait for the executor to initialize to avoid a potential data race below
+			time.Sleep(100 * time.Millisecond)
+
+			rw := httptest.NewRecorder()
+			NewHandler().ServeHTTP(rw, newRequestWithEngine(
+				engine, "PATCH", "/v1/config", bytes.NewReader([]byte(indata.Body)),
+			))
+			res := rw.Result()
+
+			if !assert.Equal(t, indata.StatusCode, res.StatusCode) {
+				return
+			}
+			if indata.StatusCode != 200 {
+				return
+			}
+
+			var status Status
+			require.NoError(t, jsonapi.Unmarshal(rw.Body.Bytes(), &status))
+			assert.Equal(t, null.IntFrom(indata.VUs), status.VUs)
+			assert.Equal(t, null.IntFrom(indata.VUsMax), status.VUsMax)
+			assert.True(t, status.Elapsed > 0)
+		})
+	}
+}
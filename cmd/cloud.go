@@ -123,7 +123,9 @@ This will execute the test on the k6 cloud service. Use "k6 login cloud" to auth
 		if err != nil {
 			return err
 		}
-		conf, err := getConsolidatedConfig(afero.NewOsFs(), Config{Options: cliOpts}, r)
+		cliConf := Config{Options: cliOpts}
+		cliConf.Collectors.Cloud.ProjectID = getNullInt64(cmd.Flags(), "cloud-project-id")
+		conf, err := getConsolidatedConfig(afero.NewOsFs(), cliConf, r)
 		if err != nil {
 			return err
 		}
@@ -182,7 +184,7 @@ This will execute the test on the k6 cloud service. Use "k6 login cloud" to auth
 		if _, ok := tmpCloudConfig["name"]; !ok && cloudConfig.Name.Valid {
 			tmpCloudConfig["name"] = cloudConfig.Name
 		}
-		if _, ok := tmpCloudConfig["projectID"]; !ok && cloudConfig.ProjectID.Valid {
+		if cloudConfig.ProjectID.Valid { // the script's one was already overridden above, if needed
 			tmpCloudConfig["projectID"] = cloudConfig.ProjectID
 		}
 
@@ -289,7 +291,7 @@ This will execute the test on the k6 cloud service. Use "k6 login cloud" to auth
 			go func() {
 				logger.Debug("Connecting to cloud logs server...")
 				// TODO replace with another context
-				if err := cloudConfig.StreamLogsToLogger(context.Background(), logger, refID, 0); err != nil {
+				if err := cloudConfig.StreamLogsToLogger(context.Background(), logger, refID, 0, logger.GetLevel()); err != nil {
 					logger.WithError(err).Error("error while tailing cloud logs")
 				}
 			}()
@@ -345,6 +347,7 @@ func cloudCmdFlagSet() *pflag.FlagSet {
 	flags.SortFlags = false
 	flags.AddFlagSet(optionFlagSet())
 	flags.AddFlagSet(runtimeOptionFlagSet(false))
+	flags.AddFlagSet(cloudProjectIDFlagSet())
 
 	// TODO: Figure out a better way to handle the CLI flags:
 	// - the default value is specified in this way so we don't overwrire whatever
@@ -0,0 +1,105 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+//nolint:gochecknoglobals
+var cloudLogsLevel = "info"
+
+//nolint:gochecknoglobals
+var cloudLogsCmd = &cobra.Command{
+	Use:   "logs <run-id>",
+	Short: "Stream the logs of a cloud test run",
+	Long: `Stream the logs of a cloud test run.
+
+The logs of the VUs of a test run that is in progress on the k6 cloud service
+are shown in the same format as the logs of local test runs, until the command
+is interrupted. Use "k6 login cloud" to authenticate.`,
+	Example: `
+        k6 cloud logs 123456
+        k6 cloud logs --log-level warning 123456`[1:],
+	Args: exactArgsWithMsg(1, "arg should be the ID of a cloud test run"),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// TODO: don't use the Global logger
+		logger := logrus.StandardLogger()
+		level, err := logrus.ParseLevel(cloudLogsLevel)
+		if err != nil {
+			return err
+		}
+		// The logs are filtered by the cloud, so the local logger shouldn't
+		// drop the ones that were explicitly requested
+		if level > logger.GetLevel() {
+			logger.SetLevel(level)
+		}
+
+		conf, err := getConsolidatedConfig(afero.NewOsFs(), Config{}, nil)
+		if err != nil {
+			return err
+		}
+		cloudConfig := conf.Collectors.Cloud
+		if !cloudConfig.Token.Valid || cloudConfig.Token.String == "" {
+			return errors.New(`not logged in, please use "k6 login cloud"`)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		sigC := make(chan os.Signal, 1)
+		signal.Notify(sigC, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+		defer signal.Stop(sigC)
+		go func() {
+			select {
+			case sig := <-sigC:
+				logger.WithField("sig", sig).Debug("Stopping the cloud logs in response to signal...")
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+
+		logger.Debug("Connecting to cloud logs server...")
+		return cloudConfig.StreamLogsToLogger(ctx, logger, args[0], 0, level)
+	},
+}
+
+func cloudLogsCmdFlagSet() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("", pflag.ContinueOnError)
+	flags.SortFlags = false
+	flags.StringVar(&cloudLogsLevel, "log-level", cloudLogsLevel,
+		"the minimum level of the logs, one of: debug, info, warning, error")
+	return flags
+}
+
+func init() {
+	cloudCmd.AddCommand(cloudLogsCmd)
+	cloudLogsCmd.Flags().SortFlags = false
+	cloudLogsCmd.Flags().AddFlagSet(cloudLogsCmdFlagSet())
+}
@@ -33,6 +33,7 @@ import (
 
 	"github.com/loadimpact/k6/lib"
 	"github.com/loadimpact/k6/lib/consts"
+	"github.com/loadimpact/k6/lib/types"
 	"github.com/loadimpact/k6/loader"
 	"github.com/loadimpact/k6/stats"
 	"github.com/loadimpact/k6/stats/cloud"
@@ -67,6 +68,16 @@ func parseCollector(s string) (t, arg string) {
 	}
 }
 
+// pushInterval returns the global metricPushInterval option, if it's set, as
+// the default push interval for an output, and its own default otherwise. The
+// push interval in the output config still takes precedence over both.
+func pushInterval(defaultInterval types.NullDuration, conf Config) types.NullDuration {
+	if conf.MetricPushInterval.Valid {
+		return conf.MetricPushInterval
+	}
+	return defaultInterval
+}
+
 // TODO: totally refactor this...
 func getCollector(
 	logger logrus.FieldLogger,
@@ -76,7 +87,9 @@ func getCollector(
 	case collectorJSON:
 		return jsonc.New(logger, afero.NewOsFs(), arg)
 	case collectorInfluxDB:
-		config := influxdb.NewConfig().Apply(conf.Collectors.InfluxDB)
+		config := *influxdb.NewConfig()
+		config.PushInterval = pushInterval(config.PushInterval, conf)
+		config = config.Apply(conf.Collectors.InfluxDB)
 		if err := envconfig.Process("", &config); err != nil {
 			return nil, err
 		}
@@ -88,7 +101,9 @@ func getCollector(
 
 		return influxdb.New(logger, config)
 	case collectorCloud:
-		config := cloud.NewConfig().Apply(conf.Collectors.Cloud)
+		config := cloud.NewConfig()
+		config.MetricPushInterval = pushInterval(config.MetricPushInterval, conf)
+		config = config.Apply(conf.Collectors.Cloud)
 		if err := envconfig.Process("", &config); err != nil {
 			return nil, err
 		}
@@ -98,7 +113,9 @@ func getCollector(
 
 		return cloud.New(logger, config, src, conf.Options, executionPlan, consts.Version)
 	case collectorKafka:
-		config := kafka.NewConfig().Apply(conf.Collectors.Kafka)
+		config := kafka.NewConfig()
+		config.PushInterval = pushInterval(config.PushInterval, conf)
+		config = config.Apply(conf.Collectors.Kafka)
 		if err := envconfig.Process("", &config); err != nil {
 			return nil, err
 		}
@@ -112,14 +129,18 @@ func getCollector(
 
 		return kafka.New(logger, config)
 	case collectorStatsD:
-		config := common.NewConfig().Apply(conf.Collectors.StatsD)
+		config := common.NewConfig()
+		config.PushInterval = pushInterval(config.PushInterval, conf)
+		config = config.Apply(conf.Collectors.StatsD)
 		if err := envconfig.Process("k6_statsd", &config); err != nil {
 			return nil, err
 		}
 
 		return statsd.New(logger, config)
 	case collectorDatadog:
-		config := datadog.NewConfig().Apply(conf.Collectors.Datadog)
+		config := datadog.NewConfig()
+		config.PushInterval = pushInterval(config.PushInterval, conf)
+		config = config.Apply(conf.Collectors.Datadog)
 		if err := envconfig.Process("k6_datadog", &config); err != nil {
 			return nil, err
 		}
@@ -0,0 +1,53 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/loadimpact/k6/lib"
+	"github.com/loadimpact/k6/lib/testutils"
+	"github.com/loadimpact/k6/lib/types"
+	"github.com/loadimpact/k6/stats/influxdb"
+)
+
+func TestCollectorMetricPushInterval(t *testing.T) {
+	logger := testutils.NewLogger(t)
+	conf := Config{Options: lib.Options{MetricPushInterval: types.NullDurationFrom(5 * time.Second)}}
+
+	collector, err := getCollector(logger, collectorInfluxDB, "", nil, conf, nil)
+	require.NoError(t, err)
+	assert.Equal(t, types.NullDurationFrom(5*time.Second), collector.(*influxdb.Collector).Config.PushInterval)
+
+	// The push interval of the output itself has precedence
+	conf.Collectors.InfluxDB.PushInterval = types.NullDurationFrom(2 * time.Second)
+	collector, err = getCollector(logger, collectorInfluxDB, "", nil, conf, nil)
+	require.NoError(t, err)
+	assert.Equal(t, types.NullDurationFrom(2*time.Second), collector.(*influxdb.Collector).Config.PushInterval)
+
+	collector, err = getCollector(logger, collectorInfluxDB, "", nil, Config{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, time.Duration(collector.(*influxdb.Collector).Config.PushInterval.Duration))
+}
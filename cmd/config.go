@@ -59,6 +59,11 @@ func configFlagSet() *pflag.FlagSet {
 		"",
 		"output the end-of-test summary report to JSON file",
 	)
+	flags.Int64("show-waterfall", 0, "show a waterfall chart of the phases of the first `n` HTTP requests in the summary")
+	flags.Lookup("show-waterfall").NoOptDefVal = "5"
+	flags.String("output-dir", "", "write a run.json manifest, the summary.json summary export and the "+
+		"results.ndjson and results.csv outputs to the `directory`")
+	flags.AddFlagSet(cloudProjectIDFlagSet())
 	return flags
 }
 
@@ -71,6 +76,8 @@ type Config struct {
 	NoThresholds  null.Bool   `json:"noThresholds" envconfig:"K6_NO_THRESHOLDS"`
 	NoSummary     null.Bool   `json:"noSummary" envconfig:"K6_NO_SUMMARY"`
 	SummaryExport null.String `json:"summaryExport" envconfig:"K6_SUMMARY_EXPORT"`
+	ShowWaterfall null.Int    `json:"showWaterfall" envconfig:"K6_SHOW_WATERFALL"`
+	OutputDir     null.String `json:"outputDir" envconfig:"K6_OUTPUT_DIR"`
 
 	Collectors struct {
 		InfluxDB influxdb.Config `json:"influxdb"`
@@ -87,6 +94,9 @@ func (c Config) Validate() []error {
 	errors := c.Options.Validate()
 	//TODO: validate all of the other options... that we should have already been validating...
 	//TODO: maybe integrate an external validation lib: https://github.com/avelino/awesome-go#validation
+	if c.ShowWaterfall.Int64 < 0 {
+		errors = append(errors, fmt.Errorf("the number of requests in the waterfall chart can't be negative"))
+	}
 
 	return errors
 }
@@ -111,6 +121,12 @@ func (c Config) Apply(cfg Config) Config {
 	if cfg.SummaryExport.Valid {
 		c.SummaryExport = cfg.SummaryExport
 	}
+	if cfg.ShowWaterfall.Valid {
+		c.ShowWaterfall = cfg.ShowWaterfall
+	}
+	if cfg.OutputDir.Valid {
+		c.OutputDir = cfg.OutputDir
+	}
 	c.Collectors.InfluxDB = c.Collectors.InfluxDB.Apply(cfg.Collectors.InfluxDB)
 	c.Collectors.Cloud = c.Collectors.Cloud.Apply(cfg.Collectors.Cloud)
 	c.Collectors.Kafka = c.Collectors.Kafka.Apply(cfg.Collectors.Kafka)
@@ -130,7 +146,7 @@ func getConfig(flags *pflag.FlagSet) (Config, error) {
 	if err != nil {
 		return Config{}, err
 	}
-	return Config{
+	conf := Config{
 		Options:       opts,
 		Out:           out,
 		Linger:        getNullBool(flags, "linger"),
@@ -138,7 +154,19 @@ func getConfig(flags *pflag.FlagSet) (Config, error) {
 		NoThresholds:  getNullBool(flags, "no-thresholds"),
 		NoSummary:     getNullBool(flags, "no-summary"),
 		SummaryExport: getNullString(flags, "summary-export"),
-	}, nil
+		ShowWaterfall: getNullInt64(flags, "show-waterfall"),
+		OutputDir:     getNullString(flags, "output-dir"),
+	}
+	conf.Collectors.Cloud.ProjectID = getNullInt64(flags, "cloud-project-id")
+	return conf, nil
+}
+
+// cloudProjectIDFlagSet returns a FlagSet with the flag that overrides the
+// k6 Cloud project of the script, for both `k6 run --out cloud` and `k6 cloud`.
+func cloudProjectIDFlagSet() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("", 0)
+	flags.Int64("cloud-project-id", 0, "the k6 Cloud project `id` to use instead of the one in the script options")
+	return flags
 }
 
 // Reads the configuration file from the supplied filesystem and returns it and its path.
@@ -147,7 +175,15 @@ func getConfig(flags *pflag.FlagSet) (Config, error) {
 // an error will be returned.
 // If there's no custom config specified and no file exists in the default config path, it will
 // return an empty config struct, the default config location and *no* error.
+// The config is returned as it is in the file, so it can be written back to it.
 func readDiskConfig(fs afero.Fs) (Config, string, error) {
+	return readDiskConfigWithEnv(fs, nil)
+}
+
+// readDiskConfigWithEnv reads the configuration file like readDiskConfig, but
+// if getenv isn't nil, the ${VAR} placeholders in the string values of the
+// config are replaced with the values it returns for the variables.
+func readDiskConfigWithEnv(fs afero.Fs, getenv func(string) string) (Config, string, error) {
 	realConfigFilePath := configFilePath
 	if realConfigFilePath == "" {
 		// The user didn't specify K6_CONFIG or --config, use the default path
@@ -168,6 +204,11 @@ func readDiskConfig(fs afero.Fs) (Config, string, error) {
 	if err != nil {
 		return Config{}, realConfigFilePath, err
 	}
+	if getenv != nil {
+		if data, err = lib.ExpandEnvInJSON(data, getenv); err != nil {
+			return Config{}, realConfigFilePath, err
+		}
+	}
 	var conf Config
 	err = json.Unmarshal(data, &conf)
 	return conf, realConfigFilePath, err
@@ -220,7 +261,9 @@ func getConsolidatedConfig(fs afero.Fs, cliConf Config, runner lib.Runner) (conf
 	cliConf.Collectors.StatsD = common.NewConfig().Apply(cliConf.Collectors.StatsD)
 	cliConf.Collectors.Datadog = datadog.NewConfig().Apply(cliConf.Collectors.Datadog)
 
-	fileConf, _, err := readDiskConfig(fs)
+	// Only the config that's used for the test has its placeholders expanded,
+	// the logins write the file back with them
+	fileConf, _, err := readDiskConfigWithEnv(fs, os.Getenv)
 	if err != nil {
 		return conf, err
 	}
@@ -235,6 +278,9 @@ func getConsolidatedConfig(fs afero.Fs, cliConf Config, runner lib.Runner) (conf
 	}
 	conf = conf.Apply(envConf).Apply(cliConf)
 	conf = applyDefault(conf)
+	if conf, err = applyGitTags(conf, ""); err != nil {
+		return conf, err
+	}
 
 	// TODO(imiric): Move this validation where it makes sense in the configuration
 	// refactor of #883. This repeats the trend stats validation already done
@@ -293,6 +339,14 @@ func validateConfig(conf Config, isExecutable func(string) bool) error {
 }
 
 func validateScenarioConfig(conf lib.ExecutorConfig, isExecutable func(string) bool) error {
+	if probs := conf.GetExecProbabilities(); len(probs) > 0 {
+		for _, p := range probs {
+			if !isExecutable(p.Exec) {
+				return fmt.Errorf("executor %s: function '%s' not found in exports", conf.GetName(), p.Exec)
+			}
+		}
+		return nil
+	}
 	execFn := conf.GetExec()
 	if !isExecutable(execFn) {
 		return fmt.Errorf("executor %s: function '%s' not found in exports", conf.GetName(), execFn)
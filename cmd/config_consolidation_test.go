@@ -269,6 +269,19 @@ func getConfigConsolidationTestCases() []configConsolidationTestCase {
 				cli: []string{"--config", "/my/config.file"},
 			}, exp{}, verifyConstLoopingVUs(I(8), 120*time.Second),
 		},
+		{
+			opts{
+				fs: defaultConfig(`{
+					"vus": 8, "duration": "${K6_TEST_DURATION}",
+					"userAgent": "k6 (${K6_TEST_AGENT})", "tags": {"env": "${K6_TEST_ENV}"}
+				}`),
+				env: []string{"K6_TEST_AGENT=consolidation", "K6_TEST_ENV=staging", "K6_TEST_DURATION=1m"},
+			}, exp{}, func(t *testing.T, c Config) {
+				verifyConstLoopingVUs(I(8), time.Minute)(t, c)
+				assert.Equal(t, null.StringFrom("k6 (consolidation)"), c.UserAgent)
+				assert.Equal(t, map[string]string{"env": "staging"}, c.RunTags.CloneTags())
+			},
+		},
 		{
 			opts{
 				fs:  defaultConfig(`{"stages": [{"duration": "20s", "target": 20}], "vus": 10}`),
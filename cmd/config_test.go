@@ -27,6 +27,7 @@ import (
 
 	"github.com/kelseyhightower/envconfig"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gopkg.in/guregu/null.v3"
 
 	"github.com/loadimpact/k6/lib"
@@ -88,6 +89,22 @@ func TestConfigCmd(t *testing.T) {
 	}
 }
 
+func TestConfigCloudProjectID(t *testing.T) {
+	fs := configFlagSet()
+	fs.AddFlagSet(optionFlagSet())
+	require.NoError(t, fs.Parse([]string{}))
+	config, err := getConfig(fs)
+	require.NoError(t, err)
+	assert.False(t, config.Collectors.Cloud.ProjectID.Valid)
+
+	fs = configFlagSet()
+	fs.AddFlagSet(optionFlagSet())
+	require.NoError(t, fs.Parse([]string{"--cloud-project-id", "12345"}))
+	config, err = getConfig(fs)
+	require.NoError(t, err)
+	assert.Equal(t, null.IntFrom(12345), config.Collectors.Cloud.ProjectID)
+}
+
 func TestConfigEnv(t *testing.T) {
 	testdata := map[struct{ Name, Key string }]map[string]func(Config){
 		{"Linger", "K6_LINGER"}: {
@@ -122,6 +139,21 @@ func TestConfigEnv(t *testing.T) {
 	}
 }
 
+func TestReadDiskConfigEnvPlaceholders(t *testing.T) {
+	fs := defaultConfig(`{"userAgent": "k6 (${K6_TEST_AGENT})"}`)
+
+	// The logins write the config back, so its placeholders have to be kept
+	conf, _, err := readDiskConfig(fs)
+	require.NoError(t, err)
+	assert.Equal(t, null.StringFrom("k6 (${K6_TEST_AGENT})"), conf.UserAgent)
+
+	conf, _, err = readDiskConfigWithEnv(fs, func(key string) string {
+		return map[string]string{"K6_TEST_AGENT": "tests"}[key]
+	})
+	require.NoError(t, err)
+	assert.Equal(t, null.StringFrom("k6 (tests)"), conf.UserAgent)
+}
+
 func TestConfigApply(t *testing.T) {
 	t.Run("Linger", func(t *testing.T) {
 		conf := Config{}.Apply(Config{Linger: null.BoolFrom(true)})
@@ -169,6 +201,15 @@ func TestDeriveAndValidateConfig(t *testing.T) {
 			}}}}, false,
 			"executor per_vu_iters: function 'nonDefaultErr' not found in exports",
 		},
+		{"probabilityErr", Config{Options: lib.Options{Scenarios: lib.ScenarioConfigs{
+			"per_vu_iters": executor.PerVUIterationsConfig{BaseConfig: executor.BaseConfig{
+				Name: "per_vu_iters", Type: "per-vu-iterations", Probability: map[string]float64{"browse": 1}},
+				VUs:         null.IntFrom(1),
+				Iterations:  null.IntFrom(1),
+				MaxDuration: types.NullDurationFrom(time.Second),
+			}}}}, false,
+			"executor per_vu_iters: function 'browse' not found in exports",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -0,0 +1,137 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+
+	"github.com/loadimpact/k6/js/compiler"
+	"github.com/loadimpact/k6/js/format"
+)
+
+const notFormattedErrorCode = 1
+
+var (
+	formatCheck bool
+	formatDiff  bool
+)
+
+var formatCmd = &cobra.Command{
+	Use:   "format [file...]",
+	Short: "Format test scripts",
+	Long: `Format test scripts.
+
+Scripts are re-indented with two spaces, missing semicolons and the trailing
+commas of multi-line object and array literals are added, and the top-level
+import statements are sorted, k6 modules first. The files are formatted in
+place, unless --check or --diff are used.`,
+	Example: `
+  # Format a script in place.
+  k6 format script.js
+
+  # Fail if any of the scripts isn't formatted, e.g. in CI.
+  k6 format --check *.js
+
+  # Show the changes that formatting would make.
+  k6 format --diff script.js`[1:],
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		unformatted := 0
+		for _, filename := range args {
+			src, err := afero.ReadFile(defaultFs, filename)
+			if err != nil {
+				return err
+			}
+			formatted, err := formatScript(filename, src)
+			if err != nil {
+				return err
+			}
+			if bytes.Equal(src, formatted) {
+				continue
+			}
+			unformatted++
+
+			switch {
+			case formatDiff:
+				diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+					A:        difflib.SplitLines(string(src)),
+					B:        difflib.SplitLines(string(formatted)),
+					FromFile: filename,
+					ToFile:   filename + " (formatted)",
+					Context:  3,
+				})
+				if err != nil {
+					return err
+				}
+				fprintf(defaultWriter, "%s", diff)
+			case formatCheck:
+				fprintf(defaultWriter, "%s\n", filename)
+			default:
+				if err := afero.WriteFile(defaultFs, filename, formatted, 0644); err != nil {
+					return err
+				}
+			}
+		}
+
+		if formatCheck && unformatted > 0 {
+			return ExitCode{error: errors.Errorf("%d file(s) need formatting", unformatted), Code: notFormattedErrorCode}
+		}
+		return nil
+	},
+}
+
+// formatScript formats the given script. Since the formatter doesn't parse
+// the whole script, JS files are transpiled before and after formatting, to
+// make sure that formatting didn't break anything. Scripts that can't be
+// transpiled aren't formatted at all, since that couldn't be verified.
+func formatScript(filename string, src []byte) ([]byte, error) {
+	formatted, err := format.Source(src)
+	if err != nil {
+		return nil, errors.Wrapf(err, "couldn't format %s", filename)
+	}
+	if bytes.Equal(src, formatted) || filepath.Ext(filename) != ".js" {
+		return formatted, nil
+	}
+
+	// TODO: don't use the Global logger
+	c := compiler.New(logrus.StandardLogger())
+	if _, _, err := c.Transform(string(src), filename); err != nil {
+		return nil, errors.Errorf("couldn't format %s, it isn't a valid script: %s", filename, err)
+	}
+	if _, _, err := c.Transform(string(formatted), filename); err != nil {
+		return nil, errors.Errorf("formatting %s would break it, please report this as a bug: %s", filename, err)
+	}
+	return formatted, nil
+}
+
+func init() {
+	RootCmd.AddCommand(formatCmd)
+	formatCmd.Flags().SortFlags = false
+	formatCmd.Flags().BoolVar(&formatCheck, "check", false, "don't modify the files, exit with code 1 if any of them needs formatting")
+	formatCmd.Flags().BoolVar(&formatDiff, "diff", false, "don't modify the files, print a unified diff of the changes instead")
+}
@@ -0,0 +1,100 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatCmd(t *testing.T) {
+	oldFs, oldWriter := defaultFs, defaultWriter
+	defer func() {
+		formatCheck, formatDiff = false, false
+		defaultFs, defaultWriter = oldFs, oldWriter
+	}()
+
+	const (
+		unformatted = "import http from \"k6/http\"\nexport default function() {\n    http.get(\"https://test.k6.io\")\n}\n"
+		formatted   = "import http from \"k6/http\";\nexport default function() {\n  http.get(\"https://test.k6.io\");\n}\n"
+	)
+	setup := func(check, diff bool) *bytes.Buffer {
+		formatCheck, formatDiff = check, diff
+		defaultFs = afero.NewMemMapFs()
+		require.NoError(t, afero.WriteFile(defaultFs, "/script.js", []byte(unformatted), 0644))
+		require.NoError(t, afero.WriteFile(defaultFs, "/formatted.js", []byte(formatted), 0644))
+		buf := &bytes.Buffer{}
+		defaultWriter = buf
+		return buf
+	}
+	readScript := func() string {
+		data, err := afero.ReadFile(defaultFs, "/script.js")
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	t.Run("InPlace", func(t *testing.T) {
+		setup(false, false)
+		require.NoError(t, formatCmd.RunE(formatCmd, []string{"/script.js", "/formatted.js"}))
+		assert.Equal(t, formatted, readScript())
+	})
+
+	t.Run("Check", func(t *testing.T) {
+		out := setup(true, false)
+		err := formatCmd.RunE(formatCmd, []string{"/script.js", "/formatted.js"})
+		if assert.Error(t, err) {
+			assert.Equal(t, notFormattedErrorCode, err.(ExitCode).Code)
+			assert.EqualError(t, err, "1 file(s) need formatting")
+		}
+		assert.Equal(t, "/script.js\n", out.String())
+		assert.Equal(t, unformatted, readScript())
+
+		assert.NoError(t, formatCmd.RunE(formatCmd, []string{"/formatted.js"}))
+	})
+
+	t.Run("Diff", func(t *testing.T) {
+		out := setup(false, true)
+		require.NoError(t, formatCmd.RunE(formatCmd, []string{"/script.js"}))
+		assert.Contains(t, out.String(), "--- /script.js\n+++ /script.js (formatted)\n")
+		assert.Contains(t, out.String(), "\n-    http.get(\"https://test.k6.io\")\n+  http.get(\"https://test.k6.io\");\n")
+		assert.Equal(t, unformatted, readScript())
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		setup(false, false)
+		require.NoError(t, afero.WriteFile(defaultFs, "/invalid.js", []byte("foo(\n"), 0644))
+		err := formatCmd.RunE(formatCmd, []string{"/invalid.js"})
+		assert.EqualError(t, err, "couldn't format /invalid.js: line 1: '(' is never closed")
+
+		require.NoError(t, afero.WriteFile(defaultFs, "/invalid.js", []byte("foo bar\n"), 0644))
+		err = formatCmd.RunE(formatCmd, []string{"/invalid.js"})
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "couldn't format /invalid.js, it isn't a valid script: ")
+		}
+		data, err := afero.ReadFile(defaultFs, "/invalid.js")
+		require.NoError(t, err)
+		assert.Equal(t, "foo bar\n", string(data))
+	})
+}
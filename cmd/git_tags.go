@@ -0,0 +1,80 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2019 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/loadimpact/k6/stats"
+)
+
+// applyGitTags adds the git metadata of the repository in dir, or in the
+// current directory if it's empty, to the run tags if the gitTags option is
+// enabled. Tags with the same names that were set explicitly take precedence.
+func applyGitTags(conf Config, dir string) (Config, error) {
+	if !conf.GitTags.Bool {
+		return conf, nil
+	}
+	tags, err := readGitTags(dir)
+	if err != nil {
+		return conf, fmt.Errorf("couldn't read the git metadata for the gitTags option: %w", err)
+	}
+	for k, v := range conf.RunTags.CloneTags() {
+		tags[k] = v
+	}
+	conf.RunTags = stats.IntoSampleTags(&tags)
+	return conf, nil
+}
+
+// readGitTags returns the current commit, branch and tag of the git repository
+// in dir. The branch is omitted for a detached HEAD, and the tag if the commit
+// isn't tagged.
+func readGitTags(dir string) (map[string]string, error) {
+	commit, err := runGit(dir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	tags := map[string]string{"git_commit": commit}
+	if branch, err := runGit(dir, "rev-parse", "--abbrev-ref", "HEAD"); err == nil && branch != "HEAD" {
+		tags["git_branch"] = branch
+	}
+	if tag, err := runGit(dir, "describe", "--tags", "--exact-match", "HEAD"); err == nil {
+		tags["git_tag"] = tag
+	}
+	return tags, nil
+}
+
+func runGit(dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", args...) //nolint:gosec
+	cmd.Dir = dir
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,95 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2019 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/loadimpact/k6/lib"
+	"github.com/loadimpact/k6/stats"
+)
+
+func TestApplyGitTags(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git isn't installed")
+	}
+	dir, err := ioutil.TempDir("", "k6-git-tags")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	git := func(args ...string) {
+		args = append([]string{"-c", "user.name=k6", "-c", "user.email=k6@example.com"}, args...)
+		cmd := exec.Command("git", args...) //nolint:gosec
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+	git("init", "-q")
+	git("checkout", "-q", "-b", "main")
+	git("commit", "-q", "--allow-empty", "-m", "initial")
+	commit, err := runGit(dir, "rev-parse", "HEAD")
+	require.NoError(t, err)
+
+	t.Run("disabled", func(t *testing.T) {
+		conf, err := applyGitTags(Config{}, dir)
+		require.NoError(t, err)
+		assert.Nil(t, conf.RunTags)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		conf, err := applyGitTags(Config{Options: lib.Options{GitTags: null.BoolFrom(true)}}, dir)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"git_commit": commit, "git_branch": "main"}, conf.RunTags.CloneTags())
+	})
+
+	t.Run("tagged", func(t *testing.T) {
+		git("tag", "v1.0.0")
+		defer git("tag", "-d", "v1.0.0")
+		conf, err := applyGitTags(Config{Options: lib.Options{GitTags: null.BoolFrom(true)}}, dir)
+		require.NoError(t, err)
+		assert.Equal(t, "v1.0.0", conf.RunTags.CloneTags()["git_tag"])
+	})
+
+	t.Run("explicit tags take precedence", func(t *testing.T) {
+		runTags := stats.IntoSampleTags(&map[string]string{"git_branch": "release", "env": "ci"})
+		conf, err := applyGitTags(Config{Options: lib.Options{GitTags: null.BoolFrom(true), RunTags: runTags}}, dir)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"git_commit": commit, "git_branch": "release", "env": "ci",
+		}, conf.RunTags.CloneTags())
+	})
+
+	t.Run("not a repository", func(t *testing.T) {
+		emptyDir, err := ioutil.TempDir("", "k6-git-tags-empty")
+		require.NoError(t, err)
+		defer func() { _ = os.RemoveAll(emptyDir) }()
+		_, err = applyGitTags(Config{Options: lib.Options{GitTags: null.BoolFrom(true)}}, emptyDir)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "couldn't read the git metadata")
+	})
+}
@@ -0,0 +1,186 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+//go:embed templates
+var newTemplates embed.FS
+
+const defaultNewTemplate = "rest-api"
+
+var (
+	newProjectName string
+	newTemplate    = defaultNewTemplate
+)
+
+// newTemplateData is what the project and script templates are rendered with.
+type newTemplateData struct {
+	Name     string
+	Template string
+}
+
+// newCmd represents the new command
+var newCmd = &cobra.Command{
+	Use:   "new",
+	Short: "Generate a new test project or script",
+	Long: `Generate a new test project or script from one of the built-in templates.
+
+Available templates: ` + strings.Join(newTemplateNames(), ", ") + `.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmd.Usage()
+	},
+}
+
+var newProjectCmd = &cobra.Command{
+	Use:   "project",
+	Short: "Generate a new test project directory",
+	Long: `Generate a new test project directory.
+
+The project contains a script.js test script, a package.json and a tsconfig.json
+for editor support of the k6 APIs, an empty data/ directory and a README.md.`,
+	Example: `
+  # Generate the api-test/ project directory with a REST API test.
+  k6 new project --name api-test --template rest-api`[1:],
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if newProjectName == "" {
+			return errors.New("the project name must be specified with --name")
+		}
+		script, err := renderNewTemplate(path.Join("scripts", newTemplate+".js"))
+		if err != nil {
+			return err
+		}
+
+		dir := filepath.Clean(newProjectName)
+		if exists, err := afero.Exists(defaultFs, dir); err != nil {
+			return err
+		} else if exists {
+			return errors.Errorf("'%s' already exists", dir)
+		}
+
+		files := map[string][]byte{"script.js": script}
+		for _, name := range []string{"package.json", "tsconfig.json", "README.md"} {
+			if files[name], err = renderNewTemplate(path.Join("project", name)); err != nil {
+				return err
+			}
+		}
+
+		if err := defaultFs.MkdirAll(filepath.Join(dir, "data"), 0755); err != nil {
+			return err
+		}
+		for name, data := range files {
+			if err := afero.WriteFile(defaultFs, filepath.Join(dir, name), data, 0644); err != nil {
+				return err
+			}
+		}
+
+		fprintf(defaultWriter, "Created the %s project in %s\n", newTemplate, dir)
+		return nil
+	},
+}
+
+var newScriptCmd = &cobra.Command{
+	Use:   "script [filename]",
+	Short: "Generate a new test script",
+	Long:  `Generate a new test script in the current directory.`,
+	Example: `
+  # Generate the login.js script, which logs in once in setup().
+  k6 new script login.js --template authenticated`[1:],
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		script, err := renderNewTemplate(path.Join("scripts", newTemplate+".js"))
+		if err != nil {
+			return err
+		}
+
+		filename := args[0]
+		if exists, err := afero.Exists(defaultFs, filename); err != nil {
+			return err
+		} else if exists {
+			return errors.Errorf("'%s' already exists", filename)
+		}
+		if err := afero.WriteFile(defaultFs, filename, script, 0644); err != nil {
+			return err
+		}
+
+		fprintf(defaultWriter, "Created the %s script %s\n", newTemplate, filename)
+		return nil
+	},
+}
+
+// newTemplateNames returns the sorted names of the built-in script templates.
+func newTemplateNames() []string {
+	entries, err := newTemplates.ReadDir("templates/scripts")
+	if err != nil {
+		panic(err) // the templates are embedded, so this can't happen
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), ".js"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// renderNewTemplate renders the embedded template file with the given path,
+// relative to the templates directory, for the current flags.
+func renderNewTemplate(name string) ([]byte, error) {
+	data, err := newTemplates.ReadFile(path.Join("templates", name))
+	if err != nil {
+		return nil, errors.Errorf(
+			"unknown template '%s', available templates: %s", newTemplate, strings.Join(newTemplateNames(), ", "),
+		)
+	}
+
+	tmpl, err := template.New(name).Parse(string(data))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, newTemplateData{Name: newProjectName, Template: newTemplate}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func init() {
+	RootCmd.AddCommand(newCmd)
+	newCmd.AddCommand(newProjectCmd, newScriptCmd)
+
+	templateUsage := fmt.Sprintf("template `name`, one of: %s", strings.Join(newTemplateNames(), ", "))
+	newProjectCmd.Flags().StringVar(&newProjectName, "name", newProjectName, "project `name`, also used for its directory")
+	newProjectCmd.Flags().StringVarP(&newTemplate, "template", "t", newTemplate, templateUsage)
+	newScriptCmd.Flags().StringVarP(&newTemplate, "template", "t", newTemplate, templateUsage)
+}
@@ -0,0 +1,102 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCmd(t *testing.T) {
+	oldFs, oldWriter := defaultFs, defaultWriter
+	defer func() {
+		newProjectName, newTemplate = "", defaultNewTemplate
+		defaultFs, defaultWriter = oldFs, oldWriter
+	}()
+
+	assert.Equal(t, []string{"authenticated", "rest-api", "websocket"}, newTemplateNames())
+
+	t.Run("Project", func(t *testing.T) {
+		defaultFs = afero.NewMemMapFs()
+		defaultWriter = &bytes.Buffer{}
+		newProjectName, newTemplate = "api-test", "websocket"
+
+		require.NoError(t, newProjectCmd.RunE(newProjectCmd, nil))
+
+		script, err := afero.ReadFile(defaultFs, filepath.Join("api-test", "script.js"))
+		require.NoError(t, err)
+		assert.Contains(t, string(script), `import ws from "k6/ws";`)
+
+		pkg, err := afero.ReadFile(defaultFs, filepath.Join("api-test", "package.json"))
+		require.NoError(t, err)
+		var pkgJSON struct {
+			Name            string            `json:"name"`
+			DevDependencies map[string]string `json:"devDependencies"`
+		}
+		require.NoError(t, json.Unmarshal(pkg, &pkgJSON))
+		assert.Equal(t, "api-test", pkgJSON.Name)
+		assert.Contains(t, pkgJSON.DevDependencies, "@types/k6")
+
+		for _, name := range []string{"tsconfig.json", "README.md", "data"} {
+			exists, err := afero.Exists(defaultFs, filepath.Join("api-test", name))
+			assert.NoError(t, err)
+			assert.True(t, exists, name)
+		}
+
+		err = newProjectCmd.RunE(newProjectCmd, nil)
+		assert.EqualError(t, err, "'api-test' already exists")
+	})
+
+	t.Run("ProjectWithoutName", func(t *testing.T) {
+		defaultFs = afero.NewMemMapFs()
+		newProjectName, newTemplate = "", defaultNewTemplate
+		err := newProjectCmd.RunE(newProjectCmd, nil)
+		assert.EqualError(t, err, "the project name must be specified with --name")
+	})
+
+	t.Run("Script", func(t *testing.T) {
+		defaultFs = afero.NewMemMapFs()
+		defaultWriter = &bytes.Buffer{}
+		newTemplate = "authenticated"
+
+		require.NoError(t, newScriptCmd.RunE(newScriptCmd, []string{"login.js"}))
+		script, err := afero.ReadFile(defaultFs, "login.js")
+		require.NoError(t, err)
+		assert.Contains(t, string(script), "export function setup()")
+
+		err = newScriptCmd.RunE(newScriptCmd, []string{"login.js"})
+		assert.EqualError(t, err, "'login.js' already exists")
+	})
+
+	t.Run("UnknownTemplate", func(t *testing.T) {
+		defaultFs = afero.NewMemMapFs()
+		newTemplate = "soap"
+		err := newScriptCmd.RunE(newScriptCmd, []string{"script.js"})
+		assert.EqualError(t, err,
+			"unknown template 'soap', available templates: authenticated, rest-api, websocket")
+	})
+}
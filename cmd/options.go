@@ -63,11 +63,14 @@ func optionFlagSet() *pflag.FlagSet {
 	flags.Int64("batch", 20, "max parallel batch reqs")
 	flags.Int64("batch-per-host", 6, "max parallel batch reqs per host")
 	flags.Int64("rps", 0, "limit requests per second")
-	flags.String("user-agent", fmt.Sprintf("k6/%s (https://k6.io/)", consts.Version), "user agent for http requests")
+	flags.String("user-agent", fmt.Sprintf("k6/%s (https://k6.io/)", consts.Version), "user agent for http requests, an empty value disables the User-Agent header")
 	flags.String("http-debug", "", "log all HTTP requests and responses. Excludes body by default. To include body use '--http-debug=full'")
 	flags.Lookup("http-debug").NoOptDefVal = "headers"
 	flags.Bool("insecure-skip-tls-verify", false, "skip verification of TLS certificates")
+	flags.StringSlice("trusted-proxy-ca", nil,
+		"trust the CA certificates in a PEM `file`, e.g. of an intercepting proxy, in addition to the system ones; relative paths are resolved from the script's directory")
 	flags.Bool("no-connection-reuse", false, "disable keep-alive connections")
+	flags.String("ip-version", "", "only connect over `ipv4` or `ipv6`, or race them with 'any'")
 	flags.Bool("no-vu-connection-reuse", false, "don't reuse connections between iterations")
 	flags.Duration("min-iteration-duration", 0, "minimum amount of time k6 will take executing a single iteration")
 	flags.BoolP("throw", "w", false, "throw warnings (like failed http requests) as errors")
@@ -80,7 +83,8 @@ func optionFlagSet() *pflag.FlagSet {
 		strings.Join(lib.DefaultSummaryTrendStats, ","),
 	)
 	flags.StringSlice("summary-trend-stats", nil, sumTrendStatsHelp)
-	flags.String("summary-time-unit", "", "define the time unit used to display the trend stats. Possible units are: 's', 'ms' and 'us'")
+	flags.String("summary-time-unit", "", "define the time unit used to display the time values in the end-of-test "+
+		"summary and the summary export. Possible units are: 's', 'ms' and 'us'")
 	// system-tags must have a default value, but we can't specify it here, otherwiese, it will always override others.
 	// set it to nil here, and add the default in applyDefault() instead.
 	systemTagsCliHelpText := fmt.Sprintf(
@@ -89,8 +93,18 @@ func optionFlagSet() *pflag.FlagSet {
 	)
 	flags.StringSlice("system-tags", nil, systemTagsCliHelpText)
 	flags.StringSlice("tag", nil, "add a `tag` to be applied to all samples, as `[name]=[value]`")
+	flags.Bool("git-tags", false, "add the git_commit, git_branch and git_tag tags of the git repository "+
+		"in the current directory to all samples")
 	flags.String("console-output", "", "redirects the console logging to the provided output file")
 	flags.Bool("discard-response-bodies", false, "Read but don't process or save HTTP response bodies")
+	flags.Duration("metric-push-interval", time.Second, "how often the InfluxDB, Kafka, StatsD, Datadog and cloud "+
+		"outputs push the metrics, unless they have their own push interval set")
+	flags.Float64("trend-sketch-accuracy", 0, "store the Trend metrics in sketches with this relative accuracy, "+
+		"e.g. 0.01, instead of keeping all of their values")
+	flags.Bool("disable-compression", false, "don't request compressed HTTP responses with the Accept-Encoding header")
+	flags.Bool("request-coalescing", false, "send only one of the concurrent identical GET and HEAD requests without a body in a batch and share its response")
+	flags.Int64("abort-on-error-count", 0, "gracefully stop the test as soon as more than `n` HTTP requests have failed")
+	flags.Int64("random-seed", 0, "seed for the random data generated by k6/faker, to make it reproducible")
 	return flags
 }
 
@@ -111,9 +125,17 @@ func getOptions(flags *pflag.FlagSet) (lib.Options, error) {
 		InsecureSkipTLSVerify: getNullBool(flags, "insecure-skip-tls-verify"),
 		NoConnectionReuse:     getNullBool(flags, "no-connection-reuse"),
 		NoVUConnectionReuse:   getNullBool(flags, "no-vu-connection-reuse"),
+		IPVersion:             getNullString(flags, "ip-version"),
 		MinIterationDuration:  getNullDuration(flags, "min-iteration-duration"),
 		Throw:                 getNullBool(flags, "throw"),
 		DiscardResponseBodies: getNullBool(flags, "discard-response-bodies"),
+		MetricPushInterval:    getNullDuration(flags, "metric-push-interval"),
+		TrendSketchAccuracy:   getNullFloat(flags, "trend-sketch-accuracy"),
+		DisableCompression:    getNullBool(flags, "disable-compression"),
+		RequestCoalescing:     getNullBool(flags, "request-coalescing"),
+		RandomSeed:            getNullInt64(flags, "random-seed"),
+		GitTags:               getNullBool(flags, "git-tags"),
+		AbortOnErrorCount:     getNullInt64(flags, "abort-on-error-count"),
 		// Default values for options without CLI flags:
 		// TODO: find a saner and more dev-friendly and error-proof way to handle options
 		SetupTimeout:    types.NullDuration{Duration: types.Duration(60 * time.Second), Valid: false},
@@ -175,6 +197,14 @@ func getOptions(flags *pflag.FlagSet) (lib.Options, error) {
 		opts.SystemTags = stats.ToSystemTagSet(systemTagList)
 	}
 
+	if flags.Changed("trusted-proxy-ca") {
+		trustedProxyCAs, err := flags.GetStringSlice("trusted-proxy-ca")
+		if err != nil {
+			return opts, err
+		}
+		opts.TrustedProxyCAs = trustedProxyCAs
+	}
+
 	blacklistIPStrings, err := flags.GetStringSlice("blacklist-ip")
 	if err != nil {
 		return opts, err
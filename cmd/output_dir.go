@@ -0,0 +1,112 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2019 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/afero"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/loadimpact/k6/lib"
+	"github.com/loadimpact/k6/lib/consts"
+	"github.com/loadimpact/k6/lib/types"
+	"github.com/loadimpact/k6/stats"
+)
+
+// The standardized names of the files in the --output-dir directory.
+const (
+	outputDirManifestFile   = "run.json"
+	outputDirSummaryFile    = "summary.json"
+	outputDirResultsFile    = "results.ndjson"
+	outputDirCSVResultsFile = "results.csv"
+)
+
+// runManifest is the run.json file that is written to the --output-dir
+// directory when the test starts, so tools can find out what the run produced.
+type runManifest struct {
+	StartTime time.Time          `json:"startTime"`
+	Script    string             `json:"script"`
+	Version   string             `json:"version"`
+	Options   runManifestOptions `json:"options"`
+	Files     []string           `json:"files"`
+}
+
+// runManifestOptions are the options of the test run that are written to the
+// run manifest. They are only the ones that describe the load and its checks,
+// the others can have secrets, like the TLS client keys or the cloud token.
+type runManifestOptions struct {
+	VUs        null.Int                    `json:"vus"`
+	Duration   types.NullDuration          `json:"duration"`
+	Iterations null.Int                    `json:"iterations"`
+	Stages     []lib.Stage                 `json:"stages"`
+	Scenarios  lib.ScenarioConfigs         `json:"scenarios,omitempty"`
+	Thresholds map[string]stats.Thresholds `json:"thresholds"`
+	Tags       *stats.SampleTags           `json:"tags"`
+}
+
+// prepareOutputDir creates the --output-dir directory and writes the run
+// manifest to it, and returns the config with the summary export and the JSON
+// and CSV outputs set to the files in it. A summary export file that was set
+// explicitly is kept, and the outputs are added to the other --out ones.
+func prepareOutputDir(fs afero.Fs, conf Config, script string, startTime time.Time) (Config, error) {
+	dir := conf.OutputDir.String
+	if err := fs.MkdirAll(dir, 0o755); err != nil {
+		return conf, fmt.Errorf("couldn't create the output directory: %w", err)
+	}
+
+	files := []string{outputDirResultsFile, outputDirCSVResultsFile}
+	if conf.SummaryExport.ValueOrZero() == "" {
+		conf.SummaryExport = null.StringFrom(filepath.Join(dir, outputDirSummaryFile))
+		files = append(files, outputDirSummaryFile)
+	}
+	conf.Out = append(append([]string{}, conf.Out...),
+		collectorJSON+"="+filepath.Join(dir, outputDirResultsFile),
+		collectorCSV+"="+filepath.Join(dir, outputDirCSVResultsFile),
+	)
+
+	opts := conf.Options
+	manifest, err := json.MarshalIndent(runManifest{
+		StartTime: startTime,
+		Script:    script,
+		Version:   consts.Version,
+		Options: runManifestOptions{
+			VUs:        opts.VUs,
+			Duration:   opts.Duration,
+			Iterations: opts.Iterations,
+			Stages:     opts.Stages,
+			Scenarios:  opts.Scenarios,
+			Thresholds: opts.Thresholds,
+			Tags:       opts.RunTags,
+		},
+		Files: files,
+	}, "", "  ")
+	if err != nil {
+		return conf, err
+	}
+	if err := afero.WriteFile(fs, filepath.Join(dir, outputDirManifestFile), manifest, 0o644); err != nil {
+		return conf, fmt.Errorf("couldn't write the run manifest: %w", err)
+	}
+	return conf, nil
+}
@@ -0,0 +1,97 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2019 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/loadimpact/k6/lib"
+	"github.com/loadimpact/k6/lib/consts"
+)
+
+func TestPrepareOutputDir(t *testing.T) {
+	startTime := time.Date(2020, 10, 15, 12, 0, 0, 0, time.UTC)
+
+	t.Run("default files", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		dir := filepath.Join("/tmp", "run1", "nested")
+		conf := Config{
+			Options: lib.Options{
+				VUs:     null.IntFrom(5),
+				TLSAuth: []*lib.TLSAuth{{TLSAuthFields: lib.TLSAuthFields{Key: "secret key"}}},
+			},
+			Out:       []string{"influxdb=http://localhost:8086/k6"},
+			OutputDir: null.StringFrom(dir),
+		}
+		conf, err := prepareOutputDir(fs, conf, "script.js", startTime)
+		require.NoError(t, err)
+		assert.Equal(t, null.StringFrom(filepath.Join(dir, "summary.json")), conf.SummaryExport)
+		assert.Equal(t, []string{
+			"influxdb=http://localhost:8086/k6",
+			"json=" + filepath.Join(dir, "results.ndjson"),
+			"csv=" + filepath.Join(dir, "results.csv"),
+		}, conf.Out)
+
+		data, err := afero.ReadFile(fs, filepath.Join(dir, "run.json"))
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "secret key")
+		var manifest struct {
+			StartTime time.Time              `json:"startTime"`
+			Script    string                 `json:"script"`
+			Version   string                 `json:"version"`
+			Options   map[string]interface{} `json:"options"`
+			Files     []string               `json:"files"`
+		}
+		require.NoError(t, json.Unmarshal(data, &manifest))
+		assert.True(t, startTime.Equal(manifest.StartTime))
+		assert.Equal(t, "script.js", manifest.Script)
+		assert.Equal(t, consts.Version, manifest.Version)
+		assert.Equal(t, float64(5), manifest.Options["vus"])
+		assert.NotContains(t, manifest.Options, "tlsAuth")
+		assert.Equal(t, []string{"results.ndjson", "results.csv", "summary.json"}, manifest.Files)
+	})
+
+	t.Run("explicit summary export", func(t *testing.T) {
+		dir := filepath.Join("/tmp", "run2")
+		conf := Config{SummaryExport: null.StringFrom("export.json"), OutputDir: null.StringFrom(dir)}
+		conf, err := prepareOutputDir(afero.NewMemMapFs(), conf, "script.js", startTime)
+		require.NoError(t, err)
+		assert.Equal(t, null.StringFrom("export.json"), conf.SummaryExport)
+		assert.Equal(t, []string{
+			"json=" + filepath.Join(dir, "results.ndjson"), "csv=" + filepath.Join(dir, "results.csv"),
+		}, conf.Out)
+	})
+
+	t.Run("invalid directory", func(t *testing.T) {
+		fs := afero.NewReadOnlyFs(afero.NewMemMapFs())
+		_, err := prepareOutputDir(fs, Config{OutputDir: null.StringFrom("/tmp/run3")}, "script.js", startTime)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "couldn't create the output directory")
+	})
+}
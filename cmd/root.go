@@ -138,8 +138,11 @@ func rootCmdPersistentFlagSet() *pflag.FlagSet {
 	flags.BoolVarP(&quiet, "quiet", "q", false, "disable progress updates")
 	flags.BoolVar(&noColor, "no-color", false, "disable colored output")
 	flags.StringVar(&logOutput, "log-output", "stderr",
-		"change the output for k6 logs, possible values are stderr,stdout,none,loki[=host:port]")
-	flags.StringVar(&logFmt, "logformat", "", "log output format") // TODO rename to log-format and warn on old usage
+		"change the output for k6 logs, possible values are stderr,stdout,none,loki[=host:port],cloud")
+	flags.StringVar(&logFmt, "log-format", "",
+		"change the format of k6 logs, possible values are text,json,logfmt,gelf,raw")
+	flags.StringVar(&logFmt, "logformat", "", "log output format")
+	must(flags.MarkDeprecated("logformat", "use --log-format instead"))
 	flags.StringVarP(&address, "address", "a", "localhost:6565", "address for the api server")
 
 	// TODO: Fix... This default value needed, so both CLI flags and environment variables work
@@ -195,6 +198,10 @@ func setupLoggers(logger *logrus.Logger, logFmt string, logOutput string) error
 		logger.SetOutput(stdout)
 	case "none":
 		logger.SetOutput(ioutil.Discard)
+	case "cloud":
+		// The logs are also sent to the cloud by `k6 run`, once the test run
+		// is created by the cloud output
+		logger.SetOutput(stderr)
 	default:
 		fallbackLogger := &logrus.Logger{
 			Out:       os.Stderr,
@@ -224,6 +231,13 @@ func setupLoggers(logger *logrus.Logger, logFmt string, logOutput string) error
 	case "json":
 		logger.SetFormatter(&logrus.JSONFormatter{})
 		logger.Debug("Logger format: JSON")
+	case "logfmt":
+		logger.SetFormatter(log.LogfmtFormatter{})
+		logger.Debug("Logger format: LOGFMT")
+	case "gelf":
+		host, _ := os.Hostname()
+		logger.SetFormatter(log.GELFFormatter{Host: host})
+		logger.Debug("Logger format: GELF")
 	default:
 		logger.SetFormatter(&logrus.TextFormatter{ForceColors: stderrTTY, DisableColors: noColor})
 		logger.Debug("Logger format: TEXT")
@@ -26,9 +26,12 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"runtime"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -40,12 +43,14 @@ import (
 	"github.com/spf13/pflag"
 
 	"github.com/loadimpact/k6/api"
+	"github.com/loadimpact/k6/converter/har"
 	"github.com/loadimpact/k6/core"
 	"github.com/loadimpact/k6/core/local"
 	"github.com/loadimpact/k6/js"
 	"github.com/loadimpact/k6/lib"
 	"github.com/loadimpact/k6/lib/consts"
 	"github.com/loadimpact/k6/loader"
+	"github.com/loadimpact/k6/stats/cloud"
 	"github.com/loadimpact/k6/ui"
 	"github.com/loadimpact/k6/ui/pb"
 )
@@ -62,11 +67,22 @@ const (
 	invalidConfigErrorCode       = 104
 	externalAbortErrorCode       = 105
 	cannotStartRESTAPIErrorCode  = 106
+
+	dryRunFailedErrorCode       = 1
+	errorCountExceededErrorCode = 1
 )
 
 // TODO: fix this, global variables are not very testable...
 //nolint:gochecknoglobals
-var runType = os.Getenv("K6_TYPE")
+var (
+	runType           = os.Getenv("K6_TYPE")
+	runWatch          bool
+	runDryRun         bool
+	runCloudExec      bool
+	runHAR            string
+	runHARTimeScale   = 1.0
+	runCorrelateForms bool
+)
 
 // runCmd represents the run command.
 var runCmd = &cobra.Command{
@@ -93,269 +109,511 @@ a commandline interface for interacting with it.`,
   k6 run -u 0 -s 10s:100 -s 60s -s 10s:0
 
   # Send metrics to an influxdb server
-  k6 run -o influxdb=http://1.2.3.4:8086/k6`[1:],
-	Args: exactArgsWithMsg(1, "arg should either be \"-\", if reading script from stdin, or a path to a script file"),
+  k6 run -o influxdb=http://1.2.3.4:8086/k6
+
+  # Restart the test whenever the script or its local imports are changed.
+  k6 run --watch script.js
+
+  # Validate the script and its options, without running it.
+  k6 run --dry-run script.js
+
+  # Run the script during its development, without evaluating its thresholds.
+  k6 run --no-thresholds script.js
+
+  # Run the test on the k6 cloud, showing its progress and logs locally.
+  # The metrics and the end-of-test summary are only available in the cloud.
+  k6 run --cloud-exec script.js
+
+  # Play back the requests of a HAR file with 10 VUs for 60s, at twice the recorded speed.
+  k6 run --har recording.har --har-time-scale 0.5 -u 10 -d 60s`[1:],
+	Args: func(cmd *cobra.Command, args []string) error {
+		if runHAR != "" {
+			return exactArgsWithMsg(0, "no script can be specified when playing back a HAR file")(cmd, args)
+		}
+		return exactArgsWithMsg(1, "arg should either be \"-\", if reading script from stdin, or a path to a script file")(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// TODO: don't use a global... or maybe change the logger?
 		logger := logrus.StandardLogger()
 
+		if runHAR != "" {
+			switch {
+			case runCloudExec:
+				return errors.New("--har can't be used with --cloud-exec")
+			case runWatch:
+				return errors.New("--har can't be used with --watch")
+			}
+		} else if runCorrelateForms || cmd.Flags().Changed("har-time-scale") {
+			return errors.New("--correlate-forms and --har-time-scale can only be used with --har")
+		}
+
+		if runCloudExec {
+			switch {
+			case runWatch:
+				return errors.New("--cloud-exec can't be used with --watch")
+			case runDryRun:
+				return errors.New("--cloud-exec can't be used with --dry-run")
+			case cmd.Flags().Changed("out"):
+				return errors.New("--cloud-exec can't be used with --out, the metrics of cloud tests are stored in the cloud")
+			}
+			// This is the same as `k6 cloud`, which archives the script,
+			// uploads it, starts the test and follows its progress and logs
+			// until it's finished, stopping it on Ctrl+C. The cloud API doesn't
+			// expose the metric samples, so unlike local runs there is no live
+			// metric output and no end-of-test summary.
+			return cloudCmd.RunE(cmd, args)
+		}
+
 		// TODO: disable in quiet mode?
 		_, _ = BannerColor.Fprintf(stdout, "\n%s\n\n", consts.Banner())
 
-		logger.Debug("Initializing the runner...")
-
-		// Create the Runner.
-		pwd, err := os.Getwd()
+		runtimeOptions, err := getRuntimeOptions(cmd.Flags(), buildEnvMap(os.Environ()))
 		if err != nil {
 			return err
 		}
-		filename := args[0]
-		filesystems := loader.CreateFilesystems()
-		src, err := loader.ReadSource(logger, filename, pwd, filesystems, os.Stdin)
+		cliConf, err := getConfig(cmd.Flags())
 		if err != nil {
 			return err
 		}
 
-		runtimeOptions, err := getRuntimeOptions(cmd.Flags(), buildEnvMap(os.Environ()))
-		if err != nil {
-			return err
+		tr := &testRun{
+			cmd:            cmd,
+			logger:         logger,
+			runtimeOptions: runtimeOptions,
+			cliConf:        cliConf,
 		}
-
-		r, err := newRunner(logger, src, runType, filesystems, runtimeOptions)
-		if err != nil {
-			return err
+		if runHAR != "" {
+			tr.filename = runHAR
+			tr.harConfig = &har.PlaybackConfig{TimeScale: runHARTimeScale, CorrelateForms: runCorrelateForms}
+		} else {
+			tr.filename = args[0]
+		}
+		if runDryRun {
+			if runWatch {
+				return errors.New("--dry-run can't be used with --watch")
+			}
+			// Any error results in the same exit code, so it's easy to check
+			if err = tr.dryRun(); err != nil {
+				if e, ok := err.(ExitCode); ok {
+					e.Code = dryRunFailedErrorCode
+					return e
+				}
+				return ExitCode{error: err, Code: dryRunFailedErrorCode}
+			}
+			return nil
+		}
+		if runWatch {
+			return tr.watch()
 		}
 
-		logger.Debug("Getting the script options...")
+		stopCtx, stop := context.WithCancel(context.Background())
+		defer stop()
+		defer trapInterrupts(logger, stop, forceExit)()
 
-		cliConf, err := getConfig(cmd.Flags())
-		if err != nil {
-			return err
-		}
-		conf, err := getConsolidatedConfig(afero.NewOsFs(), cliConf, r)
+		src, r, err := tr.load(loader.CreateFilesystems())
 		if err != nil {
 			return err
 		}
+		return tr.run(stopCtx, src, r)
+	},
+}
 
-		conf, cerr := deriveAndValidateConfig(conf, r.IsExecutable)
-		if cerr != nil {
-			return ExitCode{error: cerr, Code: invalidConfigErrorCode}
-		}
+// testRun contains everything needed to run a test that doesn't depend on the
+// script, so it can be reused between the runs in --watch mode.
+type testRun struct {
+	cmd            *cobra.Command
+	logger         *logrus.Logger
+	filename       string
+	runtimeOptions lib.RuntimeOptions
+	cliConf        Config
+	// The playback config if the requests of a HAR file are played back
+	// instead of running a script.
+	harConfig *har.PlaybackConfig
+
+	// The number of the current run in --watch mode, 0 otherwise.
+	number int
+}
 
-		// Write options back to the runner too.
-		if err = r.SetOptions(conf.Options); err != nil {
-			return err
-		}
+// load reads the script and creates its runner.
+func (tr *testRun) load(filesystems map[string]afero.Fs) (*loader.SourceData, lib.Runner, error) {
+	tr.logger.Debug("Initializing the runner...")
 
-		// We prepare a bunch of contexts:
-		//  - The runCtx is cancelled as soon as the Engine's run() lambda finishes,
-		//    and can trigger things like the usage report and end of test summary.
-		//    Crucially, metrics processing by the Engine will still work after this
-		//    context is cancelled!
-		//  - The lingerCtx is cancelled by Ctrl+C, and is used to wait for that
-		//    event when k6 was ran with the --linger option.
-		//  - The globalCtx is cancelled only after we're completely done with the
-		//    test execution and any --linger has been cleared, so that the Engine
-		//    can start winding down its metrics processing.
-		globalCtx, globalCancel := context.WithCancel(context.Background())
-		defer globalCancel()
-		lingerCtx, lingerCancel := context.WithCancel(globalCtx)
-		defer lingerCancel()
-		runCtx, runCancel := context.WithCancel(lingerCtx)
-		defer runCancel()
-
-		// Create a local execution scheduler wrapping the runner.
-		logger.Debug("Initializing the execution scheduler...")
-		execScheduler, err := local.NewExecutionScheduler(r, logger)
-		if err != nil {
+	if tr.harConfig != nil {
+		return tr.loadHAR(filesystems)
+	}
+
+	pwd, err := os.Getwd()
+	if err != nil {
+		return nil, nil, err
+	}
+	src, err := loader.ReadSource(tr.logger, tr.filename, pwd, filesystems, os.Stdin)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r, err := newRunner(tr.logger, src, runType, filesystems, tr.runtimeOptions)
+	if err != nil {
+		return nil, nil, err
+	}
+	return src, r, nil
+}
+
+// loadHAR generates the playback script of the HAR file and creates its runner.
+func (tr *testRun) loadHAR(filesystems map[string]afero.Fs) (*loader.SourceData, lib.Runner, error) {
+	filename, err := filepath.Abs(tr.filename)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := os.Open(filename) //nolint:gosec
+	if err != nil {
+		return nil, nil, err
+	}
+	h, err := har.Decode(f)
+	_ = f.Close()
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "couldn't read the HAR file %s", tr.filename)
+	}
+	script, err := har.Playback(h, *tr.harConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	src := &loader.SourceData{URL: &url.URL{Scheme: "file", Path: filepath.ToSlash(filename)}, Data: []byte(script)}
+	r, err := js.New(tr.logger, src, filesystems, tr.runtimeOptions)
+	if err != nil {
+		return nil, nil, err
+	}
+	return src, r, nil
+}
+
+// getConfig consolidates and validates the config of the test and writes
+// its options back to the runner.
+func (tr *testRun) getConfig(r lib.Runner) (Config, error) {
+	tr.logger.Debug("Getting the script options...")
+
+	conf, err := getConsolidatedConfig(afero.NewOsFs(), tr.cliConf, r)
+	if err != nil {
+		return conf, err
+	}
+
+	conf, cerr := deriveAndValidateConfig(conf, r.IsExecutable)
+	if cerr != nil {
+		return conf, ExitCode{error: cerr, Code: invalidConfigErrorCode}
+	}
+	for _, warning := range conf.TLSCipherSuitesWarnings() {
+		tr.logger.Warn(warning)
+	}
+
+	// Write options back to the runner too.
+	return conf, r.SetOptions(conf.Options)
+}
+
+// dryRun loads the script, validates the config of the test and prints it,
+// without running anything besides the init context of the script, which is
+// run when the runner is created.
+func (tr *testRun) dryRun() error {
+	_, r, err := tr.load(loader.CreateFilesystems())
+	if err != nil {
+		return err
+	}
+	conf, err := tr.getConfig(r)
+	if err != nil {
+		return err
+	}
+
+	tr.logger.Debug("Initializing the execution scheduler...")
+	execScheduler, err := local.NewExecutionScheduler(r, tr.logger)
+	if err != nil {
+		return err
+	}
+
+	output := "-"
+	if len(conf.Out) > 0 {
+		output = strings.Join(conf.Out, "; ")
+	}
+	printExecutionDescription(
+		"dry run", tr.filename, output, conf, execScheduler.GetState().ExecutionTuple,
+		execScheduler.GetExecutionPlan(), nil)
+
+	data, err := json.MarshalIndent(conf.Options, "  ", "  ")
+	if err != nil {
+		return err
+	}
+	fprintf(stdout, "  options: %s\n\n", data)
+	fprintf(stdout, "The script and its options are valid, no iterations were run.\n")
+	return nil
+}
+
+// run runs the test with the given runner, until it's done or stopCtx is
+// cancelled, which gracefully stops it.
+func (tr *testRun) run(stopCtx context.Context, src *loader.SourceData, r lib.Runner) error {
+	logger := tr.logger
+	conf, err := tr.getConfig(r)
+	if err != nil {
+		return err
+	}
+	if conf.OutputDir.String != "" {
+		if conf, err = prepareOutputDir(afero.NewOsFs(), conf, tr.filename, time.Now()); err != nil {
 			return err
 		}
+	}
 
-		executionState := execScheduler.GetState()
-
-		// This is manually triggered after the Engine's Run() has completed,
-		// and things like a single Ctrl+C don't affect it. We use it to make
-		// sure that the progressbars finish updating with the latest execution
-		// state one last time, after the test run has finished.
-		progressCtx, progressCancel := context.WithCancel(globalCtx)
-		defer progressCancel()
-		initBar := execScheduler.GetInitProgressBar()
-		progressBarWG := &sync.WaitGroup{}
-		progressBarWG.Add(1)
-		go func() {
-			pbs := []*pb.ProgressBar{execScheduler.GetInitProgressBar()}
-			for _, s := range execScheduler.GetExecutors() {
-				pbs = append(pbs, s.GetProgress())
-			}
-			showProgress(progressCtx, conf, pbs, logger)
-			progressBarWG.Done()
-		}()
+	// We prepare a bunch of contexts:
+	//  - The runCtx is cancelled as soon as the Engine's run() lambda finishes,
+	//    and can trigger things like the usage report and end of test summary.
+	//    Crucially, metrics processing by the Engine will still work after this
+	//    context is cancelled!
+	//    Ctrl+C interrupts it, so the scenarios stop starting new iterations
+	//    and the running ones have their gracefulStop periods to finish.
+	//  - The lingerCtx is cancelled by Ctrl+C, and is used to wait for that
+	//    event when k6 was ran with the --linger option.
+	//  - The globalCtx is cancelled only after we're completely done with the
+	//    test execution and any --linger has been cleared, so that the Engine
+	//    can start winding down its metrics processing.
+	globalCtx, globalCancel := context.WithCancel(context.Background())
+	defer globalCancel()
+	lingerCtx, lingerCancel := context.WithCancel(globalCtx)
+	defer lingerCancel()
+	runCtx, runCancel := context.WithCancel(lib.WithInterrupt(globalCtx, stopCtx.Done()))
+	defer runCancel()
+
+	// Create a local execution scheduler wrapping the runner.
+	logger.Debug("Initializing the execution scheduler...")
+	execScheduler, err := local.NewExecutionScheduler(r, logger)
+	if err != nil {
+		return err
+	}
 
-		// Create an engine.
-		initBar.Modify(pb.WithConstProgress(0, "Init engine"))
-		engine, err := core.NewEngine(execScheduler, conf.Options, logger)
-		if err != nil {
-			return err
+	executionState := execScheduler.GetState()
+
+	// This is manually triggered after the Engine's Run() has completed,
+	// and things like a single Ctrl+C don't affect it. We use it to make
+	// sure that the progressbars finish updating with the latest execution
+	// state one last time, after the test run has finished.
+	progressCtx, progressCancel := context.WithCancel(globalCtx)
+	defer progressCancel()
+	initBar := execScheduler.GetInitProgressBar()
+	progressBarWG := &sync.WaitGroup{}
+	progressBarWG.Add(1)
+	go func() {
+		pbs := []*pb.ProgressBar{execScheduler.GetInitProgressBar()}
+		for _, s := range execScheduler.GetExecutors() {
+			pbs = append(pbs, s.GetProgress())
 		}
+		showProgress(progressCtx, conf, pbs, logger)
+		progressBarWG.Done()
+	}()
+
+	// Create an engine.
+	initBar.Modify(pb.WithConstProgress(0, "Init engine"))
+	engine, err := core.NewEngine(execScheduler, conf.Options, logger)
+	if err != nil {
+		return err
+	}
+
+	// TODO: refactor, the engine should have a copy of the config...
+	// Configure the engine.
+	if conf.NoThresholds.Valid {
+		engine.NoThresholds = conf.NoThresholds.Bool
+	}
+	if conf.NoSummary.Valid {
+		engine.NoSummary = conf.NoSummary.Bool
+	}
+	if conf.SummaryExport.Valid {
+		engine.SummaryExport = conf.SummaryExport.String != ""
+	}
+	if !conf.NoSummary.Bool {
+		engine.WaterfallSize = int(conf.ShowWaterfall.Int64)
+	}
 
-		// TODO: refactor, the engine should have a copy of the config...
-		// Configure the engine.
-		if conf.NoThresholds.Valid {
-			engine.NoThresholds = conf.NoThresholds.Bool
+	executionPlan := execScheduler.GetExecutionPlan()
+	// Create a collector and assign it to the engine if requested.
+	initBar.Modify(pb.WithConstProgress(0, "Init metric outputs"))
+	for _, out := range conf.Out {
+		t, arg := parseCollector(out)
+		collector, cerr := newCollector(logger, t, arg, src, conf, executionPlan)
+		if cerr != nil {
+			return cerr
 		}
-		if conf.NoSummary.Valid {
-			engine.NoSummary = conf.NoSummary.Bool
+		if cerr = collector.Init(); cerr != nil {
+			return cerr
 		}
-		if conf.SummaryExport.Valid {
-			engine.SummaryExport = conf.SummaryExport.String != ""
+		engine.Collectors = append(engine.Collectors, collector)
+	}
+	if logOutput == "cloud" {
+		if err = addCloudLogsHook(globalCtx, logger, engine.Collectors); err != nil {
+			return err
 		}
+	}
 
-		executionPlan := execScheduler.GetExecutionPlan()
-		// Create a collector and assign it to the engine if requested.
-		initBar.Modify(pb.WithConstProgress(0, "Init metric outputs"))
-		for _, out := range conf.Out {
-			t, arg := parseCollector(out)
-			collector, cerr := newCollector(logger, t, arg, src, conf, executionPlan)
-			if cerr != nil {
-				return cerr
-			}
-			if cerr = collector.Init(); cerr != nil {
-				return cerr
+	// Spin up the REST API server, if not disabled. It's bound to a single
+	// engine, so it isn't started in --watch mode.
+	if address != "" && tr.number == 0 {
+		initBar.Modify(pb.WithConstProgress(0, "Init API server"))
+		go func() {
+			logger.Debugf("Starting the REST API server on %s", address)
+			if aerr := api.ListenAndServe(address, engine, logger); aerr != nil {
+				// Only exit k6 if the user has explicitly set the REST API address
+				if tr.cmd.Flags().Lookup("address").Changed {
+					logger.WithError(aerr).Error("Error from API server")
+					os.Exit(cannotStartRESTAPIErrorCode)
+				} else {
+					logger.WithError(aerr).Warn("Error from API server")
+				}
 			}
-			engine.Collectors = append(engine.Collectors, collector)
-		}
+		}()
+	}
 
-		// Spin up the REST API server, if not disabled.
-		if address != "" {
-			initBar.Modify(pb.WithConstProgress(0, "Init API server"))
-			go func() {
-				logger.Debugf("Starting the REST API server on %s", address)
-				if aerr := api.ListenAndServe(address, engine, logger); aerr != nil {
-					// Only exit k6 if the user has explicitly set the REST API address
-					if cmd.Flags().Lookup("address").Changed {
-						logger.WithError(aerr).Error("Error from API server")
-						os.Exit(cannotStartRESTAPIErrorCode)
-					} else {
-						logger.WithError(aerr).Warn("Error from API server")
-					}
-				}
-			}()
+	printExecutionDescription(
+		"local", tr.filename, "", conf, execScheduler.GetState().ExecutionTuple,
+		executionPlan, engine.Collectors)
+
+	go func() {
+		select {
+		case <-stopCtx.Done():
+			lingerCancel() // the test run is interrupted by stopCtx itself
+		case <-globalCtx.Done():
 		}
+	}()
 
-		printExecutionDescription(
-			"local", filename, "", conf, execScheduler.GetState().ExecutionTuple,
-			executionPlan, engine.Collectors)
+	// Initialize the engine
+	initBar.Modify(pb.WithConstProgress(0, "Init VUs..."))
+	engineRun, engineWait, err := engine.Init(globalCtx, runCtx)
+	if err != nil {
+		return getExitCodeFromEngine(err)
+	}
 
-		// Trap Interrupts, SIGINTs and SIGTERMs.
-		sigC := make(chan os.Signal, 1)
-		signal.Notify(sigC, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
-		defer signal.Stop(sigC)
+	// Init has passed successfully, so unless disabled, make sure we send a
+	// usage report after the context is done.
+	if !conf.NoUsageReport.Bool {
+		reportDone := make(chan struct{})
 		go func() {
-			sig := <-sigC
-			logger.WithField("sig", sig).Debug("Stopping k6 in response to signal...")
-			lingerCancel() // stop the test run, metric processing is cancelled below
-
-			// If we get a second signal, we immediately exit, so something like
-			// https://github.com/loadimpact/k6/issues/971 never happens again
-			sig = <-sigC
-			logger.WithField("sig", sig).Error("Aborting k6 in response to signal")
-			globalCancel() // not that it matters, given the following command...
-			os.Exit(externalAbortErrorCode)
+			<-runCtx.Done()
+			_ = reportUsage(execScheduler)
+			close(reportDone)
 		}()
+		defer func() {
+			select {
+			case <-reportDone:
+			case <-time.After(3 * time.Second):
+			}
+		}()
+	}
 
-		// Initialize the engine
-		initBar.Modify(pb.WithConstProgress(0, "Init VUs..."))
-		engineRun, engineWait, err := engine.Init(globalCtx, runCtx)
-		if err != nil {
-			return getExitCodeFromEngine(err)
-		}
-
-		// Init has passed successfully, so unless disabled, make sure we send a
-		// usage report after the context is done.
-		if !conf.NoUsageReport.Bool {
-			reportDone := make(chan struct{})
-			go func() {
-				<-runCtx.Done()
-				_ = reportUsage(execScheduler)
-				close(reportDone)
-			}()
-			defer func() {
-				select {
-				case <-reportDone:
-				case <-time.After(3 * time.Second):
-				}
-			}()
-		}
+	// Start the test run
+	initBar.Modify(pb.WithConstProgress(0, "Starting test..."))
+	if err := engineRun(); err != nil {
+		return getExitCodeFromEngine(err)
+	}
+	runCancel()
+	logger.Debug("Engine run terminated cleanly")
 
-		// Start the test run
-		initBar.Modify(pb.WithConstProgress(0, "Starting test..."))
-		if err := engineRun(); err != nil {
-			return getExitCodeFromEngine(err)
-		}
-		runCancel()
-		logger.Debug("Engine run terminated cleanly")
+	progressCancel()
+	progressBarWG.Wait()
 
-		progressCancel()
-		progressBarWG.Wait()
+	// Warn if no iterations could be completed.
+	if executionState.GetFullIterationCount() == 0 {
+		logger.Warn("No script iterations finished, consider making the test duration longer")
+	}
 
-		// Warn if no iterations could be completed.
-		if executionState.GetFullIterationCount() == 0 {
-			logger.Warn("No script iterations finished, consider making the test duration longer")
-		}
+	data := ui.SummaryData{
+		Metrics:   engine.Metrics,
+		RootGroup: engine.ExecutionScheduler.GetRunner().GetDefaultGroup(),
+		Time:      executionState.GetCurrentTestRunDuration(),
+		TimeUnit:  conf.Options.SummaryTimeUnit.String,
+	}
+	// Print the end-of-test summary.
+	if !conf.NoSummary.Bool {
+		fprintf(stdout, "\n")
 
-		data := ui.SummaryData{
-			Metrics:   engine.Metrics,
-			RootGroup: engine.ExecutionScheduler.GetRunner().GetDefaultGroup(),
-			Time:      executionState.GetCurrentTestRunDuration(),
-			TimeUnit:  conf.Options.SummaryTimeUnit.String,
-		}
-		// Print the end-of-test summary.
-		if !conf.NoSummary.Bool {
-			fprintf(stdout, "\n")
+		s := ui.NewSummary(conf.SummaryTrendStats)
+		s.SummarizeMetrics(stdout, tr.summaryPrefix(), data)
 
-			s := ui.NewSummary(conf.SummaryTrendStats)
-			s.SummarizeMetrics(stdout, "", data)
+		fprintf(stdout, "\n")
 
+		engine.MetricsLock.Lock()
+		waterfall := engine.Waterfall
+		engine.MetricsLock.Unlock()
+		if len(waterfall) > 0 {
+			ui.SummarizeWaterfall(stdout, tr.summaryPrefix()+"    ", waterfall)
 			fprintf(stdout, "\n")
 		}
+	}
 
-		if conf.SummaryExport.ValueOrZero() != "" {
-			f, err := os.Create(conf.SummaryExport.String)
-			if err != nil {
-				logger.WithError(err).Error("failed to create summary export file")
-			} else {
-				defer func() {
-					if err := f.Close(); err != nil {
-						logger.WithError(err).Error("failed to close summary export file")
-					}
-				}()
-				s := ui.NewSummary(conf.SummaryTrendStats)
-				if err := s.SummarizeMetricsJSON(f, data); err != nil {
-					logger.WithError(err).Error("failed to make summary export file")
+	if conf.SummaryExport.ValueOrZero() != "" {
+		f, err := os.Create(conf.SummaryExport.String)
+		if err != nil {
+			logger.WithError(err).Error("failed to create summary export file")
+		} else {
+			defer func() {
+				if err := f.Close(); err != nil {
+					logger.WithError(err).Error("failed to close summary export file")
 				}
+			}()
+			s := ui.NewSummary(conf.SummaryTrendStats)
+			if err := s.SummarizeMetricsJSON(f, data); err != nil {
+				logger.WithError(err).Error("failed to make summary export file")
 			}
 		}
+	}
 
-		if conf.Linger.Bool {
-			select {
-			case <-lingerCtx.Done():
-				// do nothing, we were interrupted by Ctrl+C already
-			default:
-				logger.Debug("Linger set; waiting for Ctrl+C...")
-				fprintf(stdout, "Linger set; waiting for Ctrl+C...")
-				<-lingerCtx.Done()
-				logger.Debug("Ctrl+C received, exiting...")
-			}
+	if conf.Linger.Bool {
+		select {
+		case <-lingerCtx.Done():
+			// do nothing, we were interrupted by Ctrl+C already
+		default:
+			logger.Debug("Linger set; waiting for Ctrl+C...")
+			fprintf(stdout, "Linger set; waiting for Ctrl+C...")
+			<-lingerCtx.Done()
+			logger.Debug("Ctrl+C received, exiting...")
 		}
-		globalCancel() // signal the Engine that it should wind down
-		logger.Debug("Waiting for engine processes to finish...")
-		engineWait()
-		logger.Debug("Everything has finished, exiting k6!")
-		if engine.IsTainted() {
-			return ExitCode{error: errors.New("some thresholds have failed"), Code: thresholdHaveFailedErrorCode}
+	}
+	globalCancel() // signal the Engine that it should wind down
+	logger.Debug("Waiting for engine processes to finish...")
+	engineWait()
+	logger.Debug("Everything has finished, exiting k6!")
+	if engine.ErrorCountExceeded() {
+		return ExitCode{error: errors.New("too many HTTP requests have failed"), Code: errorCountExceededErrorCode}
+	}
+	if engine.IsTainted() {
+		return ExitCode{error: errors.New("some thresholds have failed"), Code: thresholdHaveFailedErrorCode}
+	}
+	return nil
+}
+
+// trapInterrupts calls stop on the first Interrupt, SIGINT or SIGTERM, and
+// abort on the second one, which doesn't wait for the graceful stop of the
+// scenarios. The returned function stops trapping.
+func trapInterrupts(logger logrus.FieldLogger, stop, abort func()) func() {
+	sigC := make(chan os.Signal, 1)
+	signal.Notify(sigC, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		sig, ok := <-sigC
+		if !ok {
+			return
 		}
-		return nil
-	},
+		logger.WithField("sig", sig).Warn(
+			"Stopping k6 gracefully in response to signal, send it again to force the exit...")
+		stop()
+
+		// If we get a second signal, we immediately exit, so something like
+		// https://github.com/loadimpact/k6/issues/971 never happens again
+		sig, ok = <-sigC
+		if !ok {
+			return
+		}
+		logger.WithField("sig", sig).Error("Aborting k6 in response to signal")
+		abort()
+	}()
+	return func() {
+		signal.Stop(sigC)
+		close(sigC)
+	}
+}
+
+// forceExit immediately exits k6, for the second interrupt signal.
+func forceExit() {
+	os.Exit(externalAbortErrorCode)
 }
 
 func getExitCodeFromEngine(err error) ExitCode {
@@ -422,6 +680,13 @@ func runCmdFlagSet() *pflag.FlagSet {
 	// - and finally, global variables are not very testable... :/
 	flags.StringVarP(&runType, "type", "t", runType, "override file `type`, \"js\" or \"archive\"")
 	flags.Lookup("type").DefValue = ""
+	flags.BoolVar(&runWatch, "watch", false, "restart the test when the script or any of the local files it uses change")
+	flags.BoolVar(&runDryRun, "dry-run", false, "only load the script and validate its options, without running any iterations")
+	flags.BoolVar(&runCloudExec, "cloud-exec", false, "run the test on the k6 cloud instead of locally, like the cloud command does; "+
+		"only its progress and logs are shown, the metrics and the summary are in the cloud")
+	flags.StringVar(&runHAR, "har", "", "play back the requests of a HAR `file` instead of running a script")
+	flags.Float64Var(&runHARTimeScale, "har-time-scale", 1, "multiplier of the recorded pauses between the requests of the HAR file, 0 removes them")
+	flags.BoolVar(&runCorrelateForms, "correlate-forms", false, "send the values of the hidden inputs of previous responses in the submitted forms of the HAR file, e.g. CSRF tokens")
 	return flags
 }
 
@@ -463,3 +728,19 @@ func detectType(data []byte) string {
 	}
 	return typeJS
 }
+
+// addCloudLogsHook makes the logger send its entries to the cloud test run
+// that was created by the cloud output, for --log-output=cloud.
+func addCloudLogsHook(ctx context.Context, logger *logrus.Logger, collectors []lib.Collector) error {
+	for _, collector := range collectors {
+		if cloudCollector, ok := collector.(*cloud.Collector); ok {
+			hook, err := cloudCollector.NewLogsHook(ctx, logger, logger.GetLevel())
+			if err != nil {
+				return err
+			}
+			logger.AddHook(hook)
+			return nil
+		}
+	}
+	return errors.New("--log-output=cloud can only be used together with the cloud output, i.e. --out cloud")
+}
@@ -0,0 +1,131 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/loadimpact/k6/lib"
+	"github.com/loadimpact/k6/lib/testutils"
+)
+
+func TestRunDryRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "k6_dry_run")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	writeScript := func(name, src string) string {
+		filename := filepath.Join(dir, name)
+		require.NoError(t, ioutil.WriteFile(filename, []byte(src), 0644))
+		return filename
+	}
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "data.json"), []byte("{}"), 0644))
+
+	oldWriter := stdout.Writer
+	defer func() { stdout.Writer = oldWriter }()
+
+	logger := logrus.New()
+	logger.SetOutput(testutils.NewTestOutput(t))
+	newTestRun := func(filename string) *testRun {
+		return &testRun{
+			logger:         logger,
+			filename:       filename,
+			runtimeOptions: lib.RuntimeOptions{},
+			cliConf:        Config{},
+		}
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		stdout.Writer = buf
+		tr := newTestRun(writeScript("valid.js", `
+			var data = open("./data.json");
+			export let options = { vus: 3, duration: "10s" };
+			export function setup() { throw new Error("setup was run"); }
+			export default function() { throw new Error("the default function was run"); }
+		`))
+		require.NoError(t, tr.dryRun())
+		assert.Contains(t, buf.String(), "execution: dry run")
+		assert.Contains(t, buf.String(), "3 looping VUs for 10s")
+		assert.Contains(t, buf.String(), `"vus": 3`)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		stdout.Writer = &bytes.Buffer{}
+		tr := newTestRun(writeScript("missing.js", `
+			var data = open("./missing.json");
+			export default function() {}
+		`))
+		assert.Error(t, tr.dryRun())
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		stdout.Writer = &bytes.Buffer{}
+		tr := newTestRun(writeScript("invalid.js", `
+			export let options = { scenarios: { s: { executor: "constant-vus", vus: 1 } } };
+			export default function() {}
+		`))
+		err := tr.dryRun()
+		require.Error(t, err)
+		assert.IsType(t, ExitCode{}, err)
+	})
+}
+
+func TestTrapInterrupts(t *testing.T) {
+	proc, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+
+	stopped, aborted := make(chan struct{}), make(chan struct{})
+	logger := logrus.New()
+	logger.SetOutput(testutils.NewTestOutput(t))
+	untrap := trapInterrupts(logger, func() { close(stopped) }, func() { close(aborted) })
+	defer untrap()
+
+	if err = proc.Signal(os.Interrupt); err != nil {
+		t.Skipf("sending interrupts isn't supported: %s", err)
+	}
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the first interrupt didn't stop the test")
+	}
+	select {
+	case <-aborted:
+		t.Fatal("the first interrupt aborted k6")
+	default:
+	}
+
+	require.NoError(t, proc.Signal(os.Interrupt))
+	select {
+	case <-aborted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the second interrupt didn't abort k6")
+	}
+}
@@ -0,0 +1,187 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+
+	"github.com/loadimpact/k6/lib/fsext"
+	"github.com/loadimpact/k6/loader"
+)
+
+// How often the files of the script are checked for changes in --watch mode.
+var watchInterval = 500 * time.Millisecond //nolint:gochecknoglobals
+
+// watch runs the test and restarts it every time the script or any of the
+// local files it uses are changed, until k6 is interrupted.
+func (tr *testRun) watch() error {
+	if tr.filename == "-" {
+		return errors.New("--watch can't be used when the script is read from stdin")
+	}
+	mainFile, err := filepath.Abs(tr.filename)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	defer trapInterrupts(tr.logger, cancel, forceExit)()
+
+	for {
+		tr.number++
+		tr.logger.Infof("Starting run #%d...", tr.number)
+
+		filesystems := loader.CreateFilesystems()
+		src, r, err := tr.load(filesystems)
+		watcher := newFileWatcher(watchFs(), append(getLoadedFiles(filesystems), mainFile))
+
+		changed := make(chan struct{})
+		go func() {
+			if watcher.wait(ctx) {
+				close(changed)
+			}
+		}()
+
+		if err == nil {
+			runCtx, stopRun := context.WithCancel(ctx)
+			go func() {
+				select {
+				case <-changed:
+					stopRun()
+				case <-runCtx.Done():
+				}
+			}()
+			err = tr.run(runCtx, src, r)
+			stopRun()
+		}
+		if err != nil {
+			tr.logger.WithError(err).Errorf("Run #%d failed", tr.number)
+		}
+
+		select {
+		case <-changed:
+		default:
+			if ctx.Err() == nil {
+				tr.logger.Info("Waiting for changes in the script or its local files...")
+			}
+		}
+		select {
+		case <-changed:
+			tr.logger.Info("Changes detected, restarting the test...")
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// summaryPrefix returns the prefix of the end-of-test summary lines, which
+// contains the run number in --watch mode.
+func (tr *testRun) summaryPrefix() string {
+	if tr.number == 0 {
+		return ""
+	}
+	return fmt.Sprintf("[run #%d] ", tr.number)
+}
+
+// getLoadedFiles returns the paths of all the local files that were read while
+// the script was loaded, i.e. the script itself, its local imports and the
+// files opened in the init context. They are all cached by the file filesystem.
+func getLoadedFiles(filesystems map[string]afero.Fs) []string {
+	cachedFs, ok := filesystems["file"].(fsext.CacheOnReadFs)
+	if !ok {
+		return nil
+	}
+	var files []string
+	_ = afero.Walk(cachedFs.GetCachingFs(), "/", func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() && path != "/-" { // "/-" is the script read from stdin
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files
+}
+
+// watchFs returns the filesystem that the loaded files are checked with, the
+// paths of which have a leading path separator on Windows too.
+func watchFs() afero.Fs {
+	if runtime.GOOS == "windows" {
+		return fsext.NewTrimFilePathSeparatorFs(defaultFs)
+	}
+	return defaultFs
+}
+
+type fileState struct {
+	exists  bool
+	size    int64
+	modTime int64
+}
+
+// fileWatcher polls files for changes in their size or modification time.
+type fileWatcher struct {
+	fs     afero.Fs
+	files  []string
+	states map[string]fileState
+}
+
+func newFileWatcher(fs afero.Fs, files []string) *fileWatcher {
+	sort.Strings(files)
+	w := &fileWatcher{fs: fs, files: files, states: make(map[string]fileState, len(files))}
+	for _, file := range files {
+		w.states[file] = w.stat(file)
+	}
+	return w
+}
+
+func (w *fileWatcher) stat(file string) fileState {
+	info, err := w.fs.Stat(file)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{exists: true, size: info.Size(), modTime: info.ModTime().UnixNano()}
+}
+
+// wait blocks until any of the files is changed, created or removed, in which
+// case it returns true, or until the context is done.
+func (w *fileWatcher) wait(ctx context.Context) bool {
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+			for _, file := range w.files {
+				if w.stat(file) != w.states[file] {
+					return true
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/loadimpact/k6/lib/fsext"
+)
+
+func TestGetLoadedFiles(t *testing.T) {
+	base := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(base, "/test/script.js", []byte("import './lib.js';"), 0644))
+	require.NoError(t, afero.WriteFile(base, "/test/lib.js", []byte(""), 0644))
+	require.NoError(t, afero.WriteFile(base, "/test/unused.js", []byte(""), 0644))
+
+	filesystems := map[string]afero.Fs{"file": fsext.NewCacheOnReadFs(base, afero.NewMemMapFs(), 0)}
+	for _, name := range []string{"/test/script.js", "/test/lib.js"} {
+		_, err := afero.ReadFile(filesystems["file"], name)
+		require.NoError(t, err)
+	}
+	assert.ElementsMatch(t, []string{"/test/script.js", "/test/lib.js"}, getLoadedFiles(filesystems))
+	assert.Empty(t, getLoadedFiles(map[string]afero.Fs{"file": base}))
+}
+
+func TestFileWatcher(t *testing.T) {
+	oldInterval := watchInterval
+	defer func() { watchInterval = oldInterval }()
+	watchInterval = 10 * time.Millisecond
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/script.js", []byte("v1"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/data.json", []byte("{}"), 0644))
+
+	waitFor := func(w *fileWatcher) bool {
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		return w.wait(ctx)
+	}
+
+	files := []string{"/script.js", "/data.json", "/missing.js"}
+	assert.False(t, waitFor(newFileWatcher(fs, files)), "nothing was changed")
+
+	t.Run("modified", func(t *testing.T) {
+		w := newFileWatcher(fs, files)
+		require.NoError(t, afero.WriteFile(fs, "/script.js", []byte("v2 is longer"), 0644))
+		assert.True(t, waitFor(w))
+	})
+	t.Run("created", func(t *testing.T) {
+		w := newFileWatcher(fs, files)
+		require.NoError(t, afero.WriteFile(fs, "/missing.js", []byte(""), 0644))
+		assert.True(t, waitFor(w))
+	})
+	t.Run("removed", func(t *testing.T) {
+		w := newFileWatcher(fs, files)
+		require.NoError(t, fs.Remove("/data.json"))
+		assert.True(t, waitFor(w))
+	})
+}
@@ -58,6 +58,8 @@ func runtimeOptionFlagSet(includeSysEnv bool) *pflag.FlagSet {
 	flags := pflag.NewFlagSet("", 0)
 	flags.SortFlags = false
 	flags.Bool("include-system-env-vars", includeSysEnv, "pass the real system environment variables to the runtime")
+	flags.Bool("skip-system-env-vars", false, "don't pass the real system environment variables to the runtime, "+
+		"only the ones set with --env; the same as --include-system-env-vars=false")
 	flags.String("compatibility-mode", "extended",
 		`JavaScript compiler compatibility mode, "extended" or "base"
 base: pure Golang JS VM supporting ES5.1+
@@ -85,6 +87,17 @@ func getRuntimeOptions(flags *pflag.FlagSet, environment map[string]string) (lib
 		return opts, err
 	}
 
+	skipSysEnv, err := flags.GetBool("skip-system-env-vars")
+	if err != nil {
+		return opts, err
+	}
+	if skipSysEnv {
+		if opts.IncludeSystemEnvVars.Valid && opts.IncludeSystemEnvVars.Bool {
+			return opts, errors.New("--skip-system-env-vars can't be used together with --include-system-env-vars")
+		}
+		opts.IncludeSystemEnvVars = null.BoolFrom(false)
+	}
+
 	if !opts.IncludeSystemEnvVars.Valid { // If not explicitly set via CLI flags, look for an environment variable
 		if envVar, ok := environment["K6_INCLUDE_SYSTEM_ENV_VARS"]; ok {
 			val, err := strconv.ParseBool(envVar)
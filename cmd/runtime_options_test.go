@@ -84,6 +84,18 @@ var runtimeOptionsTestCases = map[string]runtimeOptionsTestCase{ //nolint:gochec
 		expEnv:        map[string]string{},
 		expCompatMode: baseCompatMode,
 	},
+	"disabled sys env by skip cli flag": {
+		useSysEnv:     true,
+		systemEnv:     map[string]string{"test1": "val1", "K6_INCLUDE_SYSTEM_ENV_VARS": "true"},
+		cliFlags:      []string{"--skip-system-env-vars", "-e", "test2=val2"},
+		expEnv:        map[string]string{"test2": "val2"},
+		expCompatMode: defaultCompatMode,
+	},
+	"error skip and include sys env cli flags": {
+		useSysEnv: true,
+		cliFlags:  []string{"--skip-system-env-vars", "--include-system-env-vars"},
+		expErr:    true,
+	},
 	"disabled sys env by env": {
 		useSysEnv:     true,
 		systemEnv:     map[string]string{"K6_INCLUDE_SYSTEM_ENV_VARS": "false", "K6_COMPATIBILITY_MODE": "extended"},
@@ -21,22 +21,61 @@
 package cmd
 
 import (
+	"compress/gzip"
 	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
 
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"gopkg.in/guregu/null.v3"
 
 	"github.com/loadimpact/k6/api/v1/client"
+	"github.com/loadimpact/k6/lib"
+	"github.com/loadimpact/k6/stats"
+	jsonc "github.com/loadimpact/k6/stats/json"
 	"github.com/loadimpact/k6/ui"
 )
 
+//nolint:gochecknoglobals
+var (
+	statsThresholds        []string
+	statsFrom, statsTo     string
+	statsSummaryTrendStats []string
+	statsSummaryTimeUnit   string
+)
+
 // statsCmd represents the stats command
 var statsCmd = &cobra.Command{
-	Use:   "stats",
+	Use:   "stats [results.json]",
 	Short: "Show test metrics",
 	Long: `Show test metrics.
 
-  Use the global --address flag to specify the URL to the API server.`,
+  Without arguments, the metrics of the running test are shown. Use the global
+  --address flag to specify the URL to the API server.
+
+  With the path to a file that was written by the JSON output (--out json=...),
+  the metrics in it are summarized like at the end of the test run, and the
+  thresholds are evaluated again.`,
+	Example: `
+  # Show the metrics of the running test.
+  k6 stats
+
+  # Summarize the metrics of a finished test.
+  k6 run --out json=results.json script.js
+  k6 stats results.json
+
+  # Evaluate new thresholds for the metrics between the first and the fifth minute of the test.
+  k6 stats --from 1m --to 5m --threshold "http_req_duration=p(95)<200" results.json`[1:],
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 1 {
+			return statsFromFile(args[0])
+		}
+
 		c, err := client.New(address)
 		if err != nil {
 			return err
@@ -50,6 +89,127 @@ var statsCmd = &cobra.Command{
 	},
 }
 
+// parseStatsThresholds parses the --threshold flags, in the "metric=expression"
+// format, into the thresholds of each metric.
+func parseStatsThresholds(sources []string) (map[string]stats.Thresholds, error) {
+	sourcesByMetric := make(map[string][]string)
+	for _, source := range sources {
+		parts := strings.SplitN(source, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("invalid threshold %q, it should be in the metric=expression format", source)
+		}
+		name := strings.TrimSpace(parts[0])
+		sourcesByMetric[name] = append(sourcesByMetric[name], strings.TrimSpace(parts[1]))
+	}
+
+	thresholds := make(map[string]stats.Thresholds, len(sourcesByMetric))
+	for name, sources := range sourcesByMetric {
+		ts, err := stats.NewThresholds(sources)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid threshold for %s", name)
+		}
+		thresholds[name] = ts
+	}
+	return thresholds, nil
+}
+
+func statsFromFile(filename string) error {
+	if err := ui.ValidateSummary(statsSummaryTrendStats); err != nil {
+		return err
+	}
+	switch statsSummaryTimeUnit {
+	case "", "s", "ms", "us":
+	default:
+		return errors.New("invalid summary time unit. Use: 's', 'ms' or 'us'")
+	}
+	thresholds, err := parseStatsThresholds(statsThresholds)
+	if err != nil {
+		return err
+	}
+	conf := jsonc.ReadConfig{}
+	if conf.From, err = jsonc.ParseTimeBound(statsFrom); err != nil {
+		return err
+	}
+	if conf.To, err = jsonc.ParseTimeBound(statsTo); err != nil {
+		return err
+	}
+	for name := range thresholds {
+		if strings.Contains(name, "{") {
+			conf.Submetrics = append(conf.Submetrics, name)
+		}
+	}
+
+	f, err := defaultFs.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	var r io.Reader = f
+	if strings.HasSuffix(filename, ".gz") {
+		gzr, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		r = gzr
+	}
+
+	results, err := jsonc.ReadMetrics(r, conf)
+	if err != nil {
+		return err
+	}
+
+	// The thresholds from the flags replace the recorded ones of the metrics
+	for name, ts := range thresholds {
+		if m, ok := results.Metrics[name]; ok {
+			m.Thresholds = ts
+		} else {
+			logrus.StandardLogger().Warnf("there are no samples for the metric %s in %s", name, filename)
+		}
+	}
+	names := make([]string, 0, len(results.Metrics))
+	for name := range results.Metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	tainted := false
+	for _, name := range names {
+		m := results.Metrics[name]
+		if len(m.Thresholds.Thresholds) == 0 {
+			continue
+		}
+		succ, err := m.Thresholds.Run(m.Sink, results.Duration())
+		if err != nil {
+			return errors.Wrapf(err, "threshold error for %s", name)
+		}
+		m.Tainted = null.BoolFrom(!succ)
+		tainted = tainted || !succ
+	}
+
+	fprintf(stdout, "\n")
+	ui.NewSummary(statsSummaryTrendStats).SummarizeMetrics(stdout, "", ui.SummaryData{
+		Metrics:  results.Metrics,
+		Time:     results.Duration(),
+		TimeUnit: statsSummaryTimeUnit,
+	})
+	fprintf(stdout, "\n")
+
+	if tainted {
+		return ExitCode{error: errors.New("some thresholds have failed"), Code: thresholdHaveFailedErrorCode}
+	}
+	return nil
+}
+
 func init() {
 	RootCmd.AddCommand(statsCmd)
+	statsCmd.Flags().SortFlags = false
+	statsCmd.Flags().StringArrayVar(&statsThresholds, "threshold", nil,
+		"evaluate a threshold, as `metric=expression`, instead of the recorded ones of the metric")
+	statsCmd.Flags().StringVar(&statsFrom, "from", "",
+		"only include the samples from this RFC 3339 `time`, or this duration after the first sample")
+	statsCmd.Flags().StringVar(&statsTo, "to", "",
+		"only include the samples until this RFC 3339 `time`, or this duration after the first sample")
+	statsCmd.Flags().StringSliceVar(&statsSummaryTrendStats, "summary-trend-stats", lib.DefaultSummaryTrendStats,
+		"define `stats` for trend metrics (response times), one or more as 'avg,p(95),...'")
+	statsCmd.Flags().StringVar(&statsSummaryTimeUnit, "summary-time-unit", "",
+		"define the time unit used to display the time values. Possible units are: 's', 'ms' and 'us'")
 }
@@ -0,0 +1,33 @@
+import { check, fail, sleep } from "k6";
+import http from "k6/http";
+
+export let options = {
+  vus: 5,
+  duration: "30s",
+};
+
+const BASE_URL = __ENV.BASE_URL || "https://test-api.k6.io";
+const USERNAME = __ENV.USERNAME || "test-user@example.com";
+const PASSWORD = __ENV.PASSWORD || "superCroc2020";
+
+// setup() runs once, the token it returns is passed to every iteration.
+export function setup() {
+  let res = http.post(`${BASE_URL}/auth/token/login/`, {
+    username: USERNAME,
+    password: PASSWORD,
+  });
+  if (!check(res, { "logged in successfully": (r) => r.status === 200 })) {
+    fail(`could not log in, status ${res.status}`);
+  }
+  return { token: res.json("access") };
+}
+
+export default function (data) {
+  let params = { headers: { Authorization: `Bearer ${data.token}` } };
+  let res = http.get(`${BASE_URL}/my/crocodiles/`, params);
+  check(res, {
+    "status is 200": (r) => r.status === 200,
+  });
+
+  sleep(1);
+}
@@ -0,0 +1,33 @@
+import { check, group, sleep } from "k6";
+import http from "k6/http";
+
+export let options = {
+  vus: 10,
+  duration: "30s",
+  thresholds: {
+    http_req_duration: ["p(95)<500"],
+    checks: ["rate>0.99"],
+  },
+};
+
+const BASE_URL = __ENV.BASE_URL || "https://test-api.k6.io";
+
+export default function () {
+  group("list crocodiles", function () {
+    let res = http.get(`${BASE_URL}/public/crocodiles/`);
+    check(res, {
+      "status is 200": (r) => r.status === 200,
+      "returned some crocodiles": (r) => r.json().length > 0,
+    });
+  });
+
+  group("get a crocodile", function () {
+    let res = http.get(`${BASE_URL}/public/crocodiles/1/`);
+    check(res, {
+      "status is 200": (r) => r.status === 200,
+      "has a name": (r) => r.json("name") !== "",
+    });
+  });
+
+  sleep(1);
+}
@@ -0,0 +1,28 @@
+import { check } from "k6";
+import ws from "k6/ws";
+
+export let options = {
+  vus: 10,
+  duration: "30s",
+};
+
+const URL = __ENV.WS_URL || "wss://echo.websocket.org";
+
+export default function () {
+  let res = ws.connect(URL, { tags: { endpoint: "echo" } }, function (socket) {
+    socket.on("open", function () {
+      socket.send(`hello from VU ${__VU}`);
+    });
+
+    socket.on("message", function (message) {
+      check(message, { "got the echo back": (m) => m.indexOf("hello") === 0 });
+      socket.close();
+    });
+
+    socket.setTimeout(function () {
+      socket.close();
+    }, 5000);
+  });
+
+  check(res, { "status is 101": (r) => r && r.status === 101 });
+}
@@ -21,7 +21,7 @@
 package cmd
 
 import (
-	"fmt"
+	"encoding/json"
 
 	"github.com/spf13/cobra"
 
@@ -32,12 +32,28 @@ import (
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Show application version",
-	Long:  `Show the application version and exit.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("k6 v" + consts.FullVersion())
+	Long: `Show the application version and exit.
+
+With --json, the version and build information is printed as a JSON object,
+whose schema_version is only increased when existing fields change.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !versionJSON {
+			fprintf(defaultWriter, "k6 v%s\n", consts.FullVersion())
+			return nil
+		}
+
+		data, err := json.MarshalIndent(consts.GetVersionInfo(), "", "  ")
+		if err != nil {
+			return err
+		}
+		fprintf(defaultWriter, "%s\n", data)
+		return nil
 	},
 }
 
+var versionJSON bool
+
 func init() {
 	RootCmd.AddCommand(versionCmd)
+	versionCmd.Flags().BoolVar(&versionJSON, "json", false, "print the version and build information as JSON")
 }
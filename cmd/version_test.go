@@ -0,0 +1,72 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/loadimpact/k6/lib/consts"
+)
+
+func TestVersionCmd(t *testing.T) {
+	oldWriter, oldDetails := defaultWriter, consts.VersionDetails
+	defer func() {
+		versionJSON = false
+		defaultWriter, consts.VersionDetails = oldWriter, oldDetails
+	}()
+
+	run := func(asJSON bool) string {
+		versionJSON = asJSON
+		buf := &bytes.Buffer{}
+		defaultWriter = buf
+		require.NoError(t, versionCmd.RunE(versionCmd, nil))
+		return buf.String()
+	}
+
+	consts.VersionDetails = "2020-10-15T12:00:00+0000/v0.28.0-3-g6ef5e8f-dirty"
+	assert.Equal(t, "k6 v"+consts.FullVersion()+"\n", run(false))
+
+	testdata := map[string]struct{ commit, date string }{
+		"2020-10-15T12:00:00+0000/v0.28.0-3-g6ef5e8f-dirty": {"6ef5e8f", "2020-10-15T12:00:00+0000"},
+		"2020-10-15T12:00:00+0000/v0.28.0-0-gabc1234":       {"abc1234", "2020-10-15T12:00:00+0000"},
+		"2020-10-15T12:00:00+0000/abc1234":                  {"abc1234", "2020-10-15T12:00:00+0000"},
+		"":                                                  {"", ""},
+	}
+	for details, exp := range testdata {
+		consts.VersionDetails = details
+		var info map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(run(true)), &info), details)
+		assert.Equal(t, map[string]interface{}{
+			"schema_version": float64(1),
+			"version":        consts.Version,
+			"go_version":     runtime.Version(),
+			"git_commit":     exp.commit,
+			"build_date":     exp.date,
+			"platform":       runtime.GOOS + "/" + runtime.GOARCH,
+		}, info, details)
+	}
+}
@@ -0,0 +1,298 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package har
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/pkg/errors"
+)
+
+// PlaybackConfig configures the script that Playback generates.
+type PlaybackConfig struct {
+	// TimeScale multiplies the recorded pauses between the requests, so 1
+	// preserves the recorded timing, 0.5 halves it and 0 removes it.
+	TimeScale float64
+	// CorrelateForms replaces the values of the submitted form fields that
+	// were taken from the hidden inputs of a previous HTML response, e.g. CSRF
+	// tokens, with the values that are received during the playback.
+	CorrelateForms bool
+	// The domains to only include or to skip, like in Convert
+	Only, Skip []string
+}
+
+// formSource is a form field whose value came from a previous response.
+type formSource struct {
+	name     string
+	selector string
+}
+
+// Playback generates a script that replays all of the requests of the HAR
+// file, in the order of their pages and with the recorded pauses between them
+// scaled by the time scale. Unlike Convert, requests are sent one by one, and
+// the cookies that were set by the recorded responses aren't sent as recorded,
+// since the cookie jar of the VU maintains them.
+func Playback(h HAR, conf PlaybackConfig) (string, error) {
+	if h.Log == nil {
+		return "", errors.Errorf("invalid HAR file supplied, the 'log' property is missing")
+	}
+	if conf.TimeScale < 0 {
+		return "", errors.Errorf("the time scale can't be negative")
+	}
+
+	pages := h.Log.Pages
+	sort.Sort(PageByStarted(pages))
+	if len(pages) == 0 {
+		pages = []Page{{Title: "Global"}}
+	}
+
+	pageEntries := make(map[string][]*Entry)
+	for _, e := range h.Log.Entries {
+		u, err := url.Parse(e.Request.URL)
+		if err != nil {
+			return "", err
+		}
+		if !IsAllowedURL(u.Host, conf.Only, conf.Skip) {
+			continue
+		}
+		// Binary multipart bodies can't be replayed from the recorded text
+		if e.Request.PostData != nil && strings.HasPrefix(e.Request.PostData.MimeType, "multipart/form-data") {
+			continue
+		}
+		pageEntries[e.Pageref] = append(pageEntries[e.Pageref], e)
+	}
+	var entries []*Entry
+	for _, page := range pages {
+		sort.Sort(EntryByStarted(pageEntries[page.ID]))
+		entries = append(entries, pageEntries[page.ID]...)
+	}
+
+	// The values of the form fields that should be extracted after each
+	// entry, and the sources of the form fields of each entry
+	var extractions map[*Entry][]formSource
+	var sources map[*Entry]map[string]bool
+	if conf.CorrelateForms {
+		extractions, sources = correlateForms(entries)
+	}
+
+	var b bytes.Buffer
+	w := bufio.NewWriter(&b)
+	fprint(w, "import { group, sleep } from 'k6';\n")
+	fprint(w, "import http from 'k6/http';\n\n")
+	fprintf(w, "// Playback of a HAR file created by %v\n\n", h.Log.Creator.Name)
+	// Recordings include redirections as separate requests
+	fprint(w, "export let options = {\n    maxRedirects: 0,\n};\n\n")
+	fprint(w, "export default function() {\n")
+	fprint(w, "\tlet res, fields = {};\n")
+
+	setCookies := make(map[string]bool)
+	var prev *Entry
+	for _, page := range pages {
+		if len(pageEntries[page.ID]) == 0 {
+			continue
+		}
+		groupName := page.Title
+		if page.ID != "" {
+			groupName = page.ID + " - " + page.Title
+		}
+		fprintf(w, "\tgroup(%q, function() {\n", groupName)
+		for _, e := range pageEntries[page.ID] {
+			if prev != nil {
+				if pause := pauseBetween(prev, e, conf.TimeScale); pause >= 10*time.Millisecond {
+					fprintf(w, "\t\tsleep(%.2f);\n", pause.Seconds())
+				}
+			}
+			prev = e
+
+			req, err := buildPlaybackRequest(e.Request, sources[e], setCookies)
+			if err != nil {
+				return "", err
+			}
+			fprintf(w, "\t\tres = %s;\n", req)
+			for _, source := range extractions[e] {
+				fprintf(w, "\t\tfields[%q] = res.html().find(%q).first().attr(\"value\");\n", source.name, source.selector)
+			}
+			if e.Response != nil {
+				for _, c := range e.Response.Cookies {
+					setCookies[c.Name] = true
+				}
+			}
+		}
+		fprint(w, "\t});\n")
+	}
+	fprint(w, "}\n")
+
+	if err := w.Flush(); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// pauseBetween returns the scaled time between the end of an entry and the
+// start of the next one.
+func pauseBetween(prev, next *Entry, scale float64) time.Duration {
+	prevEnd := prev.StartedDateTime.Add(time.Duration(float64(prev.Time) * float64(time.Millisecond)))
+	return time.Duration(float64(next.StartedDateTime.Sub(prevEnd)) * scale)
+}
+
+// buildPlaybackRequest returns the http.request() call for a recorded
+// request. The form fields in sources are sent with the extracted values,
+// and the cookies in setCookies are left to the cookie jar.
+func buildPlaybackRequest(req *Request, sources map[string]bool, setCookies map[string]bool) (string, error) {
+	body := "null"
+	if req.PostData != nil && req.Method != "GET" {
+		if isForm(req.PostData) {
+			params, err := formParams(req.PostData)
+			if err != nil {
+				return "", err
+			}
+			fields := make([]string, 0, len(params))
+			for _, p := range params {
+				if sources[p.Name] {
+					fields = append(fields, fmt.Sprintf("%q: fields[%q]", p.Name, p.Name))
+				} else {
+					fields = append(fields, fmt.Sprintf("%q: %q", p.Name, p.Value))
+				}
+			}
+			body = "{ " + strings.Join(fields, ", ") + " }"
+		} else {
+			body = strconv.Quote(req.PostData.Text)
+		}
+	}
+
+	var params []string
+	var cookies []string
+	for _, c := range req.Cookies {
+		if !setCookies[c.Name] {
+			cookies = append(cookies, fmt.Sprintf("%q: %q", c.Name, c.Value))
+		}
+	}
+	if len(cookies) > 0 {
+		params = append(params, fmt.Sprintf(`"cookies": { %s }`, strings.Join(cookies, ", ")))
+	}
+	if headers := buildK6Headers(req.Headers); len(headers) > 0 {
+		params = append(params, fmt.Sprintf(`"headers": { %s }`, strings.Join(headers, ", ")))
+	}
+
+	call := fmt.Sprintf("http.request(%q, %q, %s", req.Method, req.URL, body)
+	if len(params) > 0 {
+		call += ", { " + strings.Join(params, ", ") + " }"
+	}
+	return call + ")", nil
+}
+
+// correlateForms finds the submitted form fields whose values were the values
+// of a same-named input of the latest previous HTML response.
+func correlateForms(entries []*Entry) (map[*Entry][]formSource, map[*Entry]map[string]bool) {
+	extractions := make(map[*Entry][]formSource)
+	sources := make(map[*Entry]map[string]bool)
+	var documents []*goquery.Document
+	var documentEntries []*Entry
+	for _, e := range entries {
+		if e.Request.PostData != nil && isForm(e.Request.PostData) {
+			params, err := formParams(e.Request.PostData)
+			if err != nil {
+				params = nil
+			}
+			for _, p := range params {
+				if p.Value == "" {
+					continue
+				}
+				selector := fmt.Sprintf("input[name=%s]", strconv.Quote(p.Name))
+				for i := len(documents) - 1; i >= 0; i-- {
+					value, ok := documents[i].Find(selector).First().Attr("value")
+					if !ok || value != p.Value {
+						continue
+					}
+					source := documentEntries[i]
+					if !hasSource(extractions[source], p.Name) {
+						extractions[source] = append(extractions[source], formSource{name: p.Name, selector: selector})
+					}
+					if sources[e] == nil {
+						sources[e] = make(map[string]bool)
+					}
+					sources[e][p.Name] = true
+					break
+				}
+			}
+		}
+
+		if e.Response != nil && e.Response.Content != nil && e.Response.Content.Encoding == "" &&
+			strings.Contains(e.Response.Content.MimeType, "html") && e.Response.Content.Text != "" {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(e.Response.Content.Text))
+			if err == nil {
+				documents = append(documents, doc)
+				documentEntries = append(documentEntries, e)
+			}
+		}
+	}
+	return extractions, sources
+}
+
+func hasSource(sources []formSource, name string) bool {
+	for _, s := range sources {
+		if s.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func isForm(data *PostData) bool {
+	return strings.HasPrefix(data.MimeType, "application/x-www-form-urlencoded")
+}
+
+// formParams returns the unescaped fields of an URL encoded form, in order.
+func formParams(data *PostData) ([]Param, error) {
+	params := data.Params
+	if len(params) == 0 && data.Text != "" {
+		for _, field := range strings.Split(data.Text, "&") {
+			nameValue := strings.SplitN(field, "=", 2)
+			p := Param{Name: nameValue[0]}
+			if len(nameValue) == 2 {
+				p.Value = nameValue[1]
+			}
+			params = append(params, p)
+		}
+	}
+
+	result := make([]Param, 0, len(params))
+	for _, p := range params {
+		name, err := url.QueryUnescape(p.Name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := url.QueryUnescape(p.Value)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, Param{Name: name, Value: value})
+	}
+	return result, nil
+}
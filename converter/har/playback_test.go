@@ -0,0 +1,128 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package har
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/loadimpact/k6/js"
+	"github.com/loadimpact/k6/lib"
+	"github.com/loadimpact/k6/lib/testutils"
+	"github.com/loadimpact/k6/loader"
+	"github.com/loadimpact/k6/stats"
+)
+
+func TestPlayback(t *testing.T) {
+	t.Parallel()
+	var submitted []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/form", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "playback"})
+		_, _ = fmt.Fprint(w, `<form><input type="hidden" name="csrf" value="fresh-token"></form>`)
+	})
+	mux.HandleFunc("/submit", func(w http.ResponseWriter, r *http.Request) {
+		session, _ := r.Cookie("session")
+		other, _ := r.Cookie("other")
+		submitted = append(submitted, fmt.Sprintf("%s %s %s %s", r.PostFormValue("csrf"), r.PostFormValue("user"), session, other))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := HAR{Log: &Log{
+		Creator: &Creator{Name: "test"},
+		Pages:   []Page{{ID: "page_1", Title: "Login", StartedDateTime: start}},
+		Entries: []*Entry{
+			{
+				Pageref: "page_1", StartedDateTime: start, Time: 100,
+				Request: &Request{Method: "GET", URL: srv.URL + "/form"},
+				Response: &Response{
+					Status:  200,
+					Cookies: []Cookie{{Name: "session", Value: "recorded"}},
+					Content: &Content{
+						MimeType: "text/html",
+						Text:     `<form><input type="hidden" name="csrf" value="recorded-token"></form>`,
+					},
+				},
+			},
+			{
+				Pageref: "page_1", StartedDateTime: start.Add(300 * time.Millisecond), Time: 100,
+				Request: &Request{
+					Method:  "POST",
+					URL:     srv.URL + "/submit",
+					Cookies: []Cookie{{Name: "session", Value: "recorded"}, {Name: "other", Value: "kept"}},
+					PostData: &PostData{
+						MimeType: "application/x-www-form-urlencoded",
+						Text:     "csrf=recorded-token&user=admin",
+					},
+				},
+			},
+		},
+	}}
+
+	t.Run("script", func(t *testing.T) {
+		script, err := Playback(h, PlaybackConfig{TimeScale: 0.5, CorrelateForms: true})
+		require.NoError(t, err)
+		assert.Contains(t, script, "group(\"page_1 - Login\"")
+		assert.Contains(t, script, "sleep(0.10);")
+		assert.Contains(t, script, `fields["csrf"] = res.html().find("input[name=\"csrf\"]").first().attr("value");`)
+		assert.Contains(t, script, `{ "csrf": fields["csrf"], "user": "admin" }`)
+		assert.Contains(t, script, `"cookies": { "other": "kept" }`)
+
+		script, err = Playback(h, PlaybackConfig{})
+		require.NoError(t, err)
+		assert.NotContains(t, script, "sleep(")
+		assert.Contains(t, script, `{ "csrf": "recorded-token", "user": "admin" }`)
+	})
+
+	t.Run("run", func(t *testing.T) {
+		script, err := Playback(h, PlaybackConfig{CorrelateForms: true})
+		require.NoError(t, err)
+		r, err := js.New(testutils.NewLogger(t), &loader.SourceData{
+			URL:  &url.URL{Path: "/recording.har"},
+			Data: []byte(script),
+		}, nil, lib.RuntimeOptions{})
+		require.NoError(t, err)
+
+		initVU, err := r.NewVU(1, make(chan stats.SampleContainer, 100))
+		require.NoError(t, err)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		require.NoError(t, initVU.Activate(&lib.VUActivationParams{RunContext: ctx}).RunOnce())
+		assert.Equal(t, []string{"fresh-token admin session=playback other=kept"}, submitted)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := Playback(HAR{}, PlaybackConfig{})
+		assert.Error(t, err)
+		_, err = Playback(h, PlaybackConfig{TimeScale: -1})
+		assert.Error(t, err)
+	})
+}
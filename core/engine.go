@@ -32,6 +32,7 @@ import (
 
 	"github.com/loadimpact/k6/lib"
 	"github.com/loadimpact/k6/lib/metrics"
+	"github.com/loadimpact/k6/lib/netext/httpext"
 	"github.com/loadimpact/k6/stats"
 )
 
@@ -60,10 +61,19 @@ type Engine struct {
 	NoSummary     bool
 	SummaryExport bool
 
+	// The first WaterfallSize HTTP requests are kept in Waterfall, for the
+	// waterfall chart in the end-of-test summary.
+	WaterfallSize int
+	Waterfall     []*httpext.Trail
+
 	logger   *logrus.Entry
 	stopOnce sync.Once
 	stopChan chan struct{}
 
+	// Closed to gracefully stop the test run, see Interrupt()
+	interruptOnce sync.Once
+	interruptChan chan struct{}
+
 	Metrics     map[string]*stats.Metric
 	MetricsLock sync.Mutex
 
@@ -75,6 +85,10 @@ type Engine struct {
 
 	// Are thresholds tainted?
 	thresholdsTainted bool
+
+	// The failed HTTP requests, counted for the abortOnErrorCount option
+	failedRequests     int64
+	errorCountExceeded bool
 }
 
 // NewEngine instantiates a new Engine, without doing any heavy initialization.
@@ -92,11 +106,22 @@ func NewEngine(ex lib.ExecutionScheduler, o lib.Options, logger *logrus.Logger)
 		Samples:  make(chan stats.SampleContainer, o.MetricSamplesBufferSize.Int64),
 		stopChan: make(chan struct{}),
 		logger:   logger.WithField("component", "engine"),
+
+		interruptChan: make(chan struct{}),
 	}
 
 	e.thresholds = o.Thresholds
 	e.submetrics = make(map[string][]*stats.Submetric)
-	for name := range e.thresholds {
+	// The submetrics that have thresholds or are referenced by the thresholds
+	// of other metrics have to be aggregated
+	submetricNames := make(map[string]bool)
+	for name, ts := range e.thresholds {
+		submetricNames[name] = true
+		for _, ref := range ts.MetricReferences() {
+			submetricNames[ref] = true
+		}
+	}
+	for name := range submetricNames {
 		if !strings.Contains(name, "{") {
 			continue
 		}
@@ -130,7 +155,16 @@ func (e *Engine) Init(globalCtx, runCtx context.Context) (run func() error, wait
 
 	// TODO: move all of this in a separate struct? see main TODO above
 
-	runSubCtx, runSubCancel := context.WithCancel(runCtx)
+	// The test run is interrupted by Interrupt(), as well as by the interrupt
+	// of the runCtx itself, e.g. on Ctrl+C
+	go func() {
+		select {
+		case <-lib.GetInterrupt(runCtx):
+			e.Interrupt()
+		case <-runCtx.Done():
+		}
+	}()
+	runSubCtx, runSubCancel := context.WithCancel(lib.WithInterrupt(runCtx, e.interruptChan))
 
 	resultCh := make(chan error)
 	processMetricsAfterRun := make(chan struct{})
@@ -203,8 +237,19 @@ func (e *Engine) startBackgroundProcesses( //nolint:funlen
 				e.logger.WithError(err).Debug("run: execution scheduler returned an error")
 				e.setRunStatus(lib.RunStatusAbortedSystem)
 			} else {
-				e.logger.Debug("run: execution scheduler terminated")
-				e.setRunStatus(lib.RunStatusFinished)
+				select {
+				case <-e.interruptChan:
+					if e.ErrorCountExceeded() {
+						e.logger.Debug("run: execution scheduler terminated after too many failed requests")
+						e.setRunStatus(lib.RunStatusAbortedSystem)
+						return
+					}
+					e.logger.Debug("run: execution scheduler terminated after an interrupt")
+					e.setRunStatus(lib.RunStatusAbortedUser)
+				default:
+					e.logger.Debug("run: execution scheduler terminated")
+					e.setRunStatus(lib.RunStatusFinished)
+				}
 			}
 		case <-runCtx.Done():
 			e.logger.Debug("run: context expired; exiting...")
@@ -308,6 +353,14 @@ func (e *Engine) IsTainted() bool {
 	return e.thresholdsTainted
 }
 
+// ErrorCountExceeded returns whether the test run was aborted because more
+// HTTP requests than the abortOnErrorCount option allows have failed.
+func (e *Engine) ErrorCountExceeded() bool {
+	e.MetricsLock.Lock()
+	defer e.MetricsLock.Unlock()
+	return e.errorCountExceeded
+}
+
 // Stop closes a signal channel, forcing a running Engine to return
 func (e *Engine) Stop() {
 	e.stopOnce.Do(func() {
@@ -315,6 +368,25 @@ func (e *Engine) Stop() {
 	})
 }
 
+// Interrupt gracefully stops the test run, like Ctrl+C does: the scenarios stop
+// starting new iterations and the running ones have the gracefulStop periods
+// of their scenarios to finish.
+func (e *Engine) Interrupt() {
+	e.interruptOnce.Do(func() {
+		close(e.interruptChan)
+	})
+}
+
+// IsInterrupted returns whether the test run was gracefully stopped.
+func (e *Engine) IsInterrupted() bool {
+	select {
+	case <-e.interruptChan:
+		return true
+	default:
+		return false
+	}
+}
+
 // IsStopped returns a bool indicating whether the Engine has been stopped
 func (e *Engine) IsStopped() bool {
 	select {
@@ -337,27 +409,45 @@ func (e *Engine) runMetricsEmission(ctx context.Context) {
 	}
 }
 
+// connPoolStatsRunner is implemented by the runners that record the
+// statistics of the HTTP connection pools of their VUs.
+type connPoolStatsRunner interface {
+	GetConnPoolStats() *lib.ConnPoolStats
+}
+
 func (e *Engine) emitMetrics() {
 	t := time.Now()
 
 	executionState := e.ExecutionScheduler.GetState()
+	samples := []stats.Sample{
+		{
+			Time:   t,
+			Metric: metrics.VUs,
+			Value:  float64(executionState.GetCurrentlyActiveVUsCount()),
+			Tags:   e.Options.RunTags,
+		}, {
+			Time:   t,
+			Metric: metrics.VUsMax,
+			Value:  float64(executionState.GetInitializedVUsCount()),
+			Tags:   e.Options.RunTags,
+		},
+	}
+	// The connection pool metrics are only emitted once there are connections
+	if r, ok := e.ExecutionScheduler.GetRunner().(connPoolStatsRunner); ok {
+		if poolStats := r.GetConnPoolStats(); poolStats != nil && poolStats.Used() {
+			idle, active := poolStats.Get()
+			samples = append(samples,
+				stats.Sample{Time: t, Metric: metrics.HTTPConnPoolIdle, Value: float64(idle), Tags: e.Options.RunTags},
+				stats.Sample{Time: t, Metric: metrics.HTTPConnPoolActive, Value: float64(active), Tags: e.Options.RunTags},
+			)
+		}
+	}
+
 	// TODO: optimize and move this, it shouldn't call processSamples() directly
 	e.processSamples([]stats.SampleContainer{stats.ConnectedSamples{
-		Samples: []stats.Sample{
-			{
-				Time:   t,
-				Metric: metrics.VUs,
-				Value:  float64(executionState.GetCurrentlyActiveVUsCount()),
-				Tags:   e.Options.RunTags,
-			}, {
-				Time:   t,
-				Metric: metrics.VUsMax,
-				Value:  float64(executionState.GetInitializedVUsCount()),
-				Tags:   e.Options.RunTags,
-			},
-		},
-		Tags: e.Options.RunTags,
-		Time: t,
+		Samples: samples,
+		Tags:    e.Options.RunTags,
+		Time:    t,
 	}})
 }
 
@@ -375,7 +465,7 @@ func (e *Engine) processThresholds() (shouldAbort bool) {
 		m.Tainted = null.BoolFrom(false)
 
 		e.logger.WithField("m", m.Name).Debug("running thresholds")
-		succ, err := m.Thresholds.Run(m.Sink, t)
+		succ, err := m.Thresholds.RunWithMetrics(m.Sink, t, e.getMetricSink)
 		if err != nil {
 			e.logger.WithField("m", m.Name).WithError(err).Error("Threshold error")
 			continue
@@ -393,6 +483,25 @@ func (e *Engine) processThresholds() (shouldAbort bool) {
 	return shouldAbort
 }
 
+// getMetricSink returns the sink of the metric with the given name, for the
+// thresholds that reference other metrics. The metrics lock has to be held.
+func (e *Engine) getMetricSink(name string) stats.Sink {
+	if m, ok := e.Metrics[name]; ok {
+		return m.Sink
+	}
+	return nil
+}
+
+// newMetric returns a new metric for aggregating the samples, with a sketch
+// for the Trend metrics if the trendSketchAccuracy option is set.
+func (e *Engine) newMetric(name string, typ stats.MetricType, contains stats.ValueType) *stats.Metric {
+	m := stats.New(name, typ, contains)
+	if typ == stats.Trend && e.Options.TrendSketchAccuracy.Valid {
+		m.Sink = stats.NewSketchTrendSink(e.Options.TrendSketchAccuracy.Float64)
+	}
+	return m
+}
+
 func (e *Engine) processSamplesForMetrics(sampleContainers []stats.SampleContainer) {
 	for _, sampleContainer := range sampleContainers {
 		samples := sampleContainer.GetSamples()
@@ -404,7 +513,7 @@ func (e *Engine) processSamplesForMetrics(sampleContainers []stats.SampleContain
 		for _, sample := range samples {
 			m, ok := e.Metrics[sample.Metric.Name]
 			if !ok {
-				m = stats.New(sample.Metric.Name, sample.Metric.Type, sample.Metric.Contains)
+				m = e.newMetric(sample.Metric.Name, sample.Metric.Type, sample.Metric.Contains)
 				m.Thresholds = e.thresholds[m.Name]
 				m.Submetrics = e.submetrics[m.Name]
 				e.Metrics[m.Name] = m
@@ -417,7 +526,7 @@ func (e *Engine) processSamplesForMetrics(sampleContainers []stats.SampleContain
 				}
 
 				if sm.Metric == nil {
-					sm.Metric = stats.New(sm.Name, sample.Metric.Type, sample.Metric.Contains)
+					sm.Metric = e.newMetric(sm.Name, sample.Metric.Type, sample.Metric.Contains)
 					sm.Metric.Sub = *sm
 					sm.Metric.Thresholds = e.thresholds[sm.Name]
 					e.Metrics[sm.Name] = sm.Metric
@@ -441,8 +550,42 @@ func (e *Engine) processSamples(sampleContainers []stats.SampleContainer) {
 	if !(e.NoSummary && e.NoThresholds && !e.SummaryExport) {
 		e.processSamplesForMetrics(sampleContainers)
 	}
+	for _, sc := range sampleContainers {
+		if len(e.Waterfall) >= e.WaterfallSize {
+			break
+		}
+		if trail, ok := sc.(*httpext.Trail); ok {
+			e.Waterfall = append(e.Waterfall, trail)
+		}
+	}
+
+	if e.Options.AbortOnErrorCount.Valid {
+		e.countFailedRequests(sampleContainers)
+	}
 
 	for _, collector := range e.Collectors {
 		collector.Collect(sampleContainers)
 	}
 }
+
+// countFailedRequests adds up the http_req_failed samples and gracefully
+// interrupts the test run once there are more of them than the
+// abortOnErrorCount option allows. The metrics lock has to be held.
+func (e *Engine) countFailedRequests(sampleContainers []stats.SampleContainer) {
+	if e.errorCountExceeded {
+		return
+	}
+	for _, sc := range sampleContainers {
+		for _, sample := range sc.GetSamples() {
+			if sample.Metric.Name == metrics.HTTPReqFailed.Name {
+				e.failedRequests += int64(sample.Value)
+			}
+		}
+	}
+	if e.failedRequests > e.Options.AbortOnErrorCount.Int64 {
+		e.logger.WithField("failed", e.failedRequests).Errorf(
+			"More than %d HTTP requests have failed, aborting the test run", e.Options.AbortOnErrorCount.Int64)
+		e.errorCountExceeded = true
+		e.Interrupt()
+	}
+}
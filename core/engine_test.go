@@ -38,6 +38,7 @@ import (
 	"github.com/loadimpact/k6/lib"
 	"github.com/loadimpact/k6/lib/executor"
 	"github.com/loadimpact/k6/lib/metrics"
+	"github.com/loadimpact/k6/lib/netext/httpext"
 	"github.com/loadimpact/k6/lib/testutils"
 	"github.com/loadimpact/k6/lib/testutils/httpmultibin"
 	"github.com/loadimpact/k6/lib/testutils/minirunner"
@@ -181,6 +182,29 @@ func TestEngineAtTime(t *testing.T) {
 	assert.NoError(t, run())
 }
 
+func TestEngineInterrupted(t *testing.T) {
+	runner := &minirunner.MiniRunner{Fn: func(ctx context.Context, out chan<- stats.SampleContainer) error {
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	}}
+	c := &dummy.Collector{}
+	e, run, wait := newTestEngine(t, nil, runner, []lib.Collector{c}, lib.Options{
+		VUs:      null.IntFrom(1),
+		Duration: types.NullDurationFrom(20 * time.Second),
+	})
+	time.AfterFunc(100*time.Millisecond, e.Interrupt)
+
+	// The running iterations are finished, without waiting for the duration
+	start := time.Now()
+	assert.NoError(t, run())
+	assert.True(t, time.Since(start) < 10*time.Second)
+	assert.True(t, e.IsInterrupted())
+	assert.False(t, e.IsStopped())
+	e.Interrupt() // test that a second interrupt doesn't panic
+	wait()
+	assert.Equal(t, lib.RunStatusAbortedUser, c.RunStatus)
+}
+
 func TestEngineStopped(t *testing.T) {
 	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
 	defer cancel()
@@ -267,6 +291,43 @@ func TestEngine_processSamples(t *testing.T) {
 		assert.IsType(t, &stats.GaugeSink{}, e.Metrics["my_metric"].Sink)
 		assert.IsType(t, &stats.GaugeSink{}, e.Metrics["my_metric{a:1}"].Sink)
 	})
+	t.Run("trend sketch", func(t *testing.T) {
+		ths, err := stats.NewThresholds([]string{`p(95)<100`})
+		assert.NoError(t, err)
+
+		e, _, wait := newTestEngine(t, nil, nil, nil, lib.Options{
+			TrendSketchAccuracy: null.FloatFrom(0.01),
+			Thresholds:          map[string]stats.Thresholds{"my_trend{a:1}": ths},
+		})
+		defer wait()
+
+		trend := stats.New("my_trend", stats.Trend)
+		e.processSamples(
+			[]stats.SampleContainer{stats.Sample{Metric: trend, Value: 1.25, Tags: stats.IntoSampleTags(&map[string]string{"a": "1"})}},
+		)
+
+		for _, name := range []string{"my_trend", "my_trend{a:1}"} {
+			sink, ok := e.Metrics[name].Sink.(*stats.TrendSink)
+			require.True(t, ok)
+			assert.NotNil(t, sink.Sketch)
+			assert.Empty(t, sink.Values)
+			assert.Equal(t, 1.25, sink.P(0.95))
+		}
+	})
+	t.Run("waterfall", func(t *testing.T) {
+		e, _, wait := newTestEngine(t, nil, nil, nil, lib.Options{})
+		defer wait()
+		e.WaterfallSize = 2
+
+		trails := []*httpext.Trail{{Waiting: 1}, {Waiting: 2}, {Waiting: 3}}
+		for _, trail := range trails {
+			trail.SaveSamples(stats.IntoSampleTags(&map[string]string{"method": "GET"}))
+		}
+		e.processSamples([]stats.SampleContainer{stats.Sample{Metric: metric, Value: 1}, trails[0]})
+		e.processSamples([]stats.SampleContainer{trails[1], trails[2]})
+
+		assert.Equal(t, trails[:2], e.Waterfall)
+	})
 }
 
 func TestEngineThresholdsWillAbort(t *testing.T) {
@@ -319,6 +380,74 @@ func TestEngineAbortedByThresholds(t *testing.T) {
 	}
 }
 
+func TestEngineAbortedByErrorCount(t *testing.T) {
+	runner := &minirunner.MiniRunner{Fn: func(ctx context.Context, out chan<- stats.SampleContainer) error {
+		out <- stats.Sample{Metric: metrics.HTTPReqFailed, Value: 1, Time: time.Now()}
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	}}
+	c := &dummy.Collector{}
+	e, run, wait := newTestEngine(t, nil, runner, []lib.Collector{c}, lib.Options{
+		VUs:               null.IntFrom(1),
+		Duration:          types.NullDurationFrom(20 * time.Second),
+		AbortOnErrorCount: null.IntFrom(2),
+	})
+
+	// The running iterations are finished, without waiting for the duration
+	start := time.Now()
+	assert.NoError(t, run())
+	assert.True(t, time.Since(start) < 10*time.Second)
+	assert.True(t, e.ErrorCountExceeded())
+	assert.True(t, e.IsInterrupted())
+	wait()
+	assert.Equal(t, lib.RunStatusAbortedSystem, c.RunStatus)
+}
+
+func TestEngine_countFailedRequests(t *testing.T) {
+	e, _, wait := newTestEngine(t, nil, nil, nil, lib.Options{AbortOnErrorCount: null.IntFrom(1)})
+	defer wait()
+
+	ok := &httpext.Trail{}
+	ok.SaveSamples(stats.IntoSampleTags(&map[string]string{"status": "200"}))
+	failed := &httpext.Trail{Failed: true}
+	failed.SaveSamples(stats.IntoSampleTags(&map[string]string{"status": "500"}))
+
+	e.processSamples([]stats.SampleContainer{ok, failed, ok})
+	assert.False(t, e.ErrorCountExceeded())
+	e.processSamples([]stats.SampleContainer{failed})
+	assert.True(t, e.ErrorCountExceeded())
+	assert.True(t, e.IsInterrupted())
+}
+
+type connPoolStatsMiniRunner struct {
+	minirunner.MiniRunner
+	poolStats *lib.ConnPoolStats
+}
+
+func (r *connPoolStatsMiniRunner) GetConnPoolStats() *lib.ConnPoolStats {
+	return r.poolStats
+}
+
+func TestEngineConnPoolMetrics(t *testing.T) {
+	runner := &connPoolStatsMiniRunner{poolStats: &lib.ConnPoolStats{}}
+	e, _, wait := newTestEngine(t, nil, runner, nil, lib.Options{})
+	defer wait()
+
+	e.emitMetrics()
+	assert.NotContains(t, e.Metrics, metrics.HTTPConnPoolIdle.Name, "no connections were made yet")
+
+	for i := 0; i < 3; i++ {
+		runner.poolStats.ConnOpened()
+	}
+	runner.poolStats.ConnAcquired()
+	e.emitMetrics()
+
+	e.MetricsLock.Lock()
+	defer e.MetricsLock.Unlock()
+	assert.Equal(t, 2.0, e.Metrics[metrics.HTTPConnPoolIdle.Name].Sink.(*stats.GaugeSink).Value)
+	assert.Equal(t, 1.0, e.Metrics[metrics.HTTPConnPoolActive.Name].Sink.(*stats.GaugeSink).Value)
+}
+
 func TestEngine_processThresholds(t *testing.T) {
 	metric := stats.New("my_metric", stats.Gauge)
 
@@ -335,6 +464,10 @@ func TestEngine_processThresholds(t *testing.T) {
 		"submetric,match,failing":   {false, map[string][]string{"my_metric{a:1}": {"1+1==3"}}, false},
 		"submetric,nomatch,passing": {true, map[string][]string{"my_metric{a:2}": {"1+1==2"}}, false},
 		"submetric,nomatch,failing": {true, map[string][]string{"my_metric{a:2}": {"1+1==3"}}, false},
+
+		"reference,passing": {true, map[string][]string{"my_metric": {`value <= metric("my_metric{a:1}").value`}}, false},
+		"reference,failing": {false, map[string][]string{"my_metric": {`value < metric("my_metric{a:1}").value`}}, false},
+		"reference,missing": {true, map[string][]string{"my_metric": {`value < metric("other").value`}}, false},
 	}
 
 	for name, data := range testdata {
@@ -456,6 +589,8 @@ func TestSentReceivedMetrics(t *testing.T) {
 			InsecureSkipTLSVerify: null.BoolFrom(true),
 			NoVUConnectionReuse:   null.BoolFrom(noConnReuse),
 			Batch:                 null.IntFrom(20),
+			// The traceparent headers would be counted in the sent data
+			TraceContext: &lib.TraceContext{Propagator: null.StringFrom(lib.TraceContextNone)},
 		})
 
 		errC := make(chan error)
@@ -605,7 +740,7 @@ func TestRunTags(t *testing.T) {
 
 	systemMetrics := []*stats.Metric{
 		metrics.VUs, metrics.VUsMax, metrics.Iterations, metrics.IterationDuration,
-		metrics.GroupDuration, metrics.DataSent, metrics.DataReceived,
+		metrics.GroupDuration, metrics.DataSent, metrics.DataReceived, metrics.TLSCertExpirySeconds,
 	}
 
 	getExpectedOverVal := func(metricName string) string {
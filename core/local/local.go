@@ -24,6 +24,7 @@ import (
 	"context"
 	"fmt"
 	"runtime"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -49,6 +50,14 @@ type ExecutionScheduler struct {
 	state           *lib.ExecutionState
 }
 
+// executorRun tracks if an executor has finished, so the executors of the
+// scenarios that depend on it can be started.
+type executorRun struct {
+	executor  lib.Executor
+	done      chan struct{}
+	succeeded bool // set before done is closed, false if the executor was skipped
+}
+
 // Check to see if we implement the lib.ExecutionScheduler interface
 var _ lib.ExecutionScheduler = &ExecutionScheduler{}
 
@@ -285,12 +294,15 @@ func (e *ExecutionScheduler) Init(ctx context.Context, samplesOut chan<- stats.S
 
 // runExecutor gets called by the public Run() method once per configured
 // executor, each time in a new goroutine. It is responsible for waiting out the
-// configured startTime for the specific executor and then running its Run()
-// method.
+// configured startTime for the specific executor and for the scenarios it
+// depends on to finish, and then running its Run() method.
 func (e *ExecutionScheduler) runExecutor(
 	runCtx context.Context, runResults chan<- error, engineOut chan<- stats.SampleContainer, executor lib.Executor,
+	runs map[string]*executorRun,
 ) {
 	executorConfig := executor.GetConfig()
+	run := runs[executorConfig.GetName()]
+	defer close(run.done)
 	executorStartTime := executorConfig.GetStartTime()
 	executorLogger := e.logger.WithFields(logrus.Fields{
 		"executor":  executorConfig.GetName(),
@@ -315,17 +327,45 @@ func (e *ExecutionScheduler) runExecutor(
 		case <-runCtx.Done():
 			runResults <- nil // no error since executor hasn't started yet
 			return
+		case <-lib.GetInterrupt(runCtx):
+			executorProgress.Modify(pb.WithStatus(pb.Interrupted), pb.WithConstProgress(0, "not started"))
+			runResults <- nil
+			return
 		case <-time.After(executorStartTime):
 			// continue
 		}
 	}
 
+	if deps := executorConfig.GetDependsOn(); len(deps) > 0 {
+		executorProgress.Modify(
+			pb.WithStatus(pb.Waiting),
+			pb.WithConstProgress(0, "waiting for "+strings.Join(deps, ", ")),
+		)
+		executorLogger.Debugf("Waiting for the scenarios it depends on...")
+		skipReason, interrupted := waitForDependencies(runCtx, executorConfig, runs)
+		if interrupted {
+			executorProgress.Modify(pb.WithStatus(pb.Interrupted), pb.WithConstProgress(0, "not started"))
+			runResults <- nil // no error since executor hasn't started yet
+			return
+		}
+		if skipReason != "" {
+			executorLogger.Warnf("Skipping scenario %s, since %s", executorConfig.GetName(), skipReason)
+			executorProgress.Modify(pb.WithStatus(pb.Interrupted), pb.WithConstProgress(0, "skipped"))
+			runResults <- nil
+			return
+		}
+	}
+
 	executorProgress.Modify(
 		pb.WithStatus(pb.Running),
 		pb.WithConstProgress(0, "started"),
 	)
 	executorLogger.Debugf("Starting executor")
-	err := executor.Run(runCtx, engineOut) // executor should handle context cancel itself
+	executorCtx, executorCancel := context.WithCancel(runCtx)
+	defer executorCancel()
+	go hardStopAfterInterrupt(executorCtx, executorCancel, executorConfig.GetGracefulStop())
+	err := executor.Run(executorCtx, engineOut) // executor should handle context cancel itself
+	run.succeeded = err == nil
 	if err == nil {
 		executorLogger.Debugf("Executor finished successfully")
 	} else {
@@ -334,6 +374,61 @@ func (e *ExecutionScheduler) runExecutor(
 	runResults <- err
 }
 
+// hardStopAfterInterrupt cancels the context of an executor when the graceful
+// stop period of its scenario is over after the test is interrupted. Until
+// then, the executor is expected to only wait for its running iterations.
+func hardStopAfterInterrupt(
+	executorCtx context.Context, executorCancel func(), gracefulStop time.Duration,
+) {
+	select {
+	case <-lib.GetInterrupt(executorCtx):
+	case <-executorCtx.Done():
+		return
+	}
+	timer := time.NewTimer(gracefulStop)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		executorCancel()
+	case <-executorCtx.Done():
+	}
+}
+
+// waitForDependencies waits for the scenarios that the executor depends on to
+// finish, and returns why it should be skipped, if it should be. It returns
+// interrupted if the context was done or the test was interrupted before that.
+// The scenarios that have no work, so no executors, are considered finished.
+func waitForDependencies(
+	ctx context.Context, config lib.ExecutorConfig, runs map[string]*executorRun,
+) (skipReason string, interrupted bool) {
+	for _, dep := range config.GetDependsOn() {
+		depRun, ok := runs[dep]
+		if !ok {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return "", true
+		case <-lib.GetInterrupt(ctx):
+			return "", true
+		case <-depRun.done:
+		}
+		if !depRun.succeeded {
+			return fmt.Sprintf("the scenario %s that it depends on didn't finish successfully", dep), false
+		}
+		if errors := depRun.executor.GetIterationErrors(); errors > config.GetDependencyMaxErrors() {
+			return fmt.Sprintf("the scenario %s that it depends on had %d iteration errors, more than the %d allowed",
+				dep, errors, config.GetDependencyMaxErrors()), false
+		}
+	}
+	select {
+	case <-lib.GetInterrupt(ctx): // the dependencies could've finished because of it
+		return "", true
+	default:
+		return "", false
+	}
+}
+
 // Run the ExecutionScheduler, funneling all generated metric samples through the supplied
 // out channel.
 func (e *ExecutionScheduler) Run(globalCtx, runCtx context.Context, engineOut chan<- stats.SampleContainer) error {
@@ -379,8 +474,12 @@ func (e *ExecutionScheduler) Run(globalCtx, runCtx context.Context, engineOut ch
 	// Start all executors at their particular startTime in a separate goroutine...
 	logger.Debug("Start all executors...")
 	e.state.SetExecutionStatus(lib.ExecutionStatusRunning)
+	runs := make(map[string]*executorRun, len(e.executors))
+	for _, exec := range e.executors {
+		runs[exec.GetConfig().GetName()] = &executorRun{executor: exec, done: make(chan struct{})}
+	}
 	for _, exec := range e.executors {
-		go e.runExecutor(runSubCtx, runResults, engineOut, exec)
+		go e.runExecutor(runSubCtx, runResults, engineOut, exec, runs)
 	}
 
 	// Wait for all executors to finish
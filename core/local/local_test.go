@@ -25,9 +25,12 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"reflect"
 	"runtime"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -674,7 +677,7 @@ func TestExecutionSchedulerRunCustomConfigNoCrossover(t *testing.T) {
 			}
 		}
 	}
-	require.Equal(t, 8, gotSampleTags, "received wrong amount of samples with expected tags")
+	require.Equal(t, 10, gotSampleTags, "received wrong amount of samples with expected tags")
 }
 
 func TestExecutionSchedulerSetupTeardownRun(t *testing.T) {
@@ -1271,3 +1274,125 @@ func TestNewExecutionSchedulerHasWork(t *testing.T) {
 	assert.Len(t, execScheduler.executors, 2)
 	assert.Len(t, execScheduler.executorConfigs, 3)
 }
+
+func TestExecutionSchedulerDependsOn(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	script := []byte(fmt.Sprintf(`
+		import http from 'k6/http';
+
+		export let options = {
+			scenarios: {
+				a: { executor: "shared-iterations", vus: 2, iterations: 2, exec: "a" },
+				b: { executor: "shared-iterations", vus: 1, iterations: 1, exec: "b", dependsOn: ["a"] },
+				c: { executor: "shared-iterations", vus: 1, iterations: 1, exec: "c", dependsOn: ["b"] },
+				d: {
+					executor: "shared-iterations", vus: 1, iterations: 1, exec: "d",
+					dependsOn: ["a", "b"], dependsOnWith: { maxErrors: 1 },
+				},
+				e: { executor: "shared-iterations", vus: 1, iterations: 1, exec: "e", dependsOn: ["c"] },
+			},
+		};
+		export function a() { http.get("%[1]s/a"); }
+		export function b() { http.get("%[1]s/b"); throw new Error("b failed"); }
+		export function c() { http.get("%[1]s/c"); }
+		export function d() { http.get("%[1]s/d"); }
+		export function e() { http.get("%[1]s/e"); }
+	`, srv.URL))
+
+	logger := logrus.New()
+	logger.SetOutput(testutils.NewTestOutput(t))
+	runner, err := js.New(logger, &loader.SourceData{URL: &url.URL{Path: "/script.js"}, Data: script}, nil, lib.RuntimeOptions{})
+	require.NoError(t, err)
+
+	ctx, cancel, execScheduler, samples := newTestExecutionScheduler(t, runner, logger, lib.Options{})
+	defer cancel()
+	require.NoError(t, execScheduler.Run(ctx, ctx, samples))
+
+	// c is skipped, since b had an error, and so is e, which depends on c
+	assert.Equal(t, []string{"/a", "/a", "/b", "/d"}, paths)
+}
+
+func TestExecutionSchedulerDependsOnValidation(t *testing.T) {
+	t.Parallel()
+	script := []byte(`
+		export let options = {
+			scenarios: {
+				a: { executor: "shared-iterations", dependsOn: ["c"] },
+				b: { executor: "shared-iterations", dependsOn: ["a"] },
+				c: { executor: "shared-iterations", dependsOn: ["b"] },
+				d: { executor: "shared-iterations", dependsOn: ["x"] },
+			},
+		};
+		export default function() {}
+	`)
+	runner, err := js.New(testutils.NewLogger(t), &loader.SourceData{URL: &url.URL{Path: "/script.js"}, Data: script},
+		nil, lib.RuntimeOptions{})
+	require.NoError(t, err)
+	errs := runner.GetOptions().Scenarios.Validate()
+	require.Len(t, errs, 2)
+	assert.Contains(t, errs[0].Error()+errs[1].Error(), "scenario d depends on the unknown scenario x")
+	assert.Contains(t, errs[0].Error()+errs[1].Error(), "circular dependency: a -> c -> b -> a")
+}
+
+func TestExecutionSchedulerInterruptGracefulStop(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	var paths []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	script := []byte(fmt.Sprintf(`
+		import http from 'k6/http';
+		import { sleep } from 'k6';
+
+		export let options = {
+			scenarios: {
+				long: { executor: "constant-vus", vus: 1, duration: "10s", gracefulStop: "5s", exec: "long" },
+				short: { executor: "constant-vus", vus: 1, duration: "10s", gracefulStop: "200ms", exec: "short" },
+				ramping: {
+					executor: "ramping-vus", startVUs: 1, stages: [{ duration: "10s", target: 1 }],
+					gracefulStop: "5s", exec: "long",
+				},
+				later: { executor: "constant-vus", vus: 1, duration: "10s", startTime: "5s", exec: "short" },
+			},
+		};
+		export function long() { sleep(2); http.get("%[1]s/long"); }
+		export function short() { sleep(2); http.get("%[1]s/short"); }
+	`, srv.URL))
+
+	logger := logrus.New()
+	logger.SetOutput(testutils.NewTestOutput(t))
+	runner, err := js.New(logger, &loader.SourceData{URL: &url.URL{Path: "/script.js"}, Data: script}, nil, lib.RuntimeOptions{})
+	require.NoError(t, err)
+
+	ctx, cancel, execScheduler, samples := newTestExecutionScheduler(t, runner, logger, lib.Options{})
+	defer cancel()
+	interrupt := make(chan struct{})
+	runCtx := lib.WithInterrupt(ctx, interrupt)
+	time.AfterFunc(500*time.Millisecond, func() { close(interrupt) })
+	activeVUs := make(chan int64, 1)
+	time.AfterFunc(1200*time.Millisecond, func() {
+		activeVUs <- execScheduler.GetState().GetCurrentlyActiveVUsCount()
+	})
+
+	start := time.Now()
+	require.NoError(t, execScheduler.Run(ctx, runCtx, samples))
+	assert.True(t, time.Since(start) < 3*time.Second, time.Since(start))
+	// The short scenario was stopped after 200ms, while the long ones were
+	// still finishing their iterations, and the later one never started
+	assert.Equal(t, int64(2), <-activeVUs)
+	assert.Equal(t, []string{"/long", "/long"}, paths)
+}
@@ -1,6 +1,6 @@
 module github.com/loadimpact/k6
 
-go 1.14
+go 1.16
 
 require (
 	github.com/Azure/go-ntlmssp v0.0.0-20180810175552-4a21cbd618b4
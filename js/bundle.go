@@ -187,6 +187,14 @@ func (b *Bundle) makeArchive() *lib.Archive {
 	return arc
 }
 
+// getenv returns the value of an environment variable for the ${VAR}
+// placeholders in the script options. Only the ones of the bundle are used,
+// which include the system ones only if they aren't disabled, so that they
+// don't end up in the options of the archive.
+func (b *Bundle) getenv(key string) string {
+	return b.Env[key]
+}
+
 // getExports validates and extracts exported objects
 func (b *Bundle) getExports(rt *goja.Runtime, options bool) error {
 	exportsV := rt.Get("exports")
@@ -210,6 +218,9 @@ func (b *Bundle) getExports(rt *goja.Runtime, options bool) error {
 			if err != nil {
 				return err
 			}
+			if data, err = lib.ExpandEnvInJSON(data, b.getenv); err != nil {
+				return err
+			}
 			if err := json.Unmarshal(data, &b.Options); err != nil {
 				return err
 			}
@@ -24,6 +24,7 @@ import (
 	"crypto/tls"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -208,6 +209,37 @@ func TestNewBundle(t *testing.T) {
 				assert.Equal(t, null.BoolFrom(true), b.Options.Paused)
 			}
 		})
+		t.Run("EnvPlaceholders", func(t *testing.T) {
+			b, err := getSimpleBundle(t, "/script.js", `
+				export let options = {
+					userAgent: "k6/${AGENT_VERSION} (${AGENT_NAME})",
+					hosts: { "example.com": "${HOST_IP}" },
+					vus: 10,
+				};
+				export default function() {};
+			`, lib.RuntimeOptions{Env: map[string]string{
+				"AGENT_VERSION": "1.0", "AGENT_NAME": "tests", "HOST_IP": "127.0.0.1",
+			}})
+			if assert.NoError(t, err) {
+				assert.Equal(t, null.StringFrom("k6/1.0 (tests)"), b.Options.UserAgent)
+				assert.Equal(t, net.ParseIP("127.0.0.1"), b.Options.Hosts["example.com"].IP)
+				assert.Equal(t, null.IntFrom(10), b.Options.VUs)
+			}
+		})
+		t.Run("EnvPlaceholdersNoSystemEnv", func(t *testing.T) {
+			// The system env vars aren't used when they're disabled, so
+			// they can't leak into the options of the archive
+			os.Setenv("K6_TEST_SECRET", "secret") //nolint:errcheck
+			defer os.Unsetenv("K6_TEST_SECRET")   //nolint:errcheck
+			b, err := getSimpleBundle(t, "/script.js", `
+				export let options = { userAgent: "k6 ${K6_TEST_SECRET}" };
+				export default function() {};
+			`, lib.RuntimeOptions{IncludeSystemEnvVars: null.BoolFrom(false), Env: map[string]string{}})
+			if assert.NoError(t, err) {
+				assert.Equal(t, null.StringFrom("k6 "), b.Options.UserAgent)
+				assert.Equal(t, null.StringFrom("k6 "), b.makeArchive().Options.UserAgent)
+			}
+		})
 		t.Run("VUs", func(t *testing.T) {
 			b, err := getSimpleBundle(t, "/script.js", `
 				export let options = {
@@ -36,6 +36,7 @@ var (
 	errorT  = reflect.TypeOf((*error)(nil)).Elem()
 	jsValT  = reflect.TypeOf((*goja.Value)(nil)).Elem()
 	fnCallT = reflect.TypeOf((*goja.FunctionCall)(nil)).Elem()
+	bytesT  = reflect.TypeOf([]byte(nil))
 
 	constructWrap = goja.MustCompile(
 		"__constructor__",
@@ -160,7 +161,14 @@ func Bind(rt *goja.Runtime, v interface{}, ctxPtr *context.Context) map[string]i
 				wantsContextPtr = true
 			}
 		}
-		if hasError || wantsContext || wantsContextPtr {
+		// goja can't convert ArrayBuffers to []byte by itself, see exportTo()
+		wantsBytes := false
+		for j := 0; j < numIn; j++ {
+			if in := fnT.In(j); in == bytesT || (fnT.IsVariadic() && j == numIn-1 && in.Elem() == bytesT) {
+				wantsBytes = true
+			}
+		}
+		if hasError || wantsContext || wantsContextPtr || wantsBytes {
 			isVariadic := fnT.IsVariadic()
 			realFn := fn
 			fn = reflect.ValueOf(func(call goja.FunctionCall) goja.Value {
@@ -207,7 +215,7 @@ func Bind(rt *goja.Runtime, v interface{}, ctxPtr *context.Context) map[string]i
 						for j := 0; j < varArgsLen; j++ {
 							arg := call.Arguments[i+j-reservedArgs]
 							v := reflect.New(emT)
-							if err := rt.ExportTo(arg, v.Interface()); err != nil {
+							if err := exportTo(rt, arg, v); err != nil {
 								Throw(rt, err)
 							}
 							varArgs.Index(j).Set(v.Elem())
@@ -230,7 +238,7 @@ func Bind(rt *goja.Runtime, v interface{}, ctxPtr *context.Context) map[string]i
 
 					// Allocate a T* and export the JS value to it.
 					v := reflect.New(T)
-					if err := rt.ExportTo(arg, v.Interface()); err != nil {
+					if err := exportTo(rt, arg, v); err != nil {
 						Throw(rt, err)
 					}
 					args[i] = v.Elem()
@@ -280,3 +288,26 @@ func Bind(rt *goja.Runtime, v interface{}, ctxPtr *context.Context) map[string]i
 
 	return exports
 }
+
+// exportTo exports the JS value v to the Go value that target points to, like
+// rt.ExportTo(). Unlike it, it also converts ArrayBuffers, e.g. the bodies of
+// binary responses, to []byte.
+func exportTo(rt *goja.Runtime, v goja.Value, target reflect.Value) error {
+	if target.Type().Elem() == bytesT {
+		if obj, ok := v.(*goja.Object); ok {
+			if ab, ok := obj.Export().(goja.ArrayBuffer); ok {
+				target.Elem().SetBytes(ab.Bytes())
+				return nil
+			}
+		}
+	}
+	return rt.ExportTo(v, target.Interface())
+}
+
+// ToBytes converts the JS value to a []byte, the same way as the []byte
+// parameters of the bound methods are, so ArrayBuffers are accepted as well.
+func ToBytes(rt *goja.Runtime, v goja.Value) ([]byte, error) {
+	var b []byte
+	err := exportTo(rt, v, reflect.ValueOf(&b))
+	return b, err
+}
@@ -21,6 +21,7 @@
 package common
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
@@ -178,6 +179,12 @@ func (bridgeTestSumType) Sum(nums ...int) int {
 	return sum
 }
 
+type bridgeTestBytesType struct{}
+
+func (bridgeTestBytesType) Len(b []byte) int { return len(b) }
+
+func (bridgeTestBytesType) Join(bs ...[]byte) string { return string(bytes.Join(bs, nil)) }
+
 type bridgeTestSumWithContextType struct{}
 
 func (bridgeTestSumWithContextType) SumWithContext(ctx context.Context, nums ...int) int {
@@ -586,6 +593,26 @@ func TestBind(t *testing.T) {
 				})
 			}
 		}},
+		{"Bytes", bridgeTestBytesType{}, func(t *testing.T, obj interface{}, rt *goja.Runtime) {
+			t.Run("ArrayBuffer", func(t *testing.T) {
+				v, err := RunString(rt, `obj.len(new Uint8Array([1, 2, 3]).buffer)`)
+				if assert.NoError(t, err) {
+					assert.Equal(t, int64(3), v.Export())
+				}
+			})
+			t.Run("Variadic", func(t *testing.T) {
+				v, err := RunString(rt, `obj.join(new Uint8Array([97, 98]).buffer, "c")`)
+				if assert.NoError(t, err) {
+					assert.Equal(t, "abc", v.Export())
+				}
+			})
+			t.Run("String", func(t *testing.T) {
+				v, err := RunString(rt, `obj.len("abcd")`)
+				if assert.NoError(t, err) {
+					assert.Equal(t, int64(4), v.Export())
+				}
+			})
+		}},
 		{"SumWithContext", bridgeTestSumWithContextType{}, func(t *testing.T, obj interface{}, rt *goja.Runtime) {
 			_, err := RunString(rt, `obj.sumWithContext(1, 2)`)
 			assert.Contains(t, err.Error(), "GoError: sumWithContext() can only be called from within default()")
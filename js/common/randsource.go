@@ -34,9 +34,15 @@ import (
 // The returned RandSource is NOT safe for concurrent use:
 // https://golang.org/pkg/math/rand/#NewSource
 func NewRandSource() goja.RandSource {
+	return NewRand().Float64
+}
+
+// NewRand returns a new random number generator, seeded from crypto/rand.
+// Like NewRandSource(), it's NOT safe for concurrent use.
+func NewRand() *rand.Rand {
 	var seed int64
 	if err := binary.Read(crand.Reader, binary.LittleEndian, &seed); err != nil {
 		panic(fmt.Errorf("could not read random bytes: %v", err))
 	}
-	return rand.New(rand.NewSource(seed)).Float64
+	return rand.New(rand.NewSource(seed)) //nolint:gosec
 }
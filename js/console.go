@@ -22,21 +22,33 @@ package js
 
 import (
 	"context"
+	"errors"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/dop251/goja"
 	"github.com/sirupsen/logrus"
+
+	"github.com/loadimpact/k6/js/common"
+	"github.com/loadimpact/k6/lib"
 )
 
 // console represents a JS console implemented as a logrus.Logger.
 type console struct {
 	logger logrus.FieldLogger
+	timers map[string][]time.Time // started console.time() timers, stacked per label
 }
 
 // Creates a console with the standard logrus logger.
 func newConsole(logger logrus.FieldLogger) *console {
-	return &console{logger.WithField("source", "console")}
+	return &console{logger.WithField("source", "console"), make(map[string][]time.Time)}
+}
+
+// Returns a console that shares the logger of c, but has its own timers, so
+// that VUs don't see each other's console.time() labels.
+func (c console) forVU() *console {
+	return &console{c.logger, make(map[string][]time.Time)}
 }
 
 // Creates a console logger with its output set to the file at the provided `filepath`.
@@ -52,17 +64,24 @@ func newFileConsole(filepath string) (*console, error) {
 	// TODO: refactor to not rely on global variables, albeit external ones
 	l.SetFormatter(logrus.StandardLogger().Formatter)
 
-	return &console{l}, nil
+	return &console{l, make(map[string][]time.Time)}, nil
 }
 
-func (c console) log(ctx *context.Context, level logrus.Level, msgobj goja.Value, args ...goja.Value) {
+func isDone(ctx *context.Context) bool {
 	if ctx != nil && *ctx != nil {
 		select {
 		case <-(*ctx).Done():
-			return
+			return true
 		default:
 		}
 	}
+	return false
+}
+
+func (c console) log(ctx *context.Context, level logrus.Level, msgobj goja.Value, args ...goja.Value) {
+	if isDone(ctx) {
+		return
+	}
 
 	msg := msgobj.String()
 	if len(args) > 0 {
@@ -74,15 +93,36 @@ func (c console) log(ctx *context.Context, level logrus.Level, msgobj goja.Value
 
 		msg = strings.Join(strs, " ")
 	}
+	c.write(ctx, level, msg)
+}
+
+func (c console) write(ctx *context.Context, level logrus.Level, msg string) {
+	if isDone(ctx) {
+		return
+	}
+
+	logger := c.logger
+	// The context has the state of the VU, for the formatters that log its
+	// number, iteration and scenario
+	if l, ok := logger.(interface {
+		WithContext(context.Context) *logrus.Entry
+	}); ok && ctx != nil && *ctx != nil {
+		logger = l.WithContext(*ctx)
+		// The trace ID is a field too, so that it's in all of the formats,
+		// including the default text and JSON ones
+		if state := lib.GetState(*ctx); state != nil && state.TraceID != "" {
+			logger = logger.WithField("trace_id", state.TraceID)
+		}
+	}
 	switch level { //nolint:exhaustive
 	case logrus.DebugLevel:
-		c.logger.Debug(msg)
+		logger.Debug(msg)
 	case logrus.InfoLevel:
-		c.logger.Info(msg)
+		logger.Info(msg)
 	case logrus.WarnLevel:
-		c.logger.Warn(msg)
+		logger.Warn(msg)
 	case logrus.ErrorLevel:
-		c.logger.Error(msg)
+		logger.Error(msg)
 	}
 }
 
@@ -105,3 +145,75 @@ func (c console) Warn(ctx *context.Context, msg goja.Value, args ...goja.Value)
 func (c console) Error(ctx *context.Context, msg goja.Value, args ...goja.Value) {
 	c.log(ctx, logrus.ErrorLevel, msg, args...)
 }
+
+// Assert logs an error with the given message when the condition is falsy,
+// without throwing, like browsers do. When the last argument is an object
+// with only a throw property, e.g. {throw: true}, it is used as options and
+// the failed assertion also throws an exception.
+func (c console) Assert(ctx *context.Context, condition goja.Value, args ...goja.Value) {
+	if condition != nil && condition.ToBoolean() {
+		return
+	}
+
+	var throw bool
+	if n := len(args); n > 0 {
+		if opts, ok := args[n-1].(*goja.Object); ok {
+			if keys := opts.Keys(); len(keys) == 1 && keys[0] == "throw" {
+				throw = opts.Get("throw").ToBoolean()
+				args = args[:n-1]
+			}
+		}
+	}
+
+	msg := "Assertion failed"
+	if len(args) > 0 {
+		strs := make([]string, len(args))
+		for i, v := range args {
+			strs[i] = v.String()
+		}
+		msg += ": " + strings.Join(strs, " ")
+	}
+	c.write(ctx, logrus.ErrorLevel, msg)
+
+	if throw {
+		common.Throw(common.GetRuntime(*ctx), errors.New(msg))
+	}
+}
+
+func timerLabel(label goja.Value) string {
+	if label == nil || goja.IsUndefined(label) {
+		return "default"
+	}
+	return label.String()
+}
+
+// Time starts a timer with the given label. Starting a timer with a label that
+// is already in use stacks it on top of the previous one.
+func (c console) Time(ctx *context.Context, label goja.Value) {
+	l := timerLabel(label)
+	c.timers[l] = append(c.timers[l], time.Now())
+}
+
+// TimeEnd stops the most recently started timer with the given label and logs
+// the time that has elapsed since it was started.
+func (c console) TimeEnd(ctx *context.Context, label goja.Value) {
+	now := time.Now()
+	l := timerLabel(label)
+	if isDone(ctx) {
+		return
+	}
+
+	started := c.timers[l]
+	if len(started) == 0 {
+		c.logger.Warnf("Timer '%s' does not exist", l)
+		return
+	}
+	start := started[len(started)-1]
+	if len(started) == 1 {
+		delete(c.timers, l)
+	} else {
+		c.timers[l] = started[:len(started)-1]
+	}
+
+	c.logger.Infof("%s: %s", l, now.Sub(start))
+}
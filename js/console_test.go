@@ -33,6 +33,7 @@ import (
 	logtest "github.com/sirupsen/logrus/hooks/test"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gopkg.in/guregu/null.v3"
 
 	"github.com/loadimpact/k6/js/common"
@@ -48,7 +49,7 @@ func TestConsoleContext(t *testing.T) {
 
 	ctxPtr := new(context.Context)
 	logger, hook := logtest.NewNullLogger()
-	rt.Set("console", common.Bind(rt, &console{logger}, ctxPtr))
+	rt.Set("console", common.Bind(rt, &console{logger: logger}, ctxPtr))
 
 	_, err := common.RunString(rt, `console.log("a")`)
 	assert.NoError(t, err)
@@ -62,13 +63,22 @@ func TestConsoleContext(t *testing.T) {
 	assert.NoError(t, err)
 	if entry := hook.LastEntry(); assert.NotNil(t, entry) {
 		assert.Equal(t, "b", entry.Message)
+		assert.Equal(t, ctx, entry.Context)
+	}
+
+	*ctxPtr = lib.WithState(ctx, &lib.State{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736"})
+	_, err = common.RunString(rt, `console.log("traced")`)
+	assert.NoError(t, err)
+	if entry := hook.LastEntry(); assert.NotNil(t, entry) {
+		assert.Equal(t, "traced", entry.Message)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", entry.Data["trace_id"])
 	}
 
 	cancel()
 	_, err = common.RunString(rt, `console.log("c")`)
 	assert.NoError(t, err)
 	if entry := hook.LastEntry(); assert.NotNil(t, entry) {
-		assert.Equal(t, "b", entry.Message)
+		assert.Equal(t, "traced", entry.Message)
 	}
 }
 
@@ -157,9 +167,11 @@ func TestConsole(t *testing.T) {
 						assert.Equal(t, level, entry.Level)
 						assert.Equal(t, result.Message, entry.Message)
 
-						data := result.Data
-						if data == nil {
-							data = make(logrus.Fields)
+						// The iterations are traced by default
+						assert.Regexp(t, "^[0-9a-f]{32}$", entry.Data["trace_id"])
+						data := logrus.Fields{"trace_id": entry.Data["trace_id"]}
+						for k, v := range result.Data {
+							data[k] = v
 						}
 						assert.Equal(t, data, entry.Data)
 					}
@@ -169,6 +181,83 @@ func TestConsole(t *testing.T) {
 	}
 }
 
+func TestConsoleTime(t *testing.T) {
+	rt := goja.New()
+	rt.SetFieldNameMapper(common.FieldNameMapper{})
+
+	ctxPtr := new(context.Context)
+	logger, hook := logtest.NewNullLogger()
+	rt.Set("console", common.Bind(rt, newConsole(logger), ctxPtr))
+
+	_, err := common.RunString(rt, `
+		console.time("outer");
+		console.time("outer");
+		console.timeEnd("outer");
+	`)
+	require.NoError(t, err)
+	entry := hook.LastEntry()
+	require.NotNil(t, entry)
+	assert.Equal(t, logrus.InfoLevel, entry.Level)
+	assert.Regexp(t, `^outer: \S+s$`, entry.Message)
+
+	hook.Reset()
+	_, err = common.RunString(rt, `console.timeEnd("outer");`)
+	require.NoError(t, err)
+	entry = hook.LastEntry()
+	require.NotNil(t, entry)
+	assert.Equal(t, logrus.InfoLevel, entry.Level, "the stacked timer should still exist")
+
+	_, err = common.RunString(rt, `console.timeEnd("outer");`)
+	require.NoError(t, err)
+	entry = hook.LastEntry()
+	require.NotNil(t, entry)
+	assert.Equal(t, logrus.WarnLevel, entry.Level)
+	assert.Equal(t, "Timer 'outer' does not exist", entry.Message)
+
+	_, err = common.RunString(rt, `console.time(); console.timeEnd();`)
+	require.NoError(t, err)
+	entry = hook.LastEntry()
+	require.NotNil(t, entry)
+	assert.Regexp(t, `^default: `, entry.Message)
+}
+
+func TestConsoleAssert(t *testing.T) {
+	rt := goja.New()
+	rt.SetFieldNameMapper(common.FieldNameMapper{})
+
+	ctx := common.WithRuntime(context.Background(), rt)
+	logger, hook := logtest.NewNullLogger()
+	rt.Set("console", common.Bind(rt, newConsole(logger), &ctx))
+
+	_, err := common.RunString(rt, `console.assert(1 === 1, "not logged"); console.assert(true);`)
+	require.NoError(t, err)
+	assert.Empty(t, hook.AllEntries())
+
+	testdata := map[string]string{
+		`console.assert(false)`:                             "Assertion failed",
+		`console.assert(0, "value is", 0)`:                  "Assertion failed: value is 0",
+		`console.assert(null, "options", {throw: 1, a: 2})`: "Assertion failed: options [object Object]",
+		`console.assert("", "no throw", {throw: false})`:    "Assertion failed: no throw",
+	}
+	for code, msg := range testdata {
+		hook.Reset()
+		_, err := common.RunString(rt, code)
+		require.NoError(t, err, code)
+		entry := hook.LastEntry()
+		require.NotNil(t, entry, code)
+		assert.Equal(t, logrus.ErrorLevel, entry.Level, code)
+		assert.Equal(t, msg, entry.Message, code)
+	}
+
+	hook.Reset()
+	_, err = common.RunString(rt, `console.assert(undefined, "it broke", {throw: true})`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Assertion failed: it broke")
+	entry := hook.LastEntry()
+	require.NotNil(t, entry)
+	assert.Equal(t, "Assertion failed: it broke", entry.Message)
+}
+
 func TestFileConsole(t *testing.T) {
 	var (
 		levels = map[string]logrus.Level{
@@ -257,9 +346,11 @@ func TestFileConsole(t *testing.T) {
 								assert.Equal(t, level, entry.Level)
 								assert.Equal(t, result.Message, entry.Message)
 
-								data := result.Data
-								if data == nil {
-									data = make(logrus.Fields)
+								// The iterations are traced by default
+								assert.Regexp(t, "^[0-9a-f]{32}$", entry.Data["trace_id"])
+								data := logrus.Fields{"trace_id": entry.Data["trace_id"]}
+								for k, v := range result.Data {
+									data[k] = v
 								}
 								assert.Equal(t, data, entry.Data)
 
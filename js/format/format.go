@@ -0,0 +1,687 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package format implements a minimal, opinionated formatter for k6 scripts.
+//
+// It works on tokens instead of a full AST, so it never drops comments, and it
+// only makes changes that are safe without knowing the whole grammar:
+//   - lines are re-indented with two spaces per nesting level,
+//   - trailing whitespace and repeated blank lines are removed,
+//   - missing semicolons are added after statements that end on a line,
+//   - trailing commas are added to multi-line object and array literals,
+//   - the top-level import statements are sorted by module.
+package format
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const indentUnit = "  "
+
+type mode int
+
+const (
+	modeCode mode = iota
+	modeBlockComment
+	modeTemplate
+	modeString
+)
+
+// bracket is an opened and not yet closed bracket.
+type bracket struct {
+	char    byte
+	line    int  // the number of the line it was opened on, starting from 1
+	indent  int  // the indentation level of that line
+	literal bool // an object or array literal, which gets trailing commas
+	stmt    bool // closing it may end a statement, which gets a semicolon
+
+	template bool // the "${" of a template literal
+	pattern  bool // a destructuring pattern or an import/export list
+	isSwitch bool
+	sawCase  bool
+}
+
+// line is a source line with everything the formatter learned about it.
+type line struct {
+	text     string // without indentation, unless the line is raw
+	raw      bool   // kept verbatim, e.g. because it starts inside a template literal
+	star     bool   // a block comment line starting with "*"
+	codeless bool   // blank or only comments
+	indent   int
+
+	first  string // the first code token
+	last   string // the last code token
+	end    int    // the index in text after the last code token
+	closer bool   // the line starts with a closing bracket
+
+	semi   bool // ends a statement that has no semicolon
+	comma  bool // ends the last item of a multi-line literal without a comma
+	spread bool // starts with "...", which can't have trailing commas in patterns
+}
+
+type scanner struct {
+	mode    mode
+	quote   byte
+	stack   []*bracket
+	lastTok string
+	prevEnd bool // whether the previous code line ended a statement
+
+	blockClosed bool // whether the last token closed a block
+	inStmt      bool // whether the previous line may have left a statement unfinished
+	stmtLevel   int  // the bracket nesting level of that statement
+
+	lastIndent int
+	lines      []*line
+}
+
+// Source formats the given script source.
+func Source(src []byte) ([]byte, error) {
+	text := strings.ReplaceAll(string(src), "\r\n", "\n")
+	s := &scanner{prevEnd: true}
+	for i, l := range strings.Split(text, "\n") {
+		if err := s.scanLine(i+1, l); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.finish(); err != nil {
+		return nil, err
+	}
+	s.punctuate()
+	sortImports(s.lines)
+	return s.render(), nil
+}
+
+func (s *scanner) finish() error {
+	switch s.mode {
+	case modeBlockComment:
+		return fmt.Errorf("unterminated block comment")
+	case modeTemplate:
+		return fmt.Errorf("unterminated template literal")
+	case modeString:
+		return fmt.Errorf("unterminated string")
+	}
+	if len(s.stack) > 0 {
+		b := s.stack[len(s.stack)-1]
+		return fmt.Errorf("line %d: '%c' is never closed", b.line, b.char)
+	}
+	return nil
+}
+
+func (s *scanner) top() *bracket {
+	if len(s.stack) == 0 {
+		return nil
+	}
+	return s.stack[len(s.stack)-1]
+}
+
+//nolint:funlen,gocognit,gocyclo
+func (s *scanner) scanLine(num int, src string) error {
+	l := &line{end: -1}
+	s.lines = append(s.lines, l)
+
+	switch s.mode {
+	case modeTemplate, modeString:
+		l.raw, l.text, l.indent = true, src, s.lastIndent
+	case modeBlockComment:
+		l.text = strings.TrimSpace(src)
+		if strings.HasPrefix(l.text, "*") {
+			l.star = true
+		} else {
+			l.raw, l.text = true, strings.TrimRight(src, " \t")
+		}
+	default:
+		l.text = strings.TrimSpace(src)
+	}
+
+	// Lines are indented one level deeper than the line that opened the
+	// innermost bracket, unless they start by closing it.
+	startLen := len(s.stack)
+	if !l.raw {
+		closers := 0
+		if s.mode == modeCode {
+			for closers < len(l.text) && closers < startLen && strings.IndexByte(")]}", l.text[closers]) >= 0 {
+				closers++
+			}
+		}
+		l.closer = closers > 0
+		switch top := s.top(); {
+		case closers > 0:
+			l.indent = top.indent
+		case top != nil:
+			l.indent = top.indent + 1
+			if top.isSwitch && top.sawCase && !isCaseLine(l.text) {
+				l.indent++
+			}
+		}
+	}
+
+	text := l.text
+	atStmtLevel := startLen == 0
+	if top := s.top(); top != nil {
+		atStmtLevel = top.char == '{' && !top.literal && !top.template && !top.pattern
+		if top.isSwitch && s.mode == modeCode && isCaseLine(l.text) {
+			top.sawCase = true
+		}
+	}
+
+	minLen := startLen
+	poppedStmt := -1
+	for j := 0; j < len(text); {
+		c := text[j]
+		switch s.mode {
+		case modeBlockComment:
+			k := strings.Index(text[j:], "*/")
+			if k < 0 {
+				j = len(text)
+				continue
+			}
+			s.mode = modeCode
+			j += k + 2
+			continue
+		case modeString:
+			j = s.scanString(text, j)
+			if s.mode == modeCode {
+				s.tok(l, "\"", j)
+			}
+			continue
+		case modeTemplate:
+			switch {
+			case c == '\\':
+				j += 2
+			case c == '`':
+				s.mode = modeCode
+				j++
+				s.tok(l, "`", j)
+			case c == '$' && j+1 < len(text) && text[j+1] == '{':
+				s.stack = append(s.stack, &bracket{char: '{', line: num, indent: l.indent, template: true})
+				s.mode = modeCode
+				j += 2
+				s.lastTok = "{"
+			default:
+				j++
+			}
+			continue
+		}
+
+		switch {
+		case c == ' ' || c == '\t':
+			j++
+		case strings.HasPrefix(text[j:], "//"):
+			j = len(text)
+		case strings.HasPrefix(text[j:], "/*"):
+			s.mode = modeBlockComment
+			j += 2
+		case c == '"' || c == '\'':
+			s.mode, s.quote = modeString, c
+			if l.first == "" {
+				l.first = "\""
+			}
+			j = s.scanString(text, j+1)
+			if s.mode == modeCode {
+				s.tok(l, "\"", j)
+			}
+		case c == '`':
+			if l.first == "" {
+				l.first = "`"
+			}
+			s.mode = modeTemplate
+			j++
+		case c == '/' && regexAllowed(s.lastTok):
+			k := scanRegex(text, j)
+			if k < 0 {
+				return fmt.Errorf("line %d: unterminated regular expression", num)
+			}
+			if l.first == "" {
+				l.first = "/"
+			}
+			j = k
+			s.tok(l, "/re", j)
+		case c == '(' || c == '[' || c == '{':
+			if l.first == "" {
+				l.first = string(c)
+			}
+			b := &bracket{char: c, line: num, indent: l.indent}
+			switch c {
+			case '[':
+				b.literal = true
+			case '{':
+				b.literal = s.braceIsLiteral()
+				b.pattern = isPatternKeyword(s.lastTok)
+				b.isSwitch = firstWord(l.text) == "switch"
+			}
+			s.stack = append(s.stack, b)
+			j++
+			s.tok(l, string(c), j)
+		case c == ')' || c == ']' || c == '}':
+			b := s.top()
+			if b == nil {
+				return fmt.Errorf("line %d: unexpected '%c'", num, c)
+			}
+			if b.char != map[byte]byte{')': '(', ']': '[', '}': '{'}[c] {
+				return fmt.Errorf("line %d: '%c' doesn't match the '%c' opened on line %d", num, c, b.char, b.line)
+			}
+			s.stack = s.stack[:len(s.stack)-1]
+			if len(s.stack) < minLen {
+				minLen = len(s.stack)
+			}
+			if b.stmt {
+				poppedStmt = len(s.stack)
+			}
+			j++
+			if b.template {
+				s.mode = modeTemplate
+				continue
+			}
+			if l.first == "" {
+				l.first = string(c)
+			}
+			s.tok(l, string(c), j)
+			s.blockClosed = c == '}' && !b.literal
+		case isIdentChar(c):
+			k := j
+			for k < len(text) && isIdentChar(text[k]) {
+				k++
+			}
+			if l.first == "" {
+				l.first = text[j:k]
+			}
+			s.tok(l, text[j:k], k)
+			j = k
+		default:
+			tok := string(c)
+			for _, op := range []string{"...", "=>", "++", "--", "&&", "||", "??"} {
+				if strings.HasPrefix(text[j:], op) {
+					tok = op
+					break
+				}
+			}
+			if l.first == "" {
+				l.first = tok
+			}
+			j += len(tok)
+			s.tok(l, tok, j)
+		}
+	}
+	if s.mode == modeString && !strings.HasSuffix(text, "\\") {
+		return fmt.Errorf("line %d: unterminated string", num)
+	}
+
+	if l.raw {
+		return nil
+	}
+	if l.star || l.end < 0 {
+		l.codeless = true
+		s.lastIndent = l.indent
+		return nil
+	}
+	s.lastIndent = l.indent
+	l.spread = l.first == "..."
+
+	// Continuation lines of a longer expression get an extra indentation level.
+	continues := !l.closer && (isContinuationStart(l.first) || (s.prevLine() != nil && isOperator(s.prevLine().last)))
+	if continues {
+		l.indent++
+		for _, b := range s.stack[startLen:] {
+			b.indent = l.indent
+		}
+	}
+
+	// Find out whether the line ends a statement that doesn't have a semicolon.
+	startsStmt := atStmtLevel && !l.closer && (s.prevEnd && stmtStart(l.text) ||
+		continues && s.inStmt && s.stmtLevel == startLen)
+	decl := isDeclaration(l.text)
+	if s.mode == modeCode && endsValue(l.last) {
+		switch {
+		case startsStmt && minLen == startLen && len(s.stack) == startLen:
+			l.semi = !s.blockClosed || decl
+		case poppedStmt >= 0 && len(s.stack) == poppedStmt:
+			l.semi = true
+		}
+	}
+
+	// Statements that continue on the next lines end with the closing
+	// bracket of the first bracket they leave open.
+	base := -1
+	switch {
+	case poppedStmt >= 0 && len(s.stack) > poppedStmt:
+		base = poppedStmt
+	case startsStmt && minLen == startLen && len(s.stack) > startLen:
+		base = startLen
+	}
+	if base >= 0 {
+		if b := s.stack[base]; b.char != '{' || b.literal || decl || poppedStmt >= 0 {
+			b.stmt = true
+		}
+	}
+
+	// Find out whether the line ends the last item of a multi-line literal.
+	if top := s.top(); top != nil && s.mode == modeCode && top.literal && top.line < num && endsValue(l.last) {
+		l.comma = true
+	}
+
+	s.prevEnd = l.last == ";" || l.last == "{" || l.last == "}" || l.semi || l.last == ":" && isCaseLine(l.text)
+
+	// Keep track of statements that may continue on the next line.
+	s.inStmt = false
+	if s.mode == modeCode && l.last != ";" {
+		switch {
+		case startsStmt && minLen == startLen && len(s.stack) == startLen:
+			s.inStmt, s.stmtLevel = true, startLen
+		case poppedStmt >= 0 && len(s.stack) == poppedStmt:
+			s.inStmt, s.stmtLevel = true, poppedStmt
+		}
+	}
+	return nil
+}
+
+// prevLine returns the last line with code before the current one.
+func (s *scanner) prevLine() *line {
+	for i := len(s.lines) - 2; i >= 0; i-- {
+		if l := s.lines[i]; !l.codeless {
+			return l
+		}
+	}
+	return nil
+}
+
+func (s *scanner) tok(l *line, tok string, end int) {
+	s.lastTok, s.blockClosed = tok, false
+	l.last, l.end = tok, end
+}
+
+// scanString scans a string literal with the scanner's quote from the given
+// index and returns the index after it. The scanner stays in string mode if
+// the string continues on the next line.
+func (s *scanner) scanString(text string, j int) int {
+	for j < len(text) {
+		switch text[j] {
+		case '\\':
+			j += 2
+			continue
+		case s.quote:
+			s.mode = modeCode
+			return j + 1
+		}
+		j++
+	}
+	return len(text)
+}
+
+// braceIsLiteral returns whether a '{' after the last token starts an object
+// literal, and not a block.
+func (s *scanner) braceIsLiteral() bool {
+	switch s.lastTok {
+	case "(", "[", ",", "=", "?", "&&", "||", "??", "!", "return", "default":
+		return true
+	case ":":
+		top := s.top()
+		return top != nil && (top.char != '{' || top.literal)
+	}
+	return false
+}
+
+// punctuate decides which of the semicolon and trailing comma candidates
+// actually get them, based on the lines that follow.
+func (s *scanner) punctuate() {
+	for i, l := range s.lines {
+		if !l.semi && !l.comma {
+			continue
+		}
+		var next *line
+		for _, n := range s.lines[i+1:] {
+			if !n.codeless {
+				next = n
+				break
+			}
+		}
+		if l.comma {
+			l.comma = next != nil && !next.raw && (strings.HasPrefix(next.text, "}") || strings.HasPrefix(next.text, "]")) &&
+				!l.spread
+			l.semi = false
+			continue
+		}
+		l.semi = next == nil || next.raw || !continuesExpression(next)
+	}
+}
+
+func (s *scanner) render() []byte {
+	var out []string
+	blank := false
+	for _, l := range s.lines {
+		if l.raw {
+			out = append(out, l.text)
+			blank = false
+			continue
+		}
+		if l.codeless && l.text == "" {
+			blank = true
+			continue
+		}
+		if blank && len(out) > 0 && !opensBlock(out[len(out)-1]) && !l.closer {
+			out = append(out, "")
+		}
+		blank = false
+
+		text := l.text
+		switch {
+		case l.semi:
+			text = text[:l.end] + ";" + text[l.end:]
+		case l.comma:
+			text = text[:l.end] + "," + text[l.end:]
+		}
+		prefix := strings.Repeat(indentUnit, l.indent)
+		if l.star {
+			prefix += " "
+		}
+		out = append(out, prefix+text)
+	}
+	return []byte(strings.Join(out, "\n") + "\n")
+}
+
+// sortImports sorts the runs of consecutive single-line import statements at
+// the top level, k6 modules first, then remote modules, then local ones.
+func sortImports(lines []*line) {
+	for i := 0; i < len(lines); {
+		j := i
+		for j < len(lines) && isSortableImport(lines[j]) {
+			j++
+		}
+		if j == i {
+			i++
+			continue
+		}
+		run := lines[i:j]
+		sideEffects := false
+		for _, l := range run {
+			sideEffects = sideEffects || !strings.Contains(l.text, " from ")
+		}
+		if !sideEffects {
+			sort.SliceStable(run, func(a, b int) bool {
+				return importKey(run[a]) < importKey(run[b])
+			})
+		}
+		i = j
+	}
+}
+
+func isSortableImport(l *line) bool {
+	return !l.raw && !l.star && l.indent == 0 && firstWord(l.text) == "import" &&
+		(l.last == "\"" && l.semi || l.last == ";")
+}
+
+func importKey(l *line) string {
+	text := strings.TrimSuffix(strings.TrimSpace(l.text[:l.end]), ";")
+	spec := strings.Trim(text[strings.LastIndexAny(text, " \t")+1:], "'\"")
+	switch {
+	case spec == "k6" || strings.HasPrefix(spec, "k6/"):
+		return "0" + spec
+	case strings.Contains(spec, "://"):
+		return "1" + spec
+	default:
+		return "2" + spec
+	}
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+func firstWord(text string) string {
+	k := 0
+	for k < len(text) && isIdentChar(text[k]) {
+		k++
+	}
+	return text[:k]
+}
+
+func isCaseLine(text string) bool {
+	w := firstWord(text)
+	return w == "case" || w == "default" && strings.HasPrefix(strings.TrimSpace(text[len(w):]), ":")
+}
+
+// operatorKeywords are the keywords that need an operand after them.
+var operatorKeywords = map[string]bool{
+	"in": true, "of": true, "instanceof": true, "typeof": true, "new": true, "delete": true, "void": true,
+	"await": true, "yield": true, "extends": true, "else": true, "do": true, "case": true,
+}
+
+// nonStatementKeywords are the keywords that start constructs which don't end
+// with a semicolon, or which are too ambiguous to touch.
+var nonStatementKeywords = map[string]bool{
+	"if": true, "else": true, "for": true, "while": true, "do": true, "switch": true, "case": true,
+	"default": true, "function": true, "class": true, "try": true, "catch": true, "finally": true,
+	"async": true, "with": true, "interface": true, "type": true, "enum": true, "declare": true,
+	"namespace": true, "module": true, "abstract": true, "get": true, "set": true, "static": true,
+}
+
+func regexAllowed(lastTok string) bool {
+	switch lastTok {
+	case "", "(", ",", "=", ":", "[", "!", "&", "|", "?", "{", "}", ";", "+", "-", "*", "%", "<", ">",
+		"~", "^", "=>", "&&", "||", "??", "return", "typeof", "instanceof", "in", "of", "new", "delete",
+		"void", "throw", "case", "do", "else", "yield", "await":
+		return true
+	}
+	return false
+}
+
+// scanRegex returns the index after the regular expression literal starting
+// at the given index, or -1 if it isn't terminated on the same line.
+func scanRegex(text string, j int) int {
+	inClass := false
+	for j++; j < len(text); j++ {
+		switch text[j] {
+		case '\\':
+			j++
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '/':
+			if !inClass {
+				j++
+				for j < len(text) && isIdentChar(text[j]) {
+					j++
+				}
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+// endsValue returns whether an expression can end with the given token.
+func endsValue(tok string) bool {
+	switch tok {
+	case ")", "]", "}", "\"", "`", "/re", "++", "--":
+		return true
+	}
+	return tok != "" && isIdentChar(tok[0]) && !operatorKeywords[tok]
+}
+
+func isOperator(tok string) bool {
+	switch tok {
+	case "=", "+", "-", "*", "/", "%", "<", ">", "&", "|", "^", "?", "&&", "||", "??", "=>":
+		return true
+	}
+	return false
+}
+
+func isContinuationStart(tok string) bool {
+	switch tok {
+	case ".", "?", ":", "&&", "||", "??":
+		return true
+	}
+	return false
+}
+
+// continuesExpression returns whether the line can continue the expression
+// on the line before it, in which case that line mustn't get a semicolon.
+func continuesExpression(l *line) bool {
+	if l.first == "" {
+		return false
+	}
+	if strings.IndexByte(".([`?:+-*/%=<>&|^,", l.first[0]) >= 0 && l.first != "++" && l.first != "--" {
+		return true
+	}
+	return l.first == "instanceof" || l.first == "in" || l.first == "of"
+}
+
+func isDeclaration(text string) bool {
+	w := firstWord(text)
+	if w == "export" {
+		w = firstWord(strings.TrimSpace(text[len(w):]))
+	}
+	return w == "let" || w == "const" || w == "var"
+}
+
+// isPatternKeyword returns whether a '{' after the given token is a
+// destructuring pattern or an import or export list.
+func isPatternKeyword(tok string) bool {
+	return tok == "let" || tok == "const" || tok == "var" || tok == "import" || tok == "export"
+}
+
+// stmtStart returns whether the line starts a statement that ends with a
+// semicolon.
+func stmtStart(text string) bool {
+	w := firstWord(text)
+	rest := strings.TrimSpace(text[len(w):])
+	switch {
+	case w == "":
+		return false
+	case w == "export":
+		next := firstWord(rest)
+		return next == "let" || next == "const" || next == "var" ||
+			strings.HasPrefix(rest, "{") || strings.HasPrefix(rest, "*")
+	case w == "let" || w == "const" || w == "var" || w == "import" || w == "return" || w == "throw" ||
+		w == "break" || w == "continue":
+		return true
+	case nonStatementKeywords[w] || w[0] >= '0' && w[0] <= '9':
+		return false
+	}
+	// Labels and object properties.
+	return !strings.HasPrefix(rest, ":")
+}
+
+func opensBlock(text string) bool {
+	text = strings.TrimSpace(text)
+	return text != "" && strings.IndexByte("{[(", text[len(text)-1]) >= 0
+}
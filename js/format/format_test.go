@@ -0,0 +1,149 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package format
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSource(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name, src, expected string
+	}{
+		{
+			name:     "indentation",
+			src:      "export default function() {\n\t\tif (a) {\n    foo();\n\t}\n}\n",
+			expected: "export default function() {\n  if (a) {\n    foo();\n  }\n}\n",
+		},
+		{
+			name:     "whitespace",
+			src:      "\n\nlet a = 1;   \r\n\n\n\nlet b = 2;\n\n",
+			expected: "let a = 1;\n\nlet b = 2;\n",
+		},
+		{
+			name:     "blank lines at block edges",
+			src:      "function f() {\n\n  foo();\n\n}\n",
+			expected: "function f() {\n  foo();\n}\n",
+		},
+		{
+			name:     "semicolons",
+			src:      "let a = 1\nfoo(a)\nreturn\nlet f = function () {\n}\nfunction g() {\n}\n",
+			expected: "let a = 1;\nfoo(a);\nreturn;\nlet f = function () {\n};\nfunction g() {\n}\n",
+		},
+		{
+			name:     "multi-line statements",
+			src:      "check(res, {\n\"ok\": (r) => r.status === 200\n})\nsleep(1)\n",
+			expected: "check(res, {\n  \"ok\": (r) => r.status === 200,\n});\nsleep(1);\n",
+		},
+		{
+			name:     "continued expressions",
+			src:      "let a = b\n.c()\n.d()\nlet e = f +\ng\n",
+			expected: "let a = b\n  .c()\n  .d();\nlet e = f +\n  g;\n",
+		},
+		{
+			name:     "trailing commas",
+			src:      "let a = {\nb: [\n1,\n2\n],\nc: { d: 1 }\n};\n",
+			expected: "let a = {\n  b: [\n    1,\n    2,\n  ],\n  c: { d: 1 },\n};\n",
+		},
+		{
+			name:     "no trailing commas after rest elements",
+			src:      "const [\na,\n...rest\n] = arr;\n",
+			expected: "const [\n  a,\n  ...rest\n] = arr;\n",
+		},
+		{
+			name:     "no commas in blocks",
+			src:      "if (a) {\nfoo();\n}\nconst {\nb\n} = c;\n",
+			expected: "if (a) {\n  foo();\n}\nconst {\n  b\n} = c;\n",
+		},
+		{
+			name:     "comments",
+			src:      "let a = {\nb: 1 // the b\n};\n/**\n* Doc.\n*/\n// foo()\n",
+			expected: "let a = {\n  b: 1, // the b\n};\n/**\n * Doc.\n */\n// foo()\n",
+		},
+		{
+			name:     "strings and regexes",
+			src:      "let a = \"{(\"\nlet b = '[' + \"}\"\nlet c = /[/}]+/g\nlet d = 4 / 2\n",
+			expected: "let a = \"{(\";\nlet b = '[' + \"}\";\nlet c = /[/}]+/g;\nlet d = 4 / 2;\n",
+		},
+		{
+			name:     "template literals",
+			src:      "let a = `\n   {\n ${foo({\nb: 1\n})} `\n",
+			expected: "let a = `\n   {\n ${foo({\n  b: 1,\n})} `\n",
+		},
+		{
+			name:     "switch",
+			src:      "switch (a) {\ncase 1:\nfoo()\nbreak\ndefault:\nbar()\n}\n",
+			expected: "switch (a) {\n  case 1:\n    foo();\n    break;\n  default:\n    bar();\n}\n",
+		},
+		{
+			name:     "closing brackets",
+			src:      "group(\"a\", function () {\nfoo(function () {\nbar()\n}, 1)\n})\n",
+			expected: "group(\"a\", function () {\n  foo(function () {\n    bar();\n  }, 1);\n});\n",
+		},
+		{
+			name: "imports",
+			src: "import { foo } from \"./foo.js\"\nimport http from \"k6/http\"\n" +
+				"import papa from \"https://jslib.k6.io/papaparse/5.1.1/index.js\";\nimport { check } from \"k6\";\n",
+			expected: "import { check } from \"k6\";\nimport http from \"k6/http\";\n" +
+				"import papa from \"https://jslib.k6.io/papaparse/5.1.1/index.js\";\nimport { foo } from \"./foo.js\";\n",
+		},
+		{
+			name:     "side effect imports aren't sorted",
+			src:      "import \"./b.js\";\nimport a from \"./a.js\";\n",
+			expected: "import \"./b.js\";\nimport a from \"./a.js\";\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			out, err := Source([]byte(tc.src))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(out))
+
+			again, err := Source(out)
+			require.NoError(t, err)
+			assert.Equal(t, string(out), string(again), "formatting isn't idempotent")
+		})
+	}
+}
+
+func TestSourceErrors(t *testing.T) {
+	t.Parallel()
+	testCases := map[string]string{
+		"foo(\n":                "line 1: '(' is never closed",
+		"foo(]\n":               "line 1: ']' doesn't match the '(' opened on line 1",
+		"}\n":                   "line 1: unexpected '}'",
+		"/* foo\n":              "unterminated block comment",
+		"let a = `foo\n":        "unterminated template literal",
+		"let a = 'foo\nfoo()\n": "line 1: unterminated string",
+		"let a = (/foo\n)\n":    "line 1: unterminated regular expression",
+	}
+	for src, expected := range testCases {
+		_, err := Source([]byte(src))
+		assert.EqualError(t, err, expected, src)
+	}
+}
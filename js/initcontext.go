@@ -137,6 +137,9 @@ func (i *InitContext) requireModule(name string) (goja.Value, error) {
 	if !ok {
 		return nil, errors.Errorf("unknown builtin module: %s", name)
 	}
+	if perVU, ok := mod.(modules.HasModuleInstancePerVU); ok {
+		mod = perVU.NewModuleInstancePerVU(i.runtime, i.ctxPtr)
+	}
 	return i.runtime.ToValue(common.Bind(i.runtime, mod, i.ctxPtr)), nil
 }
 
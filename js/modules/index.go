@@ -21,24 +21,52 @@
 package modules
 
 import (
+	"context"
+
+	"github.com/dop251/goja"
+
 	"github.com/loadimpact/k6/js/modules/k6"
+	"github.com/loadimpact/k6/js/modules/k6/circuitbreaker"
 	"github.com/loadimpact/k6/js/modules/k6/crypto"
 	"github.com/loadimpact/k6/js/modules/k6/crypto/x509"
+	"github.com/loadimpact/k6/js/modules/k6/diff"
+	"github.com/loadimpact/k6/js/modules/k6/dns"
 	"github.com/loadimpact/k6/js/modules/k6/encoding"
+	"github.com/loadimpact/k6/js/modules/k6/execution"
+	"github.com/loadimpact/k6/js/modules/k6/faker"
 	"github.com/loadimpact/k6/js/modules/k6/html"
 	"github.com/loadimpact/k6/js/modules/k6/http"
 	"github.com/loadimpact/k6/js/modules/k6/metrics"
+	"github.com/loadimpact/k6/js/modules/k6/net/tcp"
+	"github.com/loadimpact/k6/js/modules/k6/net/udp"
+	"github.com/loadimpact/k6/js/modules/k6/url"
+	"github.com/loadimpact/k6/js/modules/k6/utils"
 	"github.com/loadimpact/k6/js/modules/k6/ws"
 )
 
 // Index of module implementations.
 var Index = map[string]interface{}{
-	"k6":             k6.New(),
-	"k6/crypto":      crypto.New(),
-	"k6/crypto/x509": x509.New(),
-	"k6/encoding":    encoding.New(),
-	"k6/http":        http.New(),
-	"k6/metrics":     metrics.New(),
-	"k6/html":        html.New(),
-	"k6/ws":          ws.New(),
+	"k6":                k6.New(),
+	"k6/circuitbreaker": circuitbreaker.New(),
+	"k6/crypto":         crypto.New(),
+	"k6/crypto/x509":    x509.New(),
+	"k6/diff":           diff.New(),
+	"k6/dns":            dns.New(),
+	"k6/encoding":       encoding.New(),
+	"k6/execution":      execution.New(),
+	"k6/faker":          faker.New(),
+	"k6/http":           http.New(),
+	"k6/metrics":        metrics.New(),
+	"k6/net/tcp":        tcp.New(),
+	"k6/net/udp":        udp.New(),
+	"k6/html":           html.New(),
+	"k6/url":            url.New(),
+	"k6/utils":          utils.New(),
+	"k6/ws":             ws.New(),
+}
+
+// HasModuleInstancePerVU is implemented by the modules that need a separate
+// instance in each VU, e.g. for properties that depend on the VU state.
+type HasModuleInstancePerVU interface {
+	NewModuleInstancePerVU(rt *goja.Runtime, ctxPtr *context.Context) interface{}
 }
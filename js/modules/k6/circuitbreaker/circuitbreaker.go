@@ -0,0 +1,281 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dop251/goja"
+
+	"github.com/loadimpact/k6/js/common"
+	"github.com/loadimpact/k6/lib"
+	"github.com/loadimpact/k6/lib/metrics"
+	"github.com/loadimpact/k6/lib/types"
+	"github.com/loadimpact/k6/stats"
+)
+
+// The states of a circuit breaker.
+const (
+	StateClosed   = "CLOSED"
+	StateOpen     = "OPEN"
+	StateHalfOpen = "HALF_OPEN"
+)
+
+// The name of the error that is thrown by call() when the circuit is open.
+const circuitOpenErrorName = "CircuitOpenError"
+
+// ErrCallInInitContext is returned when a circuit breaker is called in the init context
+var ErrCallInInitContext = common.NewInitContextError("calling circuit breakers in the init context is not supported")
+
+// CircuitBreakerModule is the k6/circuitbreaker module. The circuit breakers
+// with the same name share their state across all VUs.
+type CircuitBreakerModule struct {
+	CLOSED    string `js:"CLOSED"`
+	OPEN      string `js:"OPEN"`
+	HALF_OPEN string `js:"HALF_OPEN"` //nolint:golint,stylecheck
+
+	mu       sync.Mutex
+	breakers map[string]*breaker
+}
+
+// New returns a new k6/circuitbreaker module.
+func New() *CircuitBreakerModule {
+	return &CircuitBreakerModule{
+		CLOSED:    StateClosed,
+		OPEN:      StateOpen,
+		HALF_OPEN: StateHalfOpen,
+		breakers:  make(map[string]*breaker),
+	}
+}
+
+type breakerConfig struct {
+	name             string
+	failureThreshold int64
+	timeout          time.Duration
+	halfOpenRequests int64
+}
+
+// XCircuitBreaker is the constructor of the circuit breakers, with the name,
+// failureThreshold, timeout and halfOpenRequests options. It has to be called
+// in the init context, and the breakers of all VUs that have the same name
+// share their state.
+func (m *CircuitBreakerModule) XCircuitBreaker(ctxPtr *context.Context, opts goja.Value) (interface{}, error) {
+	if lib.GetState(*ctxPtr) != nil {
+		return nil, errors.New("circuit breakers must be created in the init context")
+	}
+	rt := common.GetRuntime(*ctxPtr)
+	config, err := parseBreakerConfig(rt, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	b, ok := m.breakers[config.name]
+	if !ok {
+		b = &breaker{config: config, state: StateClosed}
+		m.breakers[config.name] = b
+	} else if b.config != config {
+		return nil, fmt.Errorf("the circuit breaker '%s' was already created with different options", config.name)
+	}
+	return common.Bind(rt, &CircuitBreaker{breaker: b}, ctxPtr), nil
+}
+
+func parseBreakerConfig(rt *goja.Runtime, opts goja.Value) (breakerConfig, error) {
+	config := breakerConfig{
+		name:             "default",
+		failureThreshold: 5,
+		timeout:          30 * time.Second,
+		halfOpenRequests: 1,
+	}
+	if opts == nil || goja.IsUndefined(opts) || goja.IsNull(opts) {
+		return config, nil
+	}
+	params := opts.ToObject(rt)
+	for _, k := range params.Keys() {
+		v := params.Get(k)
+		switch k {
+		case "name":
+			config.name = v.String()
+		case "failureThreshold":
+			config.failureThreshold = v.ToInteger()
+		case "timeout":
+			switch t := v.Export().(type) {
+			case string:
+				d, err := types.ParseExtendedDuration(t)
+				if err != nil {
+					return config, fmt.Errorf("invalid circuit breaker timeout '%s': %w", t, err)
+				}
+				config.timeout = d
+			default:
+				config.timeout = time.Duration(v.ToFloat() * float64(time.Millisecond))
+			}
+		case "halfOpenRequests":
+			config.halfOpenRequests = v.ToInteger()
+		}
+	}
+	if config.name == "" {
+		return config, errors.New("the circuit breaker name can't be empty")
+	}
+	if config.failureThreshold <= 0 {
+		return config, errors.New("the circuit breaker failureThreshold has to be positive")
+	}
+	if config.timeout <= 0 {
+		return config, errors.New("the circuit breaker timeout has to be positive")
+	}
+	if config.halfOpenRequests <= 0 {
+		return config, errors.New("the circuit breaker halfOpenRequests has to be positive")
+	}
+	return config, nil
+}
+
+// breaker is the state of a circuit breaker that is shared across the VUs.
+type breaker struct {
+	config breakerConfig
+
+	mu               sync.Mutex
+	state            string
+	failures         int64     // the consecutive failures while closed
+	openedAt         time.Time // when the circuit was last opened
+	halfOpenInFlight int64     // the calls that were let through while half-open
+}
+
+// acquire returns whether a call can be made now, the state in which it was
+// let through, and the states the breaker changed to.
+func (b *breaker) acquire(now time.Time) (bool, string, []string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var changes []string
+	if b.state == StateOpen && now.Sub(b.openedAt) >= b.config.timeout {
+		b.state, b.halfOpenInFlight = StateHalfOpen, 0
+		changes = append(changes, StateHalfOpen)
+	}
+	switch b.state {
+	case StateClosed:
+		return true, StateClosed, changes
+	case StateHalfOpen:
+		if b.halfOpenInFlight < b.config.halfOpenRequests {
+			b.halfOpenInFlight++
+			return true, StateHalfOpen, changes
+		}
+	}
+	return false, b.state, changes
+}
+
+// release records the result of a call that was let through in the given
+// state, and returns the states the breaker changed to.
+func (b *breaker) release(now time.Time, acquiredIn string, success bool) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != acquiredIn { // the result of a call from a previous state doesn't matter
+		return nil
+	}
+	switch b.state {
+	case StateClosed:
+		if success {
+			b.failures = 0
+			return nil
+		}
+		b.failures++
+		if b.failures < b.config.failureThreshold {
+			return nil
+		}
+	case StateHalfOpen:
+		b.halfOpenInFlight--
+		if success {
+			b.state, b.failures = StateClosed, 0
+			return []string{StateClosed}
+		}
+	}
+	b.state, b.openedAt = StateOpen, now
+	return []string{StateOpen}
+}
+
+func (b *breaker) currentState() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// CircuitBreaker is a circuit breaker in a VU.
+type CircuitBreaker struct {
+	breaker *breaker
+}
+
+// Call calls fn if the circuit is closed or half-open, and returns its result.
+// Exceptions thrown by fn count as failures and are rethrown. When the circuit
+// is open, a CircuitOpenError is thrown without calling fn.
+func (c *CircuitBreaker) Call(ctx context.Context, fn goja.Value) goja.Value {
+	rt := common.GetRuntime(ctx)
+	state := lib.GetState(ctx)
+	if state == nil {
+		common.Throw(rt, ErrCallInInitContext)
+	}
+	call, ok := goja.AssertFunction(fn)
+	if !ok {
+		common.Throw(rt, errors.New("the circuit breaker call() argument has to be a function"))
+	}
+
+	allowed, acquiredIn, changes := c.breaker.acquire(time.Now())
+	c.pushStateChanges(ctx, state, changes)
+	if !allowed {
+		panic(c.newCircuitOpenError(rt))
+	}
+
+	result, err := call(goja.Undefined())
+	c.pushStateChanges(ctx, state, c.breaker.release(time.Now(), acquiredIn, err == nil))
+	if err != nil {
+		common.Throw(rt, err)
+	}
+	return result
+}
+
+// State returns the current state of the circuit breaker.
+func (c *CircuitBreaker) State() string {
+	return c.breaker.currentState()
+}
+
+func (c *CircuitBreaker) newCircuitOpenError(rt *goja.Runtime) goja.Value {
+	errObj, err := rt.New(rt.Get("Error"), rt.ToValue(fmt.Sprintf("the circuit breaker '%s' is open", c.breaker.config.name)))
+	if err != nil {
+		common.Throw(rt, err)
+	}
+	_ = errObj.Set("name", circuitOpenErrorName)
+	return errObj
+}
+
+func (c *CircuitBreaker) pushStateChanges(ctx context.Context, state *lib.State, changes []string) {
+	now := time.Now()
+	for _, change := range changes {
+		tags := state.CloneTags()
+		tags["circuit_breaker"] = c.breaker.config.name
+		tags["circuit_breaker_state"] = change
+		stats.PushIfNotDone(ctx, state.Samples, stats.Sample{
+			Time: now, Metric: metrics.CircuitBreakerStateChanges, Value: 1, Tags: stats.IntoSampleTags(&tags),
+		})
+	}
+}
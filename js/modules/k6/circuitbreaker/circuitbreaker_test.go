@@ -0,0 +1,191 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package circuitbreaker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/loadimpact/k6/js/common"
+	"github.com/loadimpact/k6/lib"
+	"github.com/loadimpact/k6/lib/metrics"
+	"github.com/loadimpact/k6/stats"
+)
+
+func newTestRuntime(t *testing.T, module *CircuitBreakerModule) (*goja.Runtime, *context.Context) {
+	rt := goja.New()
+	rt.SetFieldNameMapper(common.FieldNameMapper{})
+	ctx := new(context.Context)
+	*ctx = common.WithRuntime(context.Background(), rt)
+	rt.Set("circuitbreaker", common.Bind(rt, module, ctx))
+	_, err := common.RunString(rt, `
+	function fail() { throw new Error("failed"); }
+	function succeed() { return "ok"; }
+	function isOpenError(fn) {
+		try {
+			fn();
+		} catch (e) {
+			return e.name == "CircuitOpenError";
+		}
+		return false;
+	}`)
+	require.NoError(t, err)
+	return rt, ctx
+}
+
+func startVU(ctx *context.Context) chan stats.SampleContainer {
+	samples := make(chan stats.SampleContainer, 1000)
+	state := &lib.State{
+		Logger:  logrus.New(),
+		Samples: samples,
+		Tags:    map[string]string{},
+	}
+	*ctx = lib.WithState(*ctx, state)
+	return samples
+}
+
+func stateChanges(samples chan stats.SampleContainer) []string {
+	var changes []string
+	for _, sc := range stats.GetBufferedSamples(samples) {
+		for _, sample := range sc.GetSamples() {
+			if sample.Metric == metrics.CircuitBreakerStateChanges {
+				changes = append(changes, sample.Tags.CloneTags()["circuit_breaker_state"])
+			}
+		}
+	}
+	return changes
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Parallel()
+	rt, ctx := newTestRuntime(t, New())
+	_, err := common.RunString(rt, `
+	var breaker = new circuitbreaker.CircuitBreaker({ failureThreshold: 2, timeout: "100ms", halfOpenRequests: 1 });
+	if (breaker.state() != circuitbreaker.CLOSED) { throw new Error("wrong initial state: " + breaker.state()); }`)
+	require.NoError(t, err)
+
+	_, err = common.RunString(rt, `breaker.call(succeed)`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "calling circuit breakers in the init context is not supported")
+
+	samples := startVU(ctx)
+	_, err = common.RunString(rt, `
+	if (breaker.call(succeed) != "ok") { throw new Error("wrong result"); }
+	try { breaker.call(fail); } catch (e) {}
+	if (breaker.state() != circuitbreaker.CLOSED) { throw new Error("opened too early"); }
+	try { breaker.call(fail); } catch (e) {}
+	if (breaker.state() != circuitbreaker.OPEN) { throw new Error("wrong state: " + breaker.state()); }
+	if (!isOpenError(function() { breaker.call(succeed); })) { throw new Error("expected a CircuitOpenError"); }`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{StateOpen}, stateChanges(samples))
+
+	time.Sleep(150 * time.Millisecond)
+	_, err = common.RunString(rt, `
+	try { breaker.call(fail); } catch (e) {}
+	if (breaker.state() != circuitbreaker.OPEN) { throw new Error("a half-open failure should reopen it"); }`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{StateHalfOpen, StateOpen}, stateChanges(samples))
+
+	time.Sleep(150 * time.Millisecond)
+	_, err = common.RunString(rt, `
+	if (breaker.call(succeed) != "ok") { throw new Error("wrong result"); }
+	if (breaker.state() != circuitbreaker.CLOSED) { throw new Error("wrong state: " + breaker.state()); }`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{StateHalfOpen, StateClosed}, stateChanges(samples))
+
+	t.Run("exceptions are rethrown", func(t *testing.T) {
+		_, err := common.RunString(rt, `breaker.call(fail)`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed")
+	})
+}
+
+func TestCircuitBreakerHalfOpenRequests(t *testing.T) {
+	t.Parallel()
+	rt, ctx := newTestRuntime(t, New())
+	_, err := common.RunString(rt, `
+	var breaker = new circuitbreaker.CircuitBreaker({ failureThreshold: 1, timeout: 50, halfOpenRequests: 1 });`)
+	require.NoError(t, err)
+	startVU(ctx)
+	_, err = common.RunString(rt, `try { breaker.call(fail); } catch (e) {}`)
+	require.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+
+	// Only one call is let through while it's half-open, even if it's nested
+	_, err = common.RunString(rt, `
+	var nestedOpen = false;
+	breaker.call(function() {
+		nestedOpen = isOpenError(function() { breaker.call(succeed); });
+	});
+	if (!nestedOpen) { throw new Error("the second half-open call should have been rejected"); }
+	if (breaker.state() != circuitbreaker.CLOSED) { throw new Error("wrong state: " + breaker.state()); }`)
+	require.NoError(t, err)
+}
+
+func TestCircuitBreakerSharedState(t *testing.T) {
+	t.Parallel()
+	module := New()
+	rt1, ctx1 := newTestRuntime(t, module)
+	rt2, ctx2 := newTestRuntime(t, module)
+	src := `var breaker = new circuitbreaker.CircuitBreaker({ name: "api", failureThreshold: 1 });`
+	_, err := common.RunString(rt1, src)
+	require.NoError(t, err)
+	_, err = common.RunString(rt2, src)
+	require.NoError(t, err)
+
+	startVU(ctx1)
+	startVU(ctx2)
+	_, err = common.RunString(rt1, `try { breaker.call(fail); } catch (e) {}`)
+	require.NoError(t, err)
+	_, err = common.RunString(rt2, `
+	if (breaker.state() != circuitbreaker.OPEN) { throw new Error("the state isn't shared: " + breaker.state()); }`)
+	require.NoError(t, err)
+
+	t.Run("different options", func(t *testing.T) {
+		rt, _ := newTestRuntime(t, module)
+		_, err := common.RunString(rt, `new circuitbreaker.CircuitBreaker({ name: "api", failureThreshold: 3 });`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "was already created with different options")
+	})
+}
+
+func TestCircuitBreakerInvalidOptions(t *testing.T) {
+	t.Parallel()
+	rt, _ := newTestRuntime(t, New())
+	for opts, msg := range map[string]string{
+		`{ failureThreshold: 0 }`: "failureThreshold has to be positive",
+		`{ timeout: "soon" }`:     "invalid circuit breaker timeout",
+		`{ timeout: -1 }`:         "timeout has to be positive",
+		`{ halfOpenRequests: 0 }`: "halfOpenRequests has to be positive",
+		`{ name: "" }`:            "name can't be empty",
+	} {
+		_, err := common.RunString(rt, `new circuitbreaker.CircuitBreaker(`+opts+`);`)
+		if assert.Error(t, err, opts) {
+			assert.Contains(t, err.Error(), msg)
+		}
+	}
+}
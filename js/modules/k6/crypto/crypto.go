@@ -33,6 +33,7 @@ import (
 	"errors"
 	"hash"
 
+	"github.com/dop251/goja"
 	"golang.org/x/crypto/md4"
 	"golang.org/x/crypto/ripemd160"
 
@@ -65,55 +66,55 @@ func (*Crypto) RandomBytes(ctx context.Context, size int) []byte {
 
 func (c *Crypto) Md4(ctx context.Context, input []byte, outputEncoding string) interface{} {
 	hasher := c.CreateHash(ctx, "md4")
-	hasher.Update(input)
+	hasher.update(input)
 	return hasher.Digest(outputEncoding)
 }
 
 func (c *Crypto) Md5(ctx context.Context, input []byte, outputEncoding string) interface{} {
 	hasher := c.CreateHash(ctx, "md5")
-	hasher.Update(input)
+	hasher.update(input)
 	return hasher.Digest(outputEncoding)
 }
 
 func (c *Crypto) Sha1(ctx context.Context, input []byte, outputEncoding string) interface{} {
 	hasher := c.CreateHash(ctx, "sha1")
-	hasher.Update(input)
+	hasher.update(input)
 	return hasher.Digest(outputEncoding)
 }
 
 func (c *Crypto) Sha256(ctx context.Context, input []byte, outputEncoding string) interface{} {
 	hasher := c.CreateHash(ctx, "sha256")
-	hasher.Update(input)
+	hasher.update(input)
 	return hasher.Digest(outputEncoding)
 }
 
 func (c *Crypto) Sha384(ctx context.Context, input []byte, outputEncoding string) interface{} {
 	hasher := c.CreateHash(ctx, "sha384")
-	hasher.Update(input)
+	hasher.update(input)
 	return hasher.Digest(outputEncoding)
 }
 
 func (c *Crypto) Sha512(ctx context.Context, input []byte, outputEncoding string) interface{} {
 	hasher := c.CreateHash(ctx, "sha512")
-	hasher.Update(input)
+	hasher.update(input)
 	return hasher.Digest(outputEncoding)
 }
 
 func (c *Crypto) Sha512_224(ctx context.Context, input []byte, outputEncoding string) interface{} {
 	hasher := c.CreateHash(ctx, "sha512_224")
-	hasher.Update(input)
+	hasher.update(input)
 	return hasher.Digest(outputEncoding)
 }
 
 func (c *Crypto) Sha512_256(ctx context.Context, input []byte, outputEncoding string) interface{} {
 	hasher := c.CreateHash(ctx, "sha512_256")
-	hasher.Update(input)
+	hasher.update(input)
 	return hasher.Digest(outputEncoding)
 }
 
 func (c *Crypto) Ripemd160(ctx context.Context, input []byte, outputEncoding string) interface{} {
 	hasher := c.CreateHash(ctx, "ripemd160")
-	hasher.Update(input)
+	hasher.update(input)
 	return hasher.Digest(outputEncoding)
 }
 
@@ -140,12 +141,26 @@ func (*Crypto) CreateHash(ctx context.Context, algorithm string) *Hasher {
 		hasher.hash = sha512.New()
 	case "ripemd160":
 		hasher.hash = ripemd160.New()
+	default:
+		err := errors.New("Invalid algorithm: " + algorithm)
+		common.Throw(common.GetRuntime(hasher.ctx), err)
 	}
 
 	return &hasher
 }
 
-func (hasher *Hasher) Update(input []byte) {
+// Update adds the input, a string, an array of bytes or an ArrayBuffer, to the
+// hashed data. The hasher isn't bound with common.Bind(), so the input is
+// converted here.
+func (hasher *Hasher) Update(input goja.Value) {
+	b, err := common.ToBytes(common.GetRuntime(hasher.ctx), input)
+	if err != nil {
+		common.Throw(common.GetRuntime(hasher.ctx), err)
+	}
+	hasher.update(b)
+}
+
+func (hasher *Hasher) update(input []byte) {
 	_, err := hasher.hash.Write(input)
 	if err != nil {
 		common.Throw(common.GetRuntime(hasher.ctx), err)
@@ -219,6 +234,6 @@ func (c *Crypto) Hmac(
 	ctx context.Context, algorithm string, key []byte, input []byte, outputEncoding string,
 ) interface{} {
 	hasher := c.CreateHMAC(ctx, algorithm, key)
-	hasher.Update(input)
+	hasher.update(input)
 	return hasher.Digest(outputEncoding)
 }
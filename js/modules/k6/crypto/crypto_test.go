@@ -239,6 +239,16 @@ func TestStreamingApi(t *testing.T) {
 
 		assert.NoError(t, err)
 	})
+
+	t.Run("InvalidAlgorithm", func(t *testing.T) {
+		_, err := common.RunString(rt, `
+		var hasher = crypto.createHash("md6");
+		hasher.update("hello world");`)
+
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "GoError: Invalid algorithm: md6")
+		}
+	})
 }
 
 func TestOutputEncoding(t *testing.T) {
@@ -0,0 +1,232 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package diff
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/dop251/goja"
+)
+
+// The possible types of differences between two JSON values.
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+)
+
+// Diff is the k6/diff module.
+type Diff struct{}
+
+// New returns a new k6/diff module instance.
+func New() *Diff {
+	return &Diff{}
+}
+
+// Options control how jsonDiff() compares the values.
+type Options struct {
+	// IgnoreArrayOrder makes arrays equal if they have the same elements,
+	// regardless of their order.
+	IgnoreArrayOrder bool `js:"ignoreArrayOrder"`
+	// IgnoredPaths is a list of paths, i.e. $.data.timestamp, that won't be
+	// compared. Their children aren't compared either.
+	IgnoredPaths []string `js:"ignoredPaths"`
+}
+
+// Difference is a single difference between the expected and actual values.
+type Difference struct {
+	Path     string      `js:"path"`
+	Expected interface{} `js:"expected"`
+	Actual   interface{} `js:"actual"`
+	Type     string      `js:"type"`
+}
+
+// JsonDiff compares two JSON-like values and returns a list of all of the
+// differences between them, or an empty list if they are equal.
+//nolint: golint,stylecheck
+func (*Diff) JsonDiff(ctx context.Context, expected, actual goja.Value, opts Options) []Difference {
+	d := &differ{opts: opts, ignored: make(map[string]bool, len(opts.IgnoredPaths)), diffs: []Difference{}}
+	for _, path := range opts.IgnoredPaths {
+		d.ignored[path] = true
+	}
+	d.compare("$", normalize(exportValue(expected)), normalize(exportValue(actual)))
+	return d.diffs
+}
+
+func exportValue(v goja.Value) interface{} {
+	if v == nil || goja.IsUndefined(v) {
+		return nil
+	}
+	return v.Export()
+}
+
+// normalize converts all numbers to float64, so that e.g. 1 and 1.0 are
+// considered equal, and makes sure that all objects and arrays are of the
+// same Go types.
+func normalize(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(val))
+		for k, e := range val {
+			res[k] = normalize(e)
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(val))
+		for i, e := range val {
+			res[i] = normalize(e)
+		}
+		return res
+	case int64:
+		return float64(val)
+	case int:
+		return float64(val)
+	default:
+		return val
+	}
+}
+
+type differ struct {
+	opts    Options
+	ignored map[string]bool
+	diffs   []Difference
+}
+
+func (d *differ) add(path string, expected, actual interface{}, diffType string) {
+	d.diffs = append(d.diffs, Difference{Path: path, Expected: expected, Actual: actual, Type: diffType})
+}
+
+func (d *differ) compare(path string, expected, actual interface{}) {
+	if d.ignored[path] {
+		return
+	}
+
+	switch exp := expected.(type) {
+	case map[string]interface{}:
+		act, ok := actual.(map[string]interface{})
+		if !ok {
+			d.add(path, expected, actual, Changed)
+			return
+		}
+		d.compareObjects(path, exp, act)
+	case []interface{}:
+		act, ok := actual.([]interface{})
+		if !ok {
+			d.add(path, expected, actual, Changed)
+			return
+		}
+		if d.opts.IgnoreArrayOrder {
+			d.compareUnorderedArrays(path, exp, act)
+		} else {
+			d.compareArrays(path, exp, act)
+		}
+	default:
+		if !reflect.DeepEqual(expected, actual) {
+			d.add(path, expected, actual, Changed)
+		}
+	}
+}
+
+func (d *differ) compareObjects(path string, expected, actual map[string]interface{}) {
+	keys := make([]string, 0, len(expected)+len(actual))
+	for k := range expected {
+		keys = append(keys, k)
+	}
+	for k := range actual {
+		if _, ok := expected[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys) // for a deterministic order of the differences
+
+	for _, k := range keys {
+		childPath := objectPath(path, k)
+		expVal, inExp := expected[k]
+		actVal, inAct := actual[k]
+		switch {
+		case d.ignored[childPath]:
+		case !inAct:
+			d.add(childPath, expVal, nil, Removed)
+		case !inExp:
+			d.add(childPath, nil, actVal, Added)
+		default:
+			d.compare(childPath, expVal, actVal)
+		}
+	}
+}
+
+func (d *differ) compareArrays(path string, expected, actual []interface{}) {
+	for i := 0; i < len(expected) || i < len(actual); i++ {
+		childPath := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case d.ignored[childPath]:
+		case i >= len(actual):
+			d.add(childPath, expected[i], nil, Removed)
+		case i >= len(expected):
+			d.add(childPath, nil, actual[i], Added)
+		default:
+			d.compare(childPath, expected[i], actual[i])
+		}
+	}
+}
+
+// compareUnorderedArrays matches every expected element with an equal actual
+// element and reports the ones that couldn't be matched. Since there's no way
+// to know which elements were supposed to be the same, changed elements are
+// reported as a removal and an addition.
+func (d *differ) compareUnorderedArrays(path string, expected, actual []interface{}) {
+	matched := make([]bool, len(actual))
+	for i, expVal := range expected {
+		found := false
+		for j, actVal := range actual {
+			if !matched[j] && d.equal(fmt.Sprintf("%s[%d]", path, i), expVal, actVal) {
+				matched[j], found = true, true
+				break
+			}
+		}
+		if !found {
+			d.add(fmt.Sprintf("%s[%d]", path, i), expVal, nil, Removed)
+		}
+	}
+	for j, actVal := range actual {
+		if !matched[j] {
+			d.add(fmt.Sprintf("%s[%d]", path, j), nil, actVal, Added)
+		}
+	}
+}
+
+// equal checks whether the two values are the same, with the same options.
+func (d *differ) equal(path string, expected, actual interface{}) bool {
+	sub := &differ{opts: d.opts, ignored: d.ignored}
+	sub.compare(path, expected, actual)
+	return len(sub.diffs) == 0
+}
+
+func objectPath(path, key string) string {
+	if key != "" && !strings.ContainsAny(key, ".[]'\" ") {
+		return path + "." + key
+	}
+	return path + "['" + strings.Replace(key, "'", "\\'", -1) + "']"
+}
@@ -0,0 +1,113 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package diff
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dop251/goja"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/loadimpact/k6/js/common"
+)
+
+func TestJSONDiff(t *testing.T) {
+	rt := goja.New()
+	rt.SetFieldNameMapper(common.FieldNameMapper{})
+	ctx := common.WithRuntime(context.Background(), rt)
+	rt.Set("diff", common.Bind(rt, New(), &ctx))
+
+	testCases := []struct {
+		name, script string
+		expected     []Difference
+	}{
+		{
+			"Equal",
+			`diff.jsonDiff({a: 1, b: [1, "2", {c: null}]}, {b: [1.0, "2", {c: null}], a: 1})`,
+			[]Difference{},
+		},
+		{
+			"Objects",
+			`diff.jsonDiff({a: 1, b: true, "c d": "x"}, {a: 2, "c d": "x", e: [1]})`,
+			[]Difference{
+				{Path: "$.a", Expected: 1.0, Actual: 2.0, Type: Changed},
+				{Path: "$.b", Expected: true, Actual: nil, Type: Removed},
+				{Path: "$.e", Expected: nil, Actual: []interface{}{1.0}, Type: Added},
+			},
+		},
+		{
+			"Nested",
+			`diff.jsonDiff({a: {b: [1, {c: "x"}]}}, {a: {b: [1, {c: "y"}, 3]}})`,
+			[]Difference{
+				{Path: "$.a.b[1].c", Expected: "x", Actual: "y", Type: Changed},
+				{Path: "$.a.b[2]", Expected: nil, Actual: 3.0, Type: Added},
+			},
+		},
+		{
+			"TypeChange",
+			`diff.jsonDiff({a: [1]}, {a: {"0": 1}})`,
+			[]Difference{
+				{Path: "$.a", Expected: []interface{}{1.0}, Actual: map[string]interface{}{"0": 1.0}, Type: Changed},
+			},
+		},
+		{
+			"IgnoredPaths",
+			`diff.jsonDiff({id: 1, timestamp: 1, meta: {t: 1}}, {id: 1, timestamp: 2, meta: {t: 2}},
+				{ignoredPaths: ["$.timestamp", "$.meta"]})`,
+			[]Difference{},
+		},
+		{
+			"ArrayOrder",
+			`diff.jsonDiff([1, 2, 3], [3, 2, 1])`,
+			[]Difference{
+				{Path: "$[0]", Expected: 1.0, Actual: 3.0, Type: Changed},
+				{Path: "$[2]", Expected: 3.0, Actual: 1.0, Type: Changed},
+			},
+		},
+		{
+			"IgnoreArrayOrder",
+			`diff.jsonDiff([{a: 1}, 2, 3], [3, 4, {a: 1}], {ignoreArrayOrder: true})`,
+			[]Difference{
+				{Path: "$[1]", Expected: 2.0, Actual: nil, Type: Removed},
+				{Path: "$[1]", Expected: nil, Actual: 4.0, Type: Added},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := common.RunString(rt, tc.script)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, v.Export())
+		})
+	}
+
+	t.Run("Check", func(t *testing.T) {
+		v, err := common.RunString(rt, `
+		var res = diff.jsonDiff({a: 1}, {a: 2});
+		res.length + " " + res[0].path + " " + res[0].expected + " " + res[0].actual + " " + res[0].type`)
+		require.NoError(t, err)
+		assert.Equal(t, "1 $.a 1 2 changed", v.String())
+	})
+}
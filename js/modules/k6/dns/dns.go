@@ -0,0 +1,230 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package dns
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/pkg/errors"
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/loadimpact/k6/js/common"
+	"github.com/loadimpact/k6/lib"
+	"github.com/loadimpact/k6/lib/metrics"
+	"github.com/loadimpact/k6/stats"
+)
+
+// ErrDNSForbiddenInInitContext is used when a DNS lookup was made in the init context
+var ErrDNSForbiddenInInitContext = common.NewInitContextError("Making DNS lookups in the init context is not supported")
+
+// DNS is the k6/dns module, which sends queries directly to DNS servers.
+type DNS struct{}
+
+// New returns a new k6/dns module instance.
+func New() *DNS {
+	return &DNS{}
+}
+
+// Record is a single resource record from a DNS response.
+type Record struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	TTL  uint32 `json:"ttl"`
+	// Data is the record data in the presentation format, e.g. the IP address
+	// of A records or "10 mail.example.com." for MX records. It's empty for
+	// the record types that aren't supported, e.g. the DNSSEC ones.
+	Data string `json:"data"`
+}
+
+// LookupResult is the result of a DNS lookup.
+type LookupResult struct {
+	Server        string   `json:"server"`
+	Protocol      string   `json:"protocol"`
+	RCode         string   `json:"rcode" js:"rcode"`
+	Authoritative bool     `json:"authoritative"`
+	Authenticated bool     `json:"authenticated"` // the AD bit, set by validating resolvers
+	Truncated     bool     `json:"truncated"`
+	Answers       []Record `json:"answers"`
+	Authority     []Record `json:"authority"`
+	Additional    []Record `json:"additional"`
+	RTT           float64  `json:"rtt" js:"rtt"` // in milliseconds
+	Error         string   `json:"error"`
+}
+
+type lookupParams struct {
+	qtype     dnsmessage.Type
+	server    string
+	protocol  string
+	timeout   time.Duration
+	recursion bool
+	edns      bool
+	dnssec    bool
+	throw     bool
+	tags      map[string]string
+}
+
+// Lookup sends a query for the given name to a DNS server and returns its
+// response. The record type, the server, the protocol (udp or tcp) and
+// whether EDNS0 and the DNSSEC OK bit are used are set with the params.
+func (*DNS) Lookup(ctx context.Context, name string, params goja.Value) (*LookupResult, error) {
+	state := lib.GetState(ctx)
+	if state == nil {
+		return nil, ErrDNSForbiddenInInitContext
+	}
+
+	p, err := parseParams(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	qname, err := dnsmessage.NewName(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid name '%s'", name)
+	}
+
+	r := state.Rand
+	if r == nil {
+		r = common.NewRand()
+	}
+	id := uint16(r.Intn(1 << 16))
+	query, err := buildQuery(id, qname, p)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &LookupResult{Server: p.server, Protocol: p.protocol}
+	reqCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	response, rtt, lookupErr := exchange(reqCtx, state.Dialer, p.protocol, p.server, id, query)
+	if lookupErr == nil {
+		lookupErr = parseResponse(response, result)
+	}
+	result.RTT = stats.D(rtt)
+
+	tags := state.CloneTags()
+	for k, v := range p.tags {
+		tags[k] = v
+	}
+	// The server and the rcode are the DNS equivalents of the url and the
+	// status of HTTP requests, so they are enabled by the same system tags.
+	enabledTags := state.Options.SystemTags
+	tags["type"] = typeName(p.qtype)
+	if enabledTags.Has(stats.TagURL) {
+		tags["server"] = p.server
+	}
+	if enabledTags.Has(stats.TagProto) {
+		tags["proto"] = p.protocol
+	}
+	if lookupErr != nil {
+		result.Error = lookupErr.Error()
+		if enabledTags.Has(stats.TagError) {
+			tags["error"] = result.Error
+		}
+	} else if enabledTags.Has(stats.TagStatus) {
+		tags["rcode"] = result.RCode
+	}
+
+	now := time.Now()
+	sampleTags := stats.IntoSampleTags(&tags)
+	stats.PushIfNotDone(ctx, state.Samples, stats.ConnectedSamples{
+		Samples: []stats.Sample{
+			{Metric: metrics.DNSLookups, Time: now, Tags: sampleTags, Value: 1},
+			{Metric: metrics.DNSLookupDuration, Time: now, Tags: sampleTags, Value: result.RTT},
+		},
+		Tags: sampleTags,
+		Time: now,
+	})
+
+	if lookupErr != nil {
+		if p.throw {
+			return nil, lookupErr
+		}
+		state.Logger.WithField("error", lookupErr).Warn("DNS lookup failed")
+	}
+	return result, nil
+}
+
+func parseParams(ctx context.Context, params goja.Value) (*lookupParams, error) {
+	state := lib.GetState(ctx)
+	p := &lookupParams{
+		qtype:     dnsmessage.TypeA,
+		protocol:  "udp",
+		timeout:   5 * time.Second,
+		recursion: true,
+		throw:     state.Options.Throw.Bool,
+		tags:      map[string]string{},
+	}
+	if params == nil || goja.IsUndefined(params) || goja.IsNull(params) {
+		return nil, errors.New("the DNS server has to be specified with the server param")
+	}
+
+	rt := common.GetRuntime(ctx)
+	paramsObj := params.ToObject(rt)
+	for _, k := range paramsObj.Keys() {
+		v := paramsObj.Get(k)
+		switch k {
+		case "type":
+			qtype, ok := recordTypes[strings.ToUpper(v.String())]
+			if !ok {
+				return nil, errors.Errorf("unsupported record type '%s'", v.String())
+			}
+			p.qtype = qtype
+		case "server":
+			p.server = v.String()
+			if _, _, err := net.SplitHostPort(p.server); err != nil {
+				p.server = net.JoinHostPort(p.server, "53")
+			}
+		case "protocol":
+			p.protocol = strings.ToLower(v.String())
+			if p.protocol != "udp" && p.protocol != "tcp" {
+				return nil, errors.Errorf("unsupported protocol '%s', it should be udp or tcp", v.String())
+			}
+		case "timeout":
+			p.timeout = time.Duration(v.ToFloat() * float64(time.Millisecond))
+		case "recursion":
+			p.recursion = v.ToBoolean()
+		case "edns":
+			p.edns = v.ToBoolean()
+		case "dnssec":
+			p.dnssec = v.ToBoolean()
+		case "throw":
+			p.throw = v.ToBoolean()
+		case "tags":
+			if goja.IsUndefined(v) || goja.IsNull(v) {
+				continue
+			}
+			tagObj := v.ToObject(rt)
+			for _, key := range tagObj.Keys() {
+				p.tags[key] = tagObj.Get(key).String()
+			}
+		}
+	}
+	if p.server == "" {
+		return nil, errors.New("the DNS server has to be specified with the server param")
+	}
+	return p, nil
+}
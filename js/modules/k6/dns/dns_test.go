@@ -0,0 +1,268 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package dns
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/dop251/goja"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/loadimpact/k6/js/common"
+	"github.com/loadimpact/k6/lib"
+	"github.com/loadimpact/k6/lib/netext"
+	"github.com/loadimpact/k6/stats"
+)
+
+// answer builds the response of the test DNS server to the given query.
+func answer(t *testing.T, query []byte) []byte {
+	var p dnsmessage.Parser
+	header, err := p.Start(query)
+	require.NoError(t, err)
+	q, err := p.Question()
+	require.NoError(t, err)
+	require.NoError(t, p.SkipAllQuestions())
+	require.NoError(t, p.SkipAllAnswers())
+	require.NoError(t, p.SkipAllAuthorities())
+	optHeader, optErr := p.AdditionalHeader()
+
+	name := q.Name.String()
+	header.Response, header.Authoritative = true, true
+	if !strings.HasSuffix(name, "example.com.") {
+		header.RCode = dnsmessage.RCodeNameError
+	}
+	b := dnsmessage.NewBuilder(nil, header)
+	require.NoError(t, b.StartQuestions())
+	require.NoError(t, b.Question(q))
+	require.NoError(t, b.StartAnswers())
+	rh := dnsmessage.ResourceHeader{Name: q.Name, Class: dnsmessage.ClassINET, TTL: 300}
+	if header.RCode == dnsmessage.RCodeSuccess {
+		switch q.Type {
+		case dnsmessage.TypeA:
+			require.NoError(t, b.AResource(rh, dnsmessage.AResource{A: [4]byte{192, 0, 2, 1}}))
+			require.NoError(t, b.AResource(rh, dnsmessage.AResource{A: [4]byte{192, 0, 2, 2}}))
+		case dnsmessage.TypeMX:
+			mx := dnsmessage.MustNewName("mail.example.com.")
+			require.NoError(t, b.MXResource(rh, dnsmessage.MXResource{Pref: 10, MX: mx}))
+		case dnsmessage.TypeTXT:
+			require.NoError(t, b.TXTResource(rh, dnsmessage.TXTResource{TXT: []string{"v=spf1 ", "-all"}}))
+		}
+	}
+	require.NoError(t, b.StartAuthorities())
+	ns := dnsmessage.MustNewName("ns.example.com.")
+	require.NoError(t, b.NSResource(rh, dnsmessage.NSResource{NS: ns}))
+	if optErr == nil && optHeader.Type == dnsmessage.TypeOPT {
+		// Echo the DNSSEC OK bit in a TXT record, so the tests can check it.
+		require.NoError(t, b.StartAdditionals())
+		do := "do=0"
+		if optHeader.DNSSECAllowed() {
+			do = "do=1"
+		}
+		require.NoError(t, b.TXTResource(rh, dnsmessage.TXTResource{TXT: []string{do}}))
+		require.NoError(t, b.OPTResource(optHeader, dnsmessage.OPTResource{}))
+	}
+	response, err := b.Finish()
+	require.NoError(t, err)
+	return response
+}
+
+func startServer(t *testing.T) (string, func()) {
+	udpConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	tcpListener, err := net.Listen("tcp", udpConn.LocalAddr().String())
+	require.NoError(t, err)
+
+	go func() {
+		buf := make([]byte, 65535)
+		for {
+			n, addr, err := udpConn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			_, _ = udpConn.WriteTo(answer(t, buf[:n]), addr)
+		}
+	}()
+	go func() {
+		for {
+			conn, err := tcpListener.Accept()
+			if err != nil {
+				return
+			}
+			var length [2]byte
+			if _, err = io.ReadFull(conn, length[:]); err == nil {
+				query := make([]byte, binary.BigEndian.Uint16(length[:]))
+				if _, err = io.ReadFull(conn, query); err == nil {
+					response := answer(t, query)
+					binary.BigEndian.PutUint16(length[:], uint16(len(response)))
+					_, _ = conn.Write(append(length[:], response...))
+				}
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	return udpConn.LocalAddr().String(), func() {
+		_ = udpConn.Close()
+		_ = tcpListener.Close()
+	}
+}
+
+func TestLookup(t *testing.T) {
+	server, stop := startServer(t)
+	defer stop()
+
+	rt := goja.New()
+	rt.SetFieldNameMapper(common.FieldNameMapper{})
+	samples := make(chan stats.SampleContainer, 1000)
+	state := &lib.State{
+		Options: lib.Options{SystemTags: &stats.DefaultSystemTagSet},
+		Logger:  logrus.New(),
+		Dialer:  netext.NewDialer(net.Dialer{}),
+		Samples: samples,
+		Tags:    map[string]string{},
+	}
+	ctx := new(context.Context)
+	*ctx = common.WithRuntime(context.Background(), rt)
+	rt.Set("dns", common.Bind(rt, New(), ctx))
+	rt.Set("SERVER", server)
+
+	t.Run("init context", func(t *testing.T) {
+		_, err := common.RunString(rt, `dns.lookup("example.com", { server: SERVER })`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), ErrDNSForbiddenInInitContext.Error())
+	})
+
+	*ctx = lib.WithState(*ctx, state)
+
+	t.Run("A", func(t *testing.T) {
+		for _, protocol := range []string{"udp", "tcp"} {
+			rt.Set("PROTOCOL", protocol)
+			_, err := common.RunString(rt, `
+				var res = dns.lookup("example.com", { server: SERVER, protocol: PROTOCOL, tags: { tag: "value" } });
+				if (res.rcode !== "NOERROR") { throw new Error("unexpected rcode: " + res.rcode); }
+				if (res.answers.length !== 2) { throw new Error("unexpected answers: " + JSON.stringify(res.answers)); }
+				var a = res.answers[0];
+				if (a.name !== "example.com." || a.type !== "A" || a.ttl !== 300 || a.data !== "192.0.2.1") {
+					throw new Error("unexpected answer: " + JSON.stringify(a));
+				}
+				if (res.authority[0].data !== "ns.example.com.") { throw new Error("unexpected authority"); }
+				if (!res.authoritative || res.truncated) { throw new Error("unexpected flags"); }
+				if (res.additional.length !== 0) { throw new Error("unexpected additional records"); }
+				if (!(res.rtt >= 0)) { throw new Error("unexpected rtt: " + res.rtt); }
+			`)
+			require.NoError(t, err, protocol)
+
+			bufSamples := stats.GetBufferedSamples(samples)
+			require.Len(t, bufSamples, 1)
+			names := []string{}
+			for _, sample := range bufSamples[0].GetSamples() {
+				names = append(names, sample.Metric.Name)
+				tags := sample.Tags.CloneTags()
+				assert.Equal(t, map[string]string{
+					"type": "A", "server": server, "proto": protocol, "rcode": "NOERROR", "tag": "value",
+				}, tags)
+			}
+			assert.Equal(t, []string{"dns_lookups", "dns_lookup_duration"}, names)
+		}
+	})
+
+	t.Run("system tags", func(t *testing.T) {
+		defer func() { state.Options.SystemTags = &stats.DefaultSystemTagSet }()
+		state.Options.SystemTags = stats.NewSystemTagSet(stats.TagProto)
+
+		_, err := common.RunString(rt, `dns.lookup("example.com", { server: SERVER })`)
+		require.NoError(t, err)
+
+		bufSamples := stats.GetBufferedSamples(samples)
+		require.Len(t, bufSamples, 1)
+		for _, sample := range bufSamples[0].GetSamples() {
+			assert.Equal(t, map[string]string{"type": "A", "proto": "udp"}, sample.Tags.CloneTags())
+		}
+	})
+
+	t.Run("record types", func(t *testing.T) {
+		_, err := common.RunString(rt, `
+			var mx = dns.lookup("example.com", { server: SERVER, type: "MX" });
+			if (mx.answers[0].type !== "MX" || mx.answers[0].data !== "10 mail.example.com.") {
+				throw new Error("unexpected MX answer: " + JSON.stringify(mx.answers));
+			}
+			var txt = dns.lookup("example.com.", { server: SERVER, type: "txt" });
+			if (txt.answers[0].data !== "v=spf1 -all") {
+				throw new Error("unexpected TXT answer: " + JSON.stringify(txt.answers));
+			}
+		`)
+		require.NoError(t, err)
+
+		_, err = common.RunString(rt, `dns.lookup("example.com", { server: SERVER, type: "HINFO" })`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported record type 'HINFO'")
+	})
+
+	t.Run("NXDOMAIN", func(t *testing.T) {
+		_, err := common.RunString(rt, `
+			var res = dns.lookup("example.org", { server: SERVER });
+			if (res.rcode !== "NXDOMAIN" || res.answers.length !== 0) { throw new Error("unexpected result"); }
+		`)
+		require.NoError(t, err)
+	})
+
+	t.Run("DNSSEC", func(t *testing.T) {
+		_, err := common.RunString(rt, `
+			var res = dns.lookup("example.com", { server: SERVER, dnssec: true });
+			if (res.additional.length !== 1 || res.additional[0].data !== "do=1") {
+				throw new Error("unexpected additional records: " + JSON.stringify(res.additional));
+			}
+			res = dns.lookup("example.com", { server: SERVER, edns: true });
+			if (res.additional[0].data !== "do=0") {
+				throw new Error("unexpected additional records: " + JSON.stringify(res.additional));
+			}
+		`)
+		require.NoError(t, err)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := common.RunString(rt, `dns.lookup("example.com")`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "the DNS server has to be specified")
+
+		_, err = common.RunString(rt, `dns.lookup("example.com", { server: SERVER, protocol: "quic" })`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported protocol 'quic'")
+
+		_, err = common.RunString(rt, `dns.lookup("example.com", { server: "127.0.0.1:1", protocol: "tcp", throw: true })`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "connection refused")
+
+		_, err = common.RunString(rt, `
+			var res = dns.lookup("example.com", { server: "127.0.0.1:1", protocol: "tcp" });
+			if (!res.error) { throw new Error("expected an error"); }
+		`)
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,281 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package dns
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/loadimpact/k6/lib"
+)
+
+const (
+	// The UDP payload size that is advertised with EDNS0.
+	ednsUDPSize = 4096
+	// The Authenticated Data bit of the header flags, see RFC 4035.
+	adBit = 1 << 5
+)
+
+//nolint:gochecknoglobals
+var (
+	recordTypes = map[string]dnsmessage.Type{
+		"A":     dnsmessage.TypeA,
+		"AAAA":  dnsmessage.TypeAAAA,
+		"CNAME": dnsmessage.TypeCNAME,
+		"MX":    dnsmessage.TypeMX,
+		"NS":    dnsmessage.TypeNS,
+		"PTR":   dnsmessage.TypePTR,
+		"SOA":   dnsmessage.TypeSOA,
+		"SRV":   dnsmessage.TypeSRV,
+		"TXT":   dnsmessage.TypeTXT,
+	}
+	// The names of the other record types that can be found in responses.
+	otherTypeNames = map[dnsmessage.Type]string{
+		dnsmessage.TypeOPT: "OPT",
+		43:                 "DS",
+		46:                 "RRSIG",
+		47:                 "NSEC",
+		48:                 "DNSKEY",
+		50:                 "NSEC3",
+		51:                 "NSEC3PARAM",
+		257:                "CAA",
+	}
+	rcodeNames = map[dnsmessage.RCode]string{
+		dnsmessage.RCodeSuccess:        "NOERROR",
+		dnsmessage.RCodeFormatError:    "FORMERR",
+		dnsmessage.RCodeServerFailure:  "SERVFAIL",
+		dnsmessage.RCodeNameError:      "NXDOMAIN",
+		dnsmessage.RCodeNotImplemented: "NOTIMP",
+		dnsmessage.RCodeRefused:        "REFUSED",
+	}
+)
+
+func typeName(t dnsmessage.Type) string {
+	for name, recordType := range recordTypes {
+		if recordType == t {
+			return name
+		}
+	}
+	if name, ok := otherTypeNames[t]; ok {
+		return name
+	}
+	return "TYPE" + strconv.Itoa(int(t))
+}
+
+func rcodeName(rcode dnsmessage.RCode) string {
+	if name, ok := rcodeNames[rcode]; ok {
+		return name
+	}
+	return "RCODE" + strconv.Itoa(int(rcode))
+}
+
+func buildQuery(id uint16, name dnsmessage.Name, p *lookupParams) ([]byte, error) {
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{ID: id, RecursionDesired: p.recursion})
+	b.EnableCompression()
+	if err := b.StartQuestions(); err != nil {
+		return nil, err
+	}
+	if err := b.Question(dnsmessage.Question{Name: name, Type: p.qtype, Class: dnsmessage.ClassINET}); err != nil {
+		return nil, err
+	}
+	if p.edns || p.dnssec {
+		if err := b.StartAdditionals(); err != nil {
+			return nil, err
+		}
+		var opt dnsmessage.ResourceHeader
+		if err := opt.SetEDNS0(ednsUDPSize, dnsmessage.RCodeSuccess, p.dnssec); err != nil {
+			return nil, err
+		}
+		if err := b.OPTResource(opt, dnsmessage.OPTResource{}); err != nil {
+			return nil, err
+		}
+	}
+	return b.Finish()
+}
+
+// exchange sends the query to the server and returns the response to it and
+// the round-trip time, which doesn't include the time needed to connect.
+func exchange(
+	ctx context.Context, dialer lib.DialContexter, protocol, server string, id uint16, query []byte,
+) ([]byte, time.Duration, error) {
+	conn, err := dialer.DialContext(ctx, protocol, server)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = conn.Close() }()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	start := time.Now()
+	if protocol == "tcp" {
+		// Messages sent over TCP are prefixed with their length.
+		msg := make([]byte, 2+len(query))
+		binary.BigEndian.PutUint16(msg, uint16(len(query)))
+		copy(msg[2:], query)
+		if _, err = conn.Write(msg); err != nil {
+			return nil, 0, err
+		}
+		var length [2]byte
+		if _, err = io.ReadFull(conn, length[:]); err != nil {
+			return nil, 0, err
+		}
+		response := make([]byte, binary.BigEndian.Uint16(length[:]))
+		if _, err = io.ReadFull(conn, response); err != nil {
+			return nil, 0, err
+		}
+		return response, time.Since(start), nil
+	}
+
+	if _, err = conn.Write(query); err != nil {
+		return nil, 0, err
+	}
+	buf := make([]byte, 65535)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, 0, err
+		}
+		// Ignore anything that isn't a response to our query, e.g. late
+		// responses to previous queries sent from the same port.
+		if n >= 2 && binary.BigEndian.Uint16(buf) == id {
+			return buf[:n], time.Since(start), nil
+		}
+	}
+}
+
+func parseResponse(response []byte, result *LookupResult) error {
+	var p dnsmessage.Parser
+	header, err := p.Start(response)
+	if err != nil {
+		return errors.Wrap(err, "invalid DNS response")
+	}
+	if !header.Response {
+		return errors.New("invalid DNS response: the QR bit isn't set")
+	}
+	result.Authoritative = header.Authoritative
+	// dnsmessage doesn't parse the AD bit, so it's read from the header flags.
+	result.Authenticated = binary.BigEndian.Uint16(response[2:4])&adBit != 0
+	result.Truncated = header.Truncated
+	rcode := header.RCode
+
+	if err = p.SkipAllQuestions(); err != nil {
+		return errors.Wrap(err, "invalid DNS response")
+	}
+	if result.Answers, err = parseRecords(&p, p.AnswerHeader, p.SkipAnswer, nil); err != nil {
+		return errors.Wrap(err, "invalid DNS response")
+	}
+	if result.Authority, err = parseRecords(&p, p.AuthorityHeader, p.SkipAuthority, nil); err != nil {
+		return errors.Wrap(err, "invalid DNS response")
+	}
+	var opt *dnsmessage.ResourceHeader
+	if result.Additional, err = parseRecords(&p, p.AdditionalHeader, p.SkipAdditional, &opt); err != nil {
+		return errors.Wrap(err, "invalid DNS response")
+	}
+	if opt != nil {
+		// The upper bits of the extended RCode are in the OPT record header.
+		rcode = opt.ExtendedRCode(rcode)
+	}
+	result.RCode = rcodeName(rcode)
+	return nil
+}
+
+// parseRecords parses the records of the current section of the response.
+// The EDNS0 OPT pseudo-record isn't returned with them, but its header is
+// saved in opt, if that's not nil.
+func parseRecords(
+	p *dnsmessage.Parser, nextHeader func() (dnsmessage.ResourceHeader, error), skip func() error,
+	opt **dnsmessage.ResourceHeader,
+) ([]Record, error) {
+	records := []Record{}
+	for {
+		h, err := nextHeader()
+		if err == dnsmessage.ErrSectionDone {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if h.Type == dnsmessage.TypeOPT {
+			if opt != nil {
+				optHeader := h
+				*opt = &optHeader
+			}
+			if err = skip(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		record := Record{Name: h.Name.String(), Type: typeName(h.Type), TTL: h.TTL}
+		if record.Data, err = parseRecordData(p, h.Type, skip); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+}
+
+//nolint:gocyclo
+func parseRecordData(p *dnsmessage.Parser, t dnsmessage.Type, skip func() error) (string, error) {
+	switch t {
+	case dnsmessage.TypeA:
+		r, err := p.AResource()
+		return net.IP(r.A[:]).String(), err
+	case dnsmessage.TypeAAAA:
+		r, err := p.AAAAResource()
+		return net.IP(r.AAAA[:]).String(), err
+	case dnsmessage.TypeCNAME:
+		r, err := p.CNAMEResource()
+		return r.CNAME.String(), err
+	case dnsmessage.TypeNS:
+		r, err := p.NSResource()
+		return r.NS.String(), err
+	case dnsmessage.TypePTR:
+		r, err := p.PTRResource()
+		return r.PTR.String(), err
+	case dnsmessage.TypeMX:
+		r, err := p.MXResource()
+		return fmt.Sprintf("%d %s", r.Pref, r.MX.String()), err
+	case dnsmessage.TypeSRV:
+		r, err := p.SRVResource()
+		return fmt.Sprintf("%d %d %d %s", r.Priority, r.Weight, r.Port, r.Target.String()), err
+	case dnsmessage.TypeSOA:
+		r, err := p.SOAResource()
+		return fmt.Sprintf("%s %s %d %d %d %d %d", r.NS.String(), r.MBox.String(),
+			r.Serial, r.Refresh, r.Retry, r.Expire, r.MinTTL), err
+	case dnsmessage.TypeTXT:
+		// Long TXT records are split in multiple strings, which are joined
+		// back, since that's how they are used.
+		r, err := p.TXTResource()
+		return strings.Join(r.TXT, ""), err
+	default:
+		return "", skip()
+	}
+}
@@ -23,6 +23,8 @@ package encoding
 import (
 	"context"
 	"encoding/base64"
+	"encoding/hex"
+	"fmt"
 
 	"github.com/loadimpact/k6/js/common"
 )
@@ -48,7 +50,10 @@ func (e *Encoding) B64encode(ctx context.Context, input []byte, encoding string)
 	}
 }
 
-func (e *Encoding) B64decode(ctx context.Context, input string, encoding string) string {
+// B64decode decodes the base64 input using the given encoding. The result is
+// returned as a string, unless the "b" format is requested, in which case
+// the raw decoded bytes are returned instead.
+func (e *Encoding) B64decode(ctx context.Context, input string, encoding string, format ...string) interface{} {
 	var output []byte
 	var err error
 
@@ -69,5 +74,36 @@ func (e *Encoding) B64decode(ctx context.Context, input string, encoding string)
 		common.Throw(common.GetRuntime(ctx), err)
 	}
 
-	return string(output)
+	return formatOutput(ctx, output, format)
+}
+
+// Hexencode returns the hex representation of the input.
+func (e *Encoding) Hexencode(ctx context.Context, input []byte) string {
+	return hex.EncodeToString(input)
+}
+
+// Hexdecode decodes the hex input. Just like with B64decode(), the result is
+// a string, unless the "b" format is requested.
+func (e *Encoding) Hexdecode(ctx context.Context, input string, format ...string) interface{} {
+	output, err := hex.DecodeString(input)
+	if err != nil {
+		common.Throw(common.GetRuntime(ctx), err)
+	}
+
+	return formatOutput(ctx, output, format)
+}
+
+func formatOutput(ctx context.Context, output []byte, format []string) interface{} {
+	if len(format) == 0 {
+		return string(output)
+	}
+	switch format[0] {
+	case "", "s":
+		return string(output)
+	case "b":
+		return output
+	default:
+		common.Throw(common.GetRuntime(ctx), fmt.Errorf("invalid output format '%s'", format[0]))
+		return nil
+	}
 }
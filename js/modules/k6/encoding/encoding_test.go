@@ -151,4 +151,59 @@ func TestEncodingAlgorithms(t *testing.T) {
 			assert.NoError(t, err)
 		})
 	})
+	t.Run("Binary", func(t *testing.T) {
+		t.Run("B64Dec", func(t *testing.T) {
+			_, err := common.RunString(rt, `
+			var decoded = encoding.b64decode("AQID/w==", "std", "b");
+			if (decoded.length !== 4 || decoded[0] !== 1 || decoded[3] !== 255) {
+				throw new Error("Decoding mismatch: " + JSON.stringify(decoded));
+			}`)
+			assert.NoError(t, err)
+		})
+		t.Run("B64Enc", func(t *testing.T) {
+			_, err := common.RunString(rt, `
+			var correct = "AQID/w==";
+			var encoded = encoding.b64encode([1, 2, 3, 255], "std");
+			if (encoded !== correct) {
+				throw new Error("Encoding mismatch: " + encoded);
+			}`)
+			assert.NoError(t, err)
+		})
+		t.Run("InvalidFormat", func(t *testing.T) {
+			_, err := common.RunString(rt, `encoding.b64decode("AQID/w==", "std", "x");`)
+			assert.Contains(t, err.Error(), "invalid output format 'x'")
+		})
+	})
+	t.Run("Hex", func(t *testing.T) {
+		t.Run("Enc", func(t *testing.T) {
+			_, err := common.RunString(rt, `
+			var correct = "68656c6c6f20776f726c64";
+			var encoded = encoding.hexencode("hello world");
+			if (encoded !== correct) {
+				throw new Error("Encoding mismatch: " + encoded);
+			}`)
+			assert.NoError(t, err)
+		})
+		t.Run("Dec", func(t *testing.T) {
+			_, err := common.RunString(rt, `
+			var correct = "hello world";
+			var decoded = encoding.hexdecode("68656c6c6f20776f726c64");
+			if (decoded !== correct) {
+				throw new Error("Decoding mismatch: " + decoded);
+			}`)
+			assert.NoError(t, err)
+		})
+		t.Run("DecBinary", func(t *testing.T) {
+			_, err := common.RunString(rt, `
+			var decoded = encoding.hexdecode("0102ff", "b");
+			if (decoded.length !== 3 || decoded[2] !== 255) {
+				throw new Error("Decoding mismatch: " + JSON.stringify(decoded));
+			}`)
+			assert.NoError(t, err)
+		})
+		t.Run("DecInvalid", func(t *testing.T) {
+			_, err := common.RunString(rt, `encoding.hexdecode("zz");`)
+			assert.Error(t, err)
+		})
+	})
 }
@@ -0,0 +1,94 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package execution
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dop251/goja"
+	"golang.org/x/time/rate"
+
+	"github.com/loadimpact/k6/js/common"
+	"github.com/loadimpact/k6/lib"
+)
+
+// Execution is the k6/execution module, with information about the current
+// execution of the test.
+type Execution struct{}
+
+// New returns a new k6/execution module.
+func New() *Execution {
+	return &Execution{}
+}
+
+// ModuleInstance is the instance of the module in a VU.
+type ModuleInstance struct {
+	VU *goja.Object `js:"vu"`
+}
+
+// NewModuleInstancePerVU returns the instance of the module for a VU, whose
+// properties are read from its current state.
+func (*Execution) NewModuleInstancePerVU(rt *goja.Runtime, ctxPtr *context.Context) interface{} {
+	vu := rt.NewObject()
+	getters := map[string]func(state *lib.State) interface{}{
+		"id":        func(state *lib.State) interface{} { return state.Vu },
+		"iteration": func(state *lib.State) interface{} { return state.Iteration },
+		"traceId":   func(state *lib.State) interface{} { return state.TraceID },
+		// The same as traceId, for when a script reads it as a per-iteration ID
+		"iterationTraceId": func(state *lib.State) interface{} { return state.TraceID },
+	}
+	for name, getter := range getters {
+		getter := getter
+		_ = vu.DefineAccessorProperty(name, rt.ToValue(func(goja.FunctionCall) goja.Value {
+			state := lib.GetState(*ctxPtr)
+			if state == nil {
+				return goja.Undefined() // in the init context
+			}
+			return rt.ToValue(getter(state))
+		}), nil, goja.FLAG_FALSE, goja.FLAG_TRUE)
+	}
+
+	// The rate limit of the HTTP requests of the VU, which can also be changed
+	// from the script, and 0 or less removes it
+	_ = vu.DefineAccessorProperty("maxRPS", rt.ToValue(func(goja.FunctionCall) goja.Value {
+		state := lib.GetState(*ctxPtr)
+		if state == nil {
+			return goja.Undefined()
+		}
+		if state.VURPSLimit == nil {
+			return rt.ToValue(0)
+		}
+		return rt.ToValue(float64(state.VURPSLimit.Limit()))
+	}), rt.ToValue(func(call goja.FunctionCall) goja.Value {
+		state := lib.GetState(*ctxPtr)
+		if state == nil {
+			common.Throw(rt, errors.New("the maxRPS can't be set in the init context"))
+		}
+		if maxRPS := call.Argument(0).ToFloat(); maxRPS > 0 {
+			state.VURPSLimit = rate.NewLimiter(rate.Limit(maxRPS), 1)
+		} else {
+			state.VURPSLimit = nil
+		}
+		return goja.Undefined()
+	}), goja.FLAG_FALSE, goja.FLAG_TRUE)
+	return &ModuleInstance{VU: vu}
+}
@@ -0,0 +1,55 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package faker
+
+//nolint:gochecknoglobals
+var (
+	firstNames = []string{
+		"Aaron", "Abigail", "Adam", "Alice", "Amelia", "Andrew", "Anna", "Benjamin", "Carlos", "Charlotte",
+		"Chloe", "Daniel", "David", "Elena", "Elijah", "Emily", "Emma", "Ethan", "Fatima", "Gabriel",
+		"Grace", "Hannah", "Henry", "Isabella", "Jack", "James", "Julia", "Kevin", "Laura", "Liam",
+		"Lucas", "Maria", "Mason", "Mia", "Noah", "Olivia", "Oscar", "Priya", "Rachel", "Samuel",
+		"Sara", "Sofia", "Thomas", "Victoria", "William", "Yuki", "Zoe",
+	}
+	lastNames = []string{
+		"Anderson", "Brown", "Clark", "Davis", "Evans", "Fischer", "Garcia", "Gonzalez", "Harris", "Hernandez",
+		"Jackson", "Johnson", "Jones", "Kim", "Lee", "Lewis", "Lopez", "Martin", "Martinez", "Miller",
+		"Moore", "Meyer", "Nguyen", "Novak", "Patel", "Perez", "Robinson", "Rodriguez", "Rossi", "Sanchez",
+		"Schmidt", "Smith", "Taylor", "Thomas", "Thompson", "Walker", "White", "Williams", "Wilson", "Young",
+	}
+	emailDomains = []string{"example.com", "example.net", "example.org"}
+
+	streetNames = []string{
+		"Main", "Oak", "Pine", "Maple", "Cedar", "Elm", "Washington", "Lake", "Hill", "Park",
+		"Sunset", "River", "Church", "Mill", "Spring", "Highland", "Forest", "Meadow", "Willow", "Lincoln",
+	}
+	streetSuffixes = []string{"Street", "Avenue", "Road", "Boulevard", "Lane", "Drive", "Court", "Way"}
+	cities         = []string{
+		"Springfield", "Riverside", "Franklin", "Greenville", "Bristol", "Clinton", "Fairview", "Salem",
+		"Madison", "Georgetown", "Arlington", "Ashland", "Burlington", "Dover", "Hudson", "Kingston",
+		"Lexington", "Milton", "Newport", "Oxford",
+	}
+	states = []string{
+		"AL", "AK", "AZ", "AR", "CA", "CO", "CT", "DE", "FL", "GA", "HI", "ID", "IL", "IN", "IA", "KS", "KY",
+		"LA", "ME", "MD", "MA", "MI", "MN", "MS", "MO", "MT", "NE", "NV", "NH", "NJ", "NM", "NY", "NC", "ND",
+		"OH", "OK", "OR", "PA", "RI", "SC", "SD", "TN", "TX", "UT", "VT", "VA", "WA", "WV", "WI", "WY",
+	}
+)
@@ -0,0 +1,203 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package faker
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/pkg/errors"
+
+	"github.com/loadimpact/k6/js/common"
+	"github.com/loadimpact/k6/lib"
+)
+
+// Faker is the k6/faker module, which generates fake test data. In VU code,
+// the data comes from the random number generator of the VU, so it can be
+// made reproducible with the randomSeed option.
+type Faker struct{}
+
+// New returns a new k6/faker module instance.
+func New() *Faker {
+	return &Faker{}
+}
+
+// getRand returns the random number generator of the VU, or a new randomly
+// seeded one in the init context.
+func getRand(ctx context.Context) *rand.Rand {
+	if state := lib.GetState(ctx); state != nil && state.Rand != nil {
+		return state.Rand
+	}
+	return common.NewRand()
+}
+
+func pick(r *rand.Rand, values []string) string {
+	return values[r.Intn(len(values))]
+}
+
+// FirstName returns a random first name.
+func (*Faker) FirstName(ctx context.Context) string {
+	return pick(getRand(ctx), firstNames)
+}
+
+// LastName returns a random last name.
+func (*Faker) LastName(ctx context.Context) string {
+	return pick(getRand(ctx), lastNames)
+}
+
+// Name returns a random full name.
+func (*Faker) Name(ctx context.Context) string {
+	r := getRand(ctx)
+	return pick(r, firstNames) + " " + pick(r, lastNames)
+}
+
+// Email returns a random email address in one of the domains reserved for
+// examples, so that nothing is ever sent to a real mailbox.
+func (*Faker) Email(ctx context.Context) string {
+	r := getRand(ctx)
+	return fmt.Sprintf("%s.%s%d@%s",
+		strings.ToLower(pick(r, firstNames)), strings.ToLower(pick(r, lastNames)),
+		r.Intn(1000), pick(r, emailDomains))
+}
+
+// Address returns a random US-style street address.
+func (*Faker) Address(ctx context.Context) string {
+	r := getRand(ctx)
+	return fmt.Sprintf("%d %s %s, %s, %s %05d",
+		1+r.Intn(9999), pick(r, streetNames), pick(r, streetSuffixes),
+		pick(r, cities), pick(r, states), 1+r.Intn(99999))
+}
+
+// Phone returns a random US phone number from the 555-0100 to 555-0199 range,
+// which is reserved for fictional use.
+func (*Faker) Phone(ctx context.Context) string {
+	r := getRand(ctx)
+	return fmt.Sprintf("(%d) 555-01%02d", 201+r.Intn(789), r.Intn(100))
+}
+
+// CreditCard returns a random 16-digit card number starting with 4, with a
+// valid Luhn check digit.
+func (*Faker) CreditCard(ctx context.Context) string {
+	r := getRand(ctx)
+	digits := make([]byte, 16)
+	digits[0] = 4
+	for i := 1; i < 15; i++ {
+		digits[i] = byte(r.Intn(10))
+	}
+
+	sum := 0
+	for i := 14; i >= 0; i-- {
+		d := int(digits[i])
+		if (14-i)%2 == 0 { // every second digit from the right, starting with the one next to the check digit
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	digits[15] = byte((10 - sum%10) % 10)
+
+	for i := range digits {
+		digits[i] += '0'
+	}
+	return string(digits)
+}
+
+// Uuid returns a random (version 4) RFC 4122 UUID.
+func (*Faker) Uuid(ctx context.Context) string {
+	var uuid [16]byte
+	_, _ = getRand(ctx).Read(uuid[:])
+	uuid[6] = (uuid[6] & 0x0f) | 0x40
+	uuid[8] = (uuid[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:])
+}
+
+// Integer returns a random integer between min and max, inclusive.
+func (*Faker) Integer(ctx context.Context, min, max int64) (int64, error) {
+	if min > max {
+		return 0, errors.Errorf("the min value %d is greater than the max value %d", min, max)
+	}
+	return min + getRand(ctx).Int63n(max-min+1), nil
+}
+
+// Float returns a random floating-point number between min and max.
+func (*Faker) Float(ctx context.Context, min, max float64) (float64, error) {
+	if min > max {
+		return 0, errors.Errorf("the min value %g is greater than the max value %g", min, max)
+	}
+	return min + getRand(ctx).Float64()*(max-min), nil
+}
+
+// Boolean returns true or false, with equal probability.
+func (*Faker) Boolean(ctx context.Context) bool {
+	return getRand(ctx).Intn(2) == 1
+}
+
+// Date returns a random date between from and to, which can be Date objects,
+// timestamps in milliseconds or ISO 8601 strings. They default to a year ago
+// and the current time.
+func (*Faker) Date(ctx context.Context, from, to goja.Value) (goja.Value, error) {
+	rt := common.GetRuntime(ctx)
+	now := time.Now()
+	start, err := toTime(from, now.AddDate(-1, 0, 0))
+	if err != nil {
+		return nil, err
+	}
+	end, err := toTime(to, now)
+	if err != nil {
+		return nil, err
+	}
+	if start.After(end) {
+		return nil, errors.Errorf("the from date %s is after the to date %s",
+			start.Format(time.RFC3339), end.Format(time.RFC3339))
+	}
+
+	fromMs, toMs := start.UnixNano()/int64(time.Millisecond), end.UnixNano()/int64(time.Millisecond)
+	ms := fromMs + getRand(ctx).Int63n(toMs-fromMs+1)
+	return rt.New(rt.Get("Date"), rt.ToValue(ms))
+}
+
+func toTime(v goja.Value, def time.Time) (time.Time, error) {
+	if v == nil || goja.IsUndefined(v) || goja.IsNull(v) {
+		return def, nil
+	}
+	switch t := v.Export().(type) {
+	case time.Time:
+		return t, nil
+	case int64:
+		return time.Unix(0, t*int64(time.Millisecond)), nil
+	case float64:
+		return time.Unix(0, int64(t)*int64(time.Millisecond)), nil
+	case string:
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02"} {
+			if parsed, err := time.Parse(layout, t); err == nil {
+				return parsed, nil
+			}
+		}
+		return time.Time{}, errors.Errorf("invalid date '%s', it should be in the ISO 8601 format", t)
+	default:
+		return time.Time{}, errors.Errorf("invalid date %s", v)
+	}
+}
@@ -0,0 +1,152 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package faker
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/loadimpact/k6/js/common"
+	"github.com/loadimpact/k6/lib"
+)
+
+func newRuntime(seed int64) *goja.Runtime {
+	rt := goja.New()
+	rt.SetFieldNameMapper(common.FieldNameMapper{})
+	ctx := common.WithRuntime(context.Background(), rt)
+	ctx = lib.WithState(ctx, &lib.State{Rand: rand.New(rand.NewSource(seed))}) //nolint:gosec
+	rt.Set("faker", common.Bind(rt, New(), &ctx))
+	return rt
+}
+
+func TestFaker(t *testing.T) {
+	rt := newRuntime(1)
+
+	formats := map[string]string{
+		`faker.name()`:       `^[A-Z][a-z]+ [A-Z][a-z]+$`,
+		`faker.firstName()`:  `^[A-Z][a-z]+$`,
+		`faker.lastName()`:   `^[A-Z][a-z]+$`,
+		`faker.email()`:      `^[a-z]+\.[a-z]+\d+@example\.(com|net|org)$`,
+		`faker.address()`:    `^\d+ [A-Z][a-z]+ [A-Z][a-z]+, [A-Z][a-z]+, [A-Z]{2} \d{5}$`,
+		`faker.phone()`:      `^\(\d{3}\) 555-01\d{2}$`,
+		`faker.uuid()`:       `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`,
+		`faker.creditCard()`: `^4\d{15}$`,
+	}
+	for script, format := range formats {
+		v, err := common.RunString(rt, script)
+		require.NoError(t, err, script)
+		assert.Regexp(t, format, v.String(), script)
+	}
+
+	t.Run("creditCard", func(t *testing.T) {
+		for i := 0; i < 20; i++ {
+			v, err := common.RunString(rt, `faker.creditCard()`)
+			require.NoError(t, err)
+			assert.True(t, luhnValid(v.String()), v.String())
+		}
+	})
+
+	t.Run("integer", func(t *testing.T) {
+		seen := map[int64]bool{}
+		for i := 0; i < 100; i++ {
+			v, err := common.RunString(rt, `faker.integer(1, 3)`)
+			require.NoError(t, err)
+			assert.True(t, v.ToInteger() >= 1 && v.ToInteger() <= 3, v.ToInteger())
+			seen[v.ToInteger()] = true
+		}
+		assert.Len(t, seen, 3)
+
+		_, err := common.RunString(rt, `faker.integer(3, 1)`)
+		assert.Contains(t, err.Error(), "the min value 3 is greater than the max value 1")
+	})
+
+	t.Run("float", func(t *testing.T) {
+		v, err := common.RunString(rt, `faker.float(0.5, 1.5)`)
+		require.NoError(t, err)
+		assert.True(t, v.ToFloat() >= 0.5 && v.ToFloat() < 1.5, v.ToFloat())
+	})
+
+	t.Run("boolean", func(t *testing.T) {
+		v, err := common.RunString(rt, `typeof faker.boolean()`)
+		require.NoError(t, err)
+		assert.Equal(t, "boolean", v.String())
+	})
+
+	t.Run("date", func(t *testing.T) {
+		v, err := common.RunString(rt, `
+			var d = faker.date("2020-01-01", new Date("2020-01-02T00:00:00Z"));
+			if (!(d instanceof Date)) { throw new Error("not a Date: " + d); }
+			d.toISOString();
+		`)
+		require.NoError(t, err)
+		date, err := time.Parse(time.RFC3339, v.String())
+		require.NoError(t, err)
+		assert.False(t, date.Before(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)), date)
+		assert.False(t, date.After(time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)), date)
+
+		_, err = common.RunString(rt, `faker.date("2020-01-02", "2020-01-01")`)
+		assert.Error(t, err)
+		_, err = common.RunString(rt, `faker.date("yesterday")`)
+		assert.Error(t, err)
+		_, err = common.RunString(rt, `faker.date()`)
+		assert.NoError(t, err)
+	})
+}
+
+func TestFakerSeed(t *testing.T) {
+	script := `[faker.name(), faker.email(), faker.uuid(), faker.integer(0, 1e9)].join()`
+	generate := func(seed int64) string {
+		v, err := common.RunString(newRuntime(seed), script)
+		require.NoError(t, err)
+		return v.String()
+	}
+	assert.Equal(t, generate(42), generate(42))
+	assert.NotEqual(t, generate(42), generate(43))
+
+	t.Run("init context", func(t *testing.T) {
+		rt := goja.New()
+		ctx := common.WithRuntime(context.Background(), rt)
+		rt.Set("faker", common.Bind(rt, New(), &ctx))
+		v, err := common.RunString(rt, `faker.uuid() !== faker.uuid()`)
+		require.NoError(t, err)
+		assert.True(t, v.ToBoolean())
+	})
+}
+
+func luhnValid(number string) bool {
+	sum := 0
+	for i := len(number) - 1; i >= 0; i-- {
+		d := int(number[i] - '0')
+		if (len(number)-i)%2 == 0 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
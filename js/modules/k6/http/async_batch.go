@@ -0,0 +1,169 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package http
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dop251/goja"
+
+	"github.com/loadimpact/k6/js/common"
+	"github.com/loadimpact/k6/lib"
+	"github.com/loadimpact/k6/lib/netext/httpext"
+)
+
+// AsyncBatchHandle is returned by http.asyncBatch() and allows the script to
+// wait for or cancel the requests that are being made in the background.
+//
+// Its methods are only ever called from the VU goroutine, so the bookkeeping
+// of which requests have already finished doesn't need any locking.
+type AsyncBatchHandle struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	reqs    []httpext.BatchParsedHTTPRequest
+	results interface{} // either []*Response or *namedResponses
+	done    []bool
+	errs    []error
+}
+
+// AsyncBatch starts making multiple simultaneous HTTP requests in the
+// background, the same way Batch() does, but without waiting for them to
+// finish. It returns a handle that can be used to wait for all or some of the
+// responses, or to cancel the pending requests.
+func (h *HTTP) AsyncBatch(ctx context.Context, reqsV goja.Value) (*AsyncBatchHandle, error) {
+	state := lib.GetState(ctx)
+	if state == nil {
+		return nil, ErrBatchForbiddenInInitContext
+	}
+
+	batchReqs, results, err := h.prepareBatch(ctx, reqsV)
+	if err != nil {
+		return nil, err
+	}
+
+	reqCount := len(batchReqs)
+	for i := range batchReqs {
+		batchReqs[i].Done = make(chan error, 1)
+	}
+
+	batchCtx, cancel := context.WithCancel(ctx)
+	// The requests that the script neither waited for nor cancelled are
+	// cancelled when the iteration ends, so they don't outlive it.
+	state.CloseAtIterationEnd(cancelCloser(cancel))
+	_ = httpext.MakeBatchRequests(
+		batchCtx, batchReqs, reqCount,
+		int(state.Options.Batch.Int64), int(state.Options.BatchPerHost.Int64),
+	)
+
+	return &AsyncBatchHandle{
+		ctx:     ctx,
+		cancel:  cancel,
+		reqs:    batchReqs,
+		results: results,
+		done:    make([]bool, reqCount),
+		errs:    make([]error, reqCount),
+	}, nil
+}
+
+// cancelCloser cancels a context when it's closed.
+type cancelCloser context.CancelFunc
+
+func (c cancelCloser) Close() error {
+	c()
+	return nil
+}
+
+func (b *AsyncBatchHandle) waitIndex(i int) error {
+	if !b.done[i] {
+		b.errs[i] = <-b.reqs[i].Done
+		b.done[i] = true
+		if res := b.response(i); res != nil {
+			res.wrapForJS(common.GetRuntime(b.ctx))
+		}
+	}
+	return b.errs[i]
+}
+
+// response returns the response object for the i-th request of the batch.
+func (b *AsyncBatchHandle) response(i int) *Response {
+	switch results := b.results.(type) {
+	case []*Response:
+		for _, res := range results {
+			if res.Response == b.reqs[i].Response {
+				return res
+			}
+		}
+	case *namedResponses:
+		for _, res := range results.responses {
+			if res.Response == b.reqs[i].Response {
+				return res
+			}
+		}
+	}
+	return nil
+}
+
+// Wait blocks until all of the requests in the batch have finished and
+// returns their responses, in the same shape as http.batch() would have.
+func (b *AsyncBatchHandle) Wait() (goja.Value, error) {
+	var err error
+	for i := range b.reqs {
+		if e := b.waitIndex(i); e != nil && err == nil { // Save only the first error
+			err = e
+		}
+	}
+	b.cancel() // release the context resources, everything has finished
+	return batchResultsValue(common.GetRuntime(b.ctx), b.results), err
+}
+
+// WaitFor blocks until a single request from the batch has finished and
+// returns its response. The key is the index of the request if the batch was
+// an array, or its name if the batch was an object.
+func (b *AsyncBatchHandle) WaitFor(key goja.Value) (*Response, error) {
+	var res *Response
+	switch results := b.results.(type) {
+	case []*Response:
+		i := int(key.ToInteger())
+		if i < 0 || i >= len(results) {
+			return nil, fmt.Errorf("invalid batch request index %s", key)
+		}
+		res = results[i]
+	case *namedResponses:
+		var ok bool
+		if res, ok = results.responses[key.String()]; !ok {
+			return nil, fmt.Errorf("invalid batch request key %q", key)
+		}
+	}
+
+	for i := range b.reqs {
+		if b.reqs[i].Response == res.Response {
+			return res, b.waitIndex(i)
+		}
+	}
+	return nil, fmt.Errorf("invalid batch request key %q", key) // shouldn't happen
+}
+
+// Cancel aborts all of the requests in the batch that haven't finished yet.
+// Their responses will contain the cancellation error.
+func (b *AsyncBatchHandle) Cancel() {
+	b.cancel()
+}
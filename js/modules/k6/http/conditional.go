@@ -0,0 +1,154 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/dop251/goja"
+
+	"github.com/loadimpact/k6/js/common"
+)
+
+// ConditionalGet makes an HTTP GET request with the If-None-Match and
+// If-Modified-Since headers set from the etag and lastModified options. The
+// rest of the request params can be passed with the params option. If the
+// server responds with 304 Not Modified, the response body is null and its
+// cached property is true.
+func (h *HTTP) ConditionalGet(ctx context.Context, url goja.Value, opts goja.Value) (*Response, error) {
+	rt := common.GetRuntime(ctx)
+
+	var etag, lastModified string
+	params := goja.Undefined()
+	if opts != nil && !goja.IsUndefined(opts) && !goja.IsNull(opts) {
+		o := opts.ToObject(rt)
+		for _, k := range o.Keys() {
+			v := o.Get(k)
+			if goja.IsUndefined(v) || goja.IsNull(v) {
+				continue
+			}
+			switch k {
+			case "etag":
+				etag = v.String()
+			case "lastModified":
+				lastModified = v.String()
+			case "params":
+				params = v
+			}
+		}
+	}
+
+	return h.conditionalGet(ctx, url, etag, lastModified, params)
+}
+
+// CachedGet makes a conditional HTTP GET request, like ConditionalGet, with
+// the ETag and Last-Modified values of the previous successful response for
+// the same URL, which are kept in the cache object that the script supplies.
+func (h *HTTP) CachedGet(ctx context.Context, url goja.Value, cache goja.Value, params goja.Value) (*Response, error) {
+	rt := common.GetRuntime(ctx)
+
+	if cache == nil || goja.IsUndefined(cache) || goja.IsNull(cache) {
+		return h.conditionalGet(ctx, url, "", "", params)
+	}
+	u, err := ToURL(url)
+	if err != nil {
+		return nil, err
+	}
+	cacheObj := cache.ToObject(rt)
+
+	var etag, lastModified string
+	if entry := cacheObj.Get(u.URL); entry != nil && !goja.IsUndefined(entry) && !goja.IsNull(entry) {
+		entryObj := entry.ToObject(rt)
+		if v := entryObj.Get("etag"); v != nil && !goja.IsUndefined(v) && !goja.IsNull(v) {
+			etag = v.String()
+		}
+		if v := entryObj.Get("lastModified"); v != nil && !goja.IsUndefined(v) && !goja.IsNull(v) {
+			lastModified = v.String()
+		}
+	}
+
+	res, err := h.conditionalGet(ctx, url, etag, lastModified, params)
+	if err != nil || res == nil {
+		return res, err
+	}
+
+	if res.Status >= 200 && res.Status < 300 {
+		newETag, newLastModified := res.Headers["Etag"], res.Headers["Last-Modified"]
+		if newETag != "" || newLastModified != "" {
+			if err := cacheObj.Set(u.URL, map[string]interface{}{
+				"etag":         newETag,
+				"lastModified": newLastModified,
+			}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return res, nil
+}
+
+func (h *HTTP) conditionalGet(
+	ctx context.Context, url goja.Value, etag, lastModified string, params goja.Value,
+) (*Response, error) {
+	rt := common.GetRuntime(ctx)
+
+	// Copy the params and their headers, so the ones the script passed aren't modified
+	newParams, headers := rt.NewObject(), rt.NewObject()
+	if params != nil && !goja.IsUndefined(params) && !goja.IsNull(params) {
+		p := params.ToObject(rt)
+		for _, k := range p.Keys() {
+			if err := newParams.Set(k, p.Get(k)); err != nil {
+				return nil, err
+			}
+		}
+		if hv := p.Get("headers"); hv != nil && !goja.IsUndefined(hv) && !goja.IsNull(hv) {
+			headersObj := hv.ToObject(rt)
+			for _, k := range headersObj.Keys() {
+				if err := headers.Set(k, headersObj.Get(k)); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	if etag != "" {
+		if err := headers.Set("If-None-Match", etag); err != nil {
+			return nil, err
+		}
+	}
+	if lastModified != "" {
+		if err := headers.Set("If-Modified-Since", lastModified); err != nil {
+			return nil, err
+		}
+	}
+	if err := newParams.Set("headers", headers); err != nil {
+		return nil, err
+	}
+
+	res, err := h.Get(ctx, url, newParams)
+	if err != nil || res == nil {
+		return res, err
+	}
+	if res.Status == http.StatusNotModified {
+		res.Body = nil
+		res.Cached = true
+	}
+	return res, nil
+}
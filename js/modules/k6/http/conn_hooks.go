@@ -0,0 +1,82 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package http
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dop251/goja"
+
+	"github.com/loadimpact/k6/js/common"
+	"github.com/loadimpact/k6/lib"
+)
+
+// ErrConnHooksForbiddenInInitContext is used when connection hooks were set in the init context
+var ErrConnHooksForbiddenInInitContext = common.NewInitContextError(
+	"Setting connection hooks in the init context is not supported")
+
+// OnConnect sets the function that is called with the details of every new
+// connection of the current VU, replacing the previous one. The functions are
+// called after the request that opened the connection, so they don't affect
+// the request timings. null removes the function.
+func (*HTTP) OnConnect(ctx context.Context, handler goja.Value) {
+	setConnHook(ctx, lib.ConnEventConnect, handler)
+}
+
+// OnDisconnect is like OnConnect(), but for closed connections. Connections
+// closed by the server are usually noticed before the next request.
+func (*HTTP) OnDisconnect(ctx context.Context, handler goja.Value) {
+	setConnHook(ctx, lib.ConnEventDisconnect, handler)
+}
+
+func setConnHook(ctx context.Context, eventType string, handler goja.Value) {
+	rt := common.GetRuntime(ctx)
+	state := lib.GetState(ctx)
+	if state == nil {
+		common.Throw(rt, ErrConnHooksForbiddenInInitContext)
+	}
+	if state.ConnHooks == nil {
+		common.Throw(rt, errors.New("connection hooks aren't supported"))
+	}
+	if handler == nil || goja.IsUndefined(handler) || goja.IsNull(handler) {
+		state.ConnHooks.SetHandler(eventType, nil)
+		return
+	}
+	fn, ok := goja.AssertFunction(handler)
+	if !ok {
+		common.Throw(rt, errors.New("the connection hook has to be a function"))
+	}
+	state.ConnHooks.SetHandler(eventType, func(event lib.ConnEvent) {
+		if _, err := fn(goja.Undefined(), rt.ToValue(event)); err != nil {
+			common.Throw(rt, err)
+		}
+	})
+}
+
+// dispatchConnEvents calls the connection hooks for the connections that were
+// opened or closed since the last time. It has to be called from the VU
+// goroutine.
+func dispatchConnEvents(ctx context.Context) {
+	if state := lib.GetState(ctx); state != nil && state.ConnHooks != nil {
+		state.ConnHooks.Dispatch()
+	}
+}
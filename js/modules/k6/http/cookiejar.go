@@ -22,6 +22,7 @@ package http
 
 import (
 	"context"
+	"net"
 	"net/http"
 	"net/http/cookiejar"
 	neturl "net/url"
@@ -32,12 +33,18 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/loadimpact/k6/js/common"
+	"github.com/loadimpact/k6/lib"
+	"github.com/loadimpact/k6/lib/netext/httpext"
 )
 
 // HTTPCookieJar is cookiejar.Jar wrapper to be used in js scripts
 type HTTPCookieJar struct {
 	jar *cookiejar.Jar
 	ctx *context.Context
+
+	// The jar returned by http.cookieJar() always uses the current jar of
+	// the VU, so it still works after it's replaced, e.g. by clearAll().
+	vuJar bool
 }
 
 func newCookieJar(ctxPtr *context.Context) *HTTPCookieJar {
@@ -45,17 +52,36 @@ func newCookieJar(ctxPtr *context.Context) *HTTPCookieJar {
 	if err != nil {
 		common.Throw(common.GetRuntime(*ctxPtr), err)
 	}
-	return &HTTPCookieJar{jar, ctxPtr}
+	return &HTTPCookieJar{jar: jar, ctx: ctxPtr}
+}
+
+// getJar returns the underlying jar, which is the current one of the VU for
+// the VU jar.
+func (j *HTTPCookieJar) getJar() *cookiejar.Jar {
+	if j.vuJar {
+		if state := lib.GetState(*j.ctx); state != nil {
+			return state.CookieJar
+		}
+	}
+	return j.jar
+}
+
+// JarCookie is a cookie in a jar, as it would be sent with a request. The jar
+// only keeps the names and values of the cookies for that, so it doesn't have
+// the other attributes of the cookie, like its domain, path or expiry.
+type JarCookie struct {
+	Name  string `js:"name"`
+	Value string `js:"value"`
 }
 
 // CookiesForURL return the cookies for a given url as a map of key and values
-func (j HTTPCookieJar) CookiesForURL(url string) map[string][]string {
+func (j *HTTPCookieJar) CookiesForURL(url string) map[string][]string {
 	u, err := neturl.Parse(url)
 	if err != nil {
 		panic(err)
 	}
 
-	cookies := j.jar.Cookies(u)
+	cookies := j.getJar().Cookies(u)
 	objs := make(map[string][]string, len(cookies))
 	for _, c := range cookies {
 		objs[c.Name] = append(objs[c.Name], c.Value)
@@ -63,8 +89,24 @@ func (j HTTPCookieJar) CookiesForURL(url string) map[string][]string {
 	return objs
 }
 
+// CookieListForURL returns the cookies that would be sent with a request to
+// the given url, in the order in which they would be sent.
+func (j *HTTPCookieJar) CookieListForURL(url string) []JarCookie {
+	u, err := neturl.Parse(url)
+	if err != nil {
+		panic(err)
+	}
+
+	cookies := j.getJar().Cookies(u)
+	objs := make([]JarCookie, 0, len(cookies))
+	for _, c := range cookies {
+		objs = append(objs, JarCookie{Name: c.Name, Value: c.Value})
+	}
+	return objs
+}
+
 // Set sets a cookie for a particular url with the given name value and additional opts
-func (j HTTPCookieJar) Set(url, name, value string, opts goja.Value) (bool, error) {
+func (j *HTTPCookieJar) Set(url, name, value string, opts goja.Value) (bool, error) {
 	rt := common.GetRuntime(*j.ctx)
 
 	u, err := neturl.Parse(url)
@@ -82,6 +124,11 @@ func (j HTTPCookieJar) Set(url, name, value string, opts goja.Value) (bool, erro
 				c.Path = params.Get(k).String()
 			case "domain":
 				c.Domain = params.Get(k).String()
+				if !cookieDomainMatches(c.Domain, u.Hostname()) {
+					return false, errors.Errorf(
+						"the cookie domain '%s' doesn't match the host '%s' of the url", c.Domain, u.Hostname(),
+					)
+				}
 			case "expires":
 				var t time.Time
 				expires := params.Get(k).String()
@@ -98,9 +145,106 @@ func (j HTTPCookieJar) Set(url, name, value string, opts goja.Value) (bool, erro
 				c.Secure = params.Get(k).ToBoolean()
 			case "http_only":
 				c.HttpOnly = params.Get(k).ToBoolean()
+			case "same_site":
+				if c.SameSite, err = httpext.ParseSameSite(params.Get(k).String()); err != nil {
+					return false, err
+				}
+			case "priority":
+				// net/http doesn't know about this non-standard attribute and
+				// the jar drops it, so it can't have any effect
+				return false, errors.New("the priority cookie attribute isn't supported by the cookie jar")
 			}
 		}
 	}
-	j.jar.SetCookies(u, []*http.Cookie{&c})
+	j.getJar().SetCookies(u, []*http.Cookie{&c})
 	return true, nil
 }
+
+// Clear removes all cookies that would be sent with a request to the given url
+func (j *HTTPCookieJar) Clear(url string) error {
+	u, err := neturl.Parse(url)
+	if err != nil {
+		return err
+	}
+
+	cookies := j.getJar().Cookies(u)
+	if len(cookies) == 0 {
+		return nil
+	}
+
+	// cookiejar.Jar doesn't allow us to list or remove its entries directly,
+	// so we expire the cookies with every domain and path that could have
+	// matched the url. The jar silently ignores the invalid combinations.
+	domains := cookieDomainCandidates(u.Hostname())
+	paths := cookiePathCandidates(u.Path)
+	expired := make([]*http.Cookie, 0, len(cookies)*len(domains)*len(paths))
+	for _, c := range cookies {
+		for _, domain := range domains {
+			for _, path := range paths {
+				expired = append(expired, &http.Cookie{Name: c.Name, Domain: domain, Path: path, MaxAge: -1})
+			}
+		}
+	}
+	j.getJar().SetCookies(u, expired)
+	return nil
+}
+
+// ClearAll removes all cookies from the jar. cookiejar.Jar can't be emptied,
+// so it's replaced with a new one. For the VU jar, that's done in the state,
+// so the requests and all of the http.cookieJar() objects use the new jar.
+func (j *HTTPCookieJar) ClearAll() error {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return err
+	}
+
+	if j.vuJar {
+		if state := lib.GetState(*j.ctx); state != nil {
+			state.CookieJar = jar
+			return nil
+		}
+	}
+	j.jar = jar
+	return nil
+}
+
+// cookieDomainMatches reports whether a cookie with the given domain attribute
+// can be set by the given host, i.e. if the domain is empty, the host itself or
+// one of its parent domains. IP hosts can only set cookies for themselves.
+func cookieDomainMatches(domain, host string) bool {
+	domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+	host = strings.ToLower(host)
+	if domain == "" || domain == host {
+		return true
+	}
+	if net.ParseIP(host) != nil {
+		return false
+	}
+	return strings.HasSuffix(host, "."+domain)
+}
+
+// cookieDomainCandidates returns the domain attributes with which a cookie
+// could have been set for the given host: an empty one for host-only cookies,
+// and the host itself and all of its parent domains for domain cookies.
+func cookieDomainCandidates(host string) []string {
+	domains := []string{""}
+	for domain := host; strings.Contains(domain, "."); domain = domain[strings.Index(domain, ".")+1:] {
+		domains = append(domains, domain)
+	}
+	return domains
+}
+
+// cookiePathCandidates returns all of the cookie paths that match the given
+// request path, according to RFC 6265 section 5.1.4.
+func cookiePathCandidates(path string) []string {
+	paths := []string{"/"}
+	for i := 1; i < len(path); i++ {
+		if path[i] == '/' {
+			paths = append(paths, path[:i], path[:i+1])
+		}
+	}
+	if len(path) > 1 && path[len(path)-1] != '/' {
+		paths = append(paths, path)
+	}
+	return paths
+}
@@ -0,0 +1,178 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/dop251/goja"
+
+	"github.com/loadimpact/k6/js/common"
+	"github.com/loadimpact/k6/js/modules/k6/html"
+)
+
+// defaultCSRFTokenHeader is the header the csrfToken request param sets when
+// it's given just the token value.
+const defaultCSRFTokenHeader = "X-CSRF-Token"
+
+// The response headers and HTML elements extractCSRFToken() checks, in order,
+// when it's called without a selector.
+var ( //nolint:gochecknoglobals
+	csrfTokenHeaders   = []string{"X-CSRF-Token", "X-XSRF-Token", "X-CSRFToken"}
+	csrfTokenSelectors = []string{
+		`meta[name="csrf-token"]`,
+		`meta[name="_csrf"]`,
+		`input[name="_token"]`,
+		`input[name="csrf_token"]`,
+		`input[name="_csrf"]`,
+		`input[name="csrfmiddlewaretoken"]`,
+		`input[name="authenticity_token"]`,
+		`input[name="__RequestVerificationToken"]`,
+	}
+)
+
+// ExtractCSRFToken looks for a CSRF token in the response and returns its
+// value, or undefined if there isn't one. If a selector is given, the token is
+// taken from the first element matching it, otherwise the common CSRF response
+// headers, <meta> tags and hidden form inputs are checked.
+func (res *Response) ExtractCSRFToken(selector ...string) goja.Value {
+	if len(selector) > 0 && selector[0] != "" {
+		if !res.hasHTMLBody() {
+			return goja.Undefined()
+		}
+		return csrfTokenValue(res.HTML(selector[0]))
+	}
+
+	for _, name := range csrfTokenHeaders {
+		if v, ok := res.Headers[http.CanonicalHeaderKey(name)]; ok && v != "" {
+			return common.GetRuntime(res.GetCtx()).ToValue(v)
+		}
+	}
+
+	if !res.hasHTMLBody() {
+		return goja.Undefined()
+	}
+	doc := res.HTML()
+	for _, s := range csrfTokenSelectors {
+		if v := csrfTokenValue(doc.Find(s)); !goja.IsUndefined(v) {
+			return v
+		}
+	}
+	return goja.Undefined()
+}
+
+// hasHTMLBody reports whether the response body is something HTML() can parse.
+func (res *Response) hasHTMLBody() bool {
+	switch res.Body.(type) {
+	case string, []byte, goja.ArrayBuffer:
+		return true
+	default:
+		return false
+	}
+}
+
+// csrfTokenValue returns the token held by the first element of sel, i.e. the
+// content of a <meta> tag or the value of an input, or undefined.
+func csrfTokenValue(sel html.Selection) goja.Value {
+	if sel.Size() == 0 {
+		return goja.Undefined()
+	}
+	sel = sel.First()
+	if v := sel.Attr("content"); !goja.IsUndefined(v) && v.String() != "" {
+		return v
+	}
+	if v := sel.Val(); !goja.IsUndefined(v) && v.String() != "" {
+		return v
+	}
+	return goja.Undefined()
+}
+
+// csrfTokenParam is the parsed csrfToken request param.
+type csrfTokenParam struct {
+	value  string
+	header string
+	field  string
+}
+
+// parseCSRFTokenParam parses the csrfToken request param, which is either the
+// token itself, sent in the X-CSRF-Token header, or an object like
+// {value: token, header: "X-XSRF-Token"} or {value: token, field: "_token"}.
+func parseCSRFTokenParam(rt *goja.Runtime, v goja.Value) (*csrfTokenParam, error) {
+	if goja.IsUndefined(v) || goja.IsNull(v) {
+		return nil, errors.New("the csrfToken param is empty, the token may not have been found in the response")
+	}
+
+	param := &csrfTokenParam{}
+	if _, ok := v.Export().(map[string]interface{}); ok {
+		obj := v.ToObject(rt)
+		for _, k := range obj.Keys() {
+			switch k {
+			case "value":
+				if tv := obj.Get(k); !goja.IsUndefined(tv) && !goja.IsNull(tv) {
+					param.value = tv.String()
+				}
+			case "header":
+				param.header = obj.Get(k).String()
+			case "field":
+				param.field = obj.Get(k).String()
+			default:
+				return nil, fmt.Errorf("unknown csrfToken option '%s'", k)
+			}
+		}
+	} else {
+		param.value = v.String()
+	}
+
+	if param.value == "" {
+		return nil, errors.New("the csrfToken param is empty, the token may not have been found in the response")
+	}
+	if param.header == "" && param.field == "" {
+		param.header = defaultCSRFTokenHeader
+	}
+	return param, nil
+}
+
+// addCSRFTokenField returns a copy of the object request body with the CSRF
+// token added as a form field.
+func addCSRFTokenField(rt *goja.Runtime, body interface{}, param *csrfTokenParam) (interface{}, error) {
+	switch data := body.(type) {
+	case nil:
+		return map[string]interface{}{param.field: param.value}, nil
+	case map[string]goja.Value:
+		newData := make(map[string]goja.Value, len(data)+1)
+		for k, v := range data {
+			newData[k] = v
+		}
+		newData[param.field] = rt.ToValue(param.value)
+		return newData, nil
+	case map[string]interface{}:
+		newData := make(map[string]interface{}, len(data)+1)
+		for k, v := range data {
+			newData[k] = v
+		}
+		newData[param.field] = param.value
+		return newData, nil
+	default:
+		return nil, fmt.Errorf("the csrfToken field can only be added to object request bodies, not %T", body)
+	}
+}
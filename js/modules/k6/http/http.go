@@ -22,10 +22,12 @@ package http
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/loadimpact/k6/js/common"
 	"github.com/loadimpact/k6/lib"
 	"github.com/loadimpact/k6/lib/netext"
+	"github.com/loadimpact/k6/lib/netext/httpext"
 )
 
 const (
@@ -36,11 +38,22 @@ const (
 	HTTP_METHOD_HEAD    = "HEAD"
 	HTTP_METHOD_PATCH   = "PATCH"
 	HTTP_METHOD_OPTIONS = "OPTIONS"
+	HTTP_METHOD_TRACE   = "TRACE"
 )
 
 // ErrJarForbiddenInInitContext is used when a cookie jar was made in the init context
 var ErrJarForbiddenInInitContext = common.NewInitContextError("Making cookie jars in the init context is not supported")
 
+// ErrCloseIdleConnectionsForbiddenInInitContext is used when idle connections were closed in the init context
+var ErrCloseIdleConnectionsForbiddenInInitContext = common.NewInitContextError(
+	"Closing the idle connections in the init context is not supported",
+)
+
+// ErrGlobalHeadersForbiddenInInitContext is used when global headers were modified in the init context
+var ErrGlobalHeadersForbiddenInInitContext = common.NewInitContextError(
+	"Modifying the global headers in the init context is not supported",
+)
+
 //nolint: golint
 type HTTP struct {
 	SSL_3_0                            string `js:"SSL_3_0"`
@@ -98,5 +111,60 @@ func (*HTTP) CookieJar(ctx context.Context) (*HTTPCookieJar, error) {
 	if state == nil {
 		return nil, ErrJarForbiddenInInitContext
 	}
-	return &HTTPCookieJar{state.CookieJar, &ctx}, nil
+	return &HTTPCookieJar{jar: state.CookieJar, ctx: &ctx, vuJar: true}, nil
+}
+
+// Balance returns a balancer that distributes the requests it's passed to with
+// the balancer param across the targets, with the given strategy: round-robin
+// (the default), random or least-connections.
+func (*HTTP) Balance(targets []string, strategy string) (*httpext.Balancer, error) {
+	return httpext.NewBalancer(targets, strategy)
+}
+
+// AbortController returns a controller whose signal can be passed to requests
+// with the signal param, so they can be cancelled with its abort() method.
+func (*HTTP) AbortController() *httpext.AbortController {
+	return httpext.NewAbortController()
+}
+
+// SetGlobalHeader sets a header that will be added to all subsequent requests
+// made by the current VU. Headers set in the request params take precedence.
+func (*HTTP) SetGlobalHeader(ctx context.Context, name, value string) {
+	state := lib.GetState(ctx)
+	if state == nil {
+		common.Throw(common.GetRuntime(ctx), ErrGlobalHeadersForbiddenInInitContext)
+	}
+	if state.GlobalHeaders == nil {
+		state.GlobalHeaders = make(http.Header)
+	}
+	state.GlobalHeaders.Set(name, value)
+}
+
+// ClearGlobalHeader removes a header that was set with SetGlobalHeader()
+func (*HTTP) ClearGlobalHeader(ctx context.Context, name string) {
+	state := lib.GetState(ctx)
+	if state == nil {
+		common.Throw(common.GetRuntime(ctx), ErrGlobalHeadersForbiddenInInitContext)
+	}
+	state.GlobalHeaders.Del(name)
+}
+
+// CloseIdleConnections closes the connections of the current VU that aren't
+// currently in use, so the next requests have to open new ones. This is what
+// the noVUConnectionReuse option does at the end of every iteration.
+func (*HTTP) CloseIdleConnections(ctx context.Context) {
+	state := lib.GetState(ctx)
+	if state == nil {
+		common.Throw(common.GetRuntime(ctx), ErrCloseIdleConnectionsForbiddenInInitContext)
+	}
+	state.CloseIdleConnections()
+}
+
+// ClearAllGlobalHeaders removes all headers that were set with SetGlobalHeader()
+func (*HTTP) ClearAllGlobalHeaders(ctx context.Context) {
+	state := lib.GetState(ctx)
+	if state == nil {
+		common.Throw(common.GetRuntime(ctx), ErrGlobalHeadersForbiddenInInitContext)
+	}
+	state.GlobalHeaders = nil
 }
@@ -45,7 +45,11 @@ func ToURL(u interface{}) (httpext.URL, error) {
 	}
 }
 
-// URL creates new URL from the provided parts
+// URL creates new URL from the provided parts. It's meant to be used as a JS
+// template literal tag, i.e. http.url`http://example.com/users/${id}`, and the
+// requests made with the returned URL will have their `name` metric tag set to
+// the template with its placeholders masked, i.e. http://example.com/users/${},
+// so that all of them are grouped together regardless of the actual values.
 func (http *HTTP) URL(parts []string, pieces ...string) (httpext.URL, error) {
 	var name, urlstr string
 	for i, part := range parts {
@@ -0,0 +1,100 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package http
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/dop251/goja"
+	"github.com/pkg/errors"
+
+	"github.com/loadimpact/k6/js/common"
+	"github.com/loadimpact/k6/lib"
+)
+
+// ErrMocksForbiddenInInitContext is used when HTTP mocks were modified in the init context
+var ErrMocksForbiddenInInitContext = common.NewInitContextError(
+	"Modifying the HTTP mocks in the init context is not supported",
+)
+
+// SetMock makes all subsequent requests of the current VU whose URLs match
+// the given pattern return the given response, without any network calls.
+// The pattern can be an exact URL, a glob pattern or a RegExp object, and
+// the response is an object with optional status, body and headers fields.
+func (*HTTP) SetMock(ctx context.Context, pattern goja.Value, response goja.Value) {
+	rt := common.GetRuntime(ctx)
+	state := lib.GetState(ctx)
+	if state == nil {
+		common.Throw(rt, ErrMocksForbiddenInInitContext)
+	}
+
+	if goja.IsUndefined(pattern) || goja.IsNull(pattern) {
+		common.Throw(rt, errors.New("a mock URL pattern is required"))
+	}
+	patternStr, isRegex := pattern.String(), false
+	if obj, ok := pattern.(*goja.Object); ok && obj.ClassName() == "RegExp" {
+		patternStr, isRegex = obj.Get("source").String(), true
+	}
+
+	var status int
+	var body []byte
+	headers := make(http.Header)
+	if !goja.IsUndefined(response) && !goja.IsNull(response) {
+		params := response.ToObject(rt)
+		for _, k := range params.Keys() {
+			v := params.Get(k)
+			if goja.IsUndefined(v) || goja.IsNull(v) {
+				continue
+			}
+			switch k {
+			case "status":
+				status = int(v.ToInteger())
+			case "body":
+				if b, ok := v.Export().([]byte); ok {
+					body = b
+				} else {
+					body = []byte(v.String())
+				}
+			case "headers":
+				hdrs := v.ToObject(rt)
+				for _, key := range hdrs.Keys() {
+					headers.Set(key, hdrs.Get(key).String())
+				}
+			}
+		}
+	}
+
+	mock, err := lib.NewHTTPMock(patternStr, isRegex, status, headers, body)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+	state.HTTPMocks = state.HTTPMocks.Set(mock)
+}
+
+// ClearMocks removes all mocks that were set with SetMock()
+func (*HTTP) ClearMocks(ctx context.Context) {
+	state := lib.GetState(ctx)
+	if state == nil {
+		common.Throw(common.GetRuntime(ctx), ErrMocksForbiddenInInitContext)
+	}
+	state.HTTPMocks = nil
+}
@@ -0,0 +1,99 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package http
+
+import (
+	"errors"
+
+	"github.com/dop251/goja"
+
+	"github.com/loadimpact/k6/js/common"
+	"github.com/loadimpact/k6/lib/netext/httpext"
+)
+
+// MultipartIterator iterates over the parts of a streaming multipart response.
+// It follows the JS iterator protocol, so next() returns {value, done} objects.
+type MultipartIterator struct {
+	stream *httpext.MultipartStream
+}
+
+// MultipartParts returns an iterator over the parts of a streaming multipart
+// (multipart/x-mixed-replace) response. The parts are read as they arrive, so
+// the response body doesn't have to end before the script can use them.
+func (res *Response) MultipartParts() (*MultipartIterator, error) {
+	stream := res.MultipartStream()
+	if stream == nil {
+		return nil, errors.New("the response isn't a streaming multipart response")
+	}
+	return &MultipartIterator{stream: stream}, nil
+}
+
+// Parts parses the body of a multipart/form-data, multipart/mixed or other
+// multipart response and returns its parts as {headers, body} objects. The
+// bodies of the parts are ArrayBuffers for responseType: "binary" responses
+// and strings otherwise.
+func (res *Response) Parts() ([]*httpext.MultipartPart, error) {
+	var body []byte
+	responseType := httpext.ResponseTypeText
+	switch b := res.Body.(type) {
+	case goja.ArrayBuffer:
+		body = b.Bytes()
+		responseType = httpext.ResponseTypeBinary
+	case []byte:
+		body = b
+		responseType = httpext.ResponseTypeBinary
+	case string:
+		body = []byte(b)
+	default:
+		return nil, errors.New("the response has no body to parse the multipart parts from")
+	}
+
+	parts, err := httpext.ParseMultipartBody(res.Headers["Content-Type"], body, responseType)
+	if err != nil {
+		return nil, err
+	}
+	if responseType == httpext.ResponseTypeBinary {
+		rt := common.GetRuntime(res.GetCtx())
+		for _, part := range parts {
+			part.Body = rt.NewArrayBuffer(part.Body.([]byte))
+		}
+	}
+	return parts, nil
+}
+
+// Next blocks until the next part of the response is received and returns it.
+// Once the response has ended or the iterator was closed, done is true.
+func (it *MultipartIterator) Next() (map[string]interface{}, error) {
+	part, err := it.stream.NextPart()
+	if err != nil {
+		return nil, err
+	}
+	if part == nil {
+		return map[string]interface{}{"value": nil, "done": true}, nil
+	}
+	return map[string]interface{}{"value": part, "done": false}, nil
+}
+
+// Close stops reading the response and closes its connection. It should be
+// called if the script isn't going to read the rest of the parts.
+func (it *MultipartIterator) Close() error {
+	return it.stream.Close()
+}
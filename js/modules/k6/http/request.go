@@ -80,14 +80,29 @@ func (h *HTTP) Del(ctx context.Context, url goja.Value, args ...goja.Value) (*Re
 	return h.Request(ctx, HTTP_METHOD_DELETE, url, args...)
 }
 
+// Delete is the same as Del, for the scripts that call http.delete()
+func (h *HTTP) Delete(ctx context.Context, url goja.Value, args ...goja.Value) (*Response, error) {
+	return h.Del(ctx, url, args...)
+}
+
 // Options makes an HTTP OPTIONS request and returns a corresponding response by taking goja.Values as arguments
 func (h *HTTP) Options(ctx context.Context, url goja.Value, args ...goja.Value) (*Response, error) {
 	return h.Request(ctx, HTTP_METHOD_OPTIONS, url, args...)
 }
 
+// Trace makes an HTTP TRACE request and returns a corresponding response by taking goja.Values as arguments
+func (h *HTTP) Trace(ctx context.Context, url goja.Value, args ...goja.Value) (*Response, error) {
+	// TRACE requests can't have a body, so it's always undefined, like for GETs and HEADs.
+	args = append([]goja.Value{goja.Undefined()}, args...)
+	return h.Request(ctx, HTTP_METHOD_TRACE, url, args...)
+}
+
 // Request makes an http request of the provided `method` and returns a corresponding response by
 // taking goja.Values as arguments
 func (h *HTTP) Request(ctx context.Context, method string, url goja.Value, args ...goja.Value) (*Response, error) {
+	dispatchConnEvents(ctx)
+	defer dispatchConnEvents(ctx)
+
 	u, err := ToURL(url)
 	if err != nil {
 		return nil, err
@@ -112,7 +127,9 @@ func (h *HTTP) Request(ctx context.Context, method string, url goja.Value, args
 	if err != nil {
 		return nil, err
 	}
-	return responseFromHttpext(resp), nil
+	res := responseFromHttpext(resp)
+	res.wrapForJS(common.GetRuntime(ctx))
+	return res, nil
 }
 
 //TODO break this function up
@@ -138,6 +155,13 @@ func (h *HTTP) parseRequest(
 		Redirects: state.Options.MaxRedirects,
 		Cookies:   make(map[string]*httpext.HTTPRequestCookie),
 		Tags:      make(map[string]string),
+		// The requests of async batches are made in other goroutines, so
+		// they get the mocks and the rate limit that were set when they
+		// were made
+		Mocks:      state.HTTPMocks,
+		VURPSLimit: state.VURPSLimit,
+
+		DisableCompression: state.Options.DisableCompression.Bool,
 	}
 	if state.Options.DiscardResponseBodies.Bool {
 		result.ResponseType = httpext.ResponseTypeNone
@@ -210,6 +234,21 @@ func (h *HTTP) parseRequest(
 		return nil
 	}
 
+	var csrfToken *csrfTokenParam
+	if params != nil && !goja.IsUndefined(params) && !goja.IsNull(params) {
+		if v := params.ToObject(rt).Get("csrfToken"); v != nil {
+			var err error
+			if csrfToken, err = parseCSRFTokenParam(rt, v); err != nil {
+				return nil, err
+			}
+			if csrfToken.field != "" {
+				if body, err = addCSRFTokenField(rt, body, csrfToken); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
 	if body != nil {
 		switch data := body.(type) {
 		case map[string]goja.Value:
@@ -229,6 +268,8 @@ func (h *HTTP) parseRequest(
 			result.Body = bytes.NewBufferString(data)
 		case []byte:
 			result.Body = bytes.NewBuffer(data)
+		case goja.ArrayBuffer:
+			result.Body = bytes.NewBuffer(data.Bytes())
 		default:
 			return nil, fmt.Errorf("unknown request body type %T", body)
 		}
@@ -236,12 +277,30 @@ func (h *HTTP) parseRequest(
 
 	if userAgent := state.Options.UserAgent; userAgent.String != "" {
 		result.Req.Header.Set("User-Agent", userAgent.String)
+	} else if userAgent.Valid {
+		// An explicitly empty user agent means no User-Agent header at all,
+		// instead of the default Go one.
+		result.Req.Header["User-Agent"] = []string{""}
+	}
+
+	for key, values := range state.GlobalHeaders {
+		switch strings.ToLower(key) {
+		case "host":
+			result.Req.Host = values[0]
+		default:
+			result.Req.Header[key] = append([]string(nil), values...)
+		}
 	}
 
 	if state.CookieJar != nil {
 		result.ActiveJar = state.CookieJar
 	}
 
+	// Tags from the matching tag rule can be overridden by the request params
+	for key, value := range state.Options.TagRules.TagsFor(reqURL.URL) {
+		result.Tags[key] = value
+	}
+
 	// TODO: ditch goja.Value, reflections and Object and use a simple go map and type assertions?
 	if params != nil && !goja.IsUndefined(params) && !goja.IsNull(params) {
 		params := params.ToObject(rt)
@@ -302,7 +361,7 @@ func (h *HTTP) parseRequest(
 				}
 				switch v := jarV.Export().(type) {
 				case *HTTPCookieJar:
-					result.ActiveJar = v.jar
+					result.ActiveJar = v.getJar()
 				}
 			case "compression":
 				var algosString = strings.TrimSpace(params.Get(k).ToString().String())
@@ -320,8 +379,47 @@ func (h *HTTP) parseRequest(
 							algo, httpext.CompressionTypeValues())
 					}
 				}
+			case "disableCompression":
+				result.DisableCompression = params.Get(k).ToBoolean()
 			case "redirects":
 				result.Redirects = null.IntFrom(params.Get(k).ToInteger())
+			case "balancer":
+				balancerV := params.Get(k)
+				if goja.IsUndefined(balancerV) || goja.IsNull(balancerV) {
+					continue
+				}
+				balancer, ok := balancerV.Export().(*httpext.Balancer)
+				if !ok {
+					return nil, fmt.Errorf("invalid balancer, it should be created with http.balance()")
+				}
+				result.Balancer = balancer
+				// The requests of batches are sent concurrently, so the number
+				// is drawn here, since the VU's generator isn't goroutine-safe
+				rnd := state.Rand
+				if rnd == nil {
+					rnd = common.NewRand()
+				}
+				result.BalancerRand = rnd.Float64()
+			case "responseCallback":
+				callbackV := params.Get(k)
+				if goja.IsUndefined(callbackV) || goja.IsNull(callbackV) {
+					continue
+				}
+				responseCallback, err := parseResponseCallback(callbackV)
+				if err != nil {
+					return nil, err
+				}
+				result.ResponseCallback = responseCallback
+			case "signal":
+				signalV := params.Get(k)
+				if goja.IsUndefined(signalV) || goja.IsNull(signalV) {
+					continue
+				}
+				signal, ok := signalV.Export().(*httpext.AbortSignal)
+				if !ok {
+					return nil, fmt.Errorf("invalid signal, it should be the signal of http.abortController()")
+				}
+				result.Signal = signal
 			case "tags":
 				tagsV := params.Get(k)
 				if goja.IsUndefined(tagsV) || goja.IsNull(tagsV) {
@@ -336,6 +434,12 @@ func (h *HTTP) parseRequest(
 				}
 			case "auth":
 				result.Auth = params.Get(k).String()
+			case "ipVersion":
+				ipVersion := params.Get(k).String()
+				if err := lib.ValidateIPVersion(ipVersion); err != nil {
+					return nil, err
+				}
+				result.IPVersion = ipVersion
 			case "timeout":
 				result.Timeout = time.Duration(params.Get(k).ToFloat() * float64(time.Millisecond))
 			case "throw":
@@ -350,6 +454,10 @@ func (h *HTTP) parseRequest(
 		}
 	}
 
+	if csrfToken != nil && csrfToken.header != "" && result.Req.Header.Get(csrfToken.header) == "" {
+		result.Req.Header.Set(csrfToken.header, csrfToken.value)
+	}
+
 	if result.ActiveJar != nil {
 		httpext.SetRequestCookies(result.Req, result.ActiveJar, result.Cookies)
 	}
@@ -374,22 +482,42 @@ func (h *HTTP) prepareBatchArray(
 			ParsedHTTPRequest: parsedReq,
 			Response:          response,
 		}
-		results[i] = &Response{response}
+		results[i] = &Response{Response: response}
 	}
 
 	return batchReqs, results, nil
 }
 
+// namedResponses are the responses of a batch of named requests, with the keys
+// in the order of the properties of the requests object.
+type namedResponses struct {
+	keys      []string
+	responses map[string]*Response
+}
+
+// batchResultsValue returns the results of a batch as a JS value, an array or
+// an object with the properties in the order of the requests object.
+func batchResultsValue(rt *goja.Runtime, results interface{}) goja.Value {
+	res, ok := results.(*namedResponses)
+	if !ok {
+		return rt.ToValue(results)
+	}
+	obj := rt.NewObject()
+	for _, key := range res.keys {
+		_ = obj.Set(key, res.responses[key]) // can't fail for a plain object
+	}
+	return obj
+}
+
 func (h *HTTP) prepareBatchObject(
-	ctx context.Context, requests map[string]interface{},
-) ([]httpext.BatchParsedHTTPRequest, map[string]*Response, error) {
-	reqCount := len(requests)
+	ctx context.Context, keys []string, requests map[string]interface{},
+) ([]httpext.BatchParsedHTTPRequest, *namedResponses, error) {
+	reqCount := len(keys)
 	batchReqs := make([]httpext.BatchParsedHTTPRequest, reqCount)
-	results := make(map[string]*Response, reqCount)
+	results := &namedResponses{keys: keys, responses: make(map[string]*Response, reqCount)}
 
-	i := 0
-	for key, req := range requests {
-		parsedReq, err := h.parseBatchRequest(ctx, key, req)
+	for i, key := range keys {
+		parsedReq, err := h.parseBatchRequest(ctx, key, requests[key])
 		if err != nil {
 			return nil, nil, err
 		}
@@ -398,36 +526,39 @@ func (h *HTTP) prepareBatchObject(
 			ParsedHTTPRequest: parsedReq,
 			Response:          response,
 		}
-		results[key] = &Response{response}
-		i++
+		results.responses[key] = &Response{Response: response}
 	}
 
 	return batchReqs, results, nil
 }
 
+func (h *HTTP) prepareBatch(
+	ctx context.Context, reqsV goja.Value,
+) ([]httpext.BatchParsedHTTPRequest, interface{}, error) {
+	switch v := reqsV.Export().(type) {
+	case []interface{}:
+		return h.prepareBatchArray(ctx, v)
+	case map[string]interface{}:
+		// The keys of the exported map aren't ordered, so they are taken from the JS object
+		keys := reqsV.ToObject(common.GetRuntime(ctx)).Keys()
+		return h.prepareBatchObject(ctx, keys, v)
+	default:
+		return nil, nil, fmt.Errorf("invalid http.batch() argument type %T", v)
+	}
+}
+
 // Batch makes multiple simultaneous HTTP requests. The provideds reqsV should be an array of request
-// objects. Batch returns an array of responses and/or error
+// objects, or an object with named requests. Batch returns an array of responses, or an object
+// with the responses under the same names and in the same order, and/or error
 func (h *HTTP) Batch(ctx context.Context, reqsV goja.Value) (goja.Value, error) {
 	state := lib.GetState(ctx)
 	if state == nil {
 		return nil, ErrBatchForbiddenInInitContext
 	}
+	dispatchConnEvents(ctx)
+	defer dispatchConnEvents(ctx)
 
-	var (
-		err       error
-		batchReqs []httpext.BatchParsedHTTPRequest
-		results   interface{} // either []*Response or map[string]*Response
-	)
-
-	switch v := reqsV.Export().(type) {
-	case []interface{}:
-		batchReqs, results, err = h.prepareBatchArray(ctx, v)
-	case map[string]interface{}:
-		batchReqs, results, err = h.prepareBatchObject(ctx, v)
-	default:
-		return nil, fmt.Errorf("invalid http.batch() argument type %T", v)
-	}
-
+	batchReqs, results, err := h.prepareBatch(ctx, reqsV) // results is either []*Response or *namedResponses
 	if err != nil {
 		return nil, err
 	}
@@ -443,7 +574,19 @@ func (h *HTTP) Batch(ctx context.Context, reqsV goja.Value) (goja.Value, error)
 			err = e
 		}
 	}
-	return common.GetRuntime(ctx).ToValue(results), err
+
+	rt := common.GetRuntime(ctx)
+	switch res := results.(type) {
+	case []*Response:
+		for _, r := range res {
+			r.wrapForJS(rt)
+		}
+	case *namedResponses:
+		for _, r := range res.responses {
+			r.wrapForJS(rt)
+		}
+	}
+	return batchResultsValue(rt, results), err
 }
 
 func (h *HTTP) parseBatchRequest(
@@ -25,15 +25,24 @@ import (
 	"compress/gzip"
 	"compress/zlib"
 	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math/rand"
 	"net/http"
 	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/http/httputil"
 	"net/url"
 	"runtime"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -50,6 +59,8 @@ import (
 
 	"github.com/loadimpact/k6/js/common"
 	"github.com/loadimpact/k6/js/compiler"
+	"github.com/loadimpact/k6/js/modules/k6/crypto"
+	"github.com/loadimpact/k6/js/modules/k6/encoding"
 	"github.com/loadimpact/k6/lib"
 	"github.com/loadimpact/k6/lib/metrics"
 	"github.com/loadimpact/k6/lib/testutils"
@@ -188,6 +199,26 @@ func TestRequestAndBatch(t *testing.T) {
 		time.Sleep(2 * time.Second)
 	}))
 
+	t.Run("FailedRequests", func(t *testing.T) {
+		stats.GetBufferedSamples(samples)
+		_, err := common.RunString(rt, sr(`
+		http.get("HTTPBIN_URL/status/200");
+		http.get("HTTPBIN_URL/status/404");
+		http.get("HTTPBIN_URL/status/503");
+		`))
+		assert.NoError(t, err)
+
+		failed := map[string]float64{}
+		for _, container := range stats.GetBufferedSamples(samples) {
+			for _, sample := range container.GetSamples() {
+				if sample.Metric == metrics.HTTPReqFailed {
+					failed[sample.Tags.CloneTags()["status"]] += sample.Value
+				}
+			}
+		}
+		assert.Equal(t, map[string]float64{"404": 1, "503": 1}, failed)
+	})
+
 	t.Run("Redirects", func(t *testing.T) {
 		t.Run("tracing", func(t *testing.T) {
 			_, err := common.RunString(rt, sr(`
@@ -256,6 +287,7 @@ func TestRequestAndBatch(t *testing.T) {
 			var res = http.get("HTTPBIN_URL/redirect/1", {redirects: 3});
 			if (res.status != 200) { throw new Error("wrong status: " + res.status) }
 			if (res.url != "HTTPBIN_URL/get") { throw new Error("incorrect URL: " + res.url) }
+			if (res.redirects_exceeded !== false) { throw new Error("the redirects weren't exceeded") }
 			`))
 			assert.NoError(t, err)
 		})
@@ -265,6 +297,7 @@ func TestRequestAndBatch(t *testing.T) {
 			if (res.status != 302) { throw new Error("wrong status: " + res.status) }
 			if (res.url != "HTTPBIN_URL/redirect/1") { throw new Error("incorrect URL: " + res.url) }
 			if (res.headers["Location"] != "/get") { throw new Error("incorrect Location header: " + res.headers["Location"]) }
+			if (res.redirects_exceeded !== true) { throw new Error("the redirects were exceeded") }
 			`))
 			assert.NoError(t, err)
 		})
@@ -333,6 +366,26 @@ func TestRequestAndBatch(t *testing.T) {
 			`))
 			assert.NoError(t, err)
 		})
+
+		t.Run("Empty", func(t *testing.T) {
+			oldUserAgent := state.Options.UserAgent
+			defer func() { state.Options.UserAgent = oldUserAgent }()
+			state.Options.UserAgent = null.StringFrom("")
+
+			_, err := common.RunString(rt, sr(`
+				var res = http.get("HTTPBIN_URL/headers");
+				if (res.json().headers["User-Agent"] !== undefined) {
+					throw new Error("unexpected user agent: " + res.json().headers["User-Agent"])
+				}
+				res = http.get("HTTPBIN_URL/user-agent", {
+					headers: { "User-Agent": "OtherUserAgent" },
+				});
+				if (res.json()['user-agent'] != "OtherUserAgent") {
+					throw new Error("incorrect user agent: " + res.json()['user-agent'])
+				}
+			`))
+			assert.NoError(t, err)
+		})
 	})
 	t.Run("Compression", func(t *testing.T) {
 		t.Run("gzip", func(t *testing.T) {
@@ -439,6 +492,7 @@ func TestRequestAndBatch(t *testing.T) {
 		var res = http.request("GET", "HTTP2BIN_URL/get");
 		if (res.status != 200) { throw new Error("wrong status: " + res.status) }
 		if (res.proto != "HTTP/2.0") { throw new Error("wrong proto: " + res.proto) }
+		if (res.tls_alpn != "h2") { throw new Error("wrong ALPN: " + res.tls_alpn) }
 		`))
 		assert.NoError(t, err)
 
@@ -452,6 +506,53 @@ func TestRequestAndBatch(t *testing.T) {
 			}
 		}
 	})
+	t.Run("TLS certificates", func(t *testing.T) {
+		cert := tb.ServerHTTPS.Certificate()
+		fingerprint := sha256.Sum256(cert.Raw)
+		rt.Set("expectedCert", map[string]interface{}{
+			"subject":       cert.Subject.String(),
+			"valid_to":      cert.NotAfter.Unix(),
+			"serial_number": cert.SerialNumber.Text(16),
+			"fingerprint":   hex.EncodeToString(fingerprint[:]),
+		})
+		_, err := common.RunString(rt, sr(`
+		var res = http.request("GET", "HTTPSBIN_URL/get");
+		if (res.tls_certificates.length != 1) { throw new Error("wrong chain: " + JSON.stringify(res.tls_certificates)) }
+		var cert = res.tls_certificates[0];
+		for (var k in expectedCert) {
+			if (cert[k] !== expectedCert[k]) { throw new Error("wrong " + k + ": " + cert[k]) }
+		}
+		if (cert.san.indexOf("example.com") < 0 || cert.san.indexOf("127.0.0.1") < 0) {
+			throw new Error("wrong SANs: " + cert.san)
+		}
+		if (!(cert.valid_from < cert.valid_to)) { throw new Error("wrong validity: " + JSON.stringify(cert)) }
+		if (cert.pem.indexOf("-----BEGIN CERTIFICATE-----") !== 0) { throw new Error("wrong PEM: " + cert.pem) }
+
+		res = http.request("GET", "HTTPBIN_URL/get");
+		if (res.tls_certificates && res.tls_certificates.length) { throw new Error("unexpected certificates: " + res.tls_certificates) }
+		`))
+		assert.NoError(t, err)
+	})
+	t.Run("HTTP/2 server push", func(t *testing.T) {
+		// k6 disables server push in its HTTP/2 settings, so servers can't push anything
+		tb.Mux.HandleFunc("/push", func(w http.ResponseWriter, r *http.Request) {
+			pusher, ok := w.(http.Pusher)
+			if !ok {
+				_, _ = fmt.Fprint(w, "not HTTP/2")
+				return
+			}
+			if err := pusher.Push("/get", nil); err != nil {
+				_, _ = fmt.Fprint(w, err)
+				return
+			}
+			_, _ = fmt.Fprint(w, "pushed")
+		})
+		_, err := common.RunString(rt, sr(`
+		var res = http.request("GET", "HTTP2BIN_URL/push");
+		if (res.body != "`+http.ErrNotSupported.Error()+`") { throw new Error("unexpected push result: " + res.body) }
+		`))
+		assert.NoError(t, err)
+	})
 	t.Run("TLS", func(t *testing.T) {
 		t.Run("cert_expired", func(t *testing.T) {
 			_, err := common.RunString(rt, `http.get("https://expired.badssl.com/");`)
@@ -550,6 +651,24 @@ func TestRequestAndBatch(t *testing.T) {
 			})
 		}
 
+		t.Run("ipVersion", func(t *testing.T) {
+			_, err := common.RunString(rt, sr(`
+			var res = http.get("HTTPBIN_URL/get", { ipVersion: "ipv4" });
+			if (res.status != 200) { throw new Error("wrong status: " + res.status); }
+			`))
+			assert.NoError(t, err)
+			assertRequestMetricsEmitted(t, stats.GetBufferedSamples(samples), "GET", sr("HTTPBIN_URL/get"), "", 200, "")
+
+			_, err = common.RunString(rt, sr(`http.get("HTTPBIN_URL/get", { ipVersion: "ipv6" });`))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "has no IPv6 addresses")
+
+			_, err = common.RunString(rt, sr(`http.get("HTTPBIN_URL/get", { ipVersion: "ipv5" });`))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "ipv5")
+			stats.GetBufferedSamples(samples)
+		})
+
 		t.Run("cookies", func(t *testing.T) {
 			t.Run("access", func(t *testing.T) {
 				cookieJar, err := cookiejar.New(nil)
@@ -558,7 +677,7 @@ func TestRequestAndBatch(t *testing.T) {
 				_, err = common.RunString(rt, sr(`
 				var res = http.request("GET", "HTTPBIN_URL/cookies/set?key=value", null, { redirects: 0 });
 				if (res.cookies.key[0].value != "value") { throw new Error("wrong cookie value: " + res.cookies.key[0].value); }
-				var props = ["name", "value", "domain", "path", "expires", "max_age", "secure", "http_only"];
+				var props = ["name", "value", "domain", "path", "expires", "max_age", "secure", "http_only", "same_site", "priority"];
 				var cookie = res.cookies.key[0];
 				for (var i = 0; i < props.length; i++) {
 					if (cookie[props[i]] === undefined) {
@@ -748,17 +867,28 @@ func TestRequestAndBatch(t *testing.T) {
 				if (res.json().key != "value") {
 					throw new Error("wrong cookie value 1: " + res.json().key);
 				}
-				jar.set("HTTPBIN_URL/cookies", "key2", "value2", { domain: "example.com" });
+				jar.set("HTTPBIN_URL/cookies", "key2", "value2", { domain: ".HTTPBIN_DOMAIN" });
 				res = http.request("GET", "HTTPBIN_URL/cookies");
-				if (res.json().key != "value") {
-					throw new Error("wrong cookie value 2: " + res.json().key);
-				}
-				if (res.json().key2 != undefined) {
-					throw new Error("cookie 'key2' unexpectedly found");
+				if (res.json().key2 != "value2") {
+					throw new Error("wrong cookie value 2: " + res.json().key2);
 				}
 				`))
 				assert.NoError(t, err)
 				assertRequestMetricsEmitted(t, stats.GetBufferedSamples(samples), "GET", sr("HTTPBIN_URL/cookies"), "", 200, "")
+
+				_, err = common.RunString(rt, sr(`
+				http.cookieJar().set("HTTPBIN_URL/cookies", "key3", "value3", { domain: "example.com" });
+				`))
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), sr("the cookie domain 'example.com' doesn't match the host 'HTTPBIN_DOMAIN' of the url"))
+
+				_, err = common.RunString(rt, sr(`
+				var res = http.request("GET", "HTTPBIN_URL/cookies");
+				if (res.json().key3 != undefined) {
+					throw new Error("cookie 'key3' unexpectedly found");
+				}
+				`))
+				assert.NoError(t, err)
 			})
 
 			t.Run("path", func(t *testing.T) {
@@ -839,6 +969,127 @@ func TestRequestAndBatch(t *testing.T) {
 				assert.NoError(t, err)
 				assertRequestMetricsEmitted(t, stats.GetBufferedSamples(samples), "GET", sr("HTTPBIN_URL/cookies"), "", 200, "")
 			})
+
+			t.Run("sameSiteAndPriority", func(t *testing.T) {
+				tb.Mux.HandleFunc("/same-site-cookie", func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Add("Set-Cookie", "key=value; SameSite=Strict; Priority=High")
+				})
+				cookieJar, err := cookiejar.New(nil)
+				assert.NoError(t, err)
+				state.CookieJar = cookieJar
+				_, err = common.RunString(rt, sr(`
+				var res = http.request("GET", "HTTPBIN_URL/same-site-cookie");
+				var cookie = res.cookies.key[0];
+				if (cookie.same_site != "strict") { throw new Error("wrong same_site: " + cookie.same_site); }
+				if (cookie.priority != "high") { throw new Error("wrong priority: " + cookie.priority); }
+
+				var jar = http.cookieJar();
+				jar.set("HTTPBIN_URL/cookies", "key2", "value2", { same_site: "Lax" });
+				res = http.request("GET", "HTTPBIN_URL/cookies");
+				if (res.json().key2 != "value2") { throw new Error("wrong cookie value: " + res.json().key2); }
+				`))
+				assert.NoError(t, err)
+
+				_, err = common.RunString(rt, sr(`
+				http.cookieJar().set("HTTPBIN_URL/cookies", "key", "value", { same_site: "sometimes" });
+				`))
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "invalid SameSite cookie attribute 'sometimes'")
+
+				_, err = common.RunString(rt, sr(`
+				http.cookieJar().set("HTTPBIN_URL/cookies", "key4", "value4", { same_site: "" });
+				`))
+				assert.NoError(t, err)
+
+				_, err = common.RunString(rt, sr(`
+				http.cookieJar().set("HTTPBIN_URL/cookies", "key", "value", { priority: "high" });
+				`))
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "the priority cookie attribute isn't supported by the cookie jar")
+			})
+
+			t.Run("cookieListForURL", func(t *testing.T) {
+				cookieJar, err := cookiejar.New(nil)
+				require.NoError(t, err)
+				state.CookieJar = cookieJar
+				_, err = common.RunString(rt, sr(`
+				var jar = http.cookieJar();
+				jar.set("HTTPBIN_URL/cookies/sub", "key", "value", { path: "/cookies" });
+				jar.set("HTTPBIN_URL/cookies/sub", "key", "sub", { path: "/cookies/sub" });
+				jar.set("HTTPBIN_URL/cookies", "key2", "value2", { domain: "HTTPBIN_DOMAIN" });
+				jar.set("HTTPSBIN_URL/cookies", "key3", "value3", { secure: true });
+				var jarCookies = jar.cookieListForURL("HTTPBIN_URL/cookies/sub");
+				// The cookies with the longer paths come first, like when they're sent
+				var expected = [["key", "sub"], ["key", "value"], ["key2", "value2"]];
+				if (JSON.stringify(jarCookies.map(function(c) { return [c.name, c.value]; })) != JSON.stringify(expected)) {
+					throw new Error("wrong cookies: " + JSON.stringify(jarCookies));
+				}
+				if (Object.keys(jarCookies[0]).sort().join(",") != "name,value") {
+					throw new Error("unexpected cookie properties: " + Object.keys(jarCookies[0]));
+				}
+				if (jar.cookiesForURL("HTTPBIN_URL/cookies/sub").key.join(",") != "sub,value") {
+					throw new Error("wrong cookie values: " + JSON.stringify(jar.cookiesForURL("HTTPBIN_URL/cookies/sub")));
+				}
+				if (jar.cookieListForURL("HTTPBIN_URL/cookies").some(function(c) { return c.name == "key3"; })) {
+					throw new Error("the secure cookie shouldn't be sent over HTTP");
+				}
+				`))
+				assert.NoError(t, err)
+			})
+
+			t.Run("clear", func(t *testing.T) {
+				cookieJar, err := cookiejar.New(nil)
+				assert.NoError(t, err)
+				state.CookieJar = cookieJar
+				_, err = common.RunString(rt, sr(`
+				var jar = http.cookieJar();
+				jar.set("HTTPBIN_URL/cookies", "key", "value");
+				jar.set("HTTPBIN_URL/cookies", "key2", "value2", { path: "/" });
+				jar.set("HTTPBIN_URL/cookies", "key3", "value3", { domain: "HTTPBIN_DOMAIN" });
+				jar.set("HTTPBIN_URL/other", "key4", "value4", { path: "/other" });
+				jar.clear("HTTPBIN_URL/cookies");
+				var jarCookies = jar.cookiesForURL("HTTPBIN_URL/cookies");
+				if (Object.keys(jarCookies).length != 0) { throw new Error("unexpected cookies in jar: " + JSON.stringify(jarCookies)); }
+				var res = http.request("GET", "HTTPBIN_URL/cookies");
+				if (Object.keys(res.json()).length != 0) { throw new Error("unexpected cookies sent: " + res.body); }
+				jarCookies = jar.cookiesForURL("HTTPBIN_URL/other");
+				if (jarCookies.key4[0] != "value4") { throw new Error("cookie for another path was removed"); }
+				`))
+				assert.NoError(t, err)
+				assertRequestMetricsEmitted(t, stats.GetBufferedSamples(samples), "GET", sr("HTTPBIN_URL/cookies"), "", 200, "")
+			})
+
+			t.Run("clearAll", func(t *testing.T) {
+				cookieJar, err := cookiejar.New(nil)
+				assert.NoError(t, err)
+				state.CookieJar = cookieJar
+				_, err = common.RunString(rt, sr(`
+				var jar = http.cookieJar();
+				var otherJar = http.cookieJar();
+				jar.set("HTTPBIN_URL/cookies", "key", "value");
+				jar.set("HTTPBIN_URL/other", "key2", "value2", { path: "/other" });
+				jar.clearAll();
+				var res = http.request("GET", "HTTPBIN_URL/cookies");
+				if (Object.keys(res.json()).length != 0) { throw new Error("unexpected cookies sent: " + res.body); }
+				if (Object.keys(jar.cookiesForURL("HTTPBIN_URL/other")).length != 0) { throw new Error("unexpected cookies in jar"); }
+
+				// The VU jar objects that were already obtained use the cleared jar
+				if (Object.keys(otherJar.cookiesForURL("HTTPBIN_URL/other")).length != 0) { throw new Error("unexpected cookies in the other jar"); }
+				otherJar.set("HTTPBIN_URL/cookies", "key3", "value3");
+				res = http.request("GET", "HTTPBIN_URL/cookies");
+				if (res.json().key3 != "value3") { throw new Error("the cookie set in the other jar wasn't sent: " + res.body); }
+				res = http.request("GET", "HTTPBIN_URL/cookies", null, { jar: otherJar });
+				if (res.json().key3 != "value3") { throw new Error("the other jar wasn't used: " + res.body); }
+
+				var localJar = new http.CookieJar();
+				localJar.set("HTTPBIN_URL/cookies", "key", "value");
+				localJar.clearAll();
+				res = http.request("GET", "HTTPBIN_URL/cookies", null, { jar: localJar });
+				if (Object.keys(res.json()).length != 0) { throw new Error("unexpected cookies sent: " + res.body); }
+				`))
+				assert.NoError(t, err)
+				assertRequestMetricsEmitted(t, stats.GetBufferedSamples(samples), "GET", sr("HTTPBIN_URL/cookies"), "", 200, "")
+			})
 		})
 
 		t.Run("auth", func(t *testing.T) {
@@ -917,6 +1168,165 @@ func TestRequestAndBatch(t *testing.T) {
 				assert.NoError(t, err)
 				assertRequestMetricsEmitted(t, stats.GetBufferedSamples(samples), "GET", sr("HTTPBIN_URL/headers"), "", 200, "")
 			})
+
+			t.Run("global", func(t *testing.T) {
+				defer func() { state.GlobalHeaders = nil }()
+				_, err := common.RunString(rt, sr(`
+				http.setGlobalHeader("X-Tenant-ID", "tenant");
+				http.setGlobalHeader("X-My-Header", "global");
+				var res = http.request("GET", "HTTPBIN_URL/headers", null, {
+					headers: { "X-My-Header": "value" },
+				});
+				if (res.json().headers["X-Tenant-Id"] != "tenant") { throw new Error("wrong X-Tenant-ID: " + res.json().headers["X-Tenant-Id"]); }
+				if (res.json().headers["X-My-Header"] != "value") { throw new Error("wrong X-My-Header: " + res.json().headers["X-My-Header"]); }
+
+				http.clearGlobalHeader("X-Tenant-ID");
+				res = http.get("HTTPBIN_URL/headers");
+				if (res.json().headers["X-Tenant-Id"] !== undefined) { throw new Error("unexpected X-Tenant-ID"); }
+				if (res.json().headers["X-My-Header"] != "global") { throw new Error("wrong X-My-Header: " + res.json().headers["X-My-Header"]); }
+
+				http.clearAllGlobalHeaders();
+				res = http.get("HTTPBIN_URL/headers");
+				if (res.json().headers["X-My-Header"] !== undefined) { throw new Error("unexpected X-My-Header"); }
+				`))
+				assert.NoError(t, err)
+				assertRequestMetricsEmitted(t, stats.GetBufferedSamples(samples), "GET", sr("HTTPBIN_URL/headers"), "", 200, "")
+			})
+		})
+
+		t.Run("mocks", func(t *testing.T) {
+			defer func() { state.HTTPMocks = nil }()
+			_, err := common.RunString(rt, sr(`
+			http.setMock("http://mock.k6.test/users/1", { status: 201, body: "exact", headers: { "X-Mock": "yes" } });
+			http.setMock("http://mock.k6.test/items/*", { body: "glob" });
+			http.setMock(/^http:\/\/mock\.k6\.test\/re\/\d+$/, { status: 404 });
+
+			var res = http.get("http://mock.k6.test/users/1");
+			if (res.status != 201) { throw new Error("wrong status: " + res.status); }
+			if (res.body != "exact") { throw new Error("wrong body: " + res.body); }
+			if (res.headers["X-Mock"] != "yes") { throw new Error("wrong X-Mock: " + res.headers["X-Mock"]); }
+
+			res = http.post("http://mock.k6.test/items/42/details");
+			if (res.status != 200 || res.body != "glob") { throw new Error("wrong glob response: " + res.status + " " + res.body); }
+
+			res = http.get("http://mock.k6.test/re/123");
+			if (res.status != 404 || res.body != "") { throw new Error("wrong regex response: " + res.status + " " + res.body); }
+
+			http.setMock("http://mock.k6.test/users/1", { body: "replaced" });
+			res = http.get("http://mock.k6.test/users/1");
+			if (res.status != 200 || res.body != "replaced") { throw new Error("wrong replaced response: " + res.status + " " + res.body); }
+
+			res = http.get("HTTPBIN_URL/get");
+			if (res.status != 200 || res.json().url === undefined) { throw new Error("unmatched request was mocked"); }
+
+			http.clearMocks();
+			res = http.get("http://mock.k6.test/re/123", { throw: false });
+			if (res.error_code == 0) { throw new Error("request was mocked after clearMocks()"); }
+			`))
+			assert.NoError(t, err)
+			assert.Nil(t, state.HTTPMocks)
+
+			_, err = common.RunString(rt, `http.setMock("http://mock.k6.test/[", {});`)
+			assert.NoError(t, err, "globs don't use regex syntax")
+			_, err = common.RunString(rt, `http.setMock(/^http:\/\/(?!mock)/, {});`)
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), "invalid mock URL pattern")
+			}
+
+			// The async batch requests use the mocks that were set when they
+			// were made, even if the mocks change while they are running
+			_, err = common.RunString(rt, sr(`
+			http.setMock("http://mock.k6.test/async/*", { body: "before" });
+			var reqs = [];
+			for (var i = 0; i < 20; i++) { reqs.push("http://mock.k6.test/async/" + i); }
+			var handle = http.asyncBatch(reqs);
+			for (var i = 0; i < 20; i++) { http.setMock("http://mock.k6.test/async/*", { body: "after" + i }); }
+			http.clearMocks();
+			handle.wait().forEach(function(res) {
+				if (res.body != "before") { throw new Error("wrong body: " + res.body); }
+			});
+			`))
+			assert.NoError(t, err)
+		})
+
+		t.Run("signal", func(t *testing.T) {
+			t.Run("aborted", func(t *testing.T) {
+				_, err := common.RunString(rt, sr(`
+				var ctrl = http.abortController();
+				if (ctrl.signal.aborted()) { throw new Error("the signal was already aborted"); }
+				ctrl.abort();
+				var res = http.get("HTTPBIN_URL/get", { signal: ctrl.signal, throw: false });
+				if (res.error_code != 1051) { throw new Error("wrong error code: " + res.error_code); }
+				if (res.status != 0) { throw new Error("wrong status: " + res.status); }`))
+				require.NoError(t, err)
+			})
+			t.Run("throw", func(t *testing.T) {
+				_, err := common.RunString(rt, sr(`
+				var ctrl = http.abortController();
+				ctrl.abort();
+				http.get("HTTPBIN_URL/get", { signal: ctrl.signal, throw: true });`))
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "request aborted")
+			})
+			t.Run("invalid", func(t *testing.T) {
+				_, err := common.RunString(rt, sr(`http.get("HTTPBIN_URL/get", { signal: {} });`))
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "invalid signal")
+			})
+		})
+
+		t.Run("csrfToken", func(t *testing.T) {
+			t.Run("header", func(t *testing.T) {
+				_, err := common.RunString(rt, sr(`
+				var res = http.request("GET", "HTTPBIN_URL/headers", null, { csrfToken: "abc" });
+				if (res.status != 200) { throw new Error("wrong status: " + res.status); }
+				if (res.json().headers["X-Csrf-Token"] != "abc") { throw new Error("wrong X-CSRF-Token: " + res.json().headers["X-Csrf-Token"]); }
+				`))
+				assert.NoError(t, err)
+			})
+
+			t.Run("custom header", func(t *testing.T) {
+				_, err := common.RunString(rt, sr(`
+				var res = http.request("GET", "HTTPBIN_URL/headers", null, {
+					csrfToken: { value: "abc", header: "X-XSRF-Token" },
+					headers: { "X-Xsrf-Token": "explicit" },
+				});
+				if (res.status != 200) { throw new Error("wrong status: " + res.status); }
+				if (res.json().headers["X-Xsrf-Token"] != "explicit") { throw new Error("wrong X-XSRF-Token: " + res.json().headers["X-Xsrf-Token"]); }
+				`))
+				assert.NoError(t, err)
+			})
+
+			t.Run("field", func(t *testing.T) {
+				_, err := common.RunString(rt, sr(`
+				var data = { user: "admin" };
+				var res = http.request("POST", "HTTPBIN_URL/post", data, { csrfToken: { value: "abc", field: "_token" } });
+				if (res.status != 200) { throw new Error("wrong status: " + res.status); }
+				var form = res.json().form;
+				if (form._token != "abc" || form.user != "admin") { throw new Error("wrong form: " + JSON.stringify(form)); }
+				if (res.json().headers["X-Csrf-Token"] !== undefined) { throw new Error("unexpected X-CSRF-Token header"); }
+				if (data._token !== undefined) { throw new Error("the body object was modified"); }
+				`))
+				assert.NoError(t, err)
+			})
+
+			t.Run("field with string body", func(t *testing.T) {
+				_, err := common.RunString(rt, sr(`
+				http.request("POST", "HTTPBIN_URL/post", "user=admin", { csrfToken: { value: "abc", field: "_token" } });
+				`))
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), "can only be added to object request bodies")
+				}
+			})
+
+			t.Run("undefined", func(t *testing.T) {
+				_, err := common.RunString(rt, sr(`
+				http.request("GET", "HTTPBIN_URL/headers", null, { csrfToken: undefined });
+				`))
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), "the token may not have been found")
+				}
+			})
 		})
 
 		t.Run("tags", func(t *testing.T) {
@@ -1010,6 +1420,38 @@ func TestRequestAndBatch(t *testing.T) {
 					}
 				}
 			})
+
+			t.Run("tagRules", func(t *testing.T) {
+				headersRule, err := lib.NewTagRule("*/headers", "", map[string]string{"service": "headers", "tag": "rule"})
+				require.NoError(t, err)
+				getRule, err := lib.NewTagRule("", "/get$", map[string]string{"service": "get"})
+				require.NoError(t, err)
+				state.Options.TagRules = lib.TagRules{headersRule, getRule}
+				defer func() { state.Options.TagRules = nil }()
+
+				_, err = common.RunString(rt, sr(`
+				http.get("HTTPBIN_URL/headers", { tags: { tag: "fromreq" } });
+				http.get("HTTPBIN_URL/get");
+				http.get("HTTPBIN_URL/get?a=1");
+				`))
+				assert.NoError(t, err)
+
+				expectedTags := map[string]map[string]string{
+					sr("HTTPBIN_URL/headers"): {"service": "headers", "tag": "fromreq"},
+					sr("HTTPBIN_URL/get"):     {"service": "get"},
+					sr("HTTPBIN_URL/get?a=1"): {},
+				}
+				for _, sampleC := range stats.GetBufferedSamples(samples) {
+					for _, sample := range sampleC.GetSamples() {
+						tags := sample.Tags.CloneTags()
+						expected, ok := expectedTags[tags["url"]]
+						require.True(t, ok, "unexpected url %s", tags["url"])
+						for _, key := range []string{"service", "tag"} {
+							assert.Equal(t, expected[key], tags[key])
+						}
+					}
+				}
+			})
 		})
 	})
 
@@ -1034,6 +1476,19 @@ func TestRequestAndBatch(t *testing.T) {
 			`)
 			assert.NoError(t, err)
 			assertRequestMetricsEmitted(t, stats.GetBufferedSamples(samples), "GET", sr("HTTPBIN_URL/get?a=1&b=2"), sr("HTTPBIN_URL/get?a=${}&b=${}"), 200, "")
+
+			t.Run("Grouped", func(t *testing.T) {
+				_, err := runES6String(t, rt, `
+				for (let id = 1; id <= 3; id++) {
+					http.get(http.url`+"`"+sr(`HTTPBIN_URL/get?id=${id}`)+"`"+`);
+				}
+				`)
+				assert.NoError(t, err)
+				bufSamples := stats.GetBufferedSamples(samples)
+				for _, id := range []string{"1", "2", "3"} {
+					assertRequestMetricsEmitted(t, bufSamples, "GET", sr("HTTPBIN_URL/get?id="+id), sr("HTTPBIN_URL/get?id=${}"), 200, "")
+				}
+			})
 		})
 	})
 	t.Run("HEAD", func(t *testing.T) {
@@ -1057,18 +1512,72 @@ func TestRequestAndBatch(t *testing.T) {
 		assertRequestMetricsEmitted(t, stats.GetBufferedSamples(samples), "OPTIONS", sr("HTTPBIN_URL/?a=1&b=2"), "", 200, "")
 	})
 
+	t.Run("TRACE", func(t *testing.T) {
+		tb.Mux.HandleFunc("/trace", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodTrace {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			dump, err := httputil.DumpRequest(r, false)
+			assert.NoError(t, err)
+			w.Header().Set("Content-Type", "message/http")
+			_, _ = w.Write(dump)
+		}))
+
+		_, err := common.RunString(rt, sr(`
+		var res = http.trace("HTTPBIN_URL/trace?a=1", { headers: { "X-Secret": "value" } });
+		if (res.status != 200) { throw new Error("wrong status: " + res.status); }
+		if (res.headers["Content-Type"] !== "message/http") { throw new Error("wrong content type"); }
+		if (res.body.indexOf("TRACE /trace?a=1 HTTP/1.1") !== 0) { throw new Error("wrong body: " + res.body); }
+		if (res.body.indexOf("X-Secret: value") < 0) { throw new Error("the headers weren't echoed: " + res.body); }
+		`))
+		assert.NoError(t, err)
+		assertRequestMetricsEmitted(t, stats.GetBufferedSamples(samples), "TRACE", sr("HTTPBIN_URL/trace?a=1"), "", 200, "")
+	})
+
+	t.Run("WebDAV", func(t *testing.T) {
+		tb.Mux.HandleFunc("/webdav", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := ioutil.ReadAll(r.Body)
+			assert.NoError(t, err)
+			w.Header().Set("X-Method", r.Method)
+			if r.Method == "PROPFIND" {
+				w.Header().Set("Content-Type", "application/xml")
+				w.WriteHeader(http.StatusMultiStatus)
+			}
+			_, _ = w.Write(body)
+		}))
+
+		_, err := common.RunString(rt, sr(`
+		var res = http.propfind("HTTPBIN_URL/webdav?depth=1", '<propfind xmlns="DAV:"><allprop/></propfind>', { headers: { Depth: "1" } });
+		if (res.status != 207) { throw new Error("wrong status: " + res.status); }
+		if (res.body !== '<propfind xmlns="DAV:"><allprop/></propfind>') { throw new Error("wrong body: " + res.body); }
+
+		var methods = ["proppatch", "mkcol", "copy", "move", "lock", "unlock", "report"];
+		for (var i = 0; i < methods.length; i++) {
+			res = http[methods[i]]("HTTPBIN_URL/webdav");
+			if (res.headers["X-Method"] !== methods[i].toUpperCase()) {
+				throw new Error("wrong method: " + res.headers["X-Method"]);
+			}
+		}
+		`))
+		assert.NoError(t, err)
+		assertRequestMetricsEmitted(t, stats.GetBufferedSamples(samples), "PROPFIND", sr("HTTPBIN_URL/webdav?depth=1"), "", 207, "")
+	})
+
 	// DELETE HTTP requests shouldn't usually send a request body, they should use url parameters instead; references:
 	// https://golang.org/pkg/net/http/#Request.ParseForm
 	// https://stackoverflow.com/questions/299628/is-an-entity-body-allowed-for-an-http-delete-request
 	// https://tools.ietf.org/html/rfc7231#section-4.3.5
 	t.Run("DELETE", func(t *testing.T) {
-		_, err := common.RunString(rt, sr(`
-		var res = http.del("HTTPBIN_URL/delete?test=mest");
-		if (res.status != 200) { throw new Error("wrong status: " + res.status); }
-		if (res.json().args.test != "mest") { throw new Error("wrong args: " + JSON.stringify(res.json().args)); }
-		`))
-		assert.NoError(t, err)
-		assertRequestMetricsEmitted(t, stats.GetBufferedSamples(samples), "DELETE", sr("HTTPBIN_URL/delete?test=mest"), "", 200, "")
+		for _, fn := range []string{"del", "delete"} {
+			_, err := common.RunString(rt, fmt.Sprintf(sr(`
+			var res = http.%s("HTTPBIN_URL/delete?test=mest");
+			if (res.status != 200) { throw new Error("wrong status: " + res.status); }
+			if (res.json().args.test != "mest") { throw new Error("wrong args: " + JSON.stringify(res.json().args)); }
+			`), fn))
+			assert.NoError(t, err, fn)
+			assertRequestMetricsEmitted(t, stats.GetBufferedSamples(samples), "DELETE", sr("HTTPBIN_URL/delete?test=mest"), "", 200, "")
+		}
 	})
 
 	postMethods := map[string]string{
@@ -1215,7 +1724,11 @@ func TestRequestAndBatch(t *testing.T) {
 				for (var key in res) {
 					if (res[key].status != 200) { throw new Error("wrong status: " + key + ": " + res[key].status); }
 					if (res[key].json().args.r != key) { throw new Error("wrong request id: " + key); }
-				}`))
+				}
+				if (Object.keys(res).join() !== "shorthand,arr,obj1,obj2") {
+					throw new Error("wrong keys: " + Object.keys(res).join());
+				}
+				if (!res.hasOwnProperty("obj1") || res.obj1.status !== 200) { throw new Error("no named access"); }`))
 				assert.NoError(t, err)
 				bufSamples := stats.GetBufferedSamples(samples)
 				assertRequestMetricsEmitted(t, bufSamples, "GET", sr("HTTPBIN_URL/get?r=shorthand"), "", 200, "")
@@ -1287,6 +1800,92 @@ func TestRequestAndBatch(t *testing.T) {
 			assert.NoError(t, err)
 			assertRequestMetricsEmitted(t, stats.GetBufferedSamples(samples), "PUT", sr("HTTPBIN_URL/put"), "", 200, "")
 		})
+		t.Run("Async", func(t *testing.T) {
+			t.Run("wait", func(t *testing.T) {
+				_, err := common.RunString(rt, sr(`
+				var reqs = [
+					["GET", "HTTPBIN_URL/"],
+					["GET", "HTTPBIN_IP_URL/"],
+				];
+				var handle = http.asyncBatch(reqs);
+				var res = handle.wait();
+				if (res.length != 2) { throw new Error("wrong number of responses: " + res.length); }
+				for (var key in res) {
+					if (res[key].status != 200) { throw new Error("wrong status: " + res[key].status); }
+					if (res[key].url != reqs[key][1]) { throw new Error("wrong url: " + res[key].url); }
+				}`))
+				require.NoError(t, err)
+				bufSamples := stats.GetBufferedSamples(samples)
+				assertRequestMetricsEmitted(t, bufSamples, "GET", sr("HTTPBIN_URL/"), "", 200, "")
+				assertRequestMetricsEmitted(t, bufSamples, "GET", sr("HTTPBIN_IP_URL/"), "", 200, "")
+			})
+			t.Run("waitFor", func(t *testing.T) {
+				_, err := common.RunString(rt, sr(`
+				var handle = http.asyncBatch({
+					slow: "HTTPBIN_URL/delay/1",
+					fast: "HTTPBIN_URL/get?r=fast",
+				});
+				var fast = handle.waitFor("fast");
+				if (fast.json().args.r != "fast") { throw new Error("wrong response: " + fast.body); }
+				var res = handle.wait();
+				if (res.slow.status != 200) { throw new Error("wrong status: " + res.slow.status); }
+				if (res.fast.status != 200) { throw new Error("wrong status: " + res.fast.status); }`))
+				require.NoError(t, err)
+			})
+			t.Run("waitFor/invalid", func(t *testing.T) {
+				_, err := common.RunString(rt, sr(`
+				var handle = http.asyncBatch(["HTTPBIN_URL/"]);
+				try {
+					handle.waitFor(5);
+				} finally {
+					handle.wait();
+				}`))
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "invalid batch request index 5")
+			})
+			t.Run("cancel", func(t *testing.T) {
+				_, err := common.RunString(rt, sr(`
+				var handle = http.asyncBatch([
+					["GET", "HTTPBIN_URL/delay/10", null, { throw: false }],
+				]);
+				handle.cancel();
+				var res = handle.wait();
+				if (res[0].error_code == 0) { throw new Error("expected an error: " + res[0].status); }`))
+				require.NoError(t, err)
+			})
+			t.Run("iteration end", func(t *testing.T) {
+				_, err := common.RunString(rt, sr(`
+				var unfinished = http.asyncBatch([
+					["GET", "HTTPBIN_URL/delay/10", null, { throw: false }],
+				]);`))
+				require.NoError(t, err)
+
+				// The iteration ends without the script waiting for the batch
+				start := time.Now()
+				state.CloseIterationResources()
+				_, err = common.RunString(rt, `
+				var res = unfinished.wait();
+				if (res[0].error_code == 0) { throw new Error("expected an error: " + res[0].status); }`)
+				require.NoError(t, err)
+				assert.True(t, time.Since(start) < 5*time.Second)
+			})
+			t.Run("signal", func(t *testing.T) {
+				_, err := common.RunString(rt, sr(`
+				var ctrl = http.abortController();
+				var handle = http.asyncBatch({
+					slow: ["GET", "HTTPBIN_URL/delay/10", null, { signal: ctrl.signal, throw: false }],
+					fast: "HTTPBIN_URL/get",
+				});
+				var fast = handle.waitFor("fast");
+				if (fast.status != 200) { throw new Error("wrong status: " + fast.status); }
+				ctrl.abort();
+				var res = handle.wait();
+				if (!ctrl.signal.aborted()) { throw new Error("the signal wasn't aborted"); }
+				if (res.slow.error_code != 1051) { throw new Error("wrong error code: " + res.slow.error_code); }
+				if (res.slow.error != "request aborted") { throw new Error("wrong error: " + res.slow.error); }`))
+				require.NoError(t, err)
+			})
+		})
 	})
 
 	t.Run("HTTPRequest", func(t *testing.T) {
@@ -1567,19 +2166,36 @@ func TestResponseTypes(t *testing.T) {
 		http.post("HTTPBIN_URL/compare-text", respTextImplicit);
 
 		// Check discarding of responses
-		var respNone = http.get("HTTPBIN_URL/get-text", { responseType: "none" }).body;
-		if (respNone != null) {
-			throw new Error("none response body should be null but was " + respNone);
+		var resNone = http.get("HTTPBIN_URL/get-text", { responseType: "none" });
+		if (resNone.body != null) {
+			throw new Error("none response body should be null but was " + resNone.body);
+		}
+		var expSize = http.get("HTTPBIN_URL/get-text").body_size;
+		if (expSize === 0 || resNone.body_size !== expSize) {
+			throw new Error("none response body_size should be " + expSize + " but was " + resNone.body_size);
 		}
 
 		// Check binary transmission of the text response as well
-		var respTextInBin = http.get("HTTPBIN_URL/get-text", { responseType: "binary" }).body;
+		var resTextInBin = http.get("HTTPBIN_URL/get-text", { responseType: "binary" });
+		var respTextInBin = resTextInBin.body;
+		if (!(respTextInBin instanceof ArrayBuffer)) {
+			throw new Error("binary response body should be an ArrayBuffer but was " + typeof respTextInBin);
+		}
+		try {
+			resTextInBin.json();
+			throw new Error("json() of a binary response should have thrown");
+		} catch (e) {
+			if (e.message.indexOf("ArrayBuffer") < 0) {
+				throw e;
+			}
+		}
 
 		// Hack to convert a utf-8 array to a JS string
 		var strConv = "";
+		var textBytes = new Uint8Array(respTextInBin);
 		function pad(n) { return n.length < 2 ? "0" + n : n; }
-		for( var i = 0; i < respTextInBin.length; i++ ) {
-			strConv += ( "%" + pad(respTextInBin[i].toString(16)));
+		for( var i = 0; i < textBytes.length; i++ ) {
+			strConv += ( "%" + pad(textBytes[i].toString(16)));
 		}
 		strConv = decodeURIComponent(strConv);
 		if (strConv !== expText) {
@@ -1589,12 +2205,13 @@ func TestResponseTypes(t *testing.T) {
 
 		// Check binary response
 		var respBin = http.get("HTTPBIN_URL/get-bin", { responseType: "binary" }).body;
-		if (respBin.length !== expBinLength) {
-			throw new Error("response body length should be '" + expBinLength + "' but was '" + respBin.length + "'");
+		if (respBin.byteLength !== expBinLength) {
+			throw new Error("response body length should be '" + expBinLength + "' but was '" + respBin.byteLength + "'");
 		}
-		for( var i = 0; i < respBin.length; i++ ) {
-			if ( respBin[i] !== i%256 ) {
-				throw new Error("expected value " + (i%256) + " to be at position " + i + " but it was " + respBin[i]);
+		var binBytes = new Uint8Array(respBin);
+		for( var i = 0; i < binBytes.length; i++ ) {
+			if ( binBytes[i] !== i%256 ) {
+				throw new Error("expected value " + (i%256) + " to be at position " + i + " but it was " + binBytes[i]);
 			}
 		}
 		http.post("HTTPBIN_URL/compare-bin", respBin);
@@ -1623,6 +2240,63 @@ func TestResponseTypes(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestBinaryResponseBodyAsBytes(t *testing.T) {
+	t.Parallel()
+	tb, state, _, rt, ctx := newRuntime(t)
+	defer tb.Cleanup()
+	state.Options.Throw = null.BoolFrom(true)
+	rt.Set("crypto", common.Bind(rt, crypto.New(), ctx))
+	rt.Set("encoding", common.Bind(rt, encoding.New(), ctx))
+
+	binary := make([]byte, 300)
+	for i := range binary {
+		binary[i] = byte(i)
+	}
+	tb.Mux.HandleFunc("/get-bin", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write(binary)
+		assert.NoError(t, err)
+	}))
+	tb.Mux.HandleFunc("/compare-file", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		file, _, err := r.FormFile("file")
+		require.NoError(t, err)
+		body, err := ioutil.ReadAll(file)
+		require.NoError(t, err)
+		assert.True(t, bytes.Equal(binary, body))
+	}))
+
+	md5Sum := md5.Sum(binary)
+	sha256Sum := sha256.Sum256(binary)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	_, err := mac.Write(binary)
+	require.NoError(t, err)
+
+	_, err = common.RunString(rt, strings.NewReplacer(
+		"EXP_MD5", hex.EncodeToString(md5Sum[:]),
+		"EXP_SHA256", hex.EncodeToString(sha256Sum[:]),
+		"EXP_HMAC", hex.EncodeToString(mac.Sum(nil)),
+		"EXP_B64", base64.StdEncoding.EncodeToString(binary),
+		"EXP_HEX", hex.EncodeToString(binary),
+	).Replace(tb.Replacer.Replace(`
+		function check(name, got, exp) {
+			if (got !== exp) {
+				throw new Error(name + " should be '" + exp + "' but was '" + got + "'");
+			}
+		}
+
+		var body = http.get("HTTPBIN_URL/get-bin", { responseType: "binary" }).body;
+		http.post("HTTPBIN_URL/compare-file", { file: http.file(body, "bin") });
+		check("md5", crypto.md5(body, "hex"), "EXP_MD5");
+		check("sha256", crypto.sha256(body, "hex"), "EXP_SHA256");
+		var hasher = crypto.createHash("sha256");
+		hasher.update(body);
+		check("createHash", hasher.digest("hex"), "EXP_SHA256");
+		check("hmac", crypto.hmac("sha256", "secret", body, "hex"), "EXP_HMAC");
+		check("b64encode", encoding.b64encode(body), "EXP_B64");
+		check("hexencode", encoding.hexencode(body), "EXP_HEX");
+	`)))
+	assert.NoError(t, err)
+}
+
 func checkErrorCode(t testing.TB, tags *stats.SampleTags, code int, msg string) {
 	errorMsg, ok := tags.Get("error")
 	if msg == "" {
@@ -1721,6 +2395,12 @@ func TestErrorCodes(t *testing.T) {
 			var res = http.get("HTTPBIN_URL/redirect-to?url=http%3A%2F%2F127.0.0.1%3A1%2Fpesho");
 			if (res.url != "http://127.0.0.1:1/pesho") { throw new Error("incorrect URL: " + res.url) }`,
 		},
+		{
+			name:              "Request timeout",
+			expectedErrorCode: 1050,
+			expectedErrorMsg:  "context deadline exceeded",
+			script:            `var res = http.get("HTTPBIN_URL/delay/10", {timeout: 100});`,
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -1791,6 +2471,13 @@ func TestResponseWaitingAndReceivingTimings(t *testing.T) {
 		if (resp.body !== "1st bytes!2nd bytes!") {
 			throw new Error("wrong response body: " + resp.body);
 		}
+
+		var phases = ["blocked", "dns_lookup", "connecting", "tls_handshaking", "sending", "waiting", "receiving", "duration"];
+		for (var i = 0; i < phases.length; i++) {
+			if (typeof resp.timings[phases[i]] !== "number") {
+				throw new Error("missing timing " + phases[i] + ": " + JSON.stringify(resp.timings));
+			}
+		}
 	`))
 	assert.NoError(t, err)
 }
@@ -1837,7 +2524,11 @@ func TestNoResponseBodyMangling(t *testing.T) {
 		var responses = http.batch(requests);
 
 		for (var i = 0; i < batchSize; i++) {
-			var reqNumber = parseInt(responses[i].json().args.req[0], 10);
+			var body = responses[i].body;
+			if (i % 2) {
+				body = String.fromCharCode.apply(null, new Uint8Array(body));
+			}
+			var reqNumber = parseInt(JSON.parse(body).args.req[0], 10);
 			if (i !== reqNumber) {
 				throw new Error("Response " + i + " has " + reqNumber + ", expected " + i)
 			}
@@ -1866,7 +2557,7 @@ func TestRedirectMetricTags(t *testing.T) {
 
 	checkTags := func(sc stats.SampleContainer, expTags map[string]string) {
 		allSamples := sc.GetSamples()
-		assert.Len(t, allSamples, 8)
+		assert.Len(t, allSamples, 10)
 		for _, s := range allSamples {
 			assert.Equal(t, expTags, s.Tags.CloneTags())
 		}
@@ -1986,6 +2677,103 @@ func TestErrorsWithDecompression(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestDefaultAcceptEncoding(t *testing.T) {
+	t.Parallel()
+	tb, state, samples, rt, _ := newRuntime(t)
+	defer tb.Cleanup()
+	// Like the VU transports, so Go doesn't request and decompress gzip by itself
+	transport := tb.HTTPTransport.Clone()
+	transport.DisableCompression = true
+	state.Transport = transport
+
+	body := strings.Repeat("compress me ", 100)
+	tb.Mux.HandleFunc("/accept-encoding", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Accept-Encoding", r.Header.Get("Accept-Encoding"))
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			_, _ = w.Write([]byte(body))
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		gw := gzip.NewWriter(w)
+		_, _ = gw.Write([]byte(body))
+		_ = gw.Close()
+	}))
+
+	t.Run("default", func(t *testing.T) {
+		_, err := common.RunString(rt, tb.Replacer.Replace(`
+			var res = http.get("HTTPBIN_URL/accept-encoding");
+			if (res.headers["X-Accept-Encoding"] !== "gzip, deflate, br, zstd") {
+				throw new Error("unexpected Accept-Encoding: " + res.headers["X-Accept-Encoding"]);
+			}
+			if (res.body !== "`+body+`") { throw new Error("unexpected body: " + res.body); }
+			if (res.compression_algorithm !== "gzip") { throw new Error("wrong algorithm: " + res.compression_algorithm); }
+			if (res.decompressed_size !== `+strconv.Itoa(len(body))+`) {
+				throw new Error("wrong decompressed size: " + res.decompressed_size);
+			}
+			if (!(res.compressed_size > 0 && res.compressed_size < res.decompressed_size)) {
+				throw new Error("wrong compressed size: " + res.compressed_size);
+			}
+		`))
+		require.NoError(t, err)
+
+		var compressed, uncompressed, ratio float64
+		var algorithm string
+		for _, sampleC := range stats.GetBufferedSamples(samples) {
+			for _, sample := range sampleC.GetSamples() {
+				switch sample.Metric {
+				case metrics.HTTPRespCompressedBytes:
+					compressed += sample.Value
+				case metrics.HTTPRespUncompressedBytes:
+					uncompressed += sample.Value
+				case metrics.HTTPRespCompressionRatio:
+					ratio = sample.Value
+				case metrics.HTTPRespCompressionAlgorithm:
+					algorithm, _ = sample.Tags.Get("compression_algorithm")
+				}
+			}
+		}
+		assert.Equal(t, float64(len(body)), uncompressed)
+		assert.True(t, compressed > 0 && compressed < uncompressed, compressed)
+		assert.InDelta(t, uncompressed/compressed, ratio, 0.0001)
+		assert.Equal(t, "gzip", algorithm)
+	})
+
+	t.Run("custom header", func(t *testing.T) {
+		_, err := common.RunString(rt, tb.Replacer.Replace(`
+			var res = http.get("HTTPBIN_URL/accept-encoding", { headers: { "Accept-Encoding": "identity" } });
+			if (res.headers["X-Accept-Encoding"] !== "identity") {
+				throw new Error("unexpected Accept-Encoding: " + res.headers["X-Accept-Encoding"]);
+			}
+			if (res.compression_algorithm !== "") { throw new Error("wrong algorithm: " + res.compression_algorithm); }
+			if (res.compressed_size !== res.decompressed_size || res.compressed_size !== res.body.length) {
+				throw new Error("wrong sizes: " + res.compressed_size + ", " + res.decompressed_size);
+			}
+		`))
+		require.NoError(t, err)
+	})
+
+	t.Run("disableCompression", func(t *testing.T) {
+		_, err := common.RunString(rt, tb.Replacer.Replace(`
+			var res = http.get("HTTPBIN_URL/accept-encoding", { disableCompression: true });
+			if (res.headers["X-Accept-Encoding"] !== "") {
+				throw new Error("unexpected Accept-Encoding: " + res.headers["X-Accept-Encoding"]);
+			}
+			if (res.body !== "`+body+`") { throw new Error("unexpected body: " + res.body); }
+		`))
+		require.NoError(t, err)
+
+		state.Options.DisableCompression = null.BoolFrom(true)
+		defer func() { state.Options.DisableCompression = null.Bool{} }()
+		_, err = common.RunString(rt, tb.Replacer.Replace(`
+			var res = http.get("HTTPBIN_URL/accept-encoding");
+			if (res.headers["X-Accept-Encoding"] !== "") {
+				throw new Error("unexpected Accept-Encoding: " + res.headers["X-Accept-Encoding"]);
+			}
+		`))
+		require.NoError(t, err)
+	})
+}
+
 func TestDigestAuthWithBody(t *testing.T) {
 	t.Parallel()
 	tb, state, samples, rt, _ := newRuntime(t)
@@ -2020,3 +2808,363 @@ func TestDigestAuthWithBody(t *testing.T) {
 	assertRequestMetricsEmitted(t, sampleContainers[0:1], "POST", urlRaw, urlRaw, 401, "")
 	assertRequestMetricsEmitted(t, sampleContainers[1:2], "POST", urlRaw, urlRaw, 200, "")
 }
+
+func TestConnHooks(t *testing.T) {
+	t.Parallel()
+	tb, state, _, rt, _ := newRuntime(t)
+	defer tb.Cleanup()
+
+	state.ConnHooks = &lib.ConnHooks{}
+	tb.Dialer.ConnHooks = state.ConnHooks
+
+	_, err := common.RunString(rt, tb.Replacer.Replace(`
+		var events = [];
+		http.onConnect(function(e) { events.push("connect " + e.protocol + " " + e.remoteAddr); });
+		http.onDisconnect(function(e) { events.push("disconnect " + e.remoteAddr); });
+
+		http.get("HTTPBIN_URL/get");
+		http.get("HTTPBIN_URL/get");
+		http.closeIdleConnections();
+		http.get("HTTPBIN_URL/get");
+
+		var expected = [
+			"connect tcp HTTPBIN_IP:HTTPBIN_PORT",
+			"disconnect HTTPBIN_IP:HTTPBIN_PORT",
+			"connect tcp HTTPBIN_IP:HTTPBIN_PORT",
+		];
+		if (JSON.stringify(events) !== JSON.stringify(expected)) {
+			throw new Error("unexpected events: " + JSON.stringify(events));
+		}
+
+		http.onConnect(null);
+		http.closeIdleConnections();
+		http.get("HTTPBIN_URL/get");
+		if (events.length !== 4 || events[3] !== "disconnect HTTPBIN_IP:HTTPBIN_PORT") {
+			throw new Error("unexpected events after removing the hook: " + JSON.stringify(events));
+		}
+	`))
+	assert.NoError(t, err)
+
+	_, err = common.RunString(rt, `http.onConnect("not a function")`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "the connection hook has to be a function")
+
+	_, err = common.RunString(rt, tb.Replacer.Replace(`
+		http.onConnect(function() { throw new Error("oops"); });
+		http.closeIdleConnections();
+		http.get("HTTPBIN_URL/get");
+	`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "oops")
+}
+
+func TestBalancer(t *testing.T) {
+	t.Parallel()
+	tb, state, samples, rt, _ := newRuntime(t)
+	defer tb.Cleanup()
+
+	targets := make([]string, 2)
+	for i := range targets {
+		name := fmt.Sprintf("backend%d", i)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = fmt.Fprintf(w, "%s %s %s", name, r.Host, r.URL.Path)
+		}))
+		defer srv.Close()
+		targets[i] = srv.Listener.Addr().String()
+	}
+	rt.Set("TARGETS", targets)
+
+	t.Run("round-robin", func(t *testing.T) {
+		_, err := common.RunString(rt, `
+			var backends = http.balance(TARGETS);
+			var bodies = [];
+			for (var i = 0; i < 4; i++) {
+				bodies.push(http.get("http://cluster.local/path", { balancer: backends }).body);
+			}
+			var expected = "backend0 cluster.local /path,backend1 cluster.local /path";
+			if (bodies.join(",") !== expected + "," + expected) {
+				throw new Error("unexpected bodies: " + bodies.join(","));
+			}
+		`)
+		require.NoError(t, err)
+
+		counts := map[string]int{}
+		for _, sampleC := range stats.GetBufferedSamples(samples) {
+			for _, sample := range sampleC.GetSamples() {
+				if sample.Metric != metrics.HTTPReqs {
+					continue
+				}
+				target, _ := sample.Tags.Get("target")
+				counts[target]++
+				url, _ := sample.Tags.Get("url")
+				assert.Equal(t, "http://cluster.local/path", url)
+			}
+		}
+		assert.Equal(t, map[string]int{targets[0]: 2, targets[1]: 2}, counts)
+	})
+
+	t.Run("random", func(t *testing.T) {
+		// The targets are picked with the VU's generator, so that the
+		// randomSeed option makes them reproducible
+		state.Rand = rand.New(rand.NewSource(42)) //nolint:gosec
+		expRand := rand.New(rand.NewSource(42))   //nolint:gosec
+		var expected []string
+		for i := 0; i < 10; i++ {
+			expected = append(expected, fmt.Sprintf("backend%d", int(expRand.Float64()*2)))
+		}
+
+		v, err := common.RunString(rt, `
+			var backends = http.balance(TARGETS, "random");
+			var names = [];
+			for (var i = 0; i < 10; i++) {
+				names.push(http.get("http://cluster.local/", { balancer: backends }).body.split(" ")[0]);
+			}
+			names.join(",");
+		`)
+		require.NoError(t, err)
+		assert.Equal(t, strings.Join(expected, ","), v.String())
+		stats.GetBufferedSamples(samples)
+	})
+
+	t.Run("TLS", func(t *testing.T) {
+		tb.Mux.HandleFunc("/server-name", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = fmt.Fprint(w, r.TLS.ServerName)
+		})
+		// Only the connection is made to the target, the TLS server name and
+		// the certificate verification are still based on the URL
+		_, err := common.RunString(rt, tb.Replacer.Replace(`
+			var backends = http.balance(["HTTPSBIN_IP:HTTPSBIN_PORT"]);
+			var res = http.get("HTTPSBIN_URL/server-name", { balancer: backends });
+			if (res.error || res.body !== "HTTPSBIN_DOMAIN") {
+				throw new Error("unexpected response: " + res.error + " " + res.body);
+			}
+		`))
+		require.NoError(t, err)
+		stats.GetBufferedSamples(samples)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := common.RunString(rt, `http.balance([])`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "at least one target has to be specified")
+
+		_, err = common.RunString(rt, `http.balance(TARGETS, "fastest")`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown balancing strategy 'fastest'")
+
+		_, err = common.RunString(rt, `http.get("http://cluster.local/", { balancer: TARGETS })`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid balancer")
+	})
+}
+
+func TestResponseCallback(t *testing.T) {
+	t.Parallel()
+	tb, state, samples, rt, ctx := newRuntime(t)
+	defer tb.Cleanup()
+	sr := tb.Replacer.Replace
+
+	counter := &lib.RequestCounter{}
+	*ctx = lib.WithRequestCounter(*ctx, counter)
+	assertCounted := func(t *testing.T, expTotal, expFailed uint64) {
+		total, failed := counter.Reset()
+		assert.Equal(t, expTotal, total)
+		assert.Equal(t, expFailed, failed)
+
+		// The http_req_failed samples agree with the counter
+		var failedSamples uint64
+		for _, container := range stats.GetBufferedSamples(samples) {
+			for _, sample := range container.GetSamples() {
+				if sample.Metric == metrics.HTTPReqFailed {
+					failedSamples += uint64(sample.Value)
+				}
+			}
+		}
+		assert.Equal(t, expFailed, failedSamples)
+	}
+
+	t.Run("default", func(t *testing.T) {
+		_, err := common.RunString(rt, sr(`
+			http.get("HTTPBIN_URL/status/200");
+			http.get("HTTPBIN_URL/status/404");
+		`))
+		require.NoError(t, err)
+		assertCounted(t, 2, 1)
+	})
+
+	t.Run("param", func(t *testing.T) {
+		_, err := common.RunString(rt, sr(`
+			var expected = http.expectedStatuses(404, { min: 200, max: 204 });
+			http.get("HTTPBIN_URL/status/200", { responseCallback: expected });
+			http.get("HTTPBIN_URL/status/404", { responseCallback: expected });
+			http.get("HTTPBIN_URL/status/206", { responseCallback: expected });
+			http.get("HTTPBIN_URL/status/304", { responseCallback: expected });
+		`))
+		require.NoError(t, err)
+		assertCounted(t, 4, 2)
+	})
+
+	t.Run("VU", func(t *testing.T) {
+		_, err := common.RunString(rt, sr(`
+			http.setResponseCallback(http.expectedStatuses(404));
+			http.get("HTTPBIN_URL/status/200");
+			http.get("HTTPBIN_URL/status/404");
+			http.get("HTTPBIN_URL/status/200", { responseCallback: http.expectedStatuses(200) });
+		`))
+		require.NoError(t, err)
+		assertCounted(t, 3, 1)
+
+		_, err = common.RunString(rt, sr(`
+			http.setResponseCallback(null);
+			http.get("HTTPBIN_URL/status/404");
+		`))
+		require.NoError(t, err)
+		assert.Nil(t, state.ResponseCallback)
+		assertCounted(t, 1, 1)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := common.RunString(rt, `http.expectedStatuses()`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "at least one expected status has to be specified")
+
+		_, err = common.RunString(rt, `http.expectedStatuses({ min: 300, max: 200 })`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid expected status range 300-200")
+
+		_, err = common.RunString(rt, `http.setResponseCallback(function() { return true; })`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid response callback")
+	})
+}
+
+func TestConditionalGet(t *testing.T) {
+	t.Parallel()
+	tb, _, _, rt, _ := newRuntime(t)
+	defer tb.Cleanup()
+	sr := tb.Replacer.Replace
+
+	const lastModified = "Wed, 21 Oct 2015 07:28:00 GMT"
+	tb.Mux.HandleFunc("/conditional", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Last-Modified", lastModified)
+		w.Header().Set("X-Custom-Header", r.Header.Get("X-Custom-Header"))
+		if r.Header.Get("If-None-Match") == `"v1"` || r.Header.Get("If-Modified-Since") == lastModified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = fmt.Fprint(w, "content")
+	})
+
+	t.Run("conditionalGet", func(t *testing.T) {
+		_, err := common.RunString(rt, sr(`
+			var res = http.conditionalGet("HTTPBIN_URL/conditional");
+			if (res.status != 200 || res.body != "content" || res.cached) {
+				throw new Error("unexpected response: " + res.status + " " + res.body + " " + res.cached);
+			}
+
+			var params = { headers: { "X-Custom-Header": "custom" } };
+			res = http.conditionalGet("HTTPBIN_URL/conditional", { etag: res.headers["Etag"], params: params });
+			if (res.status != 304 || res.body !== null || !res.cached) {
+				throw new Error("unexpected response: " + res.status + " " + res.body + " " + res.cached);
+			}
+			if (res.headers["X-Custom-Header"] != "custom") { throw new Error("the params weren't used"); }
+			if (params.headers["If-None-Match"] !== undefined) { throw new Error("the params were modified"); }
+
+			res = http.conditionalGet("HTTPBIN_URL/conditional", { lastModified: "`+lastModified+`" });
+			if (res.status != 304 || !res.cached) { throw new Error("unexpected status: " + res.status); }
+
+			res = http.conditionalGet("HTTPBIN_URL/conditional", { etag: '"v0"' });
+			if (res.status != 200 || res.cached) { throw new Error("unexpected status: " + res.status); }
+		`))
+		assert.NoError(t, err)
+	})
+
+	t.Run("cachedGet", func(t *testing.T) {
+		_, err := common.RunString(rt, sr(`
+			var cache = {};
+			var res = http.cachedGet("HTTPBIN_URL/conditional", cache);
+			if (res.status != 200 || res.cached) { throw new Error("unexpected status: " + res.status); }
+			var entry = cache["HTTPBIN_URL/conditional"];
+			if (entry.etag != '"v1"' || entry.lastModified != "`+lastModified+`") {
+				throw new Error("unexpected cache entry: " + JSON.stringify(entry));
+			}
+
+			res = http.cachedGet("HTTPBIN_URL/conditional", cache);
+			if (res.status != 304 || res.body !== null || !res.cached) {
+				throw new Error("unexpected response: " + res.status + " " + res.body + " " + res.cached);
+			}
+
+			res = http.cachedGet("HTTPBIN_URL/conditional", {});
+			if (res.status != 200 || res.cached) { throw new Error("unexpected status: " + res.status); }
+		`))
+		assert.NoError(t, err)
+	})
+}
+
+func TestRequestCoalescing(t *testing.T) {
+	t.Parallel()
+	tb, state, samples, rt, _ := newRuntime(t)
+	defer tb.Cleanup()
+	sr := tb.Replacer.Replace
+	state.Options.RequestCoalescing = null.BoolFrom(true)
+
+	var requests int64
+	tb.Mux.HandleFunc("/coalesced", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&requests, 1)
+		_, _ = fmt.Fprintf(w, "%s %s", r.URL.RawQuery, r.Header.Get("X-Test"))
+	})
+
+	_, err := common.RunString(rt, sr(`
+		var responses = http.batch([
+			"HTTPBIN_URL/coalesced?a",
+			["GET", "HTTPBIN_URL/coalesced?a", null, { tags: { tag: "second" } }],
+			"HTTPBIN_URL/coalesced?b",
+			["GET", "HTTPBIN_URL/coalesced?a", null, { headers: { "X-Test": "header" } }],
+			["POST", "HTTPBIN_URL/coalesced?a", "body"],
+			["POST", "HTTPBIN_URL/coalesced?a", "body"],
+		]);
+		var bodies = responses.map(function(r) { return r.status + " " + r.body; });
+		if (bodies.join(",") != "200 a ,200 a ,200 b ,200 a header,200 a ,200 a ") {
+			throw new Error("unexpected responses: " + bodies.join(","));
+		}
+	`))
+	require.NoError(t, err)
+	// The POST requests aren't coalesced, even though they're identical
+	assert.Equal(t, int64(5), atomic.LoadInt64(&requests))
+
+	var reqs, coalesced int
+	for _, sampleC := range stats.GetBufferedSamples(samples) {
+		for _, s := range sampleC.GetSamples() {
+			if s.Metric.Name != metrics.HTTPReqs.Name {
+				continue
+			}
+			reqs++
+			if v, ok := s.Tags.Get("coalesced"); ok {
+				coalesced++
+				assert.Equal(t, "true", v)
+				tag, _ := s.Tags.Get("tag")
+				assert.Equal(t, "second", tag)
+				url, _ := s.Tags.Get("url")
+				assert.Equal(t, sr("HTTPBIN_URL/coalesced?a"), url)
+			} else {
+				_, hasTag := s.Tags.Get("tag")
+				assert.False(t, hasTag)
+			}
+		}
+	}
+	assert.Equal(t, 6, reqs)
+	assert.Equal(t, 1, coalesced)
+
+	t.Run("disabled", func(t *testing.T) {
+		state.Options.RequestCoalescing = null.BoolFrom(false)
+		defer func() { state.Options.RequestCoalescing = null.BoolFrom(true) }()
+		atomic.StoreInt64(&requests, 0)
+
+		_, err := common.RunString(rt, sr(`
+			http.batch(["HTTPBIN_URL/coalesced?a", "HTTPBIN_URL/coalesced?a"]);
+		`))
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), atomic.LoadInt64(&requests))
+	})
+}
@@ -23,6 +23,7 @@ package http
 import (
 	"errors"
 	"fmt"
+	"net/textproto"
 	"net/url"
 	"strings"
 
@@ -36,15 +37,83 @@ import (
 // Response is a representation of an HTTP response to be returned to the goja VM
 type Response struct {
 	*httpext.Response `js:"-"`
+
+	// The headers as they are exposed to JS, with case-insensitive access,
+	// see wrapForJS()
+	JSHeaders goja.Value `js:"headers" json:"-"`
 }
 
 func responseFromHttpext(resp *httpext.Response) *Response {
-	res := Response{resp}
+	res := Response{Response: resp}
 	return &res
 }
 
+// wrapForJS makes the []byte bodies of responseType: "binary" responses
+// available as ArrayBuffers and the headers accessible regardless of the case
+// of their names. It has to be called from the VU goroutine, once the request
+// is done.
+func (res *Response) wrapForJS(rt *goja.Runtime) {
+	if b, ok := res.Body.([]byte); ok {
+		res.Body = rt.NewArrayBuffer(b)
+	}
+	res.JSHeaders = newHeadersObject(rt, res.Headers)
+}
+
+// newHeadersObject returns a JS object with the given headers, which can also
+// be accessed with names in a different case, like res.headers["content-type"],
+// or with its get(name) method, like in the Fetch API.
+func newHeadersObject(rt *goja.Runtime, headers map[string]string) goja.Value {
+	if headers == nil {
+		headers = map[string]string{}
+	}
+	lookup := func(name string) (string, bool) {
+		if v, ok := headers[name]; ok {
+			return v, true
+		}
+		if v, ok := headers[textproto.CanonicalMIMEHeaderKey(name)]; ok {
+			return v, true
+		}
+		for k, v := range headers {
+			if strings.EqualFold(k, name) {
+				return v, true
+			}
+		}
+		return "", false
+	}
+	get := rt.ToValue(func(name string) goja.Value {
+		if v, ok := lookup(name); ok {
+			return rt.ToValue(v)
+		}
+		return goja.Null()
+	})
+
+	return rt.ToValue(rt.NewProxy(rt.ToValue(headers).ToObject(rt), &goja.ProxyTrapConfig{
+		Get: func(target *goja.Object, property string, receiver *goja.Object) goja.Value {
+			if v := target.Get(property); v != nil {
+				return v
+			}
+			if property == "get" {
+				return get
+			}
+			if v, ok := lookup(property); ok {
+				return rt.ToValue(v)
+			}
+			return goja.Undefined()
+		},
+		Has: func(target *goja.Object, property string) bool {
+			_, ok := lookup(property)
+			return ok || property == "get"
+		},
+	}))
+}
+
 // JSON parses the body of a response as json and returns it to the goja VM
 func (res *Response) JSON(selector ...string) goja.Value {
+	if _, ok := res.Body.(goja.ArrayBuffer); ok {
+		common.Throw(common.GetRuntime(res.GetCtx()), errors.New(
+			"the body of responseType: \"binary\" responses is an ArrayBuffer and can't be parsed as JSON",
+		))
+	}
 	v, err := res.Response.JSON(selector...)
 	if err != nil {
 		common.Throw(common.GetRuntime(res.GetCtx()), err)
@@ -59,6 +128,8 @@ func (res *Response) JSON(selector ...string) goja.Value {
 func (res *Response) HTML(selector ...string) html.Selection {
 	var body string
 	switch b := res.Body.(type) {
+	case goja.ArrayBuffer:
+		body = string(b.Bytes())
 	case []byte:
 		body = string(b)
 	case string:
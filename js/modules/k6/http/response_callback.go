@@ -0,0 +1,89 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dop251/goja"
+
+	"github.com/loadimpact/k6/js/common"
+	"github.com/loadimpact/k6/lib"
+	"github.com/loadimpact/k6/lib/netext/httpext"
+)
+
+// ErrResponseCallbackForbiddenInInitContext is used when the response callback was set in the init context
+var ErrResponseCallbackForbiddenInInitContext = common.NewInitContextError(
+	"Setting the response callback in the init context is not supported")
+
+// ExpectedStatuses returns a response callback for http.setResponseCallback()
+// and the responseCallback param, which expects the given statuses and status
+// ranges, i.e. objects with min and max properties, and considers all other
+// responses as failed.
+func (*HTTP) ExpectedStatuses(args ...goja.Value) (*httpext.ExpectedStatuses, error) {
+	if len(args) == 0 {
+		return nil, errors.New("at least one expected status has to be specified")
+	}
+	expected := &httpext.ExpectedStatuses{}
+	for _, arg := range args {
+		min, max := arg, arg
+		if obj, ok := arg.(*goja.Object); ok {
+			min, max = obj.Get("min"), obj.Get("max")
+			if min == nil || max == nil {
+				return nil, fmt.Errorf("invalid expected status range %s, it should have min and max", arg)
+			}
+		}
+		if err := expected.AddRange(int(min.ToInteger()), int(max.ToInteger())); err != nil {
+			return nil, err
+		}
+	}
+	return expected, nil
+}
+
+// SetResponseCallback sets the response callback that decides which of the
+// responses to the requests of the current VU are expected, unless the
+// requests have their own. null restores the default, which considers the
+// 4xx and 5xx responses as failed.
+func (*HTTP) SetResponseCallback(ctx context.Context, callback goja.Value) {
+	rt := common.GetRuntime(ctx)
+	state := lib.GetState(ctx)
+	if state == nil {
+		common.Throw(rt, ErrResponseCallbackForbiddenInInitContext)
+	}
+	if callback == nil || goja.IsUndefined(callback) || goja.IsNull(callback) {
+		state.ResponseCallback = nil
+		return
+	}
+	expected, err := parseResponseCallback(callback)
+	if err != nil {
+		common.Throw(rt, err)
+	}
+	state.ResponseCallback = expected
+}
+
+func parseResponseCallback(callback goja.Value) (func(status int) bool, error) {
+	expected, ok := callback.Export().(*httpext.ExpectedStatuses)
+	if !ok {
+		return nil, errors.New("invalid response callback, it should be created with http.expectedStatuses()")
+	}
+	return expected.Expected, nil
+}
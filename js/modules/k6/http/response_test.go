@@ -23,11 +23,15 @@ package http
 import (
 	"encoding/json"
 	"fmt"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/loadimpact/k6/js/common"
 	"github.com/loadimpact/k6/lib/netext/httpext"
@@ -133,6 +137,17 @@ func TestResponse(t *testing.T) {
 	tb.Mux.HandleFunc("/myforms/get", myFormHandler)
 	tb.Mux.HandleFunc("/json", jsonHandler)
 	tb.Mux.HandleFunc("/invalidjson", invalidJSONHandler)
+	tb.Mux.HandleFunc("/csrf/meta", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `<html><head><meta name="csrf-token" content="meta-token"></head><body></body></html>`)
+	})
+	tb.Mux.HandleFunc("/csrf/input", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `<form><input type="hidden" name="_token" value="input-token">`+
+			`<input type="hidden" name="my_token" value="custom-token"></form>`)
+	})
+	tb.Mux.HandleFunc("/csrf/header", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-CSRF-Token", "header-token")
+		_, _ = fmt.Fprint(w, `<input type="hidden" name="_token" value="input-token">`)
+	})
 
 	t.Run("Html", func(t *testing.T) {
 		_, err := common.RunString(rt, sr(`
@@ -349,6 +364,62 @@ func TestResponse(t *testing.T) {
 		})
 	})
 
+	t.Run("Headers", func(t *testing.T) {
+		_, err := common.RunString(rt, sr(`
+			var res = http.request("GET", "HTTPBIN_URL/json");
+			var h = res.headers;
+			if (h["Content-Type"] != "application/json") { throw new Error("wrong Content-Type: " + h["Content-Type"]); }
+			if (h["content-type"] != "application/json") { throw new Error("wrong content-type: " + h["content-type"]); }
+			if (h.get("CONTENT-TYPE") != "application/json") { throw new Error("wrong get(): " + h.get("CONTENT-TYPE")); }
+			if (h.get("X-Missing") !== null) { throw new Error("unexpected header: " + h.get("X-Missing")); }
+			if (h["x-missing"] !== undefined) { throw new Error("unexpected header: " + h["x-missing"]); }
+			if (!("content-length" in h)) { throw new Error("missing content-length"); }
+			if (Object.keys(h).indexOf("Content-Type") < 0 || Object.keys(h).indexOf("get") >= 0) {
+				throw new Error("wrong keys: " + Object.keys(h));
+			}
+			if (JSON.parse(JSON.stringify(h))["Content-Type"] != "application/json") {
+				throw new Error("wrong JSON: " + JSON.stringify(h));
+			}
+
+			var batch = http.asyncBatch(["HTTPBIN_URL/json"]);
+			if (batch.waitFor(0).headers["content-type"] != "application/json") { throw new Error("wrong async batch headers"); }
+		`))
+		assert.NoError(t, err)
+	})
+
+	t.Run("ExtractCSRFToken", func(t *testing.T) {
+		testCases := []struct{ path, selector, expected string }{
+			{"/csrf/meta", "", "meta-token"},
+			{"/csrf/input", "", "input-token"},
+			{"/csrf/input", `input[name="my_token"]`, "custom-token"},
+			{"/csrf/header", "", "header-token"},
+			{"/html", "", "undefined"},
+			{"/csrf/meta", "input#doesNotExist", "undefined"},
+		}
+		for _, tc := range testCases {
+			tc := tc
+			t.Run(tc.path+tc.selector, func(t *testing.T) {
+				v, err := common.RunString(rt, sr(fmt.Sprintf(`
+					var res = http.request("GET", "HTTPBIN_URL%s");
+					String(res.extractCSRFToken(%q));
+				`, tc.path, tc.selector)))
+				if assert.NoError(t, err) {
+					assert.Equal(t, tc.expected, v.String())
+				}
+			})
+		}
+
+		t.Run("discarded body", func(t *testing.T) {
+			v, err := common.RunString(rt, sr(`
+				var res = http.request("GET", "HTTPBIN_URL/csrf/input", null, { responseType: "none" });
+				String(res.extractCSRFToken());
+			`))
+			if assert.NoError(t, err) {
+				assert.Equal(t, "undefined", v.String())
+			}
+		})
+	})
+
 	t.Run("ClickLink", func(t *testing.T) {
 		t.Run("withoutArgs", func(t *testing.T) {
 			_, err := common.RunString(rt, sr(`
@@ -427,3 +498,168 @@ func BenchmarkResponseJson(b *testing.B) {
 		}
 	})
 }
+
+func multipartStreamHandler(parts int, endless bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "multipart/x-mixed-replace; boundary=frame")
+		mw := multipart.NewWriter(w)
+		_ = mw.SetBoundary("frame")
+		for i := 0; i < parts; i++ {
+			pw, err := mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain"}})
+			if err != nil {
+				return
+			}
+			_, _ = fmt.Fprintf(pw, "part %d", i)
+			w.(http.Flusher).Flush()
+		}
+		if endless {
+			// start the next part, so the client knows the previous one ended
+			_, _ = mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"text/plain"}})
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+			return
+		}
+		_ = mw.Close()
+	}
+}
+
+func TestResponseMultipartStream(t *testing.T) {
+	tb, state, samples, rt, _ := newRuntime(t)
+	defer tb.Cleanup()
+	sr := tb.Replacer.Replace
+
+	tb.Mux.HandleFunc("/multipart-stream", multipartStreamHandler(3, false))
+	tb.Mux.HandleFunc("/multipart-stream-endless", multipartStreamHandler(2, true))
+
+	countParts := func() int {
+		count := 0
+		for _, sc := range stats.GetBufferedSamples(samples) {
+			for _, s := range sc.GetSamples() {
+				if s.Metric.Name == "multipart_parts_received" {
+					count++
+					url, _ := s.Tags.Get("url")
+					assert.Contains(t, url, "/multipart-stream")
+				}
+			}
+		}
+		return count
+	}
+
+	t.Run("full", func(t *testing.T) {
+		_, err := common.RunString(rt, sr(`
+			var res = http.get("HTTPBIN_URL/multipart-stream");
+			if (res.status != 200) { throw new Error("wrong status: " + res.status); }
+			var parts = res.multipartParts(), bodies = [];
+			for (var r = parts.next(); !r.done; r = parts.next()) {
+				if (r.value.headers["Content-Type"] != "text/plain") { throw new Error("wrong headers"); }
+				bodies.push(r.value.body);
+			}
+			if (bodies.join() != "part 0,part 1,part 2") { throw new Error("wrong parts: " + bodies.join()); }
+			if (!parts.next().done) { throw new Error("the stream should stay done"); }
+		`))
+		assert.NoError(t, err)
+		assert.Equal(t, 3, countParts())
+	})
+
+	t.Run("endless", func(t *testing.T) {
+		_, err := common.RunString(rt, sr(`
+			var res = http.get("HTTPBIN_URL/multipart-stream-endless");
+			var parts = res.multipartParts();
+			if (parts.next().value.body != "part 0") { throw new Error("wrong first part"); }
+			if (parts.next().value.body != "part 1") { throw new Error("wrong second part"); }
+			parts.close();
+			if (!parts.next().done) { throw new Error("the stream should be done after close()"); }
+		`))
+		assert.NoError(t, err)
+		assert.Equal(t, 2, countParts())
+	})
+
+	t.Run("unconsumed", func(t *testing.T) {
+		closed := make(chan struct{})
+		tb.Mux.HandleFunc("/multipart-stream-unconsumed", func(w http.ResponseWriter, r *http.Request) {
+			multipartStreamHandler(1, true)(w, r)
+			close(closed)
+		})
+
+		_, err := common.RunString(rt, sr(`http.get("HTTPBIN_URL/multipart-stream-unconsumed");`))
+		require.NoError(t, err)
+		stats.GetBufferedSamples(samples)
+
+		state.CloseIterationResources()
+		select {
+		case <-closed:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the response wasn't closed at the end of the iteration")
+		}
+	})
+
+	t.Run("not multipart", func(t *testing.T) {
+		_, err := common.RunString(rt, sr(`http.get("HTTPBIN_URL/get").multipartParts();`))
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "the response isn't a streaming multipart response")
+		}
+	})
+}
+
+func TestResponseParts(t *testing.T) {
+	tb, _, _, rt, _ := newRuntime(t)
+	defer tb.Cleanup()
+	sr := tb.Replacer.Replace
+
+	multipartHandler := func(mediaType string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			mw := multipart.NewWriter(w)
+			_ = mw.SetBoundary("b0undary")
+			w.Header().Set("Content-Type", mediaType+"; boundary=b0undary")
+			pw, _ := mw.CreateFormField("name")
+			_, _ = fmt.Fprint(pw, "k6")
+			pw, _ = mw.CreatePart(textproto.MIMEHeader{"Content-Type": {"application/json"}})
+			_, _ = fmt.Fprint(pw, `{"resourceType":"Patient"}`)
+			_ = mw.Close()
+		}
+	}
+	tb.Mux.HandleFunc("/multipart/form-data", multipartHandler("multipart/form-data"))
+	tb.Mux.HandleFunc("/multipart/mixed", multipartHandler("multipart/mixed"))
+
+	for _, mediaType := range []string{"form-data", "mixed"} {
+		mediaType := mediaType
+		t.Run(mediaType, func(t *testing.T) {
+			_, err := common.RunString(rt, sr(`
+				var parts = http.get("HTTPBIN_URL/multipart/`+mediaType+`").parts();
+				if (parts.length != 2) { throw new Error("wrong number of parts: " + parts.length); }
+				if (parts[0].headers["Content-Disposition"] != 'form-data; name="name"') {
+					throw new Error("wrong headers: " + JSON.stringify(parts[0].headers));
+				}
+				if (parts[0].body != "k6") { throw new Error("wrong body: " + parts[0].body); }
+				if (parts[1].headers["Content-Type"] != "application/json") {
+					throw new Error("wrong headers: " + JSON.stringify(parts[1].headers));
+				}
+				if (JSON.parse(parts[1].body).resourceType != "Patient") { throw new Error("wrong body: " + parts[1].body); }
+			`))
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("binary", func(t *testing.T) {
+		_, err := common.RunString(rt, sr(`
+			var parts = http.get("HTTPBIN_URL/multipart/mixed", { responseType: "binary" }).parts();
+			if (!(parts[0].body instanceof ArrayBuffer)) { throw new Error("the body isn't an ArrayBuffer"); }
+			if (parts[0].body.byteLength != 2) { throw new Error("wrong body length: " + parts[0].body.byteLength); }
+		`))
+		assert.NoError(t, err)
+	})
+
+	t.Run("not multipart", func(t *testing.T) {
+		_, err := common.RunString(rt, sr(`http.get("HTTPBIN_URL/get").parts();`))
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "isn't multipart with a boundary")
+		}
+	})
+
+	t.Run("no body", func(t *testing.T) {
+		_, err := common.RunString(rt, sr(`http.get("HTTPBIN_URL/multipart/mixed", { responseType: "none" }).parts();`))
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "the response has no body")
+		}
+	})
+}
@@ -28,10 +28,12 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gopkg.in/guregu/null.v3"
 
 	"github.com/loadimpact/k6/js/common"
 	"github.com/loadimpact/k6/lib"
+	"github.com/loadimpact/k6/stats"
 )
 
 func TestTLS13Support(t *testing.T) {
@@ -55,3 +57,54 @@ func TestTLS13Support(t *testing.T) {
 	`))
 	assert.NoError(t, err)
 }
+
+func TestTLSConnect(t *testing.T) {
+	tb, state, samples, rt, _ := newRuntime(t)
+	defer tb.Cleanup()
+	state.Dialer = tb.Dialer
+
+	t.Run("success", func(t *testing.T) {
+		_, err := common.RunString(rt, tb.Replacer.Replace(`
+			var res = http.tlsConnect("HTTPSBIN_DOMAIN", HTTPSBIN_PORT, { tags: { tag: "value" } });
+			if (res.error) { throw new Error("unexpected error: " + res.error); }
+			if (res.tls_version !== "tls1.3") { throw new Error("unexpected tls version: " + res.tls_version); }
+			if (!res.tls_cipher_suite) { throw new Error("missing cipher suite"); }
+			if (res.tls_alpn !== "http/1.1") { throw new Error("unexpected ALPN: " + res.tls_alpn); }
+			if (res.server_name !== "HTTPSBIN_DOMAIN") { throw new Error("unexpected server name: " + res.server_name); }
+			if (res.remote_ip !== "HTTPSBIN_IP") { throw new Error("unexpected remote ip: " + res.remote_ip); }
+			if (res.certificates.length < 1 || res.certificates[0].pem.indexOf("-----BEGIN CERTIFICATE-----") !== 0) {
+				throw new Error("unexpected certificates: " + res.certificates);
+			}
+			if (!(res.timings.tls_handshaking > 0)) { throw new Error("unexpected timings: " + JSON.stringify(res.timings)); }
+		`))
+		assert.NoError(t, err)
+
+		metrics := map[string]bool{}
+		for _, sampleC := range stats.GetBufferedSamples(samples) {
+			for _, sample := range sampleC.GetSamples() {
+				metrics[sample.Metric.Name] = true
+				tags := sample.Tags.CloneTags()
+				assert.Equal(t, "tls", tags["proto"])
+				assert.Equal(t, "value", tags["tag"])
+				assert.Equal(t, "tls1.3", tags["tls_version"])
+				assert.Equal(t, tb.Replacer.Replace("HTTPSBIN_DOMAIN:HTTPSBIN_PORT"), tags["name"])
+			}
+		}
+		assert.Equal(t, map[string]bool{"http_req_connecting": true, "http_req_tls_handshaking": true}, metrics)
+	})
+
+	t.Run("unknown server name", func(t *testing.T) {
+		_, err := common.RunString(rt, tb.Replacer.Replace(`
+			http.tlsConnect("HTTPSBIN_DOMAIN", HTTPSBIN_PORT, { serverName: "k6.io" });
+		`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "x509: certificate is valid for")
+
+		_, err = common.RunString(rt, tb.Replacer.Replace(`
+			var res = http.tlsConnect("HTTPSBIN_DOMAIN", HTTPSBIN_PORT, { serverName: "k6.io", throw: false });
+			if (!res.error || !res.error_code) { throw new Error("unexpected error code: " + res.error_code); }
+			if (res.tls_version !== "") { throw new Error("unexpected tls version: " + res.tls_version); }
+		`))
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,80 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package http
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/dop251/goja"
+
+	"github.com/loadimpact/k6/js/common"
+	"github.com/loadimpact/k6/lib"
+	"github.com/loadimpact/k6/lib/netext/httpext"
+)
+
+// TlsConnect connects to the given host and port, completes the TLS handshake
+// and closes the connection, without making any HTTP requests. It returns the
+// negotiated TLS version and cipher suite, the certificate chain of the server
+// and the connection timings. The SNI value can be changed with the
+// serverName param, which is useful for testing SNI-based routing.
+func (h *HTTP) TlsConnect( //nolint:golint,stylecheck
+	ctx context.Context, host string, port int64, params goja.Value,
+) (*httpext.TLSConnectResult, error) {
+	state := lib.GetState(ctx)
+	if state == nil {
+		return nil, ErrHTTPForbiddenInInitContext
+	}
+
+	tcParams := &httpext.TLSConnectParams{
+		Addr:    net.JoinHostPort(host, strconv.FormatInt(port, 10)),
+		Timeout: 60 * time.Second,
+		Tags:    map[string]string{},
+		Throw:   state.Options.Throw.Bool,
+	}
+	if params != nil && !goja.IsUndefined(params) && !goja.IsNull(params) {
+		rt := common.GetRuntime(ctx)
+		paramsObj := params.ToObject(rt)
+		for _, k := range paramsObj.Keys() {
+			switch k {
+			case "serverName":
+				tcParams.ServerName = paramsObj.Get(k).String()
+			case "timeout":
+				tcParams.Timeout = time.Duration(paramsObj.Get(k).ToFloat() * float64(time.Millisecond))
+			case "throw":
+				tcParams.Throw = paramsObj.Get(k).ToBoolean()
+			case "tags":
+				tagsV := paramsObj.Get(k)
+				if goja.IsUndefined(tagsV) || goja.IsNull(tagsV) {
+					continue
+				}
+				tagObj := tagsV.ToObject(rt)
+				for _, key := range tagObj.Keys() {
+					tcParams.Tags[key] = tagObj.Get(key).String()
+				}
+			}
+		}
+	}
+
+	return httpext.TLSConnect(ctx, tcParams)
+}
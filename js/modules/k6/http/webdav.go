@@ -0,0 +1,79 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package http
+
+import (
+	"context"
+
+	"github.com/dop251/goja"
+)
+
+// The WebDAV methods, from RFC 4918 and RFC 3253 (REPORT)
+const (
+	HTTP_METHOD_PROPFIND  = "PROPFIND"
+	HTTP_METHOD_PROPPATCH = "PROPPATCH"
+	HTTP_METHOD_MKCOL     = "MKCOL"
+	HTTP_METHOD_COPY      = "COPY"
+	HTTP_METHOD_MOVE      = "MOVE"
+	HTTP_METHOD_LOCK      = "LOCK"
+	HTTP_METHOD_UNLOCK    = "UNLOCK"
+	HTTP_METHOD_REPORT    = "REPORT"
+)
+
+// Propfind makes a WebDAV PROPFIND request and returns a corresponding response by taking goja.Values as arguments
+func (h *HTTP) Propfind(ctx context.Context, url goja.Value, args ...goja.Value) (*Response, error) {
+	return h.Request(ctx, HTTP_METHOD_PROPFIND, url, args...)
+}
+
+// Proppatch makes a WebDAV PROPPATCH request and returns a corresponding response by taking goja.Values as arguments
+func (h *HTTP) Proppatch(ctx context.Context, url goja.Value, args ...goja.Value) (*Response, error) {
+	return h.Request(ctx, HTTP_METHOD_PROPPATCH, url, args...)
+}
+
+// Mkcol makes a WebDAV MKCOL request and returns a corresponding response by taking goja.Values as arguments
+func (h *HTTP) Mkcol(ctx context.Context, url goja.Value, args ...goja.Value) (*Response, error) {
+	return h.Request(ctx, HTTP_METHOD_MKCOL, url, args...)
+}
+
+// Copy makes a WebDAV COPY request and returns a corresponding response by taking goja.Values as arguments
+func (h *HTTP) Copy(ctx context.Context, url goja.Value, args ...goja.Value) (*Response, error) {
+	return h.Request(ctx, HTTP_METHOD_COPY, url, args...)
+}
+
+// Move makes a WebDAV MOVE request and returns a corresponding response by taking goja.Values as arguments
+func (h *HTTP) Move(ctx context.Context, url goja.Value, args ...goja.Value) (*Response, error) {
+	return h.Request(ctx, HTTP_METHOD_MOVE, url, args...)
+}
+
+// Lock makes a WebDAV LOCK request and returns a corresponding response by taking goja.Values as arguments
+func (h *HTTP) Lock(ctx context.Context, url goja.Value, args ...goja.Value) (*Response, error) {
+	return h.Request(ctx, HTTP_METHOD_LOCK, url, args...)
+}
+
+// Unlock makes a WebDAV UNLOCK request and returns a corresponding response by taking goja.Values as arguments
+func (h *HTTP) Unlock(ctx context.Context, url goja.Value, args ...goja.Value) (*Response, error) {
+	return h.Request(ctx, HTTP_METHOD_UNLOCK, url, args...)
+}
+
+// Report makes a WebDAV REPORT request and returns a corresponding response by taking goja.Values as arguments
+func (h *HTTP) Report(ctx context.Context, url goja.Value, args ...goja.Value) (*Response, error) {
+	return h.Request(ctx, HTTP_METHOD_REPORT, url, args...)
+}
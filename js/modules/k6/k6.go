@@ -51,7 +51,34 @@ func (*K6) Fail(msg string) (goja.Value, error) {
 	return goja.Undefined(), errors.New(msg)
 }
 
-func (*K6) Sleep(ctx context.Context, secs float64) {
+// Sleep pauses the VU for the given number of seconds. An optional object with
+// `jitter` and `distribution` keys can be supplied to randomize the duration:
+// - "uniform" (the default) sleeps for secs ± secs*jitter
+// - "normal" uses secs as the mean and secs*jitter as the standard deviation
+// - "exponential" uses secs as the mean and ignores jitter
+func (*K6) Sleep(ctx context.Context, secs float64, opts ...goja.Value) {
+	if len(opts) > 0 && !goja.IsUndefined(opts[0]) && !goja.IsNull(opts[0]) {
+		var (
+			jitter       float64
+			distribution = "uniform"
+		)
+		rt := common.GetRuntime(ctx)
+		params := opts[0].ToObject(rt)
+		for _, k := range params.Keys() {
+			switch k {
+			case "jitter":
+				jitter = params.Get(k).ToFloat()
+			case "distribution":
+				distribution = params.Get(k).String()
+			}
+		}
+
+		var err error
+		if secs, err = randomizeSleep(getRand(ctx), secs, jitter, distribution); err != nil {
+			common.Throw(rt, err)
+		}
+	}
+
 	timer := time.NewTimer(time.Duration(secs * float64(time.Second)))
 	select {
 	case <-timer.C:
@@ -60,6 +87,40 @@ func (*K6) Sleep(ctx context.Context, secs float64) {
 	}
 }
 
+// getRand returns the random number generator of the VU, which the randomSeed
+// option makes reproducible, or a new randomly seeded one in the init context.
+func getRand(ctx context.Context) *rand.Rand {
+	if state := lib.GetState(ctx); state != nil && state.Rand != nil {
+		return state.Rand
+	}
+	return common.NewRand()
+}
+
+func randomizeSleep(r *rand.Rand, secs, jitter float64, distribution string) (float64, error) {
+	if jitter < 0 {
+		return 0, errors.Errorf("sleep() jitter should be a non-negative number, got %g", jitter)
+	}
+
+	switch distribution {
+	case "uniform":
+		secs += secs * jitter * (2*r.Float64() - 1)
+	case "normal":
+		secs += secs * jitter * r.NormFloat64()
+	case "exponential":
+		secs *= r.ExpFloat64()
+	default:
+		return 0, errors.Errorf(
+			"unknown sleep() distribution '%s', supported distributions are uniform, normal and exponential",
+			distribution,
+		)
+	}
+
+	if secs < 0 {
+		secs = 0
+	}
+	return secs, nil
+}
+
 func (*K6) RandomSeed(ctx context.Context, seed int64) {
 	randSource := rand.New(rand.NewSource(seed)).Float64
 
@@ -23,6 +23,7 @@ package k6
 import (
 	"context"
 	"fmt"
+	"math/rand"
 	"runtime"
 	"testing"
 	"time"
@@ -84,6 +85,55 @@ func TestSleep(t *testing.T) {
 	})
 }
 
+func TestSleepJitter(t *testing.T) {
+	rt := goja.New()
+	ctx := common.WithRuntime(context.Background(), rt)
+	rt.Set("k6", common.Bind(rt, New(), &ctx))
+
+	t.Run("uniform", func(t *testing.T) {
+		startTime := time.Now()
+		_, err := common.RunString(rt, `k6.sleep(0.2, { jitter: 0.5 })`)
+		endTime := time.Now()
+		assert.NoError(t, err)
+		assert.True(t, endTime.Sub(startTime) >= 100*time.Millisecond, "did not sleep long enough")
+		assert.True(t, endTime.Sub(startTime) < 1*time.Second, "slept for too long!!")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := common.RunString(rt, `k6.sleep(0.1, { jitter: 0.5, distribution: "pareto" })`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown sleep() distribution 'pareto'")
+
+		_, err = common.RunString(rt, `k6.sleep(0.1, { jitter: -1 })`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "jitter should be a non-negative number")
+	})
+
+	t.Run("distributions", func(t *testing.T) {
+		r := common.NewRand()
+		for i := 0; i < 1000; i++ {
+			secs, err := randomizeSleep(r, 2, 0.25, "uniform")
+			require.NoError(t, err)
+			assert.True(t, secs >= 1.5 && secs <= 2.5, "uniform sleep out of bounds: %g", secs)
+
+			for _, d := range []string{"normal", "exponential"} {
+				secs, err = randomizeSleep(r, 2, 0.25, d)
+				require.NoError(t, err)
+				assert.True(t, secs >= 0, "%s sleep is negative: %g", d, secs)
+			}
+		}
+	})
+
+	t.Run("seeded", func(t *testing.T) {
+		sample := func() float64 {
+			secs, err := randomizeSleep(rand.New(rand.NewSource(42)), 2, 0.25, "normal") //nolint:gosec
+			require.NoError(t, err)
+			return secs
+		}
+		assert.Equal(t, sample(), sample())
+	})
+}
+
 func TestRandSeed(t *testing.T) {
 	rt := goja.New()
 
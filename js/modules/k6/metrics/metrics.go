@@ -24,6 +24,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"regexp"
 	"time"
 
@@ -81,9 +82,9 @@ func (m Metric) Add(ctx context.Context, v goja.Value, addTags ...map[string]str
 		}
 	}
 
-	vfloat := v.ToFloat()
-	if vfloat == 0 && v.ToBoolean() {
-		vfloat = 1.0
+	vfloat, err := m.toFloat(v)
+	if err != nil {
+		return false, err
 	}
 
 	sample := stats.Sample{Time: time.Now(), Metric: m.metric, Value: vfloat, Tags: stats.IntoSampleTags(&tags)}
@@ -91,6 +92,34 @@ func (m Metric) Add(ctx context.Context, v goja.Value, addTags ...map[string]str
 	return true, nil
 }
 
+// toFloat converts the value that was added to the metric to a float, making
+// sure that it makes sense for the metric's type: Rate metrics accept any
+// value and only care whether it's truthy, while all other metrics accept
+// only numeric values.
+func (m Metric) toFloat(v goja.Value) (float64, error) {
+	_, isBool := v.Export().(bool)
+	if isBool && m.metric.Type != stats.Rate {
+		return 0, fmt.Errorf(
+			"type mismatch: can't add the boolean %s to the %s metric '%s', only Rate metrics accept booleans",
+			v, m.metric.Type, m.metric.Name,
+		)
+	}
+
+	vfloat := v.ToFloat()
+	if m.metric.Type == stats.Rate && (isBool || math.IsNaN(vfloat)) {
+		if v.ToBoolean() {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	if math.IsNaN(vfloat) {
+		return 0, fmt.Errorf(
+			"type mismatch: can't add the non-numeric value '%s' to the %s metric '%s'", v, m.metric.Type, m.metric.Name,
+		)
+	}
+	return vfloat, nil
+}
+
 type Metrics struct{}
 
 func New() *Metrics {
@@ -43,13 +43,16 @@ func TestMetrics(t *testing.T) {
 		"Rate":    stats.Rate,
 	}
 	values := map[string]struct {
-		JS    string
-		Float float64
+		JS       string
+		Float    float64
+		RateOnly bool
 	}{
-		"Float": {`2.5`, 2.5},
-		"Int":   {`5`, 5.0},
-		"True":  {`true`, 1.0},
-		"False": {`false`, 0.0},
+		"Float":  {`2.5`, 2.5, false},
+		"Int":    {`5`, 5.0, false},
+		"True":   {`true`, 1.0, true},
+		"False":  {`false`, 0.0, true},
+		"String": {`"error"`, 1.0, true},
+		"Empty":  {`""`, 0.0, false},
 	}
 	for fn, mtyp := range types {
 		fn, mtyp := fn, mtyp
@@ -104,6 +107,13 @@ func TestMetrics(t *testing.T) {
 							state.Tags["group"] = g.Path
 							for name, val := range values {
 								t.Run(name, func(t *testing.T) {
+									if val.RateOnly && mtyp != stats.Rate {
+										_, err := common.RunString(rt, fmt.Sprintf(`m.add(%v)`, val.JS))
+										require.Error(t, err)
+										assert.Contains(t, err.Error(), "type mismatch")
+										assert.Empty(t, stats.GetBufferedSamples(samples))
+										return
+									}
 									t.Run("Simple", func(t *testing.T) {
 										_, err := common.RunString(rt, fmt.Sprintf(`m.add(%v)`, val.JS))
 										assert.NoError(t, err)
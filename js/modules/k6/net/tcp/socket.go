@@ -0,0 +1,253 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package tcp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/dop251/goja"
+
+	"github.com/loadimpact/k6/js/common"
+	"github.com/loadimpact/k6/lib/metrics"
+	"github.com/loadimpact/k6/stats"
+)
+
+var (
+	errSocketClosed = errors.New("the socket is closed")
+	errEventMode    = errors.New("the socket can't be read from directly when it's used with event handlers")
+)
+
+// Socket is a TCP connection. Binary data is returned as ArrayBuffer objects.
+type Socket struct {
+	ctx           context.Context
+	conn          net.Conn
+	reader        *bufio.Reader
+	timeout       int64 // time.Duration, accessed atomically since the event loop reader uses it too
+	eventHandlers map[string][]goja.Callable
+	eventMode     bool
+	closed        bool
+	done          chan struct{}
+
+	sampleTags    *stats.SampleTags
+	samplesOutput chan<- stats.SampleContainer
+}
+
+// Write sends a string or an ArrayBuffer and returns the number of sent bytes.
+func (s *Socket) Write(data goja.Value) (int, error) {
+	if s.closed {
+		return 0, errSocketClosed
+	}
+	var buf []byte
+	switch v := data.Export().(type) {
+	case goja.ArrayBuffer:
+		buf = v.Bytes()
+	case []byte:
+		buf = v
+	case string:
+		buf = []byte(v)
+	default:
+		return 0, fmt.Errorf("unsupported data type %T, it should be a string or an ArrayBuffer", v)
+	}
+
+	s.setDeadline(s.conn.SetWriteDeadline)
+	n, err := s.conn.Write(buf)
+	s.pushBytes(metrics.TCPBytesSent, n)
+	return n, err
+}
+
+// Read reads exactly n bytes.
+func (s *Socket) Read(n int) (goja.ArrayBuffer, error) {
+	if n < 0 {
+		return goja.ArrayBuffer{}, fmt.Errorf("invalid number of bytes to read %d, it can't be negative", n)
+	}
+	if err := s.checkRead(); err != nil {
+		return goja.ArrayBuffer{}, err
+	}
+	buf := make([]byte, n)
+	s.setDeadline(s.conn.SetReadDeadline)
+	read, err := io.ReadFull(s.reader, buf)
+	s.pushBytes(metrics.TCPBytesReceived, read)
+	if err != nil {
+		return goja.ArrayBuffer{}, err
+	}
+	return common.GetRuntime(s.ctx).NewArrayBuffer(buf), nil
+}
+
+// ReadLine reads until the next newline and returns the line without the
+// trailing "\n" or "\r\n". The last line doesn't have to end with a newline.
+func (s *Socket) ReadLine() (string, error) {
+	if err := s.checkRead(); err != nil {
+		return "", err
+	}
+	s.setDeadline(s.conn.SetReadDeadline)
+	line, err := s.reader.ReadString('\n')
+	s.pushBytes(metrics.TCPBytesReceived, len(line))
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"), nil
+}
+
+// ReadAll reads until the connection is closed by the other side.
+func (s *Socket) ReadAll() (goja.ArrayBuffer, error) {
+	if err := s.checkRead(); err != nil {
+		return goja.ArrayBuffer{}, err
+	}
+	s.setDeadline(s.conn.SetReadDeadline)
+	buf, err := ioutil.ReadAll(s.reader)
+	s.pushBytes(metrics.TCPBytesReceived, len(buf))
+	if err != nil {
+		return goja.ArrayBuffer{}, err
+	}
+	return common.GetRuntime(s.ctx).NewArrayBuffer(buf), nil
+}
+
+// SetTimeout sets the timeout, in milliseconds, of every following read and
+// write. 0, which is the default, means that there's no timeout.
+func (s *Socket) SetTimeout(ms float64) {
+	atomic.StoreInt64(&s.timeout, int64(ms*float64(time.Millisecond)))
+}
+
+// On adds a handler for the "data", "error" or "close" events, which are
+// emitted when the socket is used with the event loop of tcp.connect().
+func (s *Socket) On(event string, handler goja.Value) {
+	if handler, ok := goja.AssertFunction(handler); ok {
+		s.eventHandlers[event] = append(s.eventHandlers[event], handler)
+	}
+}
+
+// Close closes the connection. It's safe to call it multiple times.
+func (s *Socket) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	close(s.done)
+	return s.conn.Close()
+}
+
+func (s *Socket) checkRead() error {
+	if s.closed {
+		return errSocketClosed
+	}
+	if s.eventMode {
+		return errEventMode
+	}
+	return nil
+}
+
+func (s *Socket) setDeadline(setter func(time.Time) error) {
+	var deadline time.Time
+	if timeout := time.Duration(atomic.LoadInt64(&s.timeout)); timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	_ = setter(deadline)
+}
+
+func (s *Socket) pushBytes(metric *stats.Metric, n int) {
+	if n == 0 {
+		return
+	}
+	stats.PushIfNotDone(s.ctx, s.samplesOutput, stats.Sample{
+		Metric: metric,
+		Time:   time.Now(),
+		Tags:   s.sampleTags,
+		Value:  float64(n),
+	})
+}
+
+func (s *Socket) handleEvent(event string, args ...goja.Value) {
+	for _, handler := range s.eventHandlers[event] {
+		if _, err := handler(goja.Undefined(), args...); err != nil {
+			common.Throw(common.GetRuntime(s.ctx), err)
+		}
+	}
+}
+
+// runEventLoop calls the setup function with the socket and then calls the
+// event handlers with the data that is received, until the socket is closed.
+// All of the JS code is executed by this goroutine.
+func (s *Socket) runEventLoop(setupFn goja.Callable) error {
+	rt := common.GetRuntime(s.ctx)
+	s.eventMode = true
+	defer func() { _ = s.Close() }()
+
+	if _, err := setupFn(goja.Undefined(), rt.ToValue(s)); err != nil {
+		return err
+	}
+
+	dataChan := make(chan []byte)
+	errChan := make(chan error)
+	go s.readPump(dataChan, errChan)
+
+	for !s.closed {
+		select {
+		case data := <-dataChan:
+			s.pushBytes(metrics.TCPBytesReceived, len(data))
+			s.handleEvent("data", rt.ToValue(rt.NewArrayBuffer(data)))
+		case err := <-errChan:
+			if err != io.EOF {
+				s.handleEvent("error", rt.ToValue(err))
+			}
+			_ = s.Close()
+		case <-s.ctx.Done():
+			// The VU is shutting down, so the events are not forwarded anymore
+			return s.Close()
+		}
+	}
+	s.handleEvent("close")
+	return nil
+}
+
+// readPump reads from the connection until an error occurs or the socket is
+// closed, and sends what it has read to the event loop.
+func (s *Socket) readPump(dataChan chan<- []byte, errChan chan<- error) {
+	buf := make([]byte, 32*1024)
+	for {
+		s.setDeadline(s.conn.SetReadDeadline)
+		n, err := s.reader.Read(buf)
+		if n > 0 {
+			data := make([]byte, n)
+			copy(data, buf[:n])
+			select {
+			case dataChan <- data:
+			case <-s.done:
+				return
+			}
+		}
+		if err != nil {
+			select {
+			case errChan <- err:
+			case <-s.done:
+			}
+			return
+		}
+	}
+}
@@ -0,0 +1,169 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package tcp
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/dop251/goja"
+
+	"github.com/loadimpact/k6/js/common"
+	"github.com/loadimpact/k6/lib"
+	"github.com/loadimpact/k6/lib/metrics"
+	"github.com/loadimpact/k6/stats"
+)
+
+// ErrTCPInInitContext is returned when TCP connections are made in the init context
+var ErrTCPInInitContext = common.NewInitContextError("using TCP connections in the init context is not supported")
+
+// TCP is the k6/net/tcp module, for testing services that speak custom
+// protocols over raw TCP connections.
+type TCP struct{}
+
+// New returns a new k6/net/tcp module instance.
+func New() *TCP {
+	return &TCP{}
+}
+
+// Connect opens a TCP connection to addr, optionally wrapped in TLS with the
+// same TLS config as the HTTP requests. When a function is passed as the last
+// argument, it's called with the socket and then an event loop is run, which
+// calls the "data" handlers with the received data until the socket is closed.
+// Otherwise the socket is returned and it's used synchronously.
+func (*TCP) Connect(ctx context.Context, addr string, args ...goja.Value) (*Socket, error) {
+	rt := common.GetRuntime(ctx)
+	state := lib.GetState(ctx)
+	if state == nil {
+		return nil, ErrTCPInInitContext
+	}
+
+	var paramsV, callableV goja.Value
+	switch len(args) {
+	case 0:
+	case 1:
+		if _, isFunc := goja.AssertFunction(args[0]); isFunc {
+			callableV = args[0]
+		} else {
+			paramsV = args[0]
+		}
+	case 2:
+		paramsV, callableV = args[0], args[1]
+	default:
+		return nil, errors.New("invalid number of arguments to tcp.connect")
+	}
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	timeout := 60 * time.Second
+	useTLS := false
+	serverName := host
+	tags := state.CloneTags()
+	if paramsV != nil && !goja.IsUndefined(paramsV) && !goja.IsNull(paramsV) {
+		params := paramsV.ToObject(rt)
+		for _, k := range params.Keys() {
+			switch k {
+			case "timeout":
+				timeout = time.Duration(params.Get(k).ToFloat() * float64(time.Millisecond))
+			case "tls":
+				useTLS = params.Get(k).ToBoolean()
+			case "serverName":
+				serverName = params.Get(k).String()
+			case "tags":
+				tagsV := params.Get(k)
+				if goja.IsUndefined(tagsV) || goja.IsNull(tagsV) {
+					continue
+				}
+				tagObj := tagsV.ToObject(rt)
+				for _, key := range tagObj.Keys() {
+					tags[key] = tagObj.Get(key).String()
+				}
+			}
+		}
+	}
+	tags["addr"] = addr
+
+	start := time.Now()
+	conn, err := dial(ctx, state, addr, timeout, useTLS, serverName)
+	if err != nil {
+		return nil, err
+	}
+	socket := &Socket{
+		ctx:           ctx,
+		conn:          conn,
+		reader:        bufio.NewReader(conn),
+		eventHandlers: make(map[string][]goja.Callable),
+		done:          make(chan struct{}),
+		samplesOutput: state.Samples,
+		sampleTags:    stats.IntoSampleTags(&tags),
+	}
+	stats.PushIfNotDone(ctx, state.Samples, stats.Sample{
+		Metric: metrics.TCPConnectDuration,
+		Time:   start,
+		Tags:   socket.sampleTags,
+		Value:  stats.D(time.Since(start)),
+	})
+
+	if callableV == nil {
+		return socket, nil
+	}
+	setupFn, isFunc := goja.AssertFunction(callableV)
+	if !isFunc {
+		_ = socket.Close()
+		return nil, errors.New("last argument to tcp.connect must be a function")
+	}
+	return socket, socket.runEventLoop(setupFn)
+}
+
+// dial connects to addr with the VU dialer, so the blacklisted IPs and the
+// host aliases are respected and the data_sent and data_received metrics are
+// emitted, and does the TLS handshake, if needed. The timeout applies to both.
+func dial(
+	ctx context.Context, state *lib.State, addr string, timeout time.Duration, useTLS bool, serverName string,
+) (net.Conn, error) {
+	dialCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	conn, err := state.Dialer.DialContext(dialCtx, "tcp", addr)
+	if err != nil || !useTLS {
+		return conn, err
+	}
+
+	tlsConfig := state.TLSConfig.Clone()
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{} //nolint:gosec
+	}
+	tlsConfig.ServerName = serverName
+	tlsConn := tls.Client(conn, tlsConfig)
+	deadline, _ := dialCtx.Deadline()
+	_ = tlsConn.SetDeadline(deadline)
+	if err = tlsConn.Handshake(); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	_ = tlsConn.SetDeadline(time.Time{})
+	return tlsConn, nil
+}
@@ -0,0 +1,228 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package tcp
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dop251/goja"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/loadimpact/k6/js/common"
+	"github.com/loadimpact/k6/lib"
+	"github.com/loadimpact/k6/lib/netext"
+	"github.com/loadimpact/k6/stats"
+)
+
+// serve runs a simple line-based server: it replies to "ECHO x" with "x",
+// to "BYE" with "bye" and then closes the connection.
+func serve(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer func() { _ = conn.Close() }()
+			scanner := bufio.NewScanner(conn)
+			for scanner.Scan() {
+				line := scanner.Text()
+				switch {
+				case strings.HasPrefix(line, "ECHO "):
+					_, _ = conn.Write([]byte(strings.TrimPrefix(line, "ECHO ") + "\r\n"))
+				case line == "BYE":
+					_, _ = conn.Write([]byte("bye"))
+					return
+				}
+			}
+		}()
+	}
+}
+
+func newRuntime() (*goja.Runtime, *lib.State, chan stats.SampleContainer) {
+	rt := goja.New()
+	rt.SetFieldNameMapper(common.FieldNameMapper{})
+	samples := make(chan stats.SampleContainer, 1000)
+	state := &lib.State{
+		Options: lib.Options{SystemTags: &stats.DefaultSystemTagSet},
+		Logger:  logrus.New(),
+		Dialer:  netext.NewDialer(net.Dialer{}),
+		Samples: samples,
+		Tags:    map[string]string{},
+	}
+	ctx := new(context.Context)
+	*ctx = common.WithRuntime(context.Background(), rt)
+	*ctx = lib.WithState(*ctx, state)
+	rt.Set("tcp", common.Bind(rt, New(), ctx))
+	return rt, state, samples
+}
+
+func TestSocket(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+	go serve(listener)
+
+	rt, _, samples := newRuntime()
+	rt.Set("ADDR", listener.Addr().String())
+
+	t.Run("sync", func(t *testing.T) {
+		_, err := common.RunString(rt, `
+			var socket = tcp.connect(ADDR, { timeout: 1000, tags: { tag: "value" } });
+			socket.setTimeout(1000);
+			socket.write("ECHO hello\n");
+			var line = socket.readLine();
+			if (line !== "hello") { throw new Error("unexpected line: " + line); }
+
+			socket.write(new Uint8Array([69, 67, 72, 79, 32, 1, 2, 10]).buffer);
+			var data = new Uint8Array(socket.read(4));
+			if (data.length !== 4 || data[0] !== 1 || data[1] !== 2 || data[2] !== 13 || data[3] !== 10) {
+				throw new Error("unexpected data: " + data);
+			}
+
+			socket.write("BYE\n");
+			var rest = socket.readAll();
+			if (!(rest instanceof ArrayBuffer) || rest.byteLength !== 3) { throw new Error("unexpected rest: " + rest); }
+			socket.close();
+			socket.close();
+		`)
+		require.NoError(t, err)
+
+		sums := map[string]float64{}
+		for _, sampleC := range stats.GetBufferedSamples(samples) {
+			for _, sample := range sampleC.GetSamples() {
+				sums[sample.Metric.Name] += sample.Value
+				assert.Equal(t, "value", sample.Tags.CloneTags()["tag"])
+				assert.Equal(t, listener.Addr().String(), sample.Tags.CloneTags()["addr"])
+			}
+		}
+		assert.Equal(t, float64(11+8+4), sums["tcp_bytes_sent"])
+		assert.Equal(t, float64(7+4+3), sums["tcp_bytes_received"])
+		assert.Contains(t, sums, "tcp_connect_duration")
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		_, err := common.RunString(rt, `
+			var socket = tcp.connect(ADDR);
+			socket.close();
+			socket.write("ECHO hello\n");
+		`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "the socket is closed")
+	})
+
+	t.Run("negative read", func(t *testing.T) {
+		_, err := common.RunString(rt, `
+			var socket = tcp.connect(ADDR);
+			try {
+				socket.read(-1);
+			} finally {
+				socket.close();
+			}
+		`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid number of bytes to read -1, it can't be negative")
+	})
+
+	t.Run("events", func(t *testing.T) {
+		_, err := common.RunString(rt, `
+			var received = "", closed = false;
+			var socket = tcp.connect(ADDR, function(socket) {
+				socket.on("data", function(data) {
+					received += String.fromCharCode.apply(null, new Uint8Array(data));
+					if (received === "first\r\n") {
+						socket.write("ECHO second\n");
+						socket.write("BYE\n");
+					}
+				});
+				socket.on("close", function() { closed = true; });
+				socket.write("ECHO first\n");
+			});
+			if (received !== "first\r\nsecond\r\nbye") { throw new Error("unexpected data: " + received); }
+			if (!closed) { throw new Error("the close event wasn't emitted"); }
+		`)
+		require.NoError(t, err)
+
+		_, err = common.RunString(rt, `
+			tcp.connect(ADDR, function(socket) { socket.readLine(); });
+		`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "can't be read from directly")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := common.RunString(rt, `tcp.connect("127.0.0.1:1")`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "connection refused")
+
+		_, err = common.RunString(rt, `
+			var socket = tcp.connect(ADDR);
+			socket.setTimeout(50);
+			socket.readLine();
+		`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "i/o timeout")
+	})
+
+	t.Run("init context", func(t *testing.T) {
+		rt := goja.New()
+		ctx := common.WithRuntime(context.Background(), rt)
+		rt.Set("tcp", common.Bind(rt, New(), &ctx))
+		_, err := common.RunString(rt, `tcp.connect("127.0.0.1:1")`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), ErrTCPInInitContext.Error())
+	})
+}
+
+func TestSocketTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", srv.TLS)
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+	go serve(listener)
+
+	rt, state, _ := newRuntime()
+	state.TLSConfig = srv.Client().Transport.(*http.Transport).TLSClientConfig
+	rt.Set("ADDR", listener.Addr().String())
+
+	_, err = common.RunString(rt, `
+		var socket = tcp.connect(ADDR, { tls: true });
+		socket.write("ECHO secret\n");
+		var line = socket.readLine();
+		if (line !== "secret") { throw new Error("unexpected line: " + line); }
+		socket.close();
+	`)
+	require.NoError(t, err)
+
+	_, err = common.RunString(rt, `tcp.connect(ADDR, { tls: true, serverName: "k6.io" })`)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "certificate is valid for")
+}
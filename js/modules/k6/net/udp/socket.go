@@ -0,0 +1,190 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package udp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/dop251/goja"
+
+	"github.com/loadimpact/k6/js/common"
+	"github.com/loadimpact/k6/lib"
+	"github.com/loadimpact/k6/lib/metrics"
+	"github.com/loadimpact/k6/stats"
+)
+
+// The receive timeout that is used when receive() isn't called with one.
+const defaultReceiveTimeout = 60 * time.Second
+
+var errSocketClosed = errors.New("the socket is closed")
+
+// Socket is a connected or an unconnected UDP socket.
+type Socket struct {
+	ctx        context.Context
+	conn       net.Conn     // for connected sockets
+	packetConn *net.UDPConn // for unconnected sockets
+	mtu        int
+	blacklist  []*lib.IPNet
+	closed     bool
+
+	sampleTags    *stats.SampleTags
+	samplesOutput chan<- stats.SampleContainer
+}
+
+// Datagram is a received datagram.
+type Datagram struct {
+	Data goja.ArrayBuffer `json:"data"`
+	Addr string           `json:"addr"` // the address of the sender
+}
+
+// Send sends a string or an ArrayBuffer as a single datagram, which can't be
+// larger than the MTU of the socket. The destination address has to be passed
+// to the unconnected sockets, and it can't be passed to the connected ones.
+func (s *Socket) Send(data goja.Value, addr ...string) (int, error) {
+	if s.closed {
+		return 0, errSocketClosed
+	}
+	var buf []byte
+	switch v := data.Export().(type) {
+	case goja.ArrayBuffer:
+		buf = v.Bytes()
+	case []byte:
+		buf = v
+	case string:
+		buf = []byte(v)
+	default:
+		return 0, fmt.Errorf("unsupported data type %T, it should be a string or an ArrayBuffer", v)
+	}
+	if len(buf) > s.mtu {
+		return 0, fmt.Errorf("the datagram of %d bytes is larger than the MTU of %d bytes", len(buf), s.mtu)
+	}
+
+	var n int
+	var err error
+	if s.conn != nil {
+		if len(addr) > 0 {
+			return 0, errors.New("the destination address can't be set for connected sockets")
+		}
+		n, err = s.conn.Write(buf)
+	} else {
+		if len(addr) == 0 {
+			return 0, errors.New("the destination address has to be set for unconnected sockets")
+		}
+		var udpAddr *net.UDPAddr
+		if udpAddr, err = s.resolve(addr[0]); err != nil {
+			return 0, err
+		}
+		n, err = s.packetConn.WriteToUDP(buf, udpAddr)
+	}
+	s.push(metrics.UDPBytesSent, float64(n))
+	return n, err
+}
+
+// Receive waits for the next datagram and returns at most n bytes of it, the
+// MTU of the socket by default. If nothing is received within the timeout, in
+// milliseconds, null is returned and the udp_receive_timeout metric is emitted.
+func (s *Socket) Receive(n int, timeout float64) (goja.Value, error) {
+	if s.closed {
+		return nil, errSocketClosed
+	}
+	if n <= 0 {
+		n = s.mtu
+	}
+	deadline := time.Now().Add(defaultReceiveTimeout)
+	if timeout > 0 {
+		deadline = time.Now().Add(time.Duration(timeout * float64(time.Millisecond)))
+	}
+
+	buf := make([]byte, n)
+	var read int
+	var from net.Addr
+	var err error
+	if s.conn != nil {
+		_ = s.conn.SetReadDeadline(deadline)
+		read, err = s.conn.Read(buf)
+		from = s.conn.RemoteAddr()
+	} else {
+		_ = s.packetConn.SetReadDeadline(deadline)
+		read, from, err = s.packetConn.ReadFrom(buf)
+	}
+	if err != nil {
+		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+			s.push(metrics.UDPReceiveTimeout, 1)
+			return goja.Null(), nil
+		}
+		return nil, err
+	}
+
+	s.push(metrics.UDPBytesReceived, float64(read))
+	rt := common.GetRuntime(s.ctx)
+	return rt.ToValue(&Datagram{Data: rt.NewArrayBuffer(buf[:read]), Addr: from.String()}), nil
+}
+
+// LocalAddr returns the local address of the socket.
+func (s *Socket) LocalAddr() string {
+	if s.conn != nil {
+		return s.conn.LocalAddr().String()
+	}
+	return s.packetConn.LocalAddr().String()
+}
+
+// Close closes the socket. It's safe to call it multiple times.
+func (s *Socket) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if s.conn != nil {
+		return s.conn.Close()
+	}
+	return s.packetConn.Close()
+}
+
+// resolve resolves the destination address of the unconnected sockets, which
+// aren't created with the VU dialer, so the blacklist is checked here.
+func (s *Socket) resolve(addr string) (*net.UDPAddr, error) {
+	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	for _, ipnet := range s.blacklist {
+		if ipnet.Contains(udpAddr.IP) {
+			return nil, fmt.Errorf("IP (%s) is in a blacklisted range (%s)", udpAddr.IP, ipnet)
+		}
+	}
+	return udpAddr, nil
+}
+
+func (s *Socket) push(metric *stats.Metric, value float64) {
+	if value == 0 {
+		return
+	}
+	stats.PushIfNotDone(s.ctx, s.samplesOutput, stats.Sample{
+		Metric: metric,
+		Time:   time.Now(),
+		Tags:   s.sampleTags,
+		Value:  value,
+	})
+}
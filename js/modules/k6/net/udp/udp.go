@@ -0,0 +1,142 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package udp
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/dop251/goja"
+
+	"github.com/loadimpact/k6/js/common"
+	"github.com/loadimpact/k6/lib"
+	"github.com/loadimpact/k6/stats"
+)
+
+// ErrUDPInInitContext is returned when UDP sockets are used in the init context
+var ErrUDPInInitContext = common.NewInitContextError("using UDP sockets in the init context is not supported")
+
+// The maximum payload of a UDP datagram, and the default MTU of the sockets.
+const maxDatagramSize = 65507
+
+// UDP is the k6/net/udp module, for testing UDP-based protocols.
+type UDP struct{}
+
+// New returns a new k6/net/udp module instance.
+func New() *UDP {
+	return &UDP{}
+}
+
+type socketParams struct {
+	localAddr string
+	mtu       int
+	tags      map[string]string
+}
+
+// Connect returns a connected UDP socket, which can only send datagrams to and
+// receive them from addr. It's created with the VU dialer, so the host aliases
+// and the blacklisted IPs are respected.
+func (*UDP) Connect(ctx context.Context, addr string, params goja.Value) (*Socket, error) {
+	state := lib.GetState(ctx)
+	if state == nil {
+		return nil, ErrUDPInInitContext
+	}
+	p, err := parseParams(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := state.Dialer.DialContext(ctx, "udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	p.tags["addr"] = addr
+	return newSocket(ctx, conn, nil, p), nil
+}
+
+// Socket returns an unconnected UDP socket, which can send datagrams to and
+// receive them from any address. It's bound to the localAddr param, a random
+// port on all interfaces by default.
+func (*UDP) Socket(ctx context.Context, params goja.Value) (*Socket, error) {
+	state := lib.GetState(ctx)
+	if state == nil {
+		return nil, ErrUDPInInitContext
+	}
+	p, err := parseParams(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	localAddr, err := net.ResolveUDPAddr("udp", p.localAddr)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP("udp", localAddr)
+	if err != nil {
+		return nil, err
+	}
+	return newSocket(ctx, nil, conn, p), nil
+}
+
+func parseParams(ctx context.Context, params goja.Value) (*socketParams, error) {
+	p := &socketParams{localAddr: ":0", mtu: maxDatagramSize, tags: lib.GetState(ctx).CloneTags()}
+	if params == nil || goja.IsUndefined(params) || goja.IsNull(params) {
+		return p, nil
+	}
+
+	rt := common.GetRuntime(ctx)
+	paramsObj := params.ToObject(rt)
+	for _, k := range paramsObj.Keys() {
+		switch k {
+		case "localAddr":
+			p.localAddr = paramsObj.Get(k).String()
+		case "mtu":
+			p.mtu = int(paramsObj.Get(k).ToInteger())
+			if p.mtu < 1 || p.mtu > maxDatagramSize {
+				return nil, fmt.Errorf("invalid mtu %d, it should be between 1 and %d", p.mtu, maxDatagramSize)
+			}
+		case "tags":
+			tagsV := paramsObj.Get(k)
+			if goja.IsUndefined(tagsV) || goja.IsNull(tagsV) {
+				continue
+			}
+			tagObj := tagsV.ToObject(rt)
+			for _, key := range tagObj.Keys() {
+				p.tags[key] = tagObj.Get(key).String()
+			}
+		}
+	}
+	return p, nil
+}
+
+func newSocket(ctx context.Context, conn net.Conn, packetConn *net.UDPConn, p *socketParams) *Socket {
+	state := lib.GetState(ctx)
+	return &Socket{
+		ctx:           ctx,
+		conn:          conn,
+		packetConn:    packetConn,
+		mtu:           p.mtu,
+		blacklist:     state.Options.BlacklistIPs,
+		samplesOutput: state.Samples,
+		sampleTags:    stats.IntoSampleTags(&p.tags),
+	}
+}
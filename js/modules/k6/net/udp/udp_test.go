@@ -0,0 +1,148 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package udp
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/dop251/goja"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/loadimpact/k6/js/common"
+	"github.com/loadimpact/k6/lib"
+	"github.com/loadimpact/k6/lib/netext"
+	"github.com/loadimpact/k6/stats"
+)
+
+// echo replies to every datagram with its uppercased contents, except for
+// "ignore" ones.
+func echo(conn net.PacketConn) {
+	buf := make([]byte, 65535)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		if data := string(buf[:n]); data != "ignore" {
+			_, _ = conn.WriteTo([]byte(strings.ToUpper(data)), addr)
+		}
+	}
+}
+
+func TestUDP(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = server.Close() }()
+	go echo(server)
+
+	rt := goja.New()
+	rt.SetFieldNameMapper(common.FieldNameMapper{})
+	samples := make(chan stats.SampleContainer, 1000)
+	blacklist, err := lib.ParseCIDR("10.0.0.0/8")
+	require.NoError(t, err)
+	state := &lib.State{
+		Options: lib.Options{BlacklistIPs: []*lib.IPNet{blacklist}},
+		Logger:  logrus.New(),
+		Dialer:  netext.NewDialer(net.Dialer{}),
+		Samples: samples,
+		Tags:    map[string]string{},
+	}
+	ctx := new(context.Context)
+	*ctx = common.WithRuntime(context.Background(), rt)
+	rt.Set("udp", common.Bind(rt, New(), ctx))
+	rt.Set("ADDR", server.LocalAddr().String())
+
+	t.Run("init context", func(t *testing.T) {
+		_, err := common.RunString(rt, `udp.connect(ADDR)`)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), ErrUDPInInitContext.Error())
+	})
+
+	*ctx = lib.WithState(*ctx, state)
+
+	t.Run("connected", func(t *testing.T) {
+		_, err := common.RunString(rt, `
+			var socket = udp.connect(ADDR, { tags: { tag: "value" } });
+			socket.send("hello");
+			var res = socket.receive();
+			if (String.fromCharCode.apply(null, new Uint8Array(res.data)) !== "HELLO" || res.addr !== ADDR) {
+				throw new Error("unexpected datagram: " + JSON.stringify(res));
+			}
+
+			socket.send(new Uint8Array([97, 98, 99]).buffer);
+			res = socket.receive(2, 1000);
+			var data = new Uint8Array(res.data);
+			if (data.length !== 2 || data[0] !== 65 || data[1] !== 66) { throw new Error("unexpected data: " + data); }
+
+			socket.send("ignore");
+			if (socket.receive(0, 50) !== null) { throw new Error("expected a timeout"); }
+			socket.close();
+		`)
+		require.NoError(t, err)
+
+		sums := map[string]float64{}
+		for _, sampleC := range stats.GetBufferedSamples(samples) {
+			for _, sample := range sampleC.GetSamples() {
+				sums[sample.Metric.Name] += sample.Value
+				assert.Equal(t, "value", sample.Tags.CloneTags()["tag"])
+				assert.Equal(t, server.LocalAddr().String(), sample.Tags.CloneTags()["addr"])
+			}
+		}
+		assert.Equal(t, map[string]float64{
+			"udp_bytes_sent": 5 + 3 + 6, "udp_bytes_received": 5 + 2, "udp_receive_timeout": 1,
+		}, sums)
+	})
+
+	t.Run("unconnected", func(t *testing.T) {
+		_, err := common.RunString(rt, `
+			var socket = udp.socket({ localAddr: "127.0.0.1:0" });
+			if (socket.localAddr().indexOf("127.0.0.1:") !== 0) { throw new Error("unexpected local address"); }
+			socket.send("hello", ADDR);
+			var res = socket.receive(100, 1000);
+			if (String.fromCharCode.apply(null, new Uint8Array(res.data)) !== "HELLO" || res.addr !== ADDR) {
+				throw new Error("unexpected datagram: " + JSON.stringify(res));
+			}
+			socket.close();
+		`)
+		require.NoError(t, err)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		testCases := map[string]string{
+			`udp.socket().send("hello")`:                        "the destination address has to be set",
+			`udp.connect(ADDR).send("hello", ADDR)`:             "the destination address can't be set",
+			`udp.socket().send("hello", "10.1.1.1:53")`:         "IP (10.1.1.1) is in a blacklisted range (10.0.0.0/8)",
+			`udp.connect(ADDR, { mtu: 4 }).send("hello")`:       "the datagram of 5 bytes is larger than the MTU of 4 bytes",
+			`udp.connect(ADDR, { mtu: 65508 })`:                 "invalid mtu 65508",
+			`var s = udp.connect(ADDR); s.close(); s.receive()`: "the socket is closed",
+		}
+		for script, expErr := range testCases {
+			_, err := common.RunString(rt, script)
+			require.Error(t, err, script)
+			assert.Contains(t, err.Error(), expErr, script)
+		}
+	})
+}
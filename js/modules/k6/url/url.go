@@ -0,0 +1,241 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package url
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	neturl "net/url"
+	"strings"
+)
+
+// ErrInvalidURL is returned when a URL can't be parsed or resolved to an
+// absolute URL.
+var ErrInvalidURL = errors.New("invalid URL")
+
+// Module is the k6/url module, which exposes a subset of the WHATWG URL
+// Standard API on top of Go's net/url package.
+type Module struct{}
+
+// New returns a new k6/url module instance.
+func New() *Module {
+	return &Module{}
+}
+
+// URL is a parsed absolute URL. Its fields are kept in sync with any changes
+// made through its searchParams.
+type URL struct {
+	Href         string           `js:"href"`
+	Origin       string           `js:"origin"`
+	Protocol     string           `js:"protocol"`
+	Username     string           `js:"username"`
+	Password     string           `js:"password"`
+	Host         string           `js:"host"`
+	Hostname     string           `js:"hostname"`
+	Port         string           `js:"port"`
+	Pathname     string           `js:"pathname"`
+	Search       string           `js:"search"`
+	Hash         string           `js:"hash"`
+	SearchParams *URLSearchParams `js:"searchParams"`
+
+	u *neturl.URL
+}
+
+// XURL is the `new URL(url, base)` constructor. If base is specified, url
+// can be relative and is resolved against it.
+func (*Module) XURL(ctx *context.Context, rawurl string, base ...string) (*URL, error) {
+	u, err := neturl.Parse(rawurl)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", ErrInvalidURL, rawurl)
+	}
+	if len(base) > 0 && base[0] != "" {
+		b, err := neturl.Parse(base[0])
+		if err != nil || !b.IsAbs() {
+			return nil, fmt.Errorf("%s: %s", ErrInvalidURL, base[0])
+		}
+		u = b.ResolveReference(u)
+	}
+	if !u.IsAbs() || (u.Host == "" && u.Opaque == "") {
+		return nil, fmt.Errorf("%s: %s", ErrInvalidURL, rawurl)
+	}
+
+	res := &URL{u: u}
+	res.SearchParams = &URLSearchParams{params: parseQuery(u.RawQuery), url: res}
+	res.update()
+	return res, nil
+}
+
+// update recalculates all of the URL fields from the underlying net/url URL.
+func (u *URL) update() {
+	if u.u.Path == "" && u.u.Opaque == "" {
+		u.u.Path = "/"
+	}
+
+	u.Href = u.u.String()
+	u.Protocol = u.u.Scheme + ":"
+	u.Username = u.u.User.Username()
+	u.Password, _ = u.u.User.Password()
+	u.Host = u.u.Host
+	u.Hostname = u.u.Hostname()
+	u.Port = u.u.Port()
+	u.Origin = u.Protocol + "//" + u.Host
+	u.Pathname = u.u.EscapedPath()
+	u.Search = ""
+	if u.u.RawQuery != "" {
+		u.Search = "?" + u.u.RawQuery
+	}
+	u.Hash = ""
+	if u.u.Fragment != "" {
+		u.Hash = "#" + u.u.EscapedFragment()
+	}
+}
+
+// ToString returns the whole URL, the same as the href property.
+func (u *URL) ToString() string {
+	return u.Href
+}
+
+// ToJSON returns the whole URL, so that URLs are serialized as strings.
+func (u *URL) ToJSON() string {
+	return u.Href
+}
+
+type queryParam struct {
+	key, value string
+}
+
+// URLSearchParams is an ordered list of query parameters. If it belongs to a
+// URL, any changes made to it are reflected in that URL's search and href.
+type URLSearchParams struct {
+	params []queryParam
+	url    *URL
+}
+
+// XURLSearchParams is the `new URLSearchParams(query)` constructor.
+func (*Module) XURLSearchParams(ctx *context.Context, query string) *URLSearchParams {
+	return &URLSearchParams{params: parseQuery(query)}
+}
+
+func parseQuery(query string) []queryParam {
+	query = strings.TrimPrefix(query, "?")
+	var params []queryParam
+	for _, part := range strings.Split(query, "&") {
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		key, err := neturl.QueryUnescape(kv[0])
+		if err != nil {
+			key = kv[0]
+		}
+		var value string
+		if len(kv) > 1 {
+			if value, err = neturl.QueryUnescape(kv[1]); err != nil {
+				value = kv[1]
+			}
+		}
+		params = append(params, queryParam{key, value})
+	}
+	return params
+}
+
+func (p *URLSearchParams) update() {
+	if p.url != nil {
+		p.url.u.RawQuery = p.ToString()
+		p.url.update()
+	}
+}
+
+// Get returns the value of the first parameter with the given key, or null
+// if there is no such parameter.
+func (p *URLSearchParams) Get(key string) interface{} {
+	for _, param := range p.params {
+		if param.key == key {
+			return param.value
+		}
+	}
+	return nil
+}
+
+// GetAll returns the values of all parameters with the given key.
+func (p *URLSearchParams) GetAll(key string) []string {
+	values := []string{}
+	for _, param := range p.params {
+		if param.key == key {
+			values = append(values, param.value)
+		}
+	}
+	return values
+}
+
+// Has returns whether there is a parameter with the given key.
+func (p *URLSearchParams) Has(key string) bool {
+	return p.Get(key) != nil
+}
+
+// Set sets the value of the first parameter with the given key and removes
+// all others with the same key. If there is no such parameter, it's appended.
+func (p *URLSearchParams) Set(key, value string) {
+	params := make([]queryParam, 0, len(p.params)+1)
+	found := false
+	for _, param := range p.params {
+		if param.key != key {
+			params = append(params, param)
+		} else if !found {
+			params = append(params, queryParam{key, value})
+			found = true
+		}
+	}
+	if !found {
+		params = append(params, queryParam{key, value})
+	}
+	p.params = params
+	p.update()
+}
+
+// Append adds a new parameter, regardless of whether there are other
+// parameters with the same key.
+func (p *URLSearchParams) Append(key, value string) {
+	p.params = append(p.params, queryParam{key, value})
+	p.update()
+}
+
+// Delete removes all parameters with the given key.
+func (p *URLSearchParams) Delete(key string) {
+	params := make([]queryParam, 0, len(p.params))
+	for _, param := range p.params {
+		if param.key != key {
+			params = append(params, param)
+		}
+	}
+	p.params = params
+	p.update()
+}
+
+// ToString returns the URL-encoded query string, without a leading "?".
+func (p *URLSearchParams) ToString() string {
+	parts := make([]string, len(p.params))
+	for i, param := range p.params {
+		parts[i] = neturl.QueryEscape(param.key) + "=" + neturl.QueryEscape(param.value)
+	}
+	return strings.Join(parts, "&")
+}
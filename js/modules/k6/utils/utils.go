@@ -0,0 +1,82 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"time"
+
+	"github.com/loadimpact/k6/js/common"
+)
+
+// Utils is the k6/utils module, a collection of small helpers that scripts
+// would otherwise have to implement (much more slowly) in JS.
+type Utils struct{}
+
+// New returns a new k6/utils module instance.
+func New() *Utils {
+	return &Utils{}
+}
+
+// Uuidv4 returns a random (version 4) RFC 4122 UUID.
+func (*Utils) Uuidv4(ctx context.Context) string {
+	var uuid [16]byte
+	if _, err := rand.Read(uuid[:]); err != nil {
+		common.Throw(common.GetRuntime(ctx), err)
+	}
+	return formatUUID(uuid, 4)
+}
+
+// Uuidv7 returns a time-ordered (version 7) UUID: its first 48 bits are the
+// current Unix time in milliseconds and the rest are random, so UUIDs
+// generated later sort after the ones generated earlier.
+func (*Utils) Uuidv7(ctx context.Context) string {
+	var uuid [16]byte
+	if _, err := rand.Read(uuid[6:]); err != nil {
+		common.Throw(common.GetRuntime(ctx), err)
+	}
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(time.Now().UnixNano()/int64(time.Millisecond)))
+	copy(uuid[:6], ts[2:])
+	return formatUUID(uuid, 7)
+}
+
+// formatUUID sets the version and the RFC 4122 variant bits and returns the
+// canonical hyphenated representation of the UUID.
+func formatUUID(uuid [16]byte, version byte) string {
+	uuid[6] = (uuid[6] & 0x0f) | version<<4
+	uuid[8] = (uuid[8] & 0x3f) | 0x80
+
+	var buf [36]byte
+	hex.Encode(buf[0:8], uuid[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], uuid[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], uuid[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], uuid[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], uuid[10:])
+	return string(buf[:])
+}
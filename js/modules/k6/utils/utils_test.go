@@ -0,0 +1,62 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/loadimpact/k6/js/common"
+)
+
+func TestUUID(t *testing.T) {
+	rt := goja.New()
+	rt.SetFieldNameMapper(common.FieldNameMapper{})
+	ctx := common.WithRuntime(context.Background(), rt)
+	rt.Set("utils", common.Bind(rt, New(), &ctx))
+
+	t.Run("v4", func(t *testing.T) {
+		v, err := common.RunString(rt, `utils.uuidv4()`)
+		require.NoError(t, err)
+		assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, v.String())
+
+		v, err = common.RunString(rt, `utils.uuidv4() === utils.uuidv4()`)
+		require.NoError(t, err)
+		assert.False(t, v.ToBoolean())
+	})
+
+	t.Run("v7", func(t *testing.T) {
+		v, err := common.RunString(rt, `utils.uuidv7()`)
+		require.NoError(t, err)
+		assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, v.String())
+
+		first := v.String()
+		time.Sleep(2 * time.Millisecond)
+		v, err = common.RunString(rt, `utils.uuidv7()`)
+		require.NoError(t, err)
+		assert.True(t, v.String() > first, "%s should sort after %s", v.String(), first)
+	})
+}
@@ -31,6 +31,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/dop251/goja"
@@ -47,6 +48,17 @@ var ErrWSInInitContext = common.NewInitContextError("using websockets in the ini
 
 type WS struct{}
 
+// The types of the errors that are counted in ws_errors, in its error_type tag.
+const (
+	wsErrorConnect = "connect"
+	wsErrorRead    = "read"
+	wsErrorWrite   = "write"
+)
+
+// activeSessions is the number of the open WebSocket connections of all VUs,
+// for the ws_sessions_active gauge.
+var activeSessions int64 //nolint:gochecknoglobals
+
 type Socket struct {
 	ctx           context.Context
 	conn          *websocket.Conn
@@ -158,7 +170,7 @@ func (*WS) Connect(ctx context.Context, url string, args ...goja.Value) (*WSHTTP
 		// Pass a custom net.DialContext function to websocket.Dialer that will substitute
 		// the underlying net.Conn with our own tracked netext.Conn
 		NetDialContext:  state.Dialer.DialContext,
-		Proxy:           http.ProxyFromEnvironment,
+		Proxy:           lib.ProxyFromState,
 		TLSClientConfig: tlsConfig,
 	}
 
@@ -167,7 +179,7 @@ func (*WS) Connect(ctx context.Context, url string, args ...goja.Value) (*WSHTTP
 	connectionEnd := time.Now()
 	connectionDuration := stats.D(connectionEnd.Sub(start))
 
-	if state.Options.SystemTags.Has(stats.TagIP) && conn.RemoteAddr() != nil {
+	if state.Options.SystemTags.Has(stats.TagIP) && conn != nil && conn.RemoteAddr() != nil {
 		if ip, _, err := net.SplitHostPort(conn.RemoteAddr().String()); err == nil {
 			tags["ip"] = ip
 		}
@@ -205,10 +217,12 @@ func (*WS) Connect(ctx context.Context, url string, args ...goja.Value) (*WSHTTP
 
 	if connErr != nil {
 		// Pass the error to the user script before exiting immediately
-		socket.handleEvent("error", rt.ToValue(connErr))
+		socket.handleError(wsErrorConnect, connErr)
 
 		return nil, connErr
 	}
+	socket.pushActiveSessions(atomic.AddInt64(&activeSessions, 1))
+	defer func() { socket.pushActiveSessions(atomic.AddInt64(&activeSessions, -1)) }()
 
 	// Run the user-provided set up function
 	if _, err := setupFn(goja.Undefined(), rt.ToValue(&socket)); err != nil {
@@ -271,7 +285,7 @@ func (*WS) Connect(ctx context.Context, url string, args ...goja.Value) (*WSHTTP
 			// - reply with pong (needed when `SetPingHandler` is overwritten)
 			err := socket.conn.WriteControl(websocket.PongMessage, []byte(pingData), time.Now().Add(writeWait))
 			if err != nil {
-				socket.handleEvent("error", rt.ToValue(err))
+				socket.handleError(wsErrorWrite, err)
 			}
 			socket.handleEvent("ping")
 
@@ -290,7 +304,7 @@ func (*WS) Connect(ctx context.Context, url string, args ...goja.Value) (*WSHTTP
 			socket.handleEvent("message", rt.ToValue(string(readData)))
 
 		case readErr := <-readErrChan:
-			socket.handleEvent("error", rt.ToValue(readErr))
+			socket.handleError(wsErrorRead, readErr)
 
 		case code := <-readCloseChan:
 			_ = socket.closeConnection(code)
@@ -329,33 +343,57 @@ func (s *Socket) handleEvent(event string, args ...goja.Value) {
 	}
 }
 
+// handleError counts the error in ws_errors, tagged with its type, and passes
+// it to the error handlers of the script.
+func (s *Socket) handleError(errType string, err error) {
+	tags := s.sampleTags.CloneTags()
+	tags["error_type"] = errType
+	stats.PushIfNotDone(s.ctx, s.samplesOutput, stats.Sample{
+		Metric: metrics.WSErrors,
+		Time:   time.Now(),
+		Tags:   stats.IntoSampleTags(&tags),
+		Value:  1,
+	})
+	s.handleEvent("error", common.GetRuntime(s.ctx).ToValue(err))
+}
+
+func (s *Socket) pushActiveSessions(active int64) {
+	stats.PushIfNotDone(s.ctx, s.samplesOutput, stats.Sample{
+		Metric: metrics.WSSessionsActive,
+		Time:   time.Now(),
+		Tags:   s.sampleTags,
+		Value:  float64(active),
+	})
+}
+
 func (s *Socket) Send(message string) {
 	// NOTE: No binary message support for the time being since goja doesn't
 	// support typed arrays.
-	rt := common.GetRuntime(s.ctx)
-
 	writeData := []byte(message)
+	start := time.Now()
 	if err := s.conn.WriteMessage(websocket.TextMessage, writeData); err != nil {
-		s.handleEvent("error", rt.ToValue(err))
+		s.handleError(wsErrorWrite, err)
 	}
+	end := time.Now()
 
-	stats.PushIfNotDone(s.ctx, s.samplesOutput, stats.Sample{
-		Metric: metrics.WSMessagesSent,
-		Time:   time.Now(),
-		Tags:   s.sampleTags,
-		Value:  1,
+	stats.PushIfNotDone(s.ctx, s.samplesOutput, stats.ConnectedSamples{
+		Samples: []stats.Sample{
+			{Metric: metrics.WSMessagesSent, Time: end, Tags: s.sampleTags, Value: 1},
+			{Metric: metrics.WSMsgSendDuration, Time: end, Tags: s.sampleTags, Value: stats.D(end.Sub(start))},
+		},
+		Tags: s.sampleTags,
+		Time: end,
 	})
 }
 
 func (s *Socket) Ping() {
-	rt := common.GetRuntime(s.ctx)
 	deadline := time.Now().Add(writeWait)
 	pingID := strconv.Itoa(s.pingSendCounter)
 	data := []byte(pingID)
 
 	err := s.conn.WriteControl(websocket.PingMessage, data, deadline)
 	if err != nil {
-		s.handleEvent("error", rt.ToValue(err))
+		s.handleError(wsErrorWrite, err)
 		return
 	}
 
@@ -469,7 +507,7 @@ func (s *Socket) closeConnection(code int) error {
 		)
 		if err != nil {
 			// Call the user-defined error handler
-			s.handleEvent("error", rt.ToValue(err))
+			s.handleError(wsErrorWrite, err)
 		}
 
 		// Call the user-defined close handler
@@ -179,6 +179,8 @@ func TestSession(t *testing.T) {
 	assertSessionMetricsEmitted(t, samplesBuf, "", sr("WSBIN_URL/ws-echo"), 101, "")
 	assertMetricEmitted(t, metrics.WSMessagesSent, samplesBuf, sr("WSBIN_URL/ws-echo"))
 	assertMetricEmitted(t, metrics.WSMessagesReceived, samplesBuf, sr("WSBIN_URL/ws-echo"))
+	assertMetricEmitted(t, metrics.WSMsgSendDuration, samplesBuf, sr("WSBIN_URL/ws-echo"))
+	assertMetricEmitted(t, metrics.WSSessionsActive, samplesBuf, sr("WSBIN_URL/ws-echo"))
 
 	t.Run("interval", func(t *testing.T) {
 		_, err := common.RunString(rt, sr(`
@@ -208,6 +210,28 @@ func TestSession(t *testing.T) {
 		require.Contains(t, err.Error(), "setInterval requires a >0 timeout parameter, received -1.23 ")
 	})
 
+	t.Run("setup error", func(t *testing.T) {
+		stats.GetBufferedSamples(samples)
+		_, err := common.RunString(rt, sr(`
+		var res = ws.connect("WSBIN_URL/ws-echo", function(socket){
+			throw new Error("setup failed");
+		});
+		`))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "setup failed")
+
+		// The session has to be counted as closed too
+		var active int
+		for _, sc := range stats.GetBufferedSamples(samples) {
+			for _, sample := range sc.GetSamples() {
+				if sample.Metric == metrics.WSSessionsActive {
+					active++
+				}
+			}
+		}
+		assert.Equal(t, 2, active)
+	})
+
 	t.Run("timeout", func(t *testing.T) {
 		_, err := common.RunString(rt, sr(`
 		var start = new Date().getTime();
@@ -422,7 +446,19 @@ func TestErrors(t *testing.T) {
 		}
 		`))
 		assert.NoError(t, err)
-		assertSessionMetricsEmitted(t, stats.GetBufferedSamples(samples), "", sr("WSBIN_URL/ws-echo-invalid"), 101, "")
+		samplesBuf := stats.GetBufferedSamples(samples)
+		assertSessionMetricsEmitted(t, samplesBuf, "", sr("WSBIN_URL/ws-echo-invalid"), 101, "")
+		var errorTypes []string
+		for _, sampleContainer := range samplesBuf {
+			for _, sample := range sampleContainer.GetSamples() {
+				if sample.Metric == metrics.WSErrors {
+					errorType, _ := sample.Tags.Get("error_type")
+					errorTypes = append(errorTypes, errorType)
+				}
+			}
+		}
+		// The connection errors are from the invalid_url tests
+		assert.Equal(t, []string{"connect", "connect", "write"}, errorTypes)
 	})
 
 	t.Run("error on close", func(t *testing.T) {
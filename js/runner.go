@@ -23,12 +23,19 @@ package js
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
+	"math/rand"
 	"net"
 	"net/http"
 	"net/http/cookiejar"
+	"net/url"
+	"path/filepath"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/dop251/goja"
@@ -65,6 +72,15 @@ type Runner struct {
 
 	console   *console
 	setupData []byte
+
+	// The system CAs with the trustedProxyCAs, or nil if there aren't any
+	rootCAs *x509.CertPool
+
+	// The hosts whose TLS certificate expiry was already recorded by a VU
+	tlsCertExpiryHosts *sync.Map
+
+	// The statistics of the HTTP connection pools of all of the VUs
+	connPoolStats *lib.ConnPoolStats
 }
 
 // New returns a new Runner for the provide source
@@ -104,8 +120,10 @@ func newFromBundle(logger *logrus.Logger, b *Bundle) (*Runner, error) {
 			KeepAlive: 30 * time.Second,
 			DualStack: true,
 		},
-		console:  newConsole(logger),
-		Resolver: dnscache.New(0),
+		console:            newConsole(logger),
+		Resolver:           dnscache.New(0),
+		tlsCertExpiryHosts: new(sync.Map),
+		connPoolStats:      new(lib.ConnPoolStats),
 	}
 
 	err = r.SetOptions(r.Bundle.Options)
@@ -134,8 +152,10 @@ func (r *Runner) newVU(id int64, samplesOut chan<- stats.SampleContainer) (*VU,
 		return nil, err
 	}
 
+	// An empty list of cipher suites means Go's default set, which a nil slice
+	// gives us, while an empty non-nil one would disable all TLS 1.0 - 1.2 suites.
 	var cipherSuites []uint16
-	if r.Bundle.Options.TLSCipherSuites != nil {
+	if r.Bundle.Options.TLSCipherSuites != nil && len(*r.Bundle.Options.TLSCipherSuites) > 0 {
 		cipherSuites = *r.Bundle.Options.TLSCipherSuites
 	}
 
@@ -163,6 +183,12 @@ func (r *Runner) newVU(id int64, samplesOut chan<- stats.SampleContainer) (*VU,
 		Resolver:  r.Resolver,
 		Blacklist: r.Bundle.Options.BlacklistIPs,
 		Hosts:     r.Bundle.Options.Hosts,
+
+		MaxRetries:     int(r.Bundle.Options.ConnectionRetries.Int64),
+		RetryDNSErrors: r.Bundle.Options.RetryDNSErrors.Bool,
+		IPVersion:      r.Bundle.Options.IPVersion.String,
+
+		ConnHooks: &lib.ConnHooks{},
 	}
 	tlsConfig := &tls.Config{
 		InsecureSkipVerify: r.Bundle.Options.InsecureSkipTLSVerify.Bool,
@@ -172,11 +198,12 @@ func (r *Runner) newVU(id int64, samplesOut chan<- stats.SampleContainer) (*VU,
 		Certificates:       certs,
 		NameToCertificate:  nameToCert,
 		Renegotiation:      tls.RenegotiateFreelyAsClient,
+		RootCAs:            r.rootCAs,
 	}
 	transport := &http.Transport{
-		Proxy:               http.ProxyFromEnvironment,
+		Proxy:               lib.ProxyFromState,
 		TLSClientConfig:     tlsConfig,
-		DialContext:         dialer.DialContext,
+		DialContext:         r.connPoolStats.WrapDialContext(dialer.DialContext),
 		DisableCompression:  true,
 		DisableKeepAlives:   r.Bundle.Options.NoConnectionReuse.Bool,
 		MaxIdleConns:        int(r.Bundle.Options.Batch.Int64),
@@ -197,25 +224,29 @@ func (r *Runner) newVU(id int64, samplesOut chan<- stats.SampleContainer) (*VU,
 		Dialer:         dialer,
 		CookieJar:      cookieJar,
 		TLSConfig:      tlsConfig,
-		Console:        r.console,
+		Console:        r.console.forVU(),
 		BPool:          bpool.NewBufferPool(100),
 		Samples:        samplesOut,
 	}
 
 	vu.state = &lib.State{
-		Logger:    vu.Runner.Logger,
-		Options:   vu.Runner.Bundle.Options,
-		Transport: vu.Transport,
-		Dialer:    vu.Dialer,
-		TLSConfig: vu.TLSConfig,
-		CookieJar: cookieJar,
-		RPSLimit:  vu.Runner.RPSLimit,
-		BPool:     vu.BPool,
-		Vu:        vu.ID,
-		Samples:   vu.Samples,
-		Iteration: vu.Iteration,
-		Tags:      vu.Runner.Bundle.Options.RunTags.CloneTags(),
-		Group:     r.defaultGroup,
+		Logger:             vu.Runner.Logger,
+		Options:            vu.Runner.Bundle.Options,
+		Transport:          vu.Transport,
+		Dialer:             vu.Dialer,
+		ConnHooks:          vu.Dialer.ConnHooks,
+		TLSConfig:          vu.TLSConfig,
+		CookieJar:          cookieJar,
+		RPSLimit:           vu.Runner.RPSLimit,
+		BPool:              vu.BPool,
+		TLSCertExpiryHosts: r.tlsCertExpiryHosts,
+		ConnPoolStats:      r.connPoolStats,
+		Vu:                 vu.ID,
+		Rand:               common.NewRand(),
+		Samples:            vu.Samples,
+		Iteration:          vu.Iteration,
+		Tags:               vu.Runner.Bundle.Options.RunTags.CloneTags(),
+		Group:              r.defaultGroup,
 	}
 	vu.Runtime.Set("console", common.Bind(vu.Runtime, vu.Console, vu.Context))
 
@@ -292,6 +323,12 @@ func (r *Runner) GetOptions() lib.Options {
 	return r.Bundle.Options
 }
 
+// GetConnPoolStats returns the statistics of the HTTP connection pools of the
+// VUs, which the engine emits as metrics.
+func (r *Runner) GetConnPoolStats() *lib.ConnPoolStats {
+	return r.connPoolStats
+}
+
 // IsExecutable returns whether the given name is an exported and
 // executable function in the script.
 func (r *Runner) IsExecutable(name string) bool {
@@ -318,9 +355,50 @@ func (r *Runner) SetOptions(opts lib.Options) error {
 		r.console = c
 	}
 
+	r.rootCAs = nil
+	if len(opts.TrustedProxyCAs) > 0 {
+		initCtx := r.Bundle.BaseInitContext
+		rootCAs, err := loadRootCAs(initCtx.filesystems["file"], initCtx.pwd, opts.TrustedProxyCAs)
+		if err != nil {
+			return err
+		}
+		r.rootCAs = rootCAs
+	}
+
 	return nil
 }
 
+// loadRootCAs returns the system CAs together with the CA certificates in the
+// given PEM files. They are read from the filesystem of the script, like the
+// files opened with open(), so that they are included in its archive.
+func loadRootCAs(fs afero.Fs, pwd *url.URL, paths []string) (*x509.CertPool, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		// There are no system CAs, e.g. on Windows before Go 1.18
+		pool = x509.NewCertPool()
+	}
+	for _, path := range paths {
+		if path == "" {
+			return nil, errors.New("the path of a trusted proxy CA can't be empty")
+		}
+		if path[0] != '/' && path[0] != '\\' && !filepath.IsAbs(path) {
+			path = filepath.Join(pwd.Path, path)
+		}
+		path = filepath.Clean(path)
+		if path[0:1] != afero.FilePathSeparator {
+			path = afero.FilePathSeparator + path
+		}
+		data, err := afero.ReadFile(fs, path)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read the trusted proxy CA: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no PEM certificates were found in the trusted proxy CA %s", path)
+		}
+	}
+	return pool, nil
+}
+
 // Runs an exported function in its own temporary VU, optionally with an argument. Execution is
 // interrupted if the context expires. No error is returned if the part does not exist.
 func (r *Runner) runPart(ctx context.Context, out chan<- stats.SampleContainer, name string, arg interface{}) (goja.Value, error) {
@@ -416,6 +494,8 @@ type ActiveVU struct {
 	*VU
 	*lib.VUActivationParams
 	busy chan struct{}
+
+	jitterDone bool // if the staggerJitter delay was already waited for
 }
 
 // GetID returns the unique VU ID.
@@ -459,6 +539,12 @@ func (u *VU) Activate(params *lib.VUActivationParams) lib.ActiveVU {
 	if opts.SystemTags.Has(stats.TagScenario) {
 		u.state.Tags["scenario"] = params.Scenario
 	}
+	u.state.Scenario = params.Scenario
+	u.state.ProxyURL = params.ProxyURL
+	u.state.VURPSLimit = nil
+	if params.MaxRPS > 0 {
+		u.state.VURPSLimit = rate.NewLimiter(rate.Limit(params.MaxRPS), 1)
+	}
 
 	params.RunContext = common.WithRuntime(params.RunContext, u.Runtime)
 	params.RunContext = lib.WithState(params.RunContext, u.state)
@@ -513,27 +599,104 @@ func (u *ActiveVU) RunOnce() error {
 		}
 	}
 
-	fn, ok := u.exports[u.Exec]
+	if !u.jitterDone {
+		u.jitterDone = true
+		if err := u.waitForStaggerJitter(); err != nil {
+			return err
+		}
+	}
+
+	exec := u.Exec
+	if len(u.ExecProbabilities) > 0 {
+		// Like the stagger jitter, the flow is picked with the VU's random
+		// number generator, seeded for the iteration if there's a randomSeed
+		if opts := u.Runner.Bundle.Options; opts.RandomSeed.Valid {
+			u.state.Rand.Seed(iterationSeed(opts.RandomSeed.Int64, u.ID, u.Iteration))
+		}
+		exec = pickExec(u.state.Rand, u.ExecProbabilities)
+		u.state.Tags["flow"] = exec
+	}
+	fn, ok := u.exports[exec]
 	if !ok {
 		// Shouldn't happen; this is validated in cmd.validateScenarioConfig()
-		panic(fmt.Sprintf("function '%s' not found in exports", u.Exec))
+		panic(fmt.Sprintf("function '%s' not found in exports", exec))
 	}
 
 	// Call the exported function.
 	_, isFullIteration, totalTime, err := u.runFn(u.RunContext, true, fn, u.setupData)
 
 	// If MinIterationDuration is specified and the iteration wasn't cancelled
-	// and was less than it, sleep for the remainder
+	// and was less than it, sleep for the remainder. Like the think time, the
+	// sleep isn't a part of the iteration_duration and ends if the VU is stopped.
 	if isFullIteration && u.Runner.Bundle.Options.MinIterationDuration.Valid {
 		durationDiff := time.Duration(u.Runner.Bundle.Options.MinIterationDuration.Duration) - totalTime
 		if durationDiff > 0 {
-			time.Sleep(durationDiff)
+			t := time.NewTimer(durationDiff)
+			select {
+			case <-t.C:
+			case <-u.RunContext.Done():
+				t.Stop()
+			}
+		}
+	}
+
+	// The think time is after the end of the iteration, so it isn't a part of
+	// its iteration_duration
+	if isFullIteration && u.ThinkTime != nil {
+		t := time.NewTimer(u.ThinkTime.Sample(u.state.Rand))
+		select {
+		case <-t.C:
+		case <-u.RunContext.Done():
+			t.Stop()
 		}
 	}
 
 	return err
 }
 
+// waitForStaggerJitter waits for a random delay of up to the staggerJitter of
+// the scenario before the first iteration of the activation. The delay comes
+// from the random number generator of the VU, which is seeded like it is for an
+// iteration, so that it's reproducible with the randomSeed option.
+func (u *ActiveVU) waitForStaggerJitter() error {
+	if u.StaggerJitter <= 0 {
+		return nil
+	}
+	if opts := u.Runner.Bundle.Options; opts.RandomSeed.Valid {
+		u.state.Rand.Seed(iterationSeed(opts.RandomSeed.Int64, u.ID, u.Iteration))
+	}
+	t := time.NewTimer(time.Duration(u.state.Rand.Int63n(int64(u.StaggerJitter))))
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-u.RunContext.Done():
+		return u.RunContext.Err()
+	}
+}
+
+// pickExec randomly picks one of the functions according to their (already
+// normalized) probabilities.
+func pickExec(rnd *rand.Rand, probs []lib.ExecProbability) string {
+	r := rnd.Float64()
+	for _, p := range probs {
+		if r < p.Probability {
+			return p.Exec
+		}
+		r -= p.Probability
+	}
+	return probs[len(probs)-1].Exec // in case of rounding errors
+}
+
+// iterationSeed returns the seed of the VU's random number generator for the
+// given iteration, so that the random data in every iteration is different,
+// but the same between test runs with the same randomSeed option.
+func iterationSeed(seed, vuID, iteration int64) int64 {
+	h := fnv.New64a()
+	_ = binary.Write(h, binary.LittleEndian, [3]int64{seed, vuID, iteration})
+	return int64(h.Sum64())
+}
+
 func (u *VU) runFn(
 	ctx context.Context, isDefault bool, fn goja.Callable, args ...goja.Value,
 ) (goja.Value, bool, time.Duration, error) {
@@ -554,11 +717,24 @@ func (u *VU) runFn(
 	// also this means that teardown and setup have __ITER defined
 	// maybe move it to RunOnce ?
 	u.Runtime.Set("__ITER", u.Iteration)
+	u.state.Iteration = u.Iteration
+	if opts.RandomSeed.Valid {
+		u.state.Rand.Seed(iterationSeed(opts.RandomSeed.Int64, u.ID, u.Iteration))
+	}
+	u.state.TraceID = ""
+	if tc := opts.TraceContext; tc.IsEnabled() {
+		// The random number generator is only used when some iterations aren't
+		// sampled, so that it doesn't change the numbers of the seeded scripts
+		if rate := tc.GetSampleRate(); rate >= 1 || u.state.Rand.Float64() < rate {
+			u.state.TraceID = lib.NewTraceID()
+		}
+	}
 	u.Iteration++
 
 	startTime := time.Now()
 	v, err := fn(goja.Undefined(), args...) // Actually run the JS script
 	endTime := time.Now()
+	u.state.CloseIterationResources()
 
 	var isFullIteration bool
 	select {
@@ -569,7 +745,7 @@ func (u *VU) runFn(
 	}
 
 	if u.Runner.Bundle.Options.NoVUConnectionReuse.Bool {
-		u.Transport.CloseIdleConnections()
+		u.state.CloseIdleConnections()
 	}
 
 	u.state.Samples <- u.Dialer.GetTrail(startTime, endTime, isFullIteration, isDefault, stats.NewSampleTags(u.state.Tags))
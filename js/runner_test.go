@@ -25,12 +25,15 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"go/build"
 	"io/ioutil"
 	stdlog "log"
+	"math/rand"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"sync"
@@ -1166,6 +1169,518 @@ func TestVUIntegrationCookiesNoReset(t *testing.T) {
 	}
 }
 
+func TestVUExecProbabilities(t *testing.T) {
+	r, err := getSimpleRunner(t, "/script.js", `
+		var Counter = require("k6/metrics").Counter;
+		var calls = new Counter("calls");
+		exports.browse = function() { calls.add(1, {fn: "browse"}); };
+		exports.checkout = function() { calls.add(1, {fn: "checkout"}); };
+		exports.default = function() { throw new Error("shouldn't be called"); };
+	`)
+	require.NoError(t, err)
+
+	samples := make(chan stats.SampleContainer, 1000)
+	initVU, err := r.NewVU(1, samples)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	vu := initVU.Activate(&lib.VUActivationParams{
+		RunContext: ctx,
+		ExecProbabilities: []lib.ExecProbability{
+			{Exec: "browse", Probability: 0.75},
+			{Exec: "checkout", Probability: 0.25},
+		},
+	})
+	for i := 0; i < 400; i++ {
+		require.NoError(t, vu.RunOnce())
+	}
+
+	flows := map[string]int{}
+	for _, sc := range stats.GetBufferedSamples(samples) {
+		for _, s := range sc.GetSamples() {
+			if s.Metric.Name != "calls" {
+				continue
+			}
+			flow, ok := s.Tags.Get("flow")
+			require.True(t, ok)
+			fn, _ := s.Tags.Get("fn")
+			require.Equal(t, fn, flow)
+			flows[flow]++
+		}
+	}
+	assert.Equal(t, 400, flows["browse"]+flows["checkout"])
+	assert.True(t, flows["browse"] > flows["checkout"], "%v", flows)
+	assert.NotZero(t, flows["checkout"])
+}
+
+func TestVUExecProbabilitiesRandomSeed(t *testing.T) {
+	r, err := getSimpleRunner(t, "/script.js", `
+		exports.browse = function() {};
+		exports.checkout = function() {};
+	`)
+	require.NoError(t, err)
+	require.NoError(t, r.SetOptions(lib.Options{RandomSeed: null.IntFrom(123)}))
+
+	// The VUs with the same ID pick the same flows with the same randomSeed
+	pickFlows := func() []string {
+		initVU, err := r.NewVU(1, make(chan stats.SampleContainer, 1000))
+		require.NoError(t, err)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		vu := initVU.Activate(&lib.VUActivationParams{
+			RunContext: ctx,
+			ExecProbabilities: []lib.ExecProbability{
+				{Exec: "browse", Probability: 0.5},
+				{Exec: "checkout", Probability: 0.5},
+			},
+		})
+		flows := make([]string, 0, 20)
+		for i := 0; i < 20; i++ {
+			require.NoError(t, vu.RunOnce())
+			flows = append(flows, initVU.(*VU).state.Tags["flow"])
+		}
+		return flows
+	}
+	flows := pickFlows()
+	assert.Equal(t, flows, pickFlows())
+	assert.Contains(t, flows, "browse")
+	assert.Contains(t, flows, "checkout")
+}
+
+func TestVUThinkTime(t *testing.T) {
+	r, err := getSimpleRunner(t, "/script.js", `exports.default = function() {};`)
+	require.NoError(t, err)
+
+	samples := make(chan stats.SampleContainer, 100)
+	initVU, err := r.NewVU(1, samples)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	thinkTime := 200 * time.Millisecond
+	vu := initVU.Activate(&lib.VUActivationParams{
+		RunContext: ctx,
+		ThinkTime: &lib.ThinkTime{
+			Min: types.NullDurationFrom(thinkTime),
+			Max: types.NullDurationFrom(thinkTime),
+		},
+	})
+	start := time.Now()
+	require.NoError(t, vu.RunOnce())
+	assert.True(t, time.Since(start) >= thinkTime)
+
+	for _, sc := range stats.GetBufferedSamples(samples) {
+		for _, s := range sc.GetSamples() {
+			if s.Metric.Name == metrics.IterationDuration.Name {
+				assert.True(t, s.Value < float64(thinkTime/time.Millisecond), s.Value)
+			}
+		}
+	}
+}
+
+func TestVUStaggerJitter(t *testing.T) {
+	r, err := getSimpleRunner(t, "/script.js", `exports.default = function() {};`)
+	require.NoError(t, err)
+	require.NoError(t, r.SetOptions(lib.Options{RandomSeed: null.IntFrom(123)}))
+
+	samples := make(chan stats.SampleContainer, 100)
+	initVU, err := r.NewVU(1, samples)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	jitter := 300 * time.Millisecond
+	vu := initVU.Activate(&lib.VUActivationParams{RunContext: ctx, StaggerJitter: jitter})
+
+	// the same delay as the VU's, since it's seeded like for the first iteration
+	expected := time.Duration(rand.New(rand.NewSource(iterationSeed(123, 1, 0))).Int63n(int64(jitter))) //nolint:gosec
+	start := time.Now()
+	require.NoError(t, vu.RunOnce())
+	assert.True(t, time.Since(start) >= expected, "%s < %s", time.Since(start), expected)
+
+	for _, sc := range stats.GetBufferedSamples(samples) {
+		for _, s := range sc.GetSamples() {
+			if s.Metric.Name == metrics.IterationDuration.Name {
+				assert.True(t, s.Value < float64(expected/time.Millisecond), s.Value)
+			}
+		}
+	}
+}
+
+func TestVUMinIterationDuration(t *testing.T) {
+	r, err := getSimpleRunner(t, "/script.js", `exports.default = function() {};`)
+	require.NoError(t, err)
+	minDuration := 200 * time.Millisecond
+	require.NoError(t, r.SetOptions(lib.Options{MinIterationDuration: types.NullDurationFrom(minDuration)}))
+
+	samples := make(chan stats.SampleContainer, 100)
+	initVU, err := r.NewVU(1, samples)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	vu := initVU.Activate(&lib.VUActivationParams{RunContext: ctx})
+	start := time.Now()
+	require.NoError(t, vu.RunOnce())
+	assert.True(t, time.Since(start) >= minDuration)
+
+	for _, sc := range stats.GetBufferedSamples(samples) {
+		for _, s := range sc.GetSamples() {
+			if s.Metric.Name == metrics.IterationDuration.Name {
+				assert.True(t, s.Value < float64(minDuration/time.Millisecond), s.Value)
+			}
+		}
+	}
+
+	// The padding ends when the VU is stopped
+	require.NoError(t, r.SetOptions(lib.Options{MinIterationDuration: types.NullDurationFrom(time.Hour)}))
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+	start = time.Now()
+	require.NoError(t, vu.RunOnce())
+	assert.True(t, time.Since(start) < time.Minute)
+}
+
+func TestVUProxyURL(t *testing.T) {
+	tb := httpmultibin.NewHTTPMultiBin(t)
+	defer tb.Cleanup()
+
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		_, _ = w.Write([]byte("proxied"))
+	}))
+	defer proxy.Close()
+	proxyURL, err := url.Parse(proxy.URL)
+	require.NoError(t, err)
+
+	r, err := getSimpleRunner(t, "/script.js", `
+		var http = require("k6/http");
+		exports.default = function() {
+			var res = http.get(__ENV.URL);
+			if (res.status !== 200 || (res.body === "proxied") !== (__ENV.PROXIED === "true")) {
+				throw new Error("unexpected response: " + res.status + " " + res.body);
+			}
+		}
+	`)
+	require.NoError(t, err)
+	initVU, err := r.NewVU(1, make(chan stats.SampleContainer, 100))
+	require.NoError(t, err)
+
+	// The same VU is activated for a scenario with a proxy and one without
+	ctx, cancel := context.WithCancel(context.Background())
+	deactivated := make(chan struct{})
+	vu := initVU.Activate(&lib.VUActivationParams{
+		RunContext:         ctx,
+		DeactivateCallback: func(lib.InitializedVU) { close(deactivated) },
+		ProxyURL:           proxyURL,
+		Env:                map[string]string{"URL": "http://k6.invalid/path", "PROXIED": "true"},
+	})
+	require.NoError(t, vu.RunOnce())
+	cancel()
+	<-deactivated
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	vu = initVU.Activate(&lib.VUActivationParams{
+		RunContext: ctx,
+		Env:        map[string]string{"URL": tb.Replacer.Replace("HTTPBIN_IP_URL/get"), "PROXIED": "false"},
+	})
+	require.NoError(t, vu.RunOnce())
+
+	assert.Equal(t, []string{"http://k6.invalid/path"}, proxied)
+}
+
+func TestVUMaxRPS(t *testing.T) {
+	tb := httpmultibin.NewHTTPMultiBin(t)
+	defer tb.Cleanup()
+
+	r, err := getSimpleRunner(t, "/script.js", tb.Replacer.Replace(`
+		var http = require("k6/http");
+		var execution = require("k6/execution");
+		exports.default = function() {
+			if (execution.vu.maxRPS !== 10) { throw new Error("wrong maxRPS: " + execution.vu.maxRPS); }
+			for (var i = 0; i < 4; i++) { http.get("HTTPBIN_IP_URL/get"); }
+			execution.vu.maxRPS = 0;
+			if (execution.vu.maxRPS !== 0) { throw new Error("the maxRPS wasn't removed"); }
+			http.get("HTTPBIN_IP_URL/get");
+		}
+	`))
+	require.NoError(t, err)
+	samples := make(chan stats.SampleContainer, 100)
+	initVU, err := r.NewVU(1, samples)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	vu := initVU.Activate(&lib.VUActivationParams{RunContext: ctx, MaxRPS: 10})
+	start := time.Now()
+	require.NoError(t, vu.RunOnce())
+	// The first request takes the burst of the limiter, the others wait 100ms each
+	assert.True(t, time.Since(start) >= 300*time.Millisecond, time.Since(start))
+
+	var queued []float64
+	for _, sampleC := range stats.GetBufferedSamples(samples) {
+		for _, sample := range sampleC.GetSamples() {
+			if sample.Metric.Name == "http_req_queued" {
+				queued = append(queued, sample.Value)
+			}
+		}
+	}
+	require.Len(t, queued, 4)
+	assert.True(t, queued[3] > 50, queued)
+}
+
+func TestVUMaxRPSAsyncBatch(t *testing.T) {
+	tb := httpmultibin.NewHTTPMultiBin(t)
+	defer tb.Cleanup()
+
+	// The async batch requests use the rate limit they were made with, even
+	// if it's changed or removed while they are running
+	r, err := getSimpleRunner(t, "/script.js", tb.Replacer.Replace(`
+		var http = require("k6/http");
+		var execution = require("k6/execution");
+		exports.options = { batch: 4 };
+		exports.default = function() {
+			execution.vu.maxRPS = 20;
+			var handle = http.asyncBatch(["HTTPBIN_IP_URL/get", "HTTPBIN_IP_URL/get", "HTTPBIN_IP_URL/get", "HTTPBIN_IP_URL/get"]);
+			for (var i = 0; i < 100; i++) { execution.vu.maxRPS = i % 2 ? 1000 : 0; }
+			handle.wait();
+		}
+	`))
+	require.NoError(t, err)
+	initVU, err := r.NewVU(1, make(chan stats.SampleContainer, 100))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	vu := initVU.Activate(&lib.VUActivationParams{RunContext: ctx})
+	start := time.Now()
+	require.NoError(t, vu.RunOnce())
+	// The first request takes the burst of the limiter, the others wait 50ms each
+	assert.True(t, time.Since(start) >= 150*time.Millisecond, time.Since(start))
+}
+
+func TestVUTrustedProxyCAs(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	fs := afero.NewMemMapFs()
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	require.NoError(t, afero.WriteFile(fs, "/path/to/ca.pem", caPEM, 0o644))
+
+	r, err := getSimpleRunner(t, "/path/to/script.js", `
+		var http = require("k6/http");
+		exports.default = function() { http.get(__ENV.URL); }
+	`, fs)
+	require.NoError(t, err)
+
+	run := func() error {
+		initVU, err := r.NewVU(1, make(chan stats.SampleContainer, 100))
+		require.NoError(t, err)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		vu := initVU.Activate(&lib.VUActivationParams{RunContext: ctx, Env: map[string]string{"URL": srv.URL}})
+		return vu.RunOnce()
+	}
+
+	require.NoError(t, r.SetOptions(lib.Options{Throw: null.BoolFrom(true)}))
+	err = run()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "x509: certificate signed by unknown authority")
+
+	require.NoError(t, r.SetOptions(lib.Options{
+		Throw:           null.BoolFrom(true),
+		TrustedProxyCAs: []string{"ca.pem"},
+	}))
+	require.NoError(t, run())
+
+	arc := r.MakeArchive()
+	data, err := afero.ReadFile(arc.Filesystems["file"], "/path/to/ca.pem")
+	require.NoError(t, err)
+	assert.Equal(t, caPEM, data)
+
+	err = r.SetOptions(lib.Options{TrustedProxyCAs: []string{"/path/to/missing.pem"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "couldn't read the trusted proxy CA")
+}
+
+func TestVUTLSCipherSuites(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	srv.TLS = &tls.Config{
+		CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384},
+		MaxVersion:   tls.VersionTLS12,
+	}
+	srv.StartTLS()
+	defer srv.Close()
+
+	r, err := getSimpleRunner(t, "/script.js", `
+		var http = require("k6/http");
+		exports.default = function() { http.get(__ENV.URL); }
+	`)
+	require.NoError(t, err)
+
+	testdata := map[string]struct {
+		suites lib.TLSCipherSuites
+		errMsg string
+	}{
+		"Empty":       {lib.TLSCipherSuites{}, ""},
+		"Overlapping": {lib.TLSCipherSuites{tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384}, ""},
+		"NonOverlapping": {
+			lib.TLSCipherSuites{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256},
+			"remote error: tls: handshake failure",
+		},
+	}
+	for name, data := range testdata {
+		data := data
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, r.SetOptions(lib.Options{
+				Throw:                 null.BoolFrom(true),
+				InsecureSkipTLSVerify: null.BoolFrom(true),
+				TLSCipherSuites:       &data.suites,
+			}))
+
+			initVU, err := r.NewVU(1, make(chan stats.SampleContainer, 100))
+			require.NoError(t, err)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			vu := initVU.Activate(&lib.VUActivationParams{RunContext: ctx, Env: map[string]string{"URL": srv.URL}})
+			err = vu.RunOnce()
+			if data.errMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), data.errMsg)
+			}
+		})
+	}
+}
+
+func TestVUTraceContext(t *testing.T) {
+	tb := httpmultibin.NewHTTPMultiBin(t)
+	defer tb.Cleanup()
+
+	notPropagated := `
+			if (execution.vu.traceId !== "") { throw new Error("unexpected trace ID: " + execution.vu.traceId); }
+			var headers = http.get("HTTPBIN_URL/headers").json().headers;
+			if (headers["Traceparent"] !== undefined) { throw new Error("unexpected traceparent: " + headers["Traceparent"]); }
+		`
+	testCases := map[string]string{
+		"default": `
+			var traceId = execution.vu.traceId;
+			if (!/^[0-9a-f]{32}$/.test(traceId)) { throw new Error("invalid trace ID: " + traceId); }
+			var headers = http.get("HTTPBIN_URL/headers").json().headers;
+			if (headers["Traceparent"][0].split("-")[1] !== traceId) { throw new Error("invalid traceparent: " + headers["Traceparent"]); }
+			if (headers["Tracestate"] !== undefined) { throw new Error("unexpected tracestate: " + headers["Tracestate"]); }
+		`,
+		"none":        notPropagated,
+		"not sampled": notPropagated,
+		"sampled": `
+			var traceId = execution.vu.traceId;
+			if (!/^[0-9a-f]{32}$/.test(traceId)) { throw new Error("invalid trace ID: " + traceId); }
+			if (execution.vu.iterationTraceId !== traceId) { throw new Error("invalid iterationTraceId: " + execution.vu.iterationTraceId); }
+			if (traceIds[traceId]) { throw new Error("repeated trace ID: " + traceId); }
+			traceIds[traceId] = true;
+
+			var parents = [];
+			for (var i = 0; i < 2; i++) {
+				var headers = http.get("HTTPBIN_URL/headers").json().headers;
+				var parts = headers["Traceparent"][0].split("-");
+				if (parts.length !== 4 || parts[0] !== "00" || parts[1] !== traceId || parts[3] !== "01" ||
+					!/^[0-9a-f]{16}$/.test(parts[2])) {
+					throw new Error("invalid traceparent: " + headers["Traceparent"]);
+				}
+				if (headers["Tracestate"][0] !== "k6=test") { throw new Error("invalid tracestate: " + headers["Tracestate"]); }
+				parents.push(parts[2]);
+			}
+			if (parents[0] === parents[1]) { throw new Error("repeated parent ID: " + parents[0]); }
+
+			var custom = http.get("HTTPBIN_URL/headers", { headers: { traceparent: "custom" } }).json().headers;
+			if (custom["Traceparent"][0] !== "custom") { throw new Error("overwritten traceparent: " + custom["Traceparent"]); }
+		`,
+		"x-request-id": `
+			var traceId = execution.vu.traceId;
+			for (var i = 0; i < 2; i++) {
+				var headers = http.get("HTTPBIN_URL/headers").json().headers;
+				if (headers["X-Request-Id"][0] !== traceId) { throw new Error("invalid X-Request-ID: " + headers["X-Request-Id"]); }
+				if (headers["Traceparent"] !== undefined) { throw new Error("unexpected traceparent: " + headers["Traceparent"]); }
+			}
+			var custom = http.get("HTTPBIN_URL/headers", { headers: { "X-Request-ID": "custom" } }).json().headers;
+			if (custom["X-Request-Id"][0] !== "custom") { throw new Error("overwritten X-Request-ID: " + custom["X-Request-Id"]); }
+		`,
+	}
+	for name, code := range testCases {
+		code := code
+		options := `{ traceContext: { propagator: "w3c", sampleRate: 1, traceState: "k6=test" } }`
+		switch name {
+		case "default":
+			options = "{}"
+		case "none":
+			options = `{ traceContext: { propagator: "none" } }`
+		case "not sampled":
+			options = `{ traceContext: { sampleRate: 0 } }`
+		case "x-request-id":
+			options = `{ traceContext: { propagator: "x-request-id" } }`
+		}
+		t.Run(name, func(t *testing.T) {
+			r, err := getSimpleRunner(t, "/script.js", tb.Replacer.Replace(fmt.Sprintf(`
+				var http = require("k6/http");
+				var execution = require("k6/execution");
+				exports.options = %s;
+				if (execution.vu.traceId !== undefined) { throw new Error("trace ID in the init context"); }
+				var traceIds = {};
+				exports.default = function() {
+					if (execution.vu.id !== 1) { throw new Error("unexpected VU ID: " + execution.vu.id); }
+					%s
+				};
+			`, options, code)))
+			require.NoError(t, err)
+			require.NoError(t, r.SetOptions(r.GetOptions().Apply(lib.Options{Hosts: tb.Dialer.Hosts})))
+
+			initVU, err := r.NewVU(1, make(chan stats.SampleContainer, 100))
+			require.NoError(t, err)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			vu := initVU.Activate(&lib.VUActivationParams{RunContext: ctx})
+			for i := 0; i < 3; i++ {
+				require.NoError(t, vu.RunOnce())
+			}
+		})
+	}
+}
+
+func TestVUTraceContextSeededSampling(t *testing.T) {
+	r, err := getSimpleRunner(t, "/script.js", `
+		var execution = require("k6/execution");
+		exports.options = { traceContext: { sampleRate: 0.5 }, randomSeed: 123 };
+		var sampled = [];
+		exports.default = function() { sampled.push(execution.vu.traceId !== ""); };
+	`)
+	require.NoError(t, err)
+
+	// The same VU samples the same iterations, since the randomSeed is set
+	getSampled := func() []bool {
+		initVU, err := r.NewVU(1, make(chan stats.SampleContainer, 100))
+		require.NoError(t, err)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		vu := initVU.Activate(&lib.VUActivationParams{RunContext: ctx})
+		for i := 0; i < 20; i++ {
+			require.NoError(t, vu.RunOnce())
+		}
+		var result []bool
+		require.NoError(t, initVU.(*VU).Runtime.ExportTo(initVU.(*VU).Runtime.Get("sampled"), &result))
+		return result
+	}
+	first := getSampled()
+	assert.Equal(t, first, getSampled())
+	assert.Contains(t, first, true)
+	assert.Contains(t, first, false)
+}
+
 func TestVUIntegrationVUID(t *testing.T) {
 	r1, err := getSimpleRunner(t, "/script.js", `
 			exports.default = function() {
@@ -1664,3 +2179,33 @@ func TestSystemTags(t *testing.T) {
 		})
 	}
 }
+
+func TestVURandomSeed(t *testing.T) {
+	r, err := getSimpleRunner(t, "/script.js", `
+		var faker = require("k6/faker");
+		exports.options = { randomSeed: 42 };
+		exports.values = [];
+		exports.default = function() { exports.values.push(faker.uuid()); }
+		`)
+	require.NoError(t, err)
+
+	run := func(vuID int64, iterations int) []string {
+		vu, err := r.newVU(vuID, make(chan stats.SampleContainer, 100))
+		require.NoError(t, err)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		activeVU := vu.Activate(&lib.VUActivationParams{RunContext: ctx})
+		for i := 0; i < iterations; i++ {
+			require.NoError(t, activeVU.RunOnce())
+		}
+		var values []string
+		require.NoError(t, vu.Runtime.ExportTo(vu.Runtime.Get("exports").ToObject(vu.Runtime).Get("values"), &values))
+		return values
+	}
+
+	first := run(1, 3)
+	require.Len(t, first, 3)
+	assert.NotEqual(t, first[0], first[1], "every iteration should get different values")
+	assert.Equal(t, first, run(1, 3), "the values should be the same for the same VU and iterations")
+	assert.NotEqual(t, first, run(2, 3), "the values should be different for another VU")
+}
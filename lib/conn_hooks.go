@@ -0,0 +1,88 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package lib
+
+import "sync"
+
+// The types of connection events.
+const (
+	ConnEventConnect    = "connect"
+	ConnEventDisconnect = "disconnect"
+)
+
+// ConnEvent is a connection of a VU being established or closed.
+type ConnEvent struct {
+	Type       string `json:"type" js:"type"`
+	RemoteAddr string `json:"remoteAddr" js:"remoteAddr"`
+	LocalAddr  string `json:"localAddr" js:"localAddr"`
+	Protocol   string `json:"protocol" js:"protocol"` // the network, e.g. "tcp"
+}
+
+// ConnHooks calls handlers for the connection events of a VU. The events are
+// recorded by the dialer, from whatever goroutine opens or closes the
+// connection, and the handlers are called later by Dispatch(), from the VU
+// goroutine. Events are only recorded while there are handlers.
+type ConnHooks struct {
+	mu       sync.Mutex
+	pending  []ConnEvent
+	handlers map[string]func(ConnEvent)
+}
+
+// Record saves the event, if there is a handler for it.
+func (h *ConnHooks) Record(event ConnEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.handlers[event.Type] != nil {
+		h.pending = append(h.pending, event)
+	}
+}
+
+// SetHandler sets the handler for the given type of events, replacing the
+// previous one. A nil handler removes it.
+func (h *ConnHooks) SetHandler(eventType string, handler func(ConnEvent)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if handler == nil {
+		delete(h.handlers, eventType)
+		return
+	}
+	if h.handlers == nil {
+		h.handlers = make(map[string]func(ConnEvent))
+	}
+	h.handlers[eventType] = handler
+}
+
+// Dispatch calls the handlers for all of the recorded events, in order.
+func (h *ConnHooks) Dispatch() {
+	h.mu.Lock()
+	pending, handlers := h.pending, make(map[string]func(ConnEvent), len(h.handlers))
+	for eventType, handler := range h.handlers {
+		handlers[eventType] = handler
+	}
+	h.pending = nil
+	h.mu.Unlock()
+
+	for _, event := range pending {
+		if handler := handlers[event.Type]; handler != nil {
+			handler(event)
+		}
+	}
+}
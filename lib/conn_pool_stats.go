@@ -0,0 +1,101 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package lib
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+)
+
+// ConnPoolStats counts the connections of the HTTP connection pools of the
+// VUs, so they can be emitted as metrics. The connections that are open but
+// not used by any request are considered idle. It's safe for concurrent use.
+type ConnPoolStats struct {
+	opened, open, active int64
+}
+
+// ConnOpened records a newly established connection.
+func (s *ConnPoolStats) ConnOpened() {
+	atomic.AddInt64(&s.opened, 1)
+	atomic.AddInt64(&s.open, 1)
+}
+
+// ConnClosed records a closed connection.
+func (s *ConnPoolStats) ConnClosed() {
+	atomic.AddInt64(&s.open, -1)
+}
+
+// ConnAcquired records that a request got a connection from the pool.
+func (s *ConnPoolStats) ConnAcquired() {
+	atomic.AddInt64(&s.active, 1)
+}
+
+// ConnReleased records that a request that had a connection is done.
+func (s *ConnPoolStats) ConnReleased() {
+	atomic.AddInt64(&s.active, -1)
+}
+
+// Used returns whether any connection was established yet.
+func (s *ConnPoolStats) Used() bool {
+	return atomic.LoadInt64(&s.opened) > 0
+}
+
+// Get returns the current numbers of idle and active connections. With
+// HTTP/2 a connection can be used by multiple requests at the same time, so
+// the active ones can be more than the open ones.
+func (s *ConnPoolStats) Get() (idle, active int64) {
+	open, active := atomic.LoadInt64(&s.open), atomic.LoadInt64(&s.active)
+	if idle = open - active; idle < 0 {
+		idle = 0
+	}
+	return idle, active
+}
+
+// WrapDialContext wraps the DialContext function of an HTTP transport, so the
+// connections it opens are counted until they are closed. Only the transport
+// dials should be wrapped, the other connections of the VU aren't pooled.
+func (s *ConnPoolStats) WrapDialContext(
+	dial func(ctx context.Context, network, addr string) (net.Conn, error),
+) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return conn, err
+		}
+		s.ConnOpened()
+		return &pooledConn{Conn: conn, stats: s}, nil
+	}
+}
+
+// pooledConn records when a connection of an HTTP transport is closed.
+type pooledConn struct {
+	net.Conn
+	stats     *ConnPoolStats
+	closeOnce sync.Once
+}
+
+func (c *pooledConn) Close() error {
+	err := c.Conn.Close()
+	c.closeOnce.Do(c.stats.ConnClosed)
+	return err
+}
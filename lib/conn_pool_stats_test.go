@@ -0,0 +1,58 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package lib
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnPoolStatsWrapDialContext(t *testing.T) {
+	t.Parallel()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+
+	poolStats := &ConnPoolStats{}
+	dial := poolStats.WrapDialContext((&net.Dialer{}).DialContext)
+	assert.False(t, poolStats.Used())
+
+	// Failed dials aren't counted
+	_, err = dial(context.Background(), "tcp", "127.0.0.1:0")
+	require.Error(t, err)
+	assert.False(t, poolStats.Used())
+
+	conn, err := dial(context.Background(), "tcp", listener.Addr().String())
+	require.NoError(t, err)
+	assert.True(t, poolStats.Used())
+	idle, active := poolStats.Get()
+	assert.Equal(t, int64(1), idle)
+	assert.Equal(t, int64(0), active)
+
+	require.NoError(t, conn.Close())
+	_ = conn.Close() // closing it again isn't counted
+	idle, _ = poolStats.Get()
+	assert.Equal(t, int64(0), idle)
+}
@@ -48,6 +48,46 @@ func FullVersion() string {
 	return fmt.Sprintf("%s (dev build, %s)", Version, goVersionArch)
 }
 
+// VersionInfoSchema is the version of the VersionInfo JSON schema. It should
+// only be bumped when fields are changed or removed, not when they are added.
+const VersionInfoSchema = 1
+
+// VersionInfo is the machine-readable version and build information for the
+// currently running k6 executable, printed by `k6 version --json`.
+type VersionInfo struct {
+	SchemaVersion int    `json:"schema_version"`
+	Version       string `json:"version"`
+	GoVersion     string `json:"go_version"`
+	GitCommit     string `json:"git_commit"`
+	BuildDate     string `json:"build_date"`
+	Platform      string `json:"platform"`
+}
+
+// GetVersionInfo returns the VersionInfo of the currently running k6
+// executable. The git commit and build date are parsed from VersionDetails,
+// which the release builds set to "<build date>/<git describe output>", so
+// they are empty for other builds.
+func GetVersionInfo() VersionInfo {
+	info := VersionInfo{
+		SchemaVersion: VersionInfoSchema,
+		Version:       Version,
+		GoVersion:     runtime.Version(),
+		Platform:      runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	if i := strings.Index(VersionDetails, "/"); i >= 0 {
+		info.BuildDate = VersionDetails[:i]
+		// e.g. "v0.28.0-3-g6ef5e8f-dirty", or just "6ef5e8f" if there are no tags
+		describe := strings.TrimSuffix(VersionDetails[i+1:], "-dirty")
+		if j := strings.LastIndex(describe, "-g"); j >= 0 {
+			describe = describe[j+2:]
+		}
+		info.GitCommit = describe
+	}
+
+	return info
+}
+
 // Banner returns the ASCII-art banner with the k6 logo and stylized website URL
 func Banner() string {
 	banner := strings.Join([]string{
@@ -26,6 +26,9 @@ type ctxKey int
 
 const (
 	ctxKeyState ctxKey = iota
+	ctxKeyRequestCounter
+	ctxKeyInterrupt
+	ctxKeyIPVersion
 )
 
 func WithState(ctx context.Context, state *State) context.Context {
@@ -39,3 +42,48 @@ func GetState(ctx context.Context) *State {
 	}
 	return v.(*State)
 }
+
+// WithRequestCounter returns a context with the counter of the HTTP requests
+// that are made with it.
+func WithRequestCounter(ctx context.Context, counter *RequestCounter) context.Context {
+	return context.WithValue(ctx, ctxKeyRequestCounter, counter)
+}
+
+// GetRequestCounter returns the request counter of the context, if it has one.
+func GetRequestCounter(ctx context.Context) *RequestCounter {
+	v := ctx.Value(ctxKeyRequestCounter)
+	if v == nil {
+		return nil
+	}
+	return v.(*RequestCounter)
+}
+
+// WithInterrupt returns a context with a channel that is closed when the test
+// run is interrupted, e.g. by Ctrl+C. The scenarios then stop starting new
+// iterations, and the running ones have the graceful stop period of their
+// scenario to finish, instead of being stopped immediately.
+func WithInterrupt(ctx context.Context, interrupt <-chan struct{}) context.Context {
+	return context.WithValue(ctx, ctxKeyInterrupt, interrupt)
+}
+
+// GetInterrupt returns the interrupt channel of the context, or nil if it
+// doesn't have one, which blocks forever.
+func GetInterrupt(ctx context.Context) <-chan struct{} {
+	v := ctx.Value(ctxKeyInterrupt)
+	if v == nil {
+		return nil
+	}
+	return v.(<-chan struct{})
+}
+
+// WithIPVersion returns a context with the IP version that the connections
+// dialed with it are restricted to, overriding the ipVersion option.
+func WithIPVersion(ctx context.Context, ipVersion string) context.Context {
+	return context.WithValue(ctx, ctxKeyIPVersion, ipVersion)
+}
+
+// GetIPVersion returns the IP version of the context, if it has one.
+func GetIPVersion(ctx context.Context) string {
+	v, _ := ctx.Value(ctxKeyIPVersion).(string)
+	return v
+}
@@ -22,12 +22,15 @@ package executor
 
 import (
 	"fmt"
+	"net/url"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
 	"gopkg.in/guregu/null.v3"
 
+	"github.com/loadimpact/k6/lib"
 	"github.com/loadimpact/k6/lib/consts"
 	"github.com/loadimpact/k6/lib/types"
 )
@@ -47,12 +50,34 @@ type BaseConfig struct {
 	StartTime    types.NullDuration `json:"startTime"`
 	GracefulStop types.NullDuration `json:"gracefulStop"`
 	Env          map[string]string  `json:"env"`
-	Exec         null.String        `json:"exec"` // function name, externally validated
+	Exec         null.String        `json:"exec"`        // function name, externally validated
+	Probability  map[string]float64 `json:"probability"` // function names, externally validated
 	Tags         map[string]string  `json:"tags"`
+	ThinkTime    *lib.ThinkTime     `json:"thinkTime"`
+	ProxyURL     null.String        `json:"proxyUrl"` // instead of the one from the environment variables
+	MaxRPS       null.Float         `json:"maxRPS"`   // the rate limit of the HTTP requests of each VU
+
+	// The scenario is delayed by the startTimeOffset, in addition to its
+	// startTime. With staggerVus, it starts at its startTime instead, and the
+	// first iterations of its VUs are evenly spread over the startTimeOffset.
+	// The VUs are also delayed by a random staggerJitter, if it's specified.
+	StartTimeOffset types.NullDuration `json:"startTimeOffset"`
+	StaggerVUs      null.Bool          `json:"staggerVus"`
+	StaggerJitter   types.NullDuration `json:"staggerJitter"`
+
+	// The scenario isn't started before the scenarios it depends on have
+	// finished, and only if their iterations had no more than maxErrors errors.
+	DependsOn     []string       `json:"dependsOn"`
+	DependsOnWith *DependsOnWith `json:"dependsOnWith"`
 
 	// TODO: future extensions like distribution, others?
 }
 
+// DependsOnWith is the condition on the dependencies of a scenario.
+type DependsOnWith struct {
+	MaxErrors null.Int `json:"maxErrors"`
+}
+
 // NewBaseConfig returns a default base config with the default values
 func NewBaseConfig(name, configType string) BaseConfig {
 	return BaseConfig{
@@ -75,6 +100,35 @@ func (bc BaseConfig) Validate() (errors []error) {
 	if bc.Exec.Valid && bc.Exec.String == "" {
 		errors = append(errors, fmt.Errorf("exec value cannot be empty"))
 	}
+	if bc.Probability != nil {
+		if bc.Exec.Valid {
+			errors = append(errors, fmt.Errorf("exec and probability can't be used together"))
+		}
+		if len(bc.Probability) == 0 {
+			errors = append(errors, fmt.Errorf("probability should contain at least one function"))
+		}
+		for exec, p := range bc.Probability {
+			if exec == "" {
+				errors = append(errors, fmt.Errorf("probability function names cannot be empty"))
+			}
+			if p <= 0 {
+				errors = append(errors, fmt.Errorf("the probability of function '%s' should be positive", exec))
+			}
+		}
+	}
+	if bc.ThinkTime != nil {
+		errors = append(errors, bc.ThinkTime.Validate()...)
+	}
+	if bc.ProxyURL.Valid {
+		if u, err := url.Parse(bc.ProxyURL.String); err != nil {
+			errors = append(errors, fmt.Errorf("invalid proxyUrl: %w", err))
+		} else if u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5" {
+			errors = append(errors, fmt.Errorf("the proxyUrl scheme should be http, https or socks5"))
+		}
+	}
+	if bc.MaxRPS.Valid && bc.MaxRPS.Float64 <= 0 {
+		errors = append(errors, fmt.Errorf("the maxRPS should be positive"))
+	}
 	if bc.Type == "" {
 		errors = append(errors, fmt.Errorf("missing or empty type field"))
 	}
@@ -85,6 +139,40 @@ func (bc BaseConfig) Validate() (errors []error) {
 	if bc.GracefulStop.Duration < 0 {
 		errors = append(errors, fmt.Errorf("the gracefulStop timeout can't be negative"))
 	}
+	if bc.StartTimeOffset.Duration < 0 {
+		errors = append(errors, fmt.Errorf("the startTimeOffset can't be negative"))
+	}
+	if bc.StaggerVUs.Bool && bc.StartTimeOffset.Duration <= 0 {
+		errors = append(errors, fmt.Errorf("staggerVus requires a startTimeOffset to spread the VUs over"))
+	}
+	if bc.StaggerJitter.Duration < 0 {
+		errors = append(errors, fmt.Errorf("the staggerJitter can't be negative"))
+	}
+	for _, dep := range bc.DependsOn {
+		if dep == bc.Name {
+			errors = append(errors, fmt.Errorf("the scenario can't depend on itself"))
+		}
+	}
+	if bc.DependsOnWith != nil {
+		if len(bc.DependsOn) == 0 {
+			errors = append(errors, fmt.Errorf("dependsOnWith requires dependsOn"))
+		}
+		if bc.DependsOnWith.MaxErrors.Int64 < 0 {
+			errors = append(errors, fmt.Errorf("the dependsOnWith maxErrors can't be negative"))
+		}
+	}
+	return errors
+}
+
+// validateNoStaggering is used by the executors that don't start all of their
+// VUs at once, so the VU starts can't be staggered.
+func (bc BaseConfig) validateNoStaggering() (errors []error) {
+	if bc.StaggerVUs.Bool {
+		errors = append(errors, fmt.Errorf("staggerVus is not supported by the %s executor", bc.Type))
+	}
+	if bc.StaggerJitter.Valid {
+		errors = append(errors, fmt.Errorf("staggerJitter is not supported by the %s executor", bc.Type))
+	}
 	return errors
 }
 
@@ -101,7 +189,36 @@ func (bc BaseConfig) GetType() string {
 // GetStartTime returns the starting time, relative to the beginning of the
 // actual test, that this executor is supposed to execute.
 func (bc BaseConfig) GetStartTime() time.Duration {
-	return time.Duration(bc.StartTime.Duration)
+	if bc.StaggerVUs.Bool {
+		return time.Duration(bc.StartTime.Duration)
+	}
+	return time.Duration(bc.StartTime.Duration + bc.StartTimeOffset.Duration)
+}
+
+// getStaggerDelay returns how long the VU with the given index, out of all of
+// the VUs that the executor starts at once, should wait before its first
+// iteration. The random staggerJitter is added to it by the VU itself, since it
+// has to come from the random number generator of the VU.
+func (bc BaseConfig) getStaggerDelay(vuIndex, numVUs int64) time.Duration {
+	if !bc.StaggerVUs.Bool || numVUs <= 0 {
+		return 0
+	}
+	return time.Duration(int64(bc.StartTimeOffset.Duration) * vuIndex / numVUs)
+}
+
+// GetDependsOn returns the names of the scenarios that have to finish before
+// this one is started.
+func (bc BaseConfig) GetDependsOn() []string {
+	return bc.DependsOn
+}
+
+// GetDependencyMaxErrors returns how many iteration errors each of the
+// dependencies can have for this scenario to still be started, 0 by default.
+func (bc BaseConfig) GetDependencyMaxErrors() uint64 {
+	if bc.DependsOnWith == nil {
+		return 0
+	}
+	return uint64(bc.DependsOnWith.MaxErrors.Int64)
 }
 
 // GetGracefulStop returns how long k6 is supposed to wait for any still
@@ -129,6 +246,35 @@ func (bc BaseConfig) GetExec() string {
 	return exec
 }
 
+// GetExecProbabilities returns the functions that should be randomly picked
+// to be executed in each iteration, sorted by name, with their probabilities
+// normalized so that they sum up to 1.
+func (bc BaseConfig) GetExecProbabilities() []lib.ExecProbability {
+	if len(bc.Probability) == 0 {
+		return nil
+	}
+	var total float64
+	for _, p := range bc.Probability {
+		total += p
+	}
+	result := make([]lib.ExecProbability, 0, len(bc.Probability))
+	for exec, p := range bc.Probability {
+		result = append(result, lib.ExecProbability{Exec: exec, Probability: p / total})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Exec < result[j].Exec })
+	return result
+}
+
+// GetProxyURL returns the proxy that the HTTP requests of the executor are
+// made through, or nil if they should use the one from the environment.
+func (bc BaseConfig) GetProxyURL() *url.URL {
+	if !bc.ProxyURL.Valid {
+		return nil
+	}
+	u, _ := url.Parse(bc.ProxyURL.String) // it's checked by Validate()
+	return u
+}
+
 // GetTags returns any custom tags configured for the executor.
 func (bc BaseConfig) GetTags() map[string]string {
 	return bc.Tags
@@ -145,9 +291,37 @@ func (bc BaseConfig) getBaseInfo(facts ...string) string {
 	if bc.Exec.Valid {
 		facts = append(facts, fmt.Sprintf("exec: %s", bc.Exec.String))
 	}
+	if probs := bc.GetExecProbabilities(); len(probs) > 0 {
+		execs := make([]string, len(probs))
+		for i, p := range probs {
+			execs[i] = fmt.Sprintf("%s %.f%%", p.Exec, p.Probability*100)
+		}
+		facts = append(facts, fmt.Sprintf("exec: %s", strings.Join(execs, ", ")))
+	}
+	if bc.ThinkTime != nil {
+		facts = append(facts, fmt.Sprintf("thinkTime: %s", bc.ThinkTime))
+	}
+	if u := bc.GetProxyURL(); u != nil {
+		facts = append(facts, fmt.Sprintf("proxyUrl: %s", u.Redacted()))
+	}
+	if bc.MaxRPS.Valid {
+		facts = append(facts, fmt.Sprintf("maxRPS: %g", bc.MaxRPS.Float64))
+	}
 	if bc.StartTime.Duration > 0 {
 		facts = append(facts, fmt.Sprintf("startTime: %s", bc.StartTime.Duration))
 	}
+	if bc.StartTimeOffset.Duration > 0 {
+		facts = append(facts, fmt.Sprintf("startTimeOffset: %s", bc.StartTimeOffset.Duration))
+	}
+	if bc.StaggerVUs.Bool {
+		facts = append(facts, "staggerVus")
+	}
+	if bc.StaggerJitter.Duration > 0 {
+		facts = append(facts, fmt.Sprintf("staggerJitter: %s", bc.StaggerJitter.Duration))
+	}
+	if len(bc.DependsOn) > 0 {
+		facts = append(facts, fmt.Sprintf("dependsOn: %s", strings.Join(bc.DependsOn, ", ")))
+	}
 	if bc.GracefulStop.Duration > 0 {
 		facts = append(facts, fmt.Sprintf("gracefulStop: %s", bc.GracefulStop.Duration))
 	}
@@ -23,6 +23,7 @@ package executor
 import (
 	"context"
 	"strconv"
+	"sync/atomic"
 
 	"github.com/sirupsen/logrus"
 
@@ -40,6 +41,8 @@ type BaseExecutor struct {
 	executionState *lib.ExecutionState
 	logger         *logrus.Entry
 	progress       *pb.ProgressBar
+
+	iterationErrors uint64 // accessed atomically
 }
 
 // NewBaseExecutor returns an initialized BaseExecutor
@@ -76,6 +79,11 @@ func (bs BaseExecutor) GetProgress() *pb.ProgressBar {
 	return bs.progress
 }
 
+// GetIterationErrors returns the number of iterations that ended with an error.
+func (bs *BaseExecutor) GetIterationErrors() uint64 {
+	return atomic.LoadUint64(&bs.iterationErrors)
+}
+
 // getMetricTags returns a tag set that can be used to emit metrics by the
 // executor. The VU ID is optional.
 func (bs BaseExecutor) getMetricTags(vuID *int64) *stats.SampleTags {
@@ -110,7 +110,7 @@ func (carc ConstantArrivalRateConfig) GetDescription(et *lib.ExecutionTuple) str
 
 // Validate makes sure all options are configured and valid
 func (carc *ConstantArrivalRateConfig) Validate() []error {
-	errors := carc.BaseConfig.Validate()
+	errors := append(carc.BaseConfig.Validate(), carc.validateNoStaggering()...)
 	if !carc.Rate.Valid {
 		errors = append(errors, fmt.Errorf("the iteration rate isn't specified"))
 	} else if carc.Rate.Int64 <= 0 {
@@ -313,7 +313,7 @@ func (car ConstantArrivalRate) Run(parentCtx context.Context, out chan<- stats.S
 	car.progress.Modify(pb.WithProgress(progressFn))
 	go trackProgress(parentCtx, maxDurationCtx, regDurationCtx, &car, progressFn)
 
-	runIterationBasic := getIterationRunner(car.executionState, car.logger)
+	runIterationBasic := getIterationRunner(car.executionState, car.logger, &car.iterationErrors)
 	runIteration := func(vu lib.ActiveVU) {
 		runIterationBasic(maxDurationCtx, vu)
 		activeVUs <- vu
@@ -174,14 +174,14 @@ func (clv ConstantVUs) Run(parentCtx context.Context, out chan<- stats.SampleCon
 	defer activeVUs.Wait()
 
 	regDurationDone := regDurationCtx.Done()
-	runIteration := getIterationRunner(clv.executionState, clv.logger)
+	runIteration := getIterationRunner(clv.executionState, clv.logger, &clv.iterationErrors)
 
 	activationParams := getVUActivationParams(maxDurationCtx, clv.config.BaseConfig,
 		func(u lib.InitializedVU) {
 			clv.executionState.ReturnVU(u, true)
 			activeVUs.Done()
 		})
-	handleVU := func(initVU lib.InitializedVU) {
+	handleVU := func(initVU lib.InitializedVU, staggerDelay time.Duration) {
 		ctx, cancel := context.WithCancel(maxDurationCtx)
 		defer cancel()
 
@@ -189,6 +189,7 @@ func (clv ConstantVUs) Run(parentCtx context.Context, out chan<- stats.SampleCon
 		newParams.RunContext = ctx
 
 		activeVU := initVU.Activate(&newParams)
+		waitForStaggerDelay(regDurationCtx, staggerDelay)
 
 		for {
 			select {
@@ -208,7 +209,7 @@ func (clv ConstantVUs) Run(parentCtx context.Context, out chan<- stats.SampleCon
 			return err
 		}
 		activeVUs.Add(1)
-		go handleVU(initVU)
+		go handleVU(initVU, clv.config.getStaggerDelay(i, numVUs))
 	}
 
 	return nil
@@ -76,3 +76,41 @@ func TestConstantVUsRun(t *testing.T) {
 	})
 	assert.Equal(t, uint64(50), totalIters)
 }
+
+func TestConstantVUsRunStaggered(t *testing.T) {
+	t.Parallel()
+	var firstIters sync.Map
+	et, err := lib.NewExecutionTuple(nil, nil)
+	require.NoError(t, err)
+	es := lib.NewExecutionState(lib.Options{}, et, 10, 50)
+	config := getTestConstantVUsConfig()
+	config.StartTimeOffset = types.NullDurationFrom(500 * time.Millisecond)
+	config.StaggerVUs = null.BoolFrom(true)
+	start := time.Now()
+	var ctx, cancel, executor, _ = setupExecutor(
+		t, config, es,
+		simpleRunner(func(ctx context.Context) error {
+			firstIters.LoadOrStore(lib.GetState(ctx).Vu, time.Since(start))
+			time.Sleep(100 * time.Millisecond)
+			return nil
+		}),
+	)
+	defer cancel()
+	err = executor.Run(ctx, nil)
+	require.NoError(t, err)
+
+	var min, max time.Duration = time.Hour, 0
+	firstIters.Range(func(key, value interface{}) bool {
+		d := value.(time.Duration)
+		if d < min {
+			min = d
+		}
+		if d > max {
+			max = d
+		}
+		return true
+	})
+	assert.True(t, min < 100*time.Millisecond, min)
+	assert.True(t, max >= 450*time.Millisecond, max)
+	assert.Equal(t, time.Duration(0), config.GetStartTime())
+}
@@ -0,0 +1,186 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/loadimpact/k6/lib"
+	"github.com/loadimpact/k6/lib/types"
+)
+
+// ExecutorFactory creates a new custom executor for a scenario.
+//
+// The returned lib.Executor has the same lifecycle as the built-in ones: Init()
+// is called once before the test starts, and Run() is called at the scenario's
+// startTime. Run() should return once the scenario duration and graceful stop
+// have passed or the context is done, at the latest. The execution state is
+// the VU pool of the test run - it has config.VUs pre-initialized VUs, which
+// can be taken with GetPlannedVU(), and up to config.MaxVUs in total, where
+// the rest can be initialized with GetUnplannedVU(). All VUs should be given
+// back with ReturnVU() before Run() returns.
+//
+// Custom executors can embed the BaseExecutor returned by NewBaseExecutor().
+type ExecutorFactory func(config CustomConfig, es *lib.ExecutionState, logger *logrus.Entry) (lib.Executor, error)
+
+// RegisterExecutor registers a custom executor, which can then be used in the
+// scenarios with the given name as their executor type. It should be called
+// from an init() function, e.g. of an extension, and it panics if an executor
+// with the same name was already registered.
+func RegisterExecutor(name string, factory ExecutorFactory) {
+	if factory == nil {
+		panic("executor configs: the factory of " + name + " is nil")
+	}
+	lib.RegisterExecutorConfigType(name, func(scenarioName string, rawJSON []byte) (lib.ExecutorConfig, error) {
+		config := NewCustomConfig(scenarioName, name, factory)
+		if err := json.Unmarshal(rawJSON, &config); err != nil {
+			return config, err
+		}
+		config.Options = append(json.RawMessage(nil), rawJSON...)
+		return config, nil
+	})
+}
+
+// CustomConfig is the config of the scenarios with custom executors. Besides
+// the options common to all scenarios, it contains the VU requirements of the
+// executor. All other options are specific to the custom executor, which can
+// parse them from Options.
+type CustomConfig struct {
+	BaseConfig
+	VUs      null.Int           `json:"vus"`
+	MaxVUs   null.Int           `json:"maxVUs"`
+	Duration types.NullDuration `json:"duration"`
+
+	// The raw JSON of the whole scenario config.
+	Options json.RawMessage `json:"-"`
+
+	factory ExecutorFactory
+}
+
+// NewCustomConfig returns a CustomConfig with default values
+func NewCustomConfig(name, configType string, factory ExecutorFactory) CustomConfig {
+	return CustomConfig{
+		BaseConfig: NewBaseConfig(name, configType),
+		VUs:        null.NewInt(1, false),
+		factory:    factory,
+	}
+}
+
+// Make sure we implement the lib.ExecutorConfig interface
+var _ lib.ExecutorConfig = &CustomConfig{}
+
+// GetVUs returns the scaled number of pre-initialized VUs for the executor.
+func (cc CustomConfig) GetVUs(et *lib.ExecutionTuple) int64 {
+	return et.Segment.Scale(cc.VUs.Int64)
+}
+
+// GetMaxVUs returns the scaled maximum number of VUs for the executor.
+func (cc CustomConfig) GetMaxVUs(et *lib.ExecutionTuple) int64 {
+	if !cc.MaxVUs.Valid {
+		return cc.GetVUs(et)
+	}
+	return et.Segment.Scale(cc.MaxVUs.Int64)
+}
+
+// GetDescription returns a human-readable description of the executor options
+func (cc CustomConfig) GetDescription(et *lib.ExecutionTuple) string {
+	vus := fmt.Sprintf("%d", cc.GetVUs(et))
+	if maxVUs := cc.GetMaxVUs(et); maxVUs > cc.GetVUs(et) {
+		vus = fmt.Sprintf("%s-%d", vus, maxVUs)
+	}
+	return fmt.Sprintf("%s VUs for %s with the custom %s executor%s",
+		vus, cc.Duration.Duration, cc.Type, cc.getBaseInfo())
+}
+
+// Validate makes sure all options are configured and valid
+func (cc CustomConfig) Validate() []error {
+	errors := cc.BaseConfig.Validate()
+	if cc.VUs.Int64 < 0 {
+		errors = append(errors, fmt.Errorf("the number of VUs can't be negative"))
+	}
+	if cc.MaxVUs.Valid && cc.MaxVUs.Int64 < cc.VUs.Int64 {
+		errors = append(errors, fmt.Errorf("maxVUs can't be less than vus"))
+	}
+	if cc.VUs.Int64 == 0 && cc.MaxVUs.Int64 == 0 {
+		errors = append(errors, fmt.Errorf("either vus or maxVUs should be more than 0"))
+	}
+
+	if !cc.Duration.Valid {
+		errors = append(errors, fmt.Errorf("the duration is unspecified"))
+	} else if time.Duration(cc.Duration.Duration) < minDuration {
+		errors = append(errors, fmt.Errorf(
+			"the duration should be at least %s, but is %s", minDuration, cc.Duration,
+		))
+	}
+
+	return errors
+}
+
+// GetExecutionRequirements returns the number of required VUs to run the
+// executor for its whole duration (disregarding any startTime), including the
+// maximum waiting time for any iterations to gracefully stop.
+func (cc CustomConfig) GetExecutionRequirements(et *lib.ExecutionTuple) []lib.ExecutionStep {
+	return []lib.ExecutionStep{
+		{
+			TimeOffset:      0,
+			PlannedVUs:      uint64(cc.GetVUs(et)),
+			MaxUnplannedVUs: uint64(cc.GetMaxVUs(et) - cc.GetVUs(et)),
+		},
+		{
+			TimeOffset: time.Duration(cc.Duration.Duration + cc.GracefulStop.Duration),
+			PlannedVUs: 0,
+		},
+	}
+}
+
+// HasWork reports whether there is any work to be done for the given execution segment.
+func (cc CustomConfig) HasWork(et *lib.ExecutionTuple) bool {
+	return cc.GetMaxVUs(et) > 0
+}
+
+// NewExecutor creates a new custom executor with the registered factory.
+func (cc CustomConfig) NewExecutor(es *lib.ExecutionState, logger *logrus.Entry) (lib.Executor, error) {
+	return cc.factory(cc, es, logger)
+}
+
+// MarshalJSON keeps the executor-specific options, which aren't part of the
+// struct, when the config is marshaled, e.g. in archives.
+func (cc CustomConfig) MarshalJSON() ([]byte, error) {
+	type plainConfig CustomConfig
+	data, err := json.Marshal(plainConfig(cc))
+	if err != nil || len(cc.Options) == 0 {
+		return data, err
+	}
+
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(cc.Options, &fields); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return json.Marshal(fields)
+}
@@ -0,0 +1,178 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/loadimpact/k6/lib"
+	"github.com/loadimpact/k6/stats"
+)
+
+const testCustomType = "test-per-vu-bursts"
+
+// perVUBursts is a custom executor that runs a burst of iterations with each
+// of its pre-initialized VUs, in parallel.
+type perVUBursts struct {
+	*BaseExecutor
+	config CustomConfig
+	burst  int
+
+	inited bool
+}
+
+func (p *perVUBursts) Init(_ context.Context) error {
+	p.inited = true
+	return nil
+}
+
+func (p *perVUBursts) Run(ctx context.Context, _ chan<- stats.SampleContainer) error {
+	if !p.inited {
+		return assert.AnError
+	}
+	es := p.executionState
+	wg := sync.WaitGroup{}
+	errs := make(chan error, p.config.VUs.Int64)
+	for i := int64(0); i < p.config.VUs.Int64; i++ {
+		vu, err := es.GetPlannedVU(p.logger, true)
+		if err != nil {
+			return err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer es.ReturnVU(vu, true)
+			activeVU := vu.Activate(&lib.VUActivationParams{RunContext: ctx})
+			for j := 0; j < p.burst; j++ {
+				if err := activeVU.RunOnce(); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	return <-errs
+}
+
+func init() {
+	RegisterExecutor(testCustomType, func(config CustomConfig, es *lib.ExecutionState, logger *logrus.Entry) (lib.Executor, error) {
+		var opts struct {
+			Burst int `json:"burst"`
+		}
+		if err := json.Unmarshal(config.Options, &opts); err != nil {
+			return nil, err
+		}
+		return &perVUBursts{BaseExecutor: NewBaseExecutor(config, es, logger), config: config, burst: opts.Burst}, nil
+	})
+}
+
+func TestCustomExecutorConfig(t *testing.T) {
+	t.Parallel()
+	var scenarios lib.ScenarioConfigs
+	require.NoError(t, json.Unmarshal([]byte(`{"bursts": {
+		"executor": "`+testCustomType+`", "vus": 2, "maxVUs": 3, "duration": "5s", "burst": 7
+	}}`), &scenarios))
+
+	config, ok := scenarios["bursts"].(CustomConfig)
+	require.True(t, ok)
+	assert.Empty(t, config.Validate())
+	assert.Equal(t, "bursts", config.GetName())
+	assert.Equal(t, testCustomType, config.GetType())
+
+	et, err := lib.NewExecutionTuple(nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []lib.ExecutionStep{
+		{TimeOffset: 0, PlannedVUs: 2, MaxUnplannedVUs: 1},
+		{TimeOffset: 35 * time.Second, PlannedVUs: 0},
+	}, config.GetExecutionRequirements(et))
+	assert.Equal(t, "2-3 VUs for 5s with the custom "+testCustomType+" executor (gracefulStop: 30s)",
+		config.GetDescription(et))
+
+	data, err := json.Marshal(config)
+	require.NoError(t, err)
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &fields))
+	assert.Equal(t, 7.0, fields["burst"], "the custom options should be kept")
+	assert.Equal(t, testCustomType, fields["executor"])
+
+	t.Run("invalid", func(t *testing.T) {
+		config := NewCustomConfig("bursts", testCustomType, nil)
+		config.VUs.Int64 = 2
+		config.MaxVUs.Int64, config.MaxVUs.Valid = 1, true
+		assert.Len(t, config.Validate(), 2) // maxVUs < vus and no duration
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		assert.Panics(t, func() { RegisterExecutor(testCustomType, nil) })
+		assert.Panics(t, func() {
+			RegisterExecutor(constantVUsType, func(CustomConfig, *lib.ExecutionState, *logrus.Entry) (lib.Executor, error) {
+				return nil, nil
+			})
+		})
+	})
+}
+
+// TestCustomExecutorLifecycle checks that a custom executor goes through the
+// same lifecycle as the built-in ones, and that it gets its VUs from the pool.
+func TestCustomExecutorLifecycle(t *testing.T) {
+	t.Parallel()
+	var scenarios lib.ScenarioConfigs
+	require.NoError(t, json.Unmarshal([]byte(`{"bursts": {
+		"executor": "`+testCustomType+`", "vus": 3, "duration": "5s", "burst": 4
+	}}`), &scenarios))
+
+	et, err := lib.NewExecutionTuple(nil, nil)
+	require.NoError(t, err)
+	es := lib.NewExecutionState(lib.Options{}, et, 3, 3)
+	var iterations, maxActive int64
+	ctx, cancel, executor, _ := setupExecutor(
+		t, scenarios["bursts"], es,
+		simpleRunner(func(ctx context.Context) error {
+			atomic.AddInt64(&iterations, 1)
+			if active := es.GetCurrentlyActiveVUsCount(); active > atomic.LoadInt64(&maxActive) {
+				atomic.StoreInt64(&maxActive, active)
+			}
+			return nil
+		}),
+	)
+	defer cancel()
+
+	_, ok := executor.(*perVUBursts)
+	require.True(t, ok)
+	assert.Equal(t, "bursts", executor.GetConfig().GetName())
+
+	require.NoError(t, executor.Run(ctx, nil))
+	assert.Equal(t, int64(12), atomic.LoadInt64(&iterations))
+	assert.True(t, atomic.LoadInt64(&maxActive) > 0)
+	assert.Equal(t, int64(0), es.GetCurrentlyActiveVUsCount(), "all VUs should be returned")
+	assert.Equal(t, int64(3), es.GetInitializedVUsCount())
+}
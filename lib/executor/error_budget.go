@@ -0,0 +1,125 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package executor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/loadimpact/k6/lib/types"
+)
+
+// The actions that are taken when the error budget of a scenario is exceeded.
+const (
+	ErrorBudgetStopRamping = "stop-ramping"
+	ErrorBudgetReduceVUs   = "reduce-vus-50pct"
+	ErrorBudgetAbort       = "abort"
+)
+
+const defaultErrorBudgetInterval = time.Second
+
+// ErrorBudget is the maximum rate of failed HTTP requests of a scenario, over
+// every interval, and the action that is taken when it's exceeded:
+//   - stop-ramping keeps the VUs from increasing for the rest of the stages,
+//   - reduce-vus-50pct halves the VUs, every interval that the budget is
+//     exceeded, and keeps them from increasing again,
+//   - abort stops the test.
+type ErrorBudget struct {
+	MaxRate  null.Float         `json:"maxRate"`
+	Action   null.String        `json:"action"`
+	Interval types.NullDuration `json:"interval"`
+}
+
+// Validate checks the error budget config.
+func (eb ErrorBudget) Validate() (errors []error) {
+	if !eb.MaxRate.Valid || eb.MaxRate.Float64 < 0 || eb.MaxRate.Float64 > 1 {
+		errors = append(errors, fmt.Errorf("the error budget maxRate should be between 0 and 1"))
+	}
+	switch eb.Action.String {
+	case "", ErrorBudgetStopRamping, ErrorBudgetReduceVUs, ErrorBudgetAbort:
+	default:
+		errors = append(errors, fmt.Errorf(
+			"unknown error budget action '%s', it should be one of %s, %s or %s", eb.Action.String,
+			ErrorBudgetStopRamping, ErrorBudgetReduceVUs, ErrorBudgetAbort,
+		))
+	}
+	if eb.Interval.Valid && eb.Interval.Duration <= 0 {
+		errors = append(errors, fmt.Errorf("the error budget interval should be positive"))
+	}
+	return errors
+}
+
+// GetAction returns the action of the error budget, stop-ramping by default.
+func (eb ErrorBudget) GetAction() string {
+	if eb.Action.String == "" {
+		return ErrorBudgetStopRamping
+	}
+	return eb.Action.String
+}
+
+// GetInterval returns the interval over which the error rate is checked, 1s
+// by default.
+func (eb ErrorBudget) GetInterval() time.Duration {
+	if !eb.Interval.Valid {
+		return defaultErrorBudgetInterval
+	}
+	return time.Duration(eb.Interval.Duration)
+}
+
+// String returns a short description of the error budget.
+func (eb ErrorBudget) String() string {
+	return fmt.Sprintf("%g%% %s", eb.MaxRate.Float64*100, eb.GetAction())
+}
+
+// checkingWaiter is like waiter, but it also calls check every interval while
+// it waits, and stops waiting if check returns true.
+func checkingWaiter(
+	ctx context.Context, startTime time.Time, interval time.Duration, check func() bool,
+) func(offset time.Duration) bool {
+	timer := time.NewTimer(time.Hour * 24)
+	ticker := time.NewTicker(interval)
+	go func() {
+		<-ctx.Done()
+		ticker.Stop()
+	}()
+	return func(offset time.Duration) bool {
+		offsetDiff := offset - time.Since(startTime)
+		if offsetDiff <= 0 {
+			return false
+		}
+		timer.Reset(offsetDiff)
+		for {
+			select {
+			case <-ctx.Done():
+				return true
+			case <-ticker.C:
+				if check() {
+					return true
+				}
+			case <-timer.C:
+				return false
+			}
+		}
+	}
+}
@@ -115,6 +115,7 @@ func (mec ExternallyControlledConfig) GetDescription(_ *lib.ExecutionTuple) stri
 // Validate makes sure all options are configured and valid
 func (mec ExternallyControlledConfig) Validate() []error {
 	errors := append(mec.BaseConfig.Validate(), mec.ExternallyControlledConfigParams.Validate()...)
+	errors = append(errors, mec.validateNoStaggering()...)
 	if mec.GracefulStop.Valid {
 		errors = append(errors, fmt.Errorf(
 			"gracefulStop is not supported by the externally controlled executor",
@@ -529,7 +530,7 @@ func (mex *ExternallyControlled) Run(parentCtx context.Context, out chan<- stats
 		currentlyPaused: false,
 		activeVUsCount:  new(int64),
 		maxVUs:          new(int64),
-		runIteration:    getIterationRunner(mex.executionState, mex.logger),
+		runIteration:    getIterationRunner(mex.executionState, mex.logger, &mex.iterationErrors),
 	}
 	*runState.maxVUs = startMaxVUs
 	if err = runState.retrieveStartMaxVUs(); err != nil {
@@ -24,6 +24,7 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"sync/atomic"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -75,12 +76,13 @@ func validateStages(stages []Stage) []error {
 }
 
 // getIterationRunner is a helper function that returns an iteration executor
-// closure. It takes care of updating the execution state statistics and
-// warning messages. And returns whether a full iteration was finished or not
+// closure. It takes care of updating the execution state statistics, the
+// count of iteration errors and warning messages. And returns whether a full
+// iteration was finished or not
 //
 // TODO: emit the end-of-test iteration metrics here (https://github.com/loadimpact/k6/issues/1250)
 func getIterationRunner(
-	executionState *lib.ExecutionState, logger *logrus.Entry,
+	executionState *lib.ExecutionState, logger *logrus.Entry, iterationErrors *uint64,
 ) func(context.Context, lib.ActiveVU) bool {
 	return func(ctx context.Context, vu lib.ActiveVU) bool {
 		err := vu.RunOnce()
@@ -96,6 +98,7 @@ func getIterationRunner(
 			return false
 		default:
 			if err != nil {
+				atomic.AddUint64(iterationErrors, 1)
 				if s, ok := err.(fmt.Stringer); ok {
 					// TODO better detection for stack traces
 					// TODO don't count this as a full iteration?
@@ -113,6 +116,20 @@ func getIterationRunner(
 	}
 }
 
+// waitForStaggerDelay waits for the delay before the first iteration of a VU,
+// or until the context is done.
+func waitForStaggerDelay(ctx context.Context, delay time.Duration) {
+	if delay <= 0 {
+		return
+	}
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-t.C:
+	case <-ctx.Done():
+	}
+}
+
 // getDurationContexts is used to create sub-contexts that can restrict an
 // executor to only run for its allotted time.
 //
@@ -138,6 +155,9 @@ func getIterationRunner(
 //  - If the whole test is aborted, the parent context will be cancelled, so
 //    that will also cancel these contexts, thus the "general abort" case is
 //    handled transparently.
+//  - If the test is interrupted (see lib.WithInterrupt()), the regDurationCtx
+//    will be done immediately, and the execution scheduler will cancel the
+//    parent context once the graceful stop period is over.
 func getDurationContexts(parentCtx context.Context, regularDuration, gracefulStop time.Duration) (
 	startTime time.Time, maxDurationCtx, regDurationCtx context.Context, maxDurationCancel func(),
 ) {
@@ -148,7 +168,14 @@ func getDurationContexts(parentCtx context.Context, regularDuration, gracefulSto
 	if gracefulStop == 0 {
 		return startTime, maxDurationCtx, maxDurationCtx, maxDurationCancel
 	}
-	regDurationCtx, _ = context.WithDeadline(maxDurationCtx, startTime.Add(regularDuration)) //nolint:govet
+	regDurationCtx, regDurationCancel := context.WithDeadline(maxDurationCtx, startTime.Add(regularDuration))
+	go func() {
+		select {
+		case <-lib.GetInterrupt(parentCtx):
+		case <-regDurationCtx.Done():
+		}
+		regDurationCancel()
+	}()
 	return startTime, maxDurationCtx, regDurationCtx, maxDurationCancel
 }
 
@@ -227,6 +254,11 @@ func getVUActivationParams(
 		RunContext:         ctx,
 		Scenario:           conf.Name,
 		Exec:               conf.GetExec(),
+		ExecProbabilities:  conf.GetExecProbabilities(),
+		ThinkTime:          conf.ThinkTime,
+		ProxyURL:           conf.GetProxyURL(),
+		MaxRPS:             conf.MaxRPS.Float64,
+		StaggerJitter:      time.Duration(conf.StaggerJitter.Duration),
 		Env:                conf.GetEnv(),
 		Tags:               conf.GetTags(),
 		DeactivateCallback: deactivateCallback,
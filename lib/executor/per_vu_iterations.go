@@ -198,7 +198,7 @@ func (pvi PerVUIterations) Run(parentCtx context.Context, out chan<- stats.Sampl
 	defer activeVUs.Wait()
 
 	regDurationDone := regDurationCtx.Done()
-	runIteration := getIterationRunner(pvi.executionState, pvi.logger)
+	runIteration := getIterationRunner(pvi.executionState, pvi.logger, &pvi.iterationErrors)
 
 	activationParams := getVUActivationParams(maxDurationCtx, pvi.config.BaseConfig,
 		func(u lib.InitializedVU) {
@@ -206,7 +206,7 @@ func (pvi PerVUIterations) Run(parentCtx context.Context, out chan<- stats.Sampl
 			activeVUs.Done()
 		})
 
-	handleVU := func(initVU lib.InitializedVU) {
+	handleVU := func(initVU lib.InitializedVU, staggerDelay time.Duration) {
 		defer handleVUsWG.Done()
 		ctx, cancel := context.WithCancel(maxDurationCtx)
 		defer cancel()
@@ -216,6 +216,7 @@ func (pvi PerVUIterations) Run(parentCtx context.Context, out chan<- stats.Sampl
 
 		vuID := initVU.GetID()
 		activeVU := initVU.Activate(&newParams)
+		waitForStaggerDelay(regDurationCtx, staggerDelay)
 
 		for i := int64(0); i < iterations; i++ {
 			select {
@@ -241,7 +242,7 @@ func (pvi PerVUIterations) Run(parentCtx context.Context, out chan<- stats.Sampl
 		}
 		activeVUs.Add(1)
 		handleVUsWG.Add(1)
-		go handleVU(initializedVU)
+		go handleVU(initializedVU, pvi.config.getStaggerDelay(i, numVUs))
 	}
 
 	return nil
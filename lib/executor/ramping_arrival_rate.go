@@ -106,7 +106,7 @@ func (varc RampingArrivalRateConfig) GetDescription(et *lib.ExecutionTuple) stri
 
 // Validate makes sure all options are configured and valid
 func (varc *RampingArrivalRateConfig) Validate() []error {
-	errors := varc.BaseConfig.Validate()
+	errors := append(varc.BaseConfig.Validate(), varc.validateNoStaggering()...)
 
 	if varc.StartRate.Int64 < 0 {
 		errors = append(errors, fmt.Errorf("the startRate value shouldn't be negative"))
@@ -409,7 +409,7 @@ func (varr RampingArrivalRate) Run(parentCtx context.Context, out chan<- stats.S
 	go trackProgress(parentCtx, maxDurationCtx, regDurationCtx, varr, progressFn)
 
 	regDurationDone := regDurationCtx.Done()
-	runIterationBasic := getIterationRunner(varr.executionState, varr.logger)
+	runIterationBasic := getIterationRunner(varr.executionState, varr.logger, &varr.iterationErrors)
 	runIteration := func(vu lib.ActiveVU) {
 		runIterationBasic(maxDurationCtx, vu)
 		activeVUs <- vu
@@ -62,6 +62,7 @@ type RampingVUsConfig struct {
 	StartVUs         null.Int           `json:"startVUs"`
 	Stages           []Stage            `json:"stages"`
 	GracefulRampDown types.NullDuration `json:"gracefulRampDown"`
+	ErrorBudget      *ErrorBudget       `json:"errorBudget"`
 }
 
 // NewRampingVUsConfig returns a RampingVUsConfig with its default values
@@ -90,17 +91,23 @@ func (vlvc RampingVUsConfig) GetGracefulRampDown() time.Duration {
 // GetDescription returns a human-readable description of the executor options
 func (vlvc RampingVUsConfig) GetDescription(et *lib.ExecutionTuple) string {
 	maxVUs := et.ScaleInt64(getStagesUnscaledMaxTarget(vlvc.StartVUs.Int64, vlvc.Stages))
+	facts := []string{fmt.Sprintf("gracefulRampDown: %s", vlvc.GetGracefulRampDown())}
+	if vlvc.ErrorBudget != nil {
+		facts = append(facts, fmt.Sprintf("errorBudget: %s", vlvc.ErrorBudget))
+	}
 	return fmt.Sprintf("Up to %d looping VUs for %s over %d stages%s",
-		maxVUs, sumStagesDuration(vlvc.Stages), len(vlvc.Stages),
-		vlvc.getBaseInfo(fmt.Sprintf("gracefulRampDown: %s", vlvc.GetGracefulRampDown())))
+		maxVUs, sumStagesDuration(vlvc.Stages), len(vlvc.Stages), vlvc.getBaseInfo(facts...))
 }
 
 // Validate makes sure all options are configured and valid
 func (vlvc RampingVUsConfig) Validate() []error {
-	errors := vlvc.BaseConfig.Validate()
+	errors := append(vlvc.BaseConfig.Validate(), vlvc.validateNoStaggering()...)
 	if vlvc.StartVUs.Int64 < 0 {
 		errors = append(errors, fmt.Errorf("the number of start VUs shouldn't be negative"))
 	}
+	if vlvc.ErrorBudget != nil {
+		errors = append(errors, vlvc.ErrorBudget.Validate()...)
+	}
 
 	return append(errors, validateStages(vlvc.Stages)...)
 }
@@ -604,7 +611,7 @@ func (vlv RampingVUs) Run(parentCtx context.Context, out chan<- stats.SampleCont
 
 	// Actually schedule the VUs and iterations, likely the most complicated
 	// executor among all of them...
-	runIteration := getIterationRunner(vlv.executionState, vlv.logger)
+	runIteration := getIterationRunner(vlv.executionState, vlv.logger, &vlv.iterationErrors)
 	getVU := func() (lib.InitializedVU, error) {
 		initVU, err := vlv.executionState.GetPlannedVU(vlv.logger, false)
 		if err != nil {
@@ -624,10 +631,17 @@ func (vlv RampingVUs) Run(parentCtx context.Context, out chan<- stats.SampleCont
 		vlv.executionState.ModCurrentlyActiveVUsCount(-1)
 	}
 
+	// The HTTP requests of the VUs are counted for the error budget
+	vuCtx := maxDurationCtx
+	requestCounter := &lib.RequestCounter{}
+	if vlv.config.ErrorBudget != nil {
+		vuCtx = lib.WithRequestCounter(maxDurationCtx, requestCounter)
+	}
+
 	vuHandles := make([]*vuHandle, maxVUs)
 	for i := uint64(0); i < maxVUs; i++ {
 		vuHandle := newStoppedVUHandle(
-			maxDurationCtx, getVU, returnVU, &vlv.config.BaseConfig,
+			vuCtx, getVU, returnVU, &vlv.config.BaseConfig,
 			vlv.logger.WithField("vuNum", i))
 		go vuHandle.runLoopsIfPossible(runIteration)
 		vuHandles[i] = vuHandle
@@ -635,8 +649,13 @@ func (vlv RampingVUs) Run(parentCtx context.Context, out chan<- stats.SampleCont
 
 	// 0 <= currentScheduledVUs <= currentMaxAllowedVUs <= maxVUs
 	var currentScheduledVUs, currentMaxAllowedVUs uint64
+	// The scheduled VUs are capped at vuCap after the error budget is exceeded
+	vuCap := maxVUs
 
 	handleNewScheduledVUs := func(newScheduledVUs uint64) {
+		if newScheduledVUs > vuCap {
+			newScheduledVUs = vuCap
+		}
 		if newScheduledVUs > currentScheduledVUs {
 			for vuNum := currentScheduledVUs; vuNum < newScheduledVUs; vuNum++ {
 				_ = vuHandles[vuNum].start() // TODO handle error
@@ -658,7 +677,58 @@ func (vlv RampingVUs) Run(parentCtx context.Context, out chan<- stats.SampleCont
 		currentMaxAllowedVUs = newMaxAllowedVUs
 	}
 
-	wait := waiter(parentCtx, startTime)
+	// The ramping is stopped early if the test is interrupted, and then the
+	// VUs are gracefully stopped, like at the end of the stages
+	rampingCtx, rampingCancel := context.WithCancel(parentCtx)
+	defer rampingCancel()
+	go func() {
+		select {
+		case <-lib.GetInterrupt(parentCtx):
+			rampingCancel()
+		case <-rampingCtx.Done():
+		}
+	}()
+	stopRamping := func() {
+		if parentCtx.Err() == nil && err == nil {
+			handleNewScheduledVUs(0)
+		}
+	}
+
+	wait := waiter(rampingCtx, startTime)
+	if eb := vlv.config.ErrorBudget; eb != nil {
+		// checkErrorBudget returns true if the test should be aborted
+		checkErrorBudget := func() bool {
+			total, failed := requestCounter.Reset()
+			if total == 0 {
+				return false
+			}
+			errorRate := float64(failed) / float64(total)
+			if errorRate <= eb.MaxRate.Float64 {
+				return false
+			}
+			logger := vlv.logger.WithFields(logrus.Fields{
+				"errorRate": errorRate, "maxRate": eb.MaxRate.Float64, "action": eb.GetAction(),
+			})
+			switch eb.GetAction() {
+			case ErrorBudgetAbort:
+				err = fmt.Errorf("the error rate %.2f%% of %s exceeded its error budget of %.2f%%",
+					errorRate*100, vlv.config.GetName(), eb.MaxRate.Float64*100)
+				cancel() // stop the VUs, so they don't have to be waited for
+				return true
+			case ErrorBudgetReduceVUs:
+				vuCap = currentScheduledVUs / 2
+				logger.Warnf("The error budget was exceeded, reducing the VUs to %d", vuCap)
+				handleNewScheduledVUs(vuCap)
+			default:
+				if vuCap > currentScheduledVUs {
+					logger.Warnf("The error budget was exceeded, stopping the ramping at %d VUs", currentScheduledVUs)
+					vuCap = currentScheduledVUs
+				}
+			}
+			return false
+		}
+		wait = checkingWaiter(rampingCtx, startTime, eb.GetInterval(), checkErrorBudget)
+	}
 	// iterate over rawExecutionSteps and gracefulExecutionSteps in order by TimeOffset
 	// giving rawExecutionSteps precedence.
 	// we stop iterating once rawExecutionSteps are over as we need to run the remaining
@@ -668,12 +738,14 @@ func (vlv RampingVUs) Run(parentCtx context.Context, out chan<- stats.SampleCont
 	for i != len(rawExecutionSteps) {
 		if rawExecutionSteps[i].TimeOffset > gracefulExecutionSteps[j].TimeOffset {
 			if wait(gracefulExecutionSteps[j].TimeOffset) {
+				stopRamping()
 				return
 			}
 			handleNewMaxAllowedVUs(gracefulExecutionSteps[j].PlannedVUs)
 			j++
 		} else {
 			if wait(rawExecutionSteps[i].TimeOffset) {
+				stopRamping()
 				return
 			}
 			handleNewScheduledVUs(rawExecutionSteps[i].PlannedVUs)
@@ -682,6 +754,7 @@ func (vlv RampingVUs) Run(parentCtx context.Context, out chan<- stats.SampleCont
 	}
 
 	go func() { // iterate over the remaining gracefulExecutionSteps
+		wait := waiter(parentCtx, startTime)
 		for _, step := range gracefulExecutionSteps[j:] {
 			if wait(step.TimeOffset) {
 				return
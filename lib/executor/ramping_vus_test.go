@@ -1282,3 +1282,67 @@ func TestSumRandomSegmentSequenceMatchesNoSegment(t *testing.T) {
 		})
 	}
 }
+
+func TestRampingVUsErrorBudget(t *testing.T) {
+	t.Parallel()
+
+	failingRunner := simpleRunner(func(ctx context.Context) error {
+		lib.GetRequestCounter(ctx).Add(true)
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	})
+	newConfig := func(action string, startVUs, target int64) RampingVUsConfig {
+		return RampingVUsConfig{
+			BaseConfig:       BaseConfig{GracefulStop: types.NullDurationFrom(0)},
+			GracefulRampDown: types.NullDurationFrom(0),
+			StartVUs:         null.IntFrom(startVUs),
+			Stages:           []Stage{{Duration: types.NullDurationFrom(2 * time.Second), Target: null.IntFrom(target)}},
+			ErrorBudget: &ErrorBudget{
+				MaxRate:  null.FloatFrom(0.05),
+				Action:   null.StringFrom(action),
+				Interval: types.NullDurationFrom(200 * time.Millisecond),
+			},
+		}
+	}
+	run := func(t *testing.T, config RampingVUsConfig, sampleTimes ...time.Duration) ([]int64, error) {
+		et, err := lib.NewExecutionTuple(nil, nil)
+		require.NoError(t, err)
+		es := lib.NewExecutionState(lib.Options{}, et, 10, 50)
+		ctx, cancel, executor, _ := setupExecutor(t, config, es, failingRunner)
+		defer cancel()
+
+		errCh := make(chan error)
+		go func() { errCh <- executor.Run(ctx, nil) }()
+		result := make([]int64, len(sampleTimes))
+		for i, d := range sampleTimes {
+			time.Sleep(d)
+			result[i] = es.GetCurrentlyActiveVUsCount()
+		}
+		return result, <-errCh
+	}
+
+	t.Run("stop ramping", func(t *testing.T) {
+		t.Parallel()
+		result, err := run(t, newConfig(ErrorBudgetStopRamping, 2, 10), 1000*time.Millisecond, 700*time.Millisecond)
+		require.NoError(t, err)
+		assert.True(t, result[0] < 5, result)
+		assert.Equal(t, result[0], result[1])
+	})
+
+	t.Run("reduce VUs", func(t *testing.T) {
+		t.Parallel()
+		result, err := run(t, newConfig(ErrorBudgetReduceVUs, 8, 8), 100*time.Millisecond, 400*time.Millisecond)
+		require.NoError(t, err)
+		assert.Equal(t, int64(8), result[0])
+		assert.True(t, result[1] <= 4, result)
+	})
+
+	t.Run("abort", func(t *testing.T) {
+		t.Parallel()
+		start := time.Now()
+		_, err := run(t, newConfig(ErrorBudgetAbort, 2, 2))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "exceeded its error budget of 5.00%")
+		assert.True(t, time.Since(start) < time.Second)
+	})
+}
@@ -230,14 +230,14 @@ func (si SharedIterations) Run(parentCtx context.Context, out chan<- stats.Sampl
 	}()
 
 	regDurationDone := regDurationCtx.Done()
-	runIteration := getIterationRunner(si.executionState, si.logger)
+	runIteration := getIterationRunner(si.executionState, si.logger, &si.iterationErrors)
 
 	activationParams := getVUActivationParams(maxDurationCtx, si.config.BaseConfig,
 		func(u lib.InitializedVU) {
 			si.executionState.ReturnVU(u, true)
 			activeVUs.Done()
 		})
-	handleVU := func(initVU lib.InitializedVU) {
+	handleVU := func(initVU lib.InitializedVU, staggerDelay time.Duration) {
 		ctx, cancel := context.WithCancel(maxDurationCtx)
 		defer cancel()
 
@@ -245,6 +245,7 @@ func (si SharedIterations) Run(parentCtx context.Context, out chan<- stats.Sampl
 		newParams.RunContext = ctx
 
 		activeVU := initVU.Activate(&newParams)
+		waitForStaggerDelay(regDurationCtx, staggerDelay)
 
 		for {
 			select {
@@ -271,7 +272,7 @@ func (si SharedIterations) Run(parentCtx context.Context, out chan<- stats.Sampl
 			return err
 		}
 		activeVUs.Add(1)
-		go handleVU(initVU)
+		go handleVU(initVU, si.config.getStaggerDelay(i, numVUs))
 	}
 
 	return nil
@@ -93,6 +93,9 @@ type ExecutorConfig interface {
 	//
 	// TODO: use interface{} so plain http requests can be specified?
 	GetExec() string
+	// Returns the normalized probabilities of the functions that should be
+	// randomly picked to be executed in each iteration, if any were specified.
+	GetExecProbabilities() []ExecProbability
 	GetTags() map[string]string
 
 	// Calculates the VU requirements in different stages of the executor's
@@ -107,6 +110,12 @@ type ExecutorConfig interface {
 
 	// HasWork reports whether there is any work for the executor to do with a given segment.
 	HasWork(*ExecutionTuple) bool
+
+	// Returns the names of the scenarios that have to finish before this one
+	// is started, and how many iteration errors each of them can have for
+	// this one to still be started.
+	GetDependsOn() []string
+	GetDependencyMaxErrors() uint64
 }
 
 // InitVUFunc is just a shorthand so we don't have to type the function
@@ -121,6 +130,9 @@ type Executor interface {
 
 	Init(ctx context.Context) error
 	Run(ctx context.Context, engineOut chan<- stats.SampleContainer) error
+
+	// Returns the number of iterations that ended with an error.
+	GetIterationErrors() uint64
 }
 
 // PausableExecutor should be implemented by the executors that can be paused
@@ -204,10 +216,110 @@ func (scs ScenarioConfigs) Validate() (errors []error) {
 			errors = append(errors,
 				fmt.Errorf("scenario %s has configuration errors: %s", name, ConcatErrors(execErr, ", ")))
 		}
+		for _, dep := range exec.GetDependsOn() {
+			if _, ok := scs[dep]; !ok {
+				errors = append(errors, fmt.Errorf("scenario %s depends on the unknown scenario %s", name, dep))
+			}
+		}
+	}
+	if cycle := scs.findDependencyCycle(); cycle != nil {
+		errors = append(errors, fmt.Errorf("the scenarios have a circular dependency: %s", strings.Join(cycle, " -> ")))
 	}
 	return errors
 }
 
+// findDependencyCycle returns the names of the scenarios in a dependency
+// cycle, starting and ending with the same one, or nil if there isn't one.
+func (scs ScenarioConfigs) findDependencyCycle() []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(scs))
+	var path []string
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			for i, n := range path {
+				if n == name {
+					return append(append([]string{}, path[i:]...), name)
+				}
+			}
+		}
+		config, ok := scs[name]
+		if !ok {
+			return nil
+		}
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range config.GetDependsOn() {
+			if cycle := visit(dep); cycle != nil {
+				return cycle
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+
+	names := make([]string, 0, len(scs))
+	for name := range scs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if cycle := visit(name); cycle != nil {
+			return cycle
+		}
+	}
+	return nil
+}
+
+// getDependentStartTimes returns the earliest and the planned time offsets at
+// which the scenarios can start, relative to the beginning of the test. The
+// scenarios without dependencies start at their startTime. The ones with
+// dependencies start when all of their dependencies have finished, so at
+// the earliest when those started, and at the latest, which is what's
+// planned, at their planned ends. The scenarios shouldn't have circular
+// dependencies.
+func (scs ScenarioConfigs) getDependentStartTimes(et *ExecutionTuple) (earliest, planned map[string]time.Duration) {
+	earliest = make(map[string]time.Duration, len(scs))
+	planned = make(map[string]time.Duration, len(scs))
+	var calculate func(name string, depth int)
+	calculate = func(name string, depth int) {
+		if _, ok := planned[name]; ok {
+			return
+		}
+		config := scs[name]
+		earliestStart, plannedStart := config.GetStartTime(), config.GetStartTime()
+		if depth <= len(scs) { // just in case there's a cycle
+			for _, dep := range config.GetDependsOn() {
+				depConfig, ok := scs[dep]
+				if !ok {
+					continue
+				}
+				calculate(dep, depth+1)
+				if earliest[dep] > earliestStart {
+					earliestStart = earliest[dep]
+				}
+				depEnd, _ := GetEndOffset(depConfig.GetExecutionRequirements(et))
+				if depEnd += planned[dep]; depEnd > plannedStart {
+					plannedStart = depEnd
+				}
+			}
+		}
+		earliest[name], planned[name] = earliestStart, plannedStart
+	}
+	for name := range scs {
+		calculate(name, 0)
+	}
+	return earliest, planned
+}
+
 // GetSortedConfigs returns a slice with the executor configurations,
 // sorted in a consistent and predictable manner. It is useful when we want or
 // have to avoid using maps with string keys (and tons of string lookups in
@@ -255,9 +367,21 @@ func (scs ScenarioConfigs) GetFullExecutionRequirements(et *ExecutionTuple) []Ex
 		configID int
 	}
 	trackedSteps := []trackedStep{}
+	earliestStartTimes, plannedStartTimes := scs.getDependentStartTimes(et)
 	for configID, config := range sortedConfigs { // orderly iteration over a slice
-		configStartTime := config.GetStartTime()
+		configStartTime := plannedStartTimes[config.GetName()]
 		configSteps := config.GetExecutionRequirements(et)
+		if earliest := earliestStartTimes[config.GetName()]; earliest < configStartTime {
+			// Scenarios with dependencies are started as soon as those have
+			// finished, so their VUs are reserved from the earliest time that
+			// can happen
+			maxPlannedVUs := GetMaxPlannedVUs(configSteps)
+			trackedSteps = append(trackedSteps, trackedStep{ExecutionStep{
+				TimeOffset:      earliest,
+				PlannedVUs:      maxPlannedVUs,
+				MaxUnplannedVUs: GetMaxPossibleVUs(configSteps) - maxPlannedVUs,
+			}, configID})
+		}
 		for _, cs := range configSteps {
 			cs.TimeOffset += configStartTime // add the executor start time to the step time offset
 			trackedSteps = append(trackedSteps, trackedStep{cs, configID})
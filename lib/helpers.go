@@ -24,10 +24,15 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
 )
 
+// envPlaceholder matches the ${VAR} environment variable placeholders that
+// ExpandEnvInJSON replaces.
+var envPlaceholder = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`) //nolint:gochecknoglobals
+
 // StrictJSONUnmarshal decodes a JSON in a strict manner, emitting an error if there
 // are unknown fields or unexpected data
 func StrictJSONUnmarshal(data []byte, v interface{}) error {
@@ -45,6 +50,43 @@ func StrictJSONUnmarshal(data []byte, v interface{}) error {
 	return nil
 }
 
+// ExpandEnvInJSON replaces the ${VAR} placeholders in all of the string values
+// of the given JSON document with the values getenv returns for them, like
+// os.ExpandEnv() does. Object keys, numbers and the strings without
+// placeholders are left as they are and if there are no placeholders at all,
+// the data is returned unchanged.
+func ExpandEnvInJSON(data []byte, getenv func(string) string) ([]byte, error) {
+	if !envPlaceholder.Match(data) {
+		return data, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(expandEnvInValue(v, getenv))
+}
+
+func expandEnvInValue(v interface{}, getenv func(string) string) interface{} {
+	switch val := v.(type) {
+	case string:
+		return envPlaceholder.ReplaceAllStringFunc(val, func(placeholder string) string {
+			return getenv(placeholder[2 : len(placeholder)-1])
+		})
+	case map[string]interface{}:
+		for k, item := range val {
+			val[k] = expandEnvInValue(item, getenv)
+		}
+	case []interface{}:
+		for i, item := range val {
+			val[i] = expandEnvInValue(item, getenv)
+		}
+	}
+	return v
+}
+
 // GetMaxPlannedVUs returns the maximum number of planned VUs at any stage of
 // the execution plan.
 func GetMaxPlannedVUs(steps []ExecutionStep) (result uint64) {
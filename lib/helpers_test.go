@@ -66,3 +66,31 @@ func TestStrictJSONUnmarshal(t *testing.T) {
 }
 
 //TODO: test EventStream very thoroughly
+
+func TestExpandEnvInJSON(t *testing.T) {
+	t.Parallel()
+	env := map[string]string{"URL": "https://example.com", "NAME": "test"}
+	getenv := func(key string) string { return env[key] }
+
+	testCases := []struct {
+		data, expected string
+	}{
+		{`{"a": "b", "c": 1}`, `{"a": "b", "c": 1}`},
+		{`{"a": "$URL", "b": "{URL}"}`, `{"a": "$URL", "b": "{URL}"}`},
+		{`{"a": "${URL}/${NAME}"}`, `{"a":"https://example.com/test"}`},
+		{`{"a": ["${NAME}", {"b": "${MISSING}"}], "c": 1.10}`, `{"a":["test",{"b":""}],"c":1.10}`},
+		{`{"${NAME}": 1}`, `{"${NAME}":1}`},
+	}
+	for i, tc := range testCases {
+		tc := tc
+		t.Run(fmt.Sprintf("TestCase#%d", i), func(t *testing.T) {
+			t.Parallel()
+			data, err := ExpandEnvInJSON([]byte(tc.data), getenv)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(data))
+		})
+	}
+
+	_, err := ExpandEnvInJSON([]byte(`{"a": "${URL}"`), getenv)
+	assert.Error(t, err)
+}
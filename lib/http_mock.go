@@ -0,0 +1,144 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package lib
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// HTTPMock is a canned response that is returned, without making any network
+// calls, for all HTTP requests whose URLs match its pattern.
+type HTTPMock struct {
+	// Glob pattern or regular expression, depending on IsRegex. A glob
+	// pattern without any "*" only matches the exact URL.
+	Pattern string
+	IsRegex bool
+
+	Status  int
+	Headers http.Header
+	Body    []byte
+
+	re *regexp.Regexp
+}
+
+// NewHTTPMock returns a new HTTPMock for the given pattern, or an error if
+// the pattern is invalid. A zero status is replaced with 200.
+func NewHTTPMock(pattern string, isRegex bool, status int, headers http.Header, body []byte) (*HTTPMock, error) {
+	if pattern == "" {
+		return nil, errors.New("the mock URL pattern can't be empty")
+	}
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if status < 100 || status > 999 {
+		return nil, errors.Errorf("invalid mock response status %d", status)
+	}
+
+	var re *regexp.Regexp
+	var err error
+	if isRegex {
+		re, err = regexp.Compile(pattern)
+	} else {
+		re, err = compileGlob(pattern)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid mock URL pattern")
+	}
+
+	if headers == nil {
+		headers = make(http.Header)
+	}
+	return &HTTPMock{
+		Pattern: pattern,
+		IsRegex: isRegex,
+		Status:  status,
+		Headers: headers,
+		Body:    body,
+		re:      re,
+	}, nil
+}
+
+// Matches returns whether the given URL matches the mock's pattern.
+func (m *HTTPMock) Matches(url string) bool {
+	return m.re.MatchString(url)
+}
+
+// Response returns a new response for the given request, as if it was
+// returned by an actual server.
+func (m *HTTPMock) Response(req *http.Request) *http.Response {
+	headers := make(http.Header, len(m.Headers)+1)
+	for k, vs := range m.Headers {
+		headers[k] = append([]string(nil), vs...)
+	}
+	if headers.Get("Content-Length") == "" {
+		headers.Set("Content-Length", strconv.Itoa(len(m.Body)))
+	}
+
+	return &http.Response{
+		Status:        strconv.Itoa(m.Status) + " " + http.StatusText(m.Status),
+		StatusCode:    m.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        headers,
+		Body:          ioutil.NopCloser(bytes.NewReader(m.Body)),
+		ContentLength: int64(len(m.Body)),
+		Request:       req,
+	}
+}
+
+// HTTPMocks is an ordered list of HTTP mocks.
+type HTTPMocks []*HTTPMock
+
+// Set returns a copy of the list with the given mock added, replacing any
+// previous mock with the same pattern. The list itself is never modified, so
+// it can be used by the requests that are still running.
+func (ms HTTPMocks) Set(mock *HTTPMock) HTTPMocks {
+	newMocks := make(HTTPMocks, 0, len(ms)+1)
+	replaced := false
+	for _, m := range ms {
+		if m.Pattern == mock.Pattern && m.IsRegex == mock.IsRegex {
+			m, replaced = mock, true
+		}
+		newMocks = append(newMocks, m)
+	}
+	if !replaced {
+		newMocks = append(newMocks, mock)
+	}
+	return newMocks
+}
+
+// For returns the first mock that matches the given URL, or nil if no mock
+// matches it.
+func (ms HTTPMocks) For(url string) *HTTPMock {
+	for _, m := range ms {
+		if m.Matches(url) {
+			return m
+		}
+	}
+	return nil
+}
@@ -49,6 +49,36 @@ var (
 	HTTPReqSending        = stats.New("http_req_sending", stats.Trend, stats.Time)
 	HTTPReqWaiting        = stats.New("http_req_waiting", stats.Trend, stats.Time)
 	HTTPReqReceiving      = stats.New("http_req_receiving", stats.Trend, stats.Time)
+	HTTPReqQueued         = stats.New("http_req_queued", stats.Trend, stats.Time)
+
+	// The HTTP requests that failed, with an error or a 4xx or 5xx status
+	HTTPReqFailed = stats.New("http_req_failed", stats.Counter)
+
+	// The sizes of the compressed HTTP response bodies, before and after decompressing them
+	HTTPRespCompressedBytes   = stats.New("http_resp_compressed_bytes", stats.Counter, stats.Data)
+	HTTPRespUncompressedBytes = stats.New("http_resp_uncompressed_bytes", stats.Counter, stats.Data)
+
+	// How many times larger the compressed HTTP response bodies are after decompressing them,
+	// and their compression algorithms, as the compression_algorithm tag
+	HTTPRespCompressionRatio     = stats.New("http_resp_compression_ratio", stats.Trend)
+	HTTPRespCompressionAlgorithm = stats.New("http_resp_compression_algorithm", stats.Counter)
+
+	// The sizes of the HTTP request and response headers, including the request and status lines
+	HTTPReqHeaderSize  = stats.New("http_req_header_size", stats.Trend, stats.Data)
+	HTTPRespHeaderSize = stats.New("http_resp_header_size", stats.Trend, stats.Data)
+
+	MultipartPartsReceived = stats.New("multipart_parts_received", stats.Counter)
+
+	// Connection-related.
+	ConnectionRetries = stats.New("connection_retries_count", stats.Counter)
+
+	// The idle and active connections of the HTTP connection pools of all VUs
+	HTTPConnPoolIdle   = stats.New("http_conn_pool_idle", stats.Gauge)
+	HTTPConnPoolActive = stats.New("http_conn_pool_active", stats.Gauge)
+
+	// The seconds until the leaf certificate of a TLS server expires, recorded
+	// once per host
+	TLSCertExpirySeconds = stats.New("tls_cert_expiry_seconds", stats.Gauge)
 
 	// Websocket-related
 	WSSessions         = stats.New("ws_sessions", stats.Counter)
@@ -57,6 +87,26 @@ var (
 	WSPing             = stats.New("ws_ping", stats.Trend, stats.Time)
 	WSSessionDuration  = stats.New("ws_session_duration", stats.Trend, stats.Time)
 	WSConnecting       = stats.New("ws_connecting", stats.Trend, stats.Time)
+	WSMsgSendDuration  = stats.New("ws_msg_send_duration", stats.Trend, stats.Time)
+	WSErrors           = stats.New("ws_errors", stats.Counter)
+	WSSessionsActive   = stats.New("ws_sessions_active", stats.Gauge)
+
+	// TCP-related
+	TCPConnectDuration = stats.New("tcp_connect_duration", stats.Trend, stats.Time)
+	TCPBytesSent       = stats.New("tcp_bytes_sent", stats.Counter, stats.Data)
+	TCPBytesReceived   = stats.New("tcp_bytes_received", stats.Counter, stats.Data)
+
+	// UDP-related
+	UDPBytesSent      = stats.New("udp_bytes_sent", stats.Counter, stats.Data)
+	UDPBytesReceived  = stats.New("udp_bytes_received", stats.Counter, stats.Data)
+	UDPReceiveTimeout = stats.New("udp_receive_timeout", stats.Counter)
+
+	// DNS-related
+	DNSLookups        = stats.New("dns_lookups", stats.Counter)
+	DNSLookupDuration = stats.New("dns_lookup_duration", stats.Trend, stats.Time)
+
+	// The state changes of the k6/circuitbreaker circuit breakers
+	CircuitBreakerStateChanges = stats.New("circuit_breaker_state_changes", stats.Counter)
 
 	// Network-related; used for future protocols as well.
 	DataSent     = stats.New("data_sent", stats.Counter, stats.Data)
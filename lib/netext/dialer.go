@@ -23,9 +23,14 @@ package netext
 import (
 	"context"
 	"fmt"
+	"math/rand"
 	"net"
+	"net/http/httptrace"
+	"os"
 	"strconv"
+	"sync"
 	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/pkg/errors"
@@ -39,11 +44,17 @@ import (
 // dnsResolver is an interface that fetches dns information
 // about a given address.
 type dnsResolver interface {
-	FetchOne(address string) (net.IP, error)
+	Fetch(address string) ([]net.IP, error)
 }
 
+// The initial and the maximum delays between connection retries.
+const (
+	retryBackoffBase = 100 * time.Millisecond
+	retryBackoffMax  = 5 * time.Second
+)
+
 // Dialer wraps net.Dialer and provides k6 specific functionality -
-// tracing, blacklists and DNS cache and aliases.
+// tracing, blacklists, DNS cache and aliases and connection retries.
 type Dialer struct {
 	net.Dialer
 
@@ -51,6 +62,20 @@ type Dialer struct {
 	Blacklist []*lib.IPNet
 	Hosts     map[string]*lib.HostAddress
 
+	// MaxRetries is how many times a failed connection attempt is retried.
+	// Only transient errors are retried, DNS errors only if RetryDNSErrors is set.
+	MaxRetries     int
+	RetryDNSErrors bool
+
+	// IPVersion restricts the connections to the IPv4 or the IPv6 addresses
+	// of the hosts, or races them with lib.IPVersionAny. It can be overridden
+	// for a connection with lib.WithIPVersion().
+	IPVersion string
+
+	// ConnHooks, if set, records the connections that are established and
+	// closed, so the VU can handle them.
+	ConnHooks *lib.ConnHooks
+
 	BytesRead    int64
 	BytesWritten int64
 }
@@ -79,18 +104,93 @@ func (b BlackListedIPError) Error() string {
 
 // DialContext wraps the net.Dialer.DialContext and handles the k6 specifics
 func (d *Dialer) DialContext(ctx context.Context, proto, addr string) (net.Conn, error) {
-	dialAddr, err := d.getDialAddr(addr)
+	conn, err := d.dial(ctx, proto, addr)
+	for attempt := 0; err != nil && attempt < d.MaxRetries && d.shouldRetry(err); attempt++ {
+		if !sleepBackoff(ctx, attempt) {
+			return nil, err
+		}
+		d.emitRetry(ctx)
+		conn, err = d.dial(ctx, proto, addr)
+	}
+	return conn, err
+}
+
+func (d *Dialer) dial(ctx context.Context, proto, addr string) (net.Conn, error) {
+	ipVersion := d.IPVersion
+	if v := lib.GetIPVersion(ctx); v != "" {
+		ipVersion = v
+	}
+	dialAddrs, err := d.getDialAddrs(ctx, addr, ipVersion)
 	if err != nil {
 		return nil, err
 	}
-	conn, err := d.Dialer.DialContext(ctx, proto, dialAddr)
+	var conn net.Conn
+	if ipVersion == lib.IPVersionAny {
+		conn, err = d.dialHappyEyeballs(ctx, proto, dialAddrs)
+	} else {
+		conn, err = d.Dialer.DialContext(ctx, proto, dialAddrs[0])
+	}
 	if err != nil {
 		return nil, err
 	}
-	conn = &Conn{conn, &d.BytesRead, &d.BytesWritten}
+	if d.ConnHooks != nil {
+		d.ConnHooks.Record(newConnEvent(lib.ConnEventConnect, proto, conn))
+	}
+	conn = &Conn{Conn: conn, BytesRead: &d.BytesRead, BytesWritten: &d.BytesWritten, hooks: d.ConnHooks, proto: proto}
 	return conn, err
 }
 
+// shouldRetry returns whether the error is a transient connection error that
+// could go away if the connection is retried.
+func (d *Dialer) shouldRetry(err error) bool {
+	switch e := err.(type) {
+	case *net.DNSError:
+		return d.RetryDNSErrors
+	case *net.OpError:
+		if e.Timeout() {
+			return true
+		}
+		if sErr, ok := e.Err.(*os.SyscallError); ok {
+			return sErr.Err == syscall.ECONNREFUSED
+		}
+	}
+	return false
+}
+
+// sleepBackoff waits before the given retry attempt, using an exponential
+// backoff with full jitter, so that VUs don't retry all at the same time. It
+// returns false if the context was done before that.
+func sleepBackoff(ctx context.Context, attempt int) bool {
+	backoff := retryBackoffMax
+	if attempt < 16 {
+		if b := retryBackoffBase << uint(attempt); b < backoff {
+			backoff = b
+		}
+	}
+	timer := time.NewTimer(time.Duration(rand.Int63n(int64(backoff)) + 1)) //nolint:gosec
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (d *Dialer) emitRetry(ctx context.Context) {
+	state := lib.GetState(ctx)
+	if state == nil {
+		return
+	}
+	tags := state.CloneTags()
+	stats.PushIfNotDone(ctx, state.Samples, stats.Sample{
+		Time:   time.Now(),
+		Metric: metrics.ConnectionRetries,
+		Value:  1,
+		Tags:   stats.IntoSampleTags(&tags),
+	})
+}
+
 // GetTrail creates a new NetTrail instance with the Dialer
 // sent and received data metrics and the supplied times and tags.
 // TODO: Refactor this according to
@@ -142,51 +242,109 @@ func (d *Dialer) GetTrail(
 	}
 }
 
-func (d *Dialer) getDialAddr(addr string) (string, error) {
-	remote, err := d.findRemote(addr)
+// getDialAddrs returns the addresses of the host that aren't blacklisted and
+// are of the given IP version. Without one, only the first address is used.
+func (d *Dialer) getDialAddrs(ctx context.Context, addr, ipVersion string) ([]string, error) {
+	remotes, err := d.findRemotes(ctx, addr)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+	if ipVersion == "" {
+		remotes = remotes[:1]
 	}
 
-	for _, ipnet := range d.Blacklist {
-		if ipnet.Contains(remote.IP) {
-			return "", BlackListedIPError{ip: remote.IP, net: ipnet}
+	var dialAddrs []string
+	var blacklistErr error
+	for _, remote := range remotes {
+		isIPv4 := remote.IP.To4() != nil
+		if (ipVersion == lib.IPVersion4 && !isIPv4) || (ipVersion == lib.IPVersion6 && isIPv4) {
+			continue
+		}
+		if err := d.checkBlacklist(remote.IP); err != nil {
+			if blacklistErr == nil {
+				blacklistErr = err
+			}
+			continue
 		}
+		dialAddrs = append(dialAddrs, remote.String())
+	}
+	if len(dialAddrs) > 0 {
+		return dialAddrs, nil
+	}
+	if blacklistErr != nil {
+		return nil, blacklistErr
 	}
+	host, _, _ := net.SplitHostPort(addr)
+	return nil, fmt.Errorf("the host %s has no %s addresses", host, ipVersionName(ipVersion))
+}
 
-	return remote.String(), nil
+func (d *Dialer) checkBlacklist(ip net.IP) error {
+	for _, ipnet := range d.Blacklist {
+		if ipnet.Contains(ip) {
+			return BlackListedIPError{ip: ip, net: ipnet}
+		}
+	}
+	return nil
 }
 
-func (d *Dialer) findRemote(addr string) (*lib.HostAddress, error) {
+// findRemotes returns the addresses of the host, in the order that they were
+// resolved in.
+func (d *Dialer) findRemotes(ctx context.Context, addr string) ([]*lib.HostAddress, error) {
 	host, port, err := net.SplitHostPort(addr)
 	if err != nil {
 		return nil, err
 	}
 
 	remote, err := d.getConfiguredHost(addr, host, port)
-	if err != nil || remote != nil {
-		return remote, err
+	if err != nil {
+		return nil, err
+	}
+	if remote != nil {
+		return []*lib.HostAddress{remote}, nil
 	}
 
 	ip := net.ParseIP(host)
 	if ip != nil {
-		return lib.NewHostAddress(ip, port)
+		remote, err = lib.NewHostAddress(ip, port)
+		if err != nil {
+			return nil, err
+		}
+		return []*lib.HostAddress{remote}, nil
 	}
 
-	return d.fetchRemoteFromResolver(host, port)
+	return d.fetchRemotesFromResolver(ctx, host, port)
 }
 
-func (d *Dialer) fetchRemoteFromResolver(host, port string) (*lib.HostAddress, error) {
-	ip, err := d.Resolver.FetchOne(host)
+func (d *Dialer) fetchRemotesFromResolver(ctx context.Context, host, port string) ([]*lib.HostAddress, error) {
+	// We do the DNS resolution ourselves, so the net.Dialer won't call the
+	// httptrace DNS hooks, if there are any, and we have to do it here.
+	trace := httptrace.ContextClientTrace(ctx)
+	if trace != nil && trace.DNSStart != nil {
+		trace.DNSStart(httptrace.DNSStartInfo{Host: host})
+	}
+	ips, err := d.Resolver.Fetch(host)
+	if trace != nil && trace.DNSDone != nil {
+		info := httptrace.DNSDoneInfo{Err: err}
+		for _, ip := range ips {
+			info.Addrs = append(info.Addrs, net.IPAddr{IP: ip})
+		}
+		trace.DNSDone(info)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	if ip == nil {
+	if len(ips) == 0 {
 		return nil, errors.Errorf("lookup %s: no such host", host)
 	}
 
-	return lib.NewHostAddress(ip, port)
+	remotes := make([]*lib.HostAddress, len(ips))
+	for i, ip := range ips {
+		if remotes[i], err = lib.NewHostAddress(ip, port); err != nil {
+			return nil, err
+		}
+	}
+	return remotes, nil
 }
 
 func (d *Dialer) getConfiguredHost(addr, host, port string) (*lib.HostAddress, error) {
@@ -248,6 +406,10 @@ type Conn struct {
 	net.Conn
 
 	BytesRead, BytesWritten *int64
+
+	hooks     *lib.ConnHooks
+	proto     string
+	closeOnce sync.Once
 }
 
 func (c *Conn) Read(b []byte) (int, error) {
@@ -265,3 +427,25 @@ func (c *Conn) Write(b []byte) (int, error) {
 	}
 	return n, err
 }
+
+// Close closes the connection and records the disconnect event.
+func (c *Conn) Close() error {
+	err := c.Conn.Close()
+	if c.hooks != nil {
+		c.closeOnce.Do(func() {
+			c.hooks.Record(newConnEvent(lib.ConnEventDisconnect, c.proto, c.Conn))
+		})
+	}
+	return err
+}
+
+func newConnEvent(eventType, proto string, conn net.Conn) lib.ConnEvent {
+	event := lib.ConnEvent{Type: eventType, Protocol: proto}
+	if addr := conn.RemoteAddr(); addr != nil {
+		event.RemoteAddr = addr.String()
+	}
+	if addr := conn.LocalAddr(); addr != nil {
+		event.LocalAddr = addr.String()
+	}
+	return event
+}
@@ -21,18 +21,22 @@
 package netext
 
 import (
+	"context"
 	"net"
 	"testing"
 
 	"github.com/loadimpact/k6/lib"
+	"github.com/loadimpact/k6/lib/metrics"
+	"github.com/loadimpact/k6/stats"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 type testResolver struct {
-	hosts map[string]net.IP
+	hosts map[string][]net.IP
 }
 
-func (r testResolver) FetchOne(host string) (net.IP, error) { return r.hosts[host], nil }
+func (r testResolver) Fetch(host string) ([]net.IP, error) { return r.hosts[host], nil }
 
 func TestDialerAddr(t *testing.T) {
 	dialer := newDialerWithResolver(net.Dialer{}, newResolver())
@@ -80,24 +84,172 @@ func TestDialerAddr(t *testing.T) {
 		tc := tc
 
 		t.Run(tc.address, func(t *testing.T) {
-			addr, err := dialer.getDialAddr(tc.address)
+			addrs, err := dialer.getDialAddrs(context.Background(), tc.address, "")
 
 			if tc.expErr != "" {
 				require.EqualError(t, err, tc.expErr)
 			} else {
 				require.NoError(t, err)
-				require.Equal(t, tc.expAddress, addr)
+				require.Equal(t, []string{tc.expAddress}, addrs)
 			}
 		})
 	}
 }
 
+func TestDialerIPVersion(t *testing.T) {
+	t.Parallel()
+	dialer := newDialerWithResolver(net.Dialer{}, newResolver())
+	dialer.Hosts = map[string]*lib.HostAddress{
+		"example.com": {IP: net.ParseIP("3.4.5.6")},
+	}
+	ipNet, err := lib.ParseCIDR("8.9.10.0/24")
+	require.NoError(t, err)
+	dialer.Blacklist = []*lib.IPNet{ipNet}
+
+	testCases := []struct {
+		address, ipVersion string
+		expAddrs           []string
+		expErr             string
+	}{
+		{"example-dual-stack.com:80", "", nil, "IP (8.9.10.11) is in a blacklisted range (8.9.10.0/24)"},
+		{"example-dual-stack.com:80", lib.IPVersion4, []string{"1.2.3.4:80"}, ""},
+		{"example-dual-stack.com:80", lib.IPVersion6, []string{"[2001:db8::68]:80"}, ""},
+		{"example-dual-stack.com:80", lib.IPVersionAny, []string{"1.2.3.4:80", "[2001:db8::68]:80"}, ""},
+		{"example-resolver.com:80", lib.IPVersion6, nil, "the host example-resolver.com has no IPv6 addresses"},
+		{"example-deny-resolver.com:80", lib.IPVersion4, nil, "IP (8.9.10.11) is in a blacklisted range (8.9.10.0/24)"},
+		{"example.com:80", lib.IPVersion6, nil, "the host example.com has no IPv6 addresses"},
+		{"[2001:db8::68]:80", lib.IPVersion4, nil, "the host 2001:db8::68 has no IPv4 addresses"},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.address+"/"+tc.ipVersion, func(t *testing.T) {
+			t.Parallel()
+			addrs, err := dialer.getDialAddrs(context.Background(), tc.address, tc.ipVersion)
+			if tc.expErr != "" {
+				require.EqualError(t, err, tc.expErr)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tc.expAddrs, addrs)
+			}
+		})
+	}
+}
+
+func TestDialerHappyEyeballs(t *testing.T) {
+	t.Parallel()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_ = conn.Close()
+		}
+	}()
+	_, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+
+	// Nothing listens on the IPv6 loopback, so the IPv4 address has to be used
+	dialer := newDialerWithResolver(net.Dialer{}, testResolver{hosts: map[string][]net.IP{
+		"dual-stack.local": {net.ParseIP("::1"), net.ParseIP("127.0.0.1")},
+	}})
+	dialer.IPVersion = lib.IPVersionAny
+	conn, err := dialer.DialContext(context.Background(), "tcp", net.JoinHostPort("dual-stack.local", port))
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:"+port, conn.RemoteAddr().String())
+	_ = conn.Close()
+
+	ctx := lib.WithIPVersion(context.Background(), lib.IPVersion6)
+	_, err = dialer.DialContext(ctx, "tcp", net.JoinHostPort("dual-stack.local", port))
+	require.Error(t, err)
+}
+
+type failingResolver struct {
+	calls int
+}
+
+func (r *failingResolver) Fetch(host string) ([]net.IP, error) {
+	r.calls++
+	return nil, &net.DNSError{Err: "server misbehaving", Name: host, IsTemporary: true}
+}
+
+func TestDialerRetries(t *testing.T) {
+	t.Parallel()
+
+	newCtx := func() (context.Context, chan stats.SampleContainer) {
+		samples := make(chan stats.SampleContainer, 10)
+		state := &lib.State{Options: lib.Options{SystemTags: &stats.DefaultSystemTagSet}, Samples: samples}
+		return lib.WithState(context.Background(), state), samples
+	}
+	countRetries := func(samples chan stats.SampleContainer) int {
+		retries := 0
+		for _, sc := range stats.GetBufferedSamples(samples) {
+			for _, s := range sc.GetSamples() {
+				require.Equal(t, metrics.ConnectionRetries, s.Metric)
+				retries += int(s.Value)
+			}
+		}
+		return retries
+	}
+
+	t.Run("refused", func(t *testing.T) {
+		t.Parallel()
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addr := listener.Addr().String()
+		require.NoError(t, listener.Close()) // nothing listens on the port anymore
+
+		ctx, samples := newCtx()
+		dialer := newDialerWithResolver(net.Dialer{}, newResolver())
+		dialer.MaxRetries = 2
+		_, err = dialer.DialContext(ctx, "tcp", addr)
+		require.Error(t, err)
+		assert.Equal(t, 2, countRetries(samples))
+	})
+
+	t.Run("dns", func(t *testing.T) {
+		t.Parallel()
+		resolver := &failingResolver{}
+		ctx, samples := newCtx()
+		dialer := newDialerWithResolver(net.Dialer{}, resolver)
+		dialer.MaxRetries = 2
+		_, err := dialer.DialContext(ctx, "tcp", "example.com:80")
+		require.Error(t, err)
+		assert.Equal(t, 1, resolver.calls, "DNS errors shouldn't be retried by default")
+		assert.Equal(t, 0, countRetries(samples))
+
+		dialer.RetryDNSErrors = true
+		_, err = dialer.DialContext(ctx, "tcp", "example.com:80")
+		require.Error(t, err)
+		assert.Equal(t, 4, resolver.calls)
+		assert.Equal(t, 2, countRetries(samples))
+	})
+
+	t.Run("blacklisted", func(t *testing.T) {
+		t.Parallel()
+		ipNet, err := lib.ParseCIDR("8.9.10.0/24")
+		require.NoError(t, err)
+
+		ctx, samples := newCtx()
+		dialer := newDialerWithResolver(net.Dialer{}, newResolver())
+		dialer.Blacklist = []*lib.IPNet{ipNet}
+		dialer.MaxRetries = 2
+		_, err = dialer.DialContext(ctx, "tcp", "8.9.10.11:80")
+		require.Error(t, err)
+		assert.Equal(t, 0, countRetries(samples))
+	})
+}
+
 func newResolver() testResolver {
 	return testResolver{
-		hosts: map[string]net.IP{
-			"example-resolver.com":           net.ParseIP("1.2.3.4"),
-			"example-deny-resolver.com":      net.ParseIP("8.9.10.11"),
-			"example-ipv6-deny-resolver.com": net.ParseIP("::1"),
+		hosts: map[string][]net.IP{
+			"example-resolver.com":           {net.ParseIP("1.2.3.4")},
+			"example-deny-resolver.com":      {net.ParseIP("8.9.10.11")},
+			"example-ipv6-deny-resolver.com": {net.ParseIP("::1")},
+			"example-dual-stack.com":         {net.ParseIP("8.9.10.11"), net.ParseIP("1.2.3.4"), net.ParseIP("2001:db8::68")},
 		},
 	}
 }
@@ -0,0 +1,120 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package netext
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/loadimpact/k6/lib"
+)
+
+// happyEyeballsDelay is how long the connection to the first address is
+// given before the other IP version is tried in parallel, like in net.Dialer.
+const happyEyeballsDelay = 300 * time.Millisecond
+
+func ipVersionName(ipVersion string) string {
+	switch ipVersion {
+	case lib.IPVersion4:
+		return "IPv4"
+	case lib.IPVersion6:
+		return "IPv6"
+	default:
+		return "usable"
+	}
+}
+
+// dialHappyEyeballs races the IPv4 and IPv6 connections to a host, as in
+// RFC 6555. The first address that the host resolved to is dialed first,
+// and the first one of the other IP version is dialed if that fails or
+// isn't connected after happyEyeballsDelay. The first connection that is
+// established is returned, and the other one is closed.
+func (d *Dialer) dialHappyEyeballs(ctx context.Context, proto string, addrs []string) (net.Conn, error) {
+	primary, fallback := addrs[0], ""
+	primaryIsIPv4 := isIPv4Addr(primary)
+	for _, addr := range addrs[1:] {
+		if isIPv4Addr(addr) != primaryIsIPv4 {
+			fallback = addr
+			break
+		}
+	}
+	if fallback == "" {
+		return d.Dialer.DialContext(ctx, proto, primary)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	type dialResult struct {
+		conn    net.Conn
+		err     error
+		primary bool
+	}
+	results := make(chan dialResult, 2)
+	dial := func(addr string, primary bool) {
+		conn, err := d.Dialer.DialContext(ctx, proto, addr)
+		results <- dialResult{conn, err, primary}
+	}
+
+	go dial(primary, true)
+	timer := time.NewTimer(happyEyeballsDelay)
+	defer timer.Stop()
+	pending, fallbackStarted := 1, false
+	startFallback := func() {
+		if !fallbackStarted {
+			fallbackStarted = true
+			pending++
+			go dial(fallback, false)
+		}
+	}
+
+	var primaryErr error
+	for {
+		select {
+		case <-timer.C:
+			startFallback()
+		case res := <-results:
+			pending--
+			if res.err == nil {
+				go func(pending int) { // close the other connection, if it's established too
+					for ; pending > 0; pending-- {
+						if other := <-results; other.conn != nil {
+							_ = other.conn.Close()
+						}
+					}
+				}(pending)
+				return res.conn, nil
+			}
+			if res.primary {
+				primaryErr = res.err
+			}
+			startFallback()
+			if pending == 0 { // both failed
+				return nil, primaryErr
+			}
+		}
+	}
+}
+
+func isIPv4Addr(addr string) bool {
+	host, _, _ := net.SplitHostPort(addr)
+	return net.ParseIP(host).To4() != nil
+}
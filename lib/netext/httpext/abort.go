@@ -0,0 +1,80 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package httpext
+
+import (
+	"errors"
+	"sync"
+)
+
+// AbortController can be used to cancel requests while they are in flight.
+// The requests it cancels are the ones that were passed its Signal with the
+// signal param. Independently of it, the in-flight requests of a VU are
+// cancelled along with its context, i.e. when the gracefulStop period of its
+// scenario is over.
+type AbortController struct {
+	Signal *AbortSignal `js:"signal"`
+}
+
+// NewAbortController returns a new AbortController with a signal that hasn't
+// been aborted yet.
+func NewAbortController() *AbortController {
+	return &AbortController{Signal: &AbortSignal{done: make(chan struct{})}}
+}
+
+// Abort cancels all in-flight requests that were sent with the controller's
+// signal, as well as any requests sent with it afterwards.
+func (c *AbortController) Abort() {
+	c.Signal.abort()
+}
+
+// AbortSignal is the signal of an AbortController that requests are passed.
+type AbortSignal struct {
+	once sync.Once
+	done chan struct{}
+}
+
+// Aborted returns whether the controller of the signal was aborted.
+func (s *AbortSignal) Aborted() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *AbortSignal) abort() {
+	s.once.Do(func() { close(s.done) })
+}
+
+// wrapError returns the error of a request that was sent with the signal as a
+// request aborted error, if the signal was aborted and it isn't one already.
+func (s *AbortSignal) wrapError(err error) error {
+	if err == nil || s == nil || !s.Aborted() {
+		return err
+	}
+	var k6Err K6Error
+	if errors.As(err, &k6Err) && k6Err.Code == requestAbortedErrorCode {
+		return err
+	}
+	return NewK6Error(requestAbortedErrorCode, requestAbortedErrorCodeMsg, err)
+}
@@ -0,0 +1,49 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package httpext
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAbortSignalWrapError(t *testing.T) {
+	t.Parallel()
+	err := errors.New("context canceled")
+	ctrl := NewAbortController()
+	assert.Equal(t, err, ctrl.Signal.wrapError(err))
+
+	var signal *AbortSignal
+	assert.Equal(t, err, signal.wrapError(err))
+
+	ctrl.Abort()
+	assert.Nil(t, ctrl.Signal.wrapError(nil))
+	wrapped := ctrl.Signal.wrapError(err)
+	assert.Equal(t, NewK6Error(requestAbortedErrorCode, requestAbortedErrorCodeMsg, err), wrapped)
+
+	// The errors of the transport are only wrapped once, even if the client
+	// wraps them in turn
+	clientErr := &url.Error{Op: "Get", URL: "http://example.com", Err: wrapped}
+	assert.Equal(t, clientErr, ctrl.Signal.wrapError(clientErr))
+}
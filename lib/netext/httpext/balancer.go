@@ -0,0 +1,165 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package httpext
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/loadimpact/k6/lib"
+)
+
+// The strategies with which a Balancer picks the targets.
+const (
+	BalanceRoundRobin       = "round-robin"
+	BalanceRandom           = "random"
+	BalanceLeastConnections = "least-connections"
+)
+
+// Balancer distributes requests across a list of target hosts, which the
+// connections of the requests are dialed to instead of the host in their URL.
+// The requests themselves aren't changed, so the Host header and the TLS server
+// name and verification are still based on their URL.
+type Balancer struct {
+	targets  []string
+	strategy string
+
+	mu     sync.Mutex
+	next   int
+	active []int // the number of in-flight requests to each target
+}
+
+// NewBalancer returns a new Balancer for the given targets, which are hosts
+// with an optional port, and strategy, round-robin by default.
+func NewBalancer(targets []string, strategy string) (*Balancer, error) {
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("at least one target has to be specified")
+	}
+	for _, target := range targets {
+		if target == "" {
+			return nil, fmt.Errorf("the targets can't be empty")
+		}
+	}
+	switch strategy {
+	case "":
+		strategy = BalanceRoundRobin
+	case BalanceRoundRobin, BalanceRandom, BalanceLeastConnections:
+	default:
+		return nil, fmt.Errorf(
+			"unknown balancing strategy '%s', it should be one of %s, %s or %s",
+			strategy, BalanceRoundRobin, BalanceRandom, BalanceLeastConnections,
+		)
+	}
+	return &Balancer{
+		targets:  append([]string{}, targets...),
+		strategy: strategy,
+		active:   make([]int, len(targets)),
+	}, nil
+}
+
+// pick returns the target for the next request, and a function that has to be
+// called when the request is done. The random strategy picks the target with
+// rnd, a random number in [0, 1).
+func (b *Balancer) pick(rnd float64) (string, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var i int
+	switch b.strategy {
+	case BalanceRandom:
+		i = int(rnd * float64(len(b.targets)))
+	case BalanceLeastConnections:
+		// Ties are broken in a round-robin fashion, so the targets are evenly
+		// used when the requests aren't concurrent
+		i = b.next
+		for j := 1; j < len(b.targets); j++ {
+			if k := (b.next + j) % len(b.targets); b.active[k] < b.active[i] {
+				i = k
+			}
+		}
+		b.next = (i + 1) % len(b.targets)
+	default:
+		i = b.next
+		b.next = (b.next + 1) % len(b.targets)
+	}
+
+	b.active[i]++
+	var once sync.Once
+	return b.targets[i], func() {
+		once.Do(func() {
+			b.mu.Lock()
+			b.active[i]--
+			b.mu.Unlock()
+		})
+	}
+}
+
+// balancerTransport returns a transport, based on the given one, that dials
+// the target instead of the host of the given URL. The transports are cached
+// in the VU state, so that the connections to every target are reused and
+// closed with the other connections of the VU. Connections to other addresses,
+// like the ones of proxies or of redirects to other hosts, aren't changed.
+func balancerTransport(state *lib.State, base *http.Transport, u *url.URL, target string) http.RoundTripper {
+	addr := canonicalAddr(u)
+	return state.BalancerTransport(addr+" "+target, func() http.RoundTripper {
+		dial := base.DialContext
+		if dial == nil {
+			dial = (&net.Dialer{}).DialContext
+		}
+		targetAddr := targetHost(target, addr)
+		t := cloneTransport(base)
+		t.DialContext = func(ctx context.Context, network, dialAddr string) (net.Conn, error) {
+			if dialAddr == addr {
+				dialAddr = targetAddr
+			}
+			return dial(ctx, network, dialAddr)
+		}
+		return t
+	})
+}
+
+// canonicalAddr returns the host and port that net/http dials for the URL.
+func canonicalAddr(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return net.JoinHostPort(u.Hostname(), port)
+	}
+	port := "80"
+	if u.Scheme == "https" {
+		port = "443"
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+// targetHost returns the host the request for the given URL host should be
+// sent to, with the port of the URL if the target doesn't have one.
+func targetHost(target, urlHost string) string {
+	if _, _, err := net.SplitHostPort(target); err == nil {
+		return target
+	}
+	if _, port, err := net.SplitHostPort(urlHost); err == nil {
+		return net.JoinHostPort(target, port)
+	}
+	return target
+}
@@ -0,0 +1,101 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package httpext
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBalancer(t *testing.T) {
+	t.Parallel()
+	targets := []string{"10.0.0.1:8080", "10.0.0.2:8080", "10.0.0.3:8080"}
+
+	t.Run("round-robin", func(t *testing.T) {
+		t.Parallel()
+		b, err := NewBalancer(targets, "")
+		require.NoError(t, err)
+		for i := 0; i < 6; i++ {
+			target, done := b.pick(0)
+			done()
+			assert.Equal(t, targets[i%3], target)
+		}
+	})
+
+	t.Run("random", func(t *testing.T) {
+		t.Parallel()
+		b, err := NewBalancer(targets, BalanceRandom)
+		require.NoError(t, err)
+		for i, rnd := range []float64{0, 0.3, 0.4, 0.6, 0.7, 0.99} {
+			target, done := b.pick(rnd)
+			done()
+			assert.Equal(t, targets[i/2], target)
+		}
+	})
+
+	t.Run("least-connections", func(t *testing.T) {
+		t.Parallel()
+		b, err := NewBalancer(targets, BalanceLeastConnections)
+		require.NoError(t, err)
+		first, doneFirst := b.pick(0)
+		second, doneSecond := b.pick(0)
+		assert.Equal(t, targets[0], first)
+		assert.Equal(t, targets[1], second)
+		doneFirst()
+		doneFirst() // calling it again shouldn't change anything
+		third, doneThird := b.pick(0)
+		assert.Equal(t, targets[2], third)
+		fourth, _ := b.pick(0)
+		assert.Equal(t, targets[0], fourth)
+		doneSecond()
+		doneThird()
+		fifth, _ := b.pick(0)
+		assert.Equal(t, targets[1], fifth)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		t.Parallel()
+		_, err := NewBalancer(nil, "")
+		assert.Error(t, err)
+		_, err = NewBalancer([]string{""}, "")
+		assert.Error(t, err)
+		_, err = NewBalancer(targets, "fastest")
+		assert.Error(t, err)
+	})
+}
+
+func TestTargetHost(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "10.0.0.1:8080", targetHost("10.0.0.1:8080", "example.com:9090"))
+	assert.Equal(t, "10.0.0.1:9090", targetHost("10.0.0.1", "example.com:9090"))
+	assert.Equal(t, "10.0.0.1", targetHost("10.0.0.1", "example.com"))
+}
+
+func TestCanonicalAddr(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "example.com:80", canonicalAddr(&url.URL{Scheme: "http", Host: "example.com"}))
+	assert.Equal(t, "example.com:443", canonicalAddr(&url.URL{Scheme: "https", Host: "example.com"}))
+	assert.Equal(t, "example.com:8443", canonicalAddr(&url.URL{Scheme: "https", Host: "example.com:8443"}))
+	assert.Equal(t, "[::1]:80", canonicalAddr(&url.URL{Scheme: "http", Host: "[::1]"}))
+}
@@ -22,9 +22,13 @@ package httpext
 
 import (
 	"context"
+	"net/http"
+	"sort"
+	"strings"
 	"sync/atomic"
 
 	"github.com/loadimpact/k6/lib"
+	"github.com/loadimpact/k6/stats"
 )
 
 // BatchParsedHTTPRequest extends the normal parsed HTTP request with a pointer
@@ -33,6 +37,11 @@ import (
 type BatchParsedHTTPRequest struct {
 	*ParsedHTTPRequest
 	Response *Response // this is modified by MakeBatchRequests()
+
+	// Done is optional. If it's not nil, MakeBatchRequests() will also send
+	// the request's error (or nil) to it once Response has been populated, so
+	// it should have a buffer of at least 1.
+	Done chan error
 }
 
 // MakeBatchRequests concurrently makes multiple requests. It spawns
@@ -42,43 +51,157 @@ type BatchParsedHTTPRequest struct {
 // pre-initialized. In addition, each processed request would emit either a nil
 // value, or an error, via the returned errors channel. The goroutines exit when
 // the requests channel is closed.
+//
+// If the requestCoalescing option is enabled, only the first of the GET and
+// HEAD requests without a body that have the same method, URL and headers is
+// actually made and the others get copies of its response and of its metrics,
+// with a coalesced tag.
 func MakeBatchRequests(
 	ctx context.Context,
 	requests []BatchParsedHTTPRequest,
 	reqCount, globalLimit, perHostLimit int,
 ) <-chan error {
-	workers := globalLimit
-	if reqCount < workers {
-		workers = reqCount
-	}
 	result := make(chan error, reqCount)
 	perHostLimiter := lib.NewMultiSlotLimiter(perHostLimit)
 
-	makeRequest := func(req BatchParsedHTTPRequest) {
+	primaries, followers := make([]int, 0, reqCount), map[int][]int{}
+	if state := lib.GetState(ctx); state != nil && state.Options.RequestCoalescing.Bool {
+		primaries, followers = coalesceRequests(requests[:reqCount])
+	} else {
+		for i := 0; i < reqCount; i++ {
+			primaries = append(primaries, i)
+		}
+	}
+
+	workers := globalLimit
+	if len(primaries) < workers {
+		workers = len(primaries)
+	}
+
+	finishRequest := func(req BatchParsedHTTPRequest, err error) {
+		if req.Done != nil {
+			req.Done <- err
+		}
+		result <- err
+	}
+
+	makeRequest := func(reqNum int) {
+		req := requests[reqNum]
 		if hl := perHostLimiter.Slot(req.URL.GetURL().Host); hl != nil {
 			hl.Begin()
 			defer hl.End()
 		}
 
 		resp, err := MakeRequest(ctx, req.ParsedHTTPRequest)
+		for _, followerNum := range followers[reqNum] {
+			follower := requests[followerNum]
+			if resp != nil {
+				*follower.Response = *resp
+				if body, ok := resp.Body.([]byte); ok {
+					follower.Response.Body = append([]byte(nil), body...)
+				}
+				emitCoalescedMetrics(ctx, resp.trail, req.ParsedHTTPRequest, follower.ParsedHTTPRequest)
+			}
+			finishRequest(follower, err)
+		}
 		if resp != nil {
 			*req.Response = *resp
 		}
-		result <- err
+		finishRequest(req, err)
 	}
 
-	counter, i32reqCount := int32(-1), int32(reqCount)
+	counter, i32primaryCount := int32(-1), int32(len(primaries))
 	for i := 0; i < workers; i++ {
 		go func() {
 			for {
 				reqNum := atomic.AddInt32(&counter, 1)
-				if reqNum >= i32reqCount {
+				if reqNum >= i32primaryCount {
 					return
 				}
-				makeRequest(requests[reqNum])
+				makeRequest(primaries[reqNum])
 			}
 		}()
 	}
 
 	return result
 }
+
+// coalesceRequests groups the identical requests together. It returns the
+// indexes of the requests that should actually be made and, for each one of
+// them that has duplicates, the indexes of the requests that will share its
+// response. Only the requests that are safe to repeat are coalesced, the
+// others are always made.
+func coalesceRequests(requests []BatchParsedHTTPRequest) (primaries []int, followers map[int][]int) {
+	primaries, followers = make([]int, 0, len(requests)), map[int][]int{}
+	seen := make(map[string]int, len(requests))
+	for i, req := range requests {
+		if !isCoalescable(req.ParsedHTTPRequest) {
+			primaries = append(primaries, i)
+			continue
+		}
+		key := coalescingKey(req.ParsedHTTPRequest)
+		if primary, ok := seen[key]; ok {
+			followers[primary] = append(followers[primary], i)
+			continue
+		}
+		seen[key] = i
+		primaries = append(primaries, i)
+	}
+	return primaries, followers
+}
+
+// isCoalescable returns whether the request is a GET or HEAD one without a
+// body, which can share the response of an identical one.
+func isCoalescable(preq *ParsedHTTPRequest) bool {
+	if preq.Req.Method != http.MethodGet && preq.Req.Method != http.MethodHead {
+		return false
+	}
+	return preq.Body == nil || preq.Body.Len() == 0
+}
+
+// coalescingKey returns a string that's the same for requests with the same
+// method, URL, headers and response type.
+func coalescingKey(preq *ParsedHTTPRequest) string {
+	var key strings.Builder
+	key.WriteString(preq.Req.Method + " " + preq.URL.URL + " " + preq.ResponseType.String() + "\n")
+
+	headerNames := make([]string, 0, len(preq.Req.Header))
+	for name := range preq.Req.Header {
+		headerNames = append(headerNames, name)
+	}
+	sort.Strings(headerNames)
+	for _, name := range headerNames {
+		key.WriteString(name + ": " + strings.Join(preq.Req.Header[name], ", ") + "\n")
+	}
+	return key.String()
+}
+
+// emitCoalescedMetrics emits the metrics of a request that wasn't made because
+// it was coalesced with an identical one. They are a copy of the ones of the
+// request that was made, but with the tags of the coalesced request.
+func emitCoalescedMetrics(ctx context.Context, trail *Trail, primary, coalesced *ParsedHTTPRequest) {
+	state := lib.GetState(ctx)
+	if trail == nil || trail.Tags == nil || state == nil {
+		return
+	}
+
+	tags := trail.Tags.CloneTags()
+	for k := range primary.Tags {
+		delete(tags, k)
+	}
+	for k, v := range coalesced.Tags {
+		tags[k] = v
+	}
+	if _, ok := tags["name"]; !ok && state.Options.SystemTags.Has(stats.TagName) {
+		if coalesced.URL.Name != "" && coalesced.URL.Name != coalesced.URL.Clean() {
+			tags["name"] = coalesced.URL.Name
+		} else if url, ok := tags["url"]; ok {
+			tags["name"] = url
+		}
+	}
+	tags["coalesced"] = "true"
+
+	coalescedTrail := *trail
+	coalescedTrail.SaveSamples(stats.IntoSampleTags(&tags))
+	stats.PushIfNotDone(ctx, state.Samples, &coalescedTrail)
+}
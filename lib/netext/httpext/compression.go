@@ -26,7 +26,6 @@ import (
 	"compress/zlib"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"net/http"
 	"strings"
 
@@ -56,6 +55,23 @@ const (
 	// https://en.wikipedia.org/wiki/HTTP_compression#Content-Encoding_tokens
 )
 
+// The Accept-Encoding header that is sent with requests by default, unless the
+// disableCompression option is set. All of these are decompressed transparently.
+const defaultAcceptEncoding = "gzip, deflate, br, zstd"
+
+// countingReadCloser counts the bytes that are read through it, e.g. the size
+// of a response body before it's decompressed.
+type countingReadCloser struct {
+	io.ReadCloser
+	n int64
+}
+
+func (c *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
 func compressBody(algos []CompressionType, body io.ReadCloser) (*bytes.Buffer, string, error) {
 	var contentEncoding string
 	var prevBuf io.Reader = body
@@ -140,27 +156,41 @@ func readResponseBody(
 	respType ResponseType,
 	resp *http.Response,
 	respErr error,
-) (interface{}, error) {
+) (interface{}, int64, error) {
 	if resp == nil || respErr != nil {
-		return nil, respErr
+		return nil, 0, respErr
 	}
 
 	if respType == ResponseTypeNone {
-		_, err := io.Copy(ioutil.Discard, resp.Body)
+		// Nothing is decompressed, so this is the size of the body on the wire
+		size, err := io.Copy(io.Discard, resp.Body)
 		_ = resp.Body.Close()
 		if err != nil {
 			respErr = err
 		}
-		return nil, respErr
+		return nil, size, respErr
 	}
 
 	rc := &readCloser{resp.Body}
 	// Ensure that the entire response body is read and closed, e.g. in case of decoding errors
 	defer func(respBody io.ReadCloser) {
-		_, _ = io.Copy(ioutil.Discard, respBody)
+		_, _ = io.Copy(io.Discard, respBody)
 		_ = respBody.Close()
 	}(resp.Body)
 
+	// All of the decoders are closed, not just the outermost one, since the
+	// zstd ones have goroutines that are only stopped by closing them
+	var decoders []*readCloser
+	var outermostClosed bool
+	defer func() {
+		for i, d := range decoders {
+			if i == len(decoders)-1 && outermostClosed {
+				continue
+			}
+			_ = d.Close()
+		}
+	}()
+
 	contentEncodings := strings.Split(resp.Header.Get("Content-Encoding"), ",")
 	// Transparently decompress the body if it's has a content-encoding we
 	// support. If not, simply return it as it is.
@@ -186,9 +216,10 @@ func readResponseBody(
 				)
 			}
 			if err != nil {
-				return nil, newDecompressionError(err)
+				return nil, 0, newDecompressionError(err)
 			}
 			rc = &readCloser{decoder}
+			decoders = append(decoders, rc)
 		}
 	}
 	buf := state.BPool.Get()
@@ -200,6 +231,7 @@ func readResponseBody(
 	}
 
 	err = rc.Close()
+	outermostClosed = true
 	if err != nil && respErr == nil { // Don't overwrite previous errors
 		respErr = wrapDecompressionError(err)
 	}
@@ -219,5 +251,5 @@ func readResponseBody(
 		respErr = fmt.Errorf("unknown responseType %s", respType)
 	}
 
-	return result, respErr
+	return result, int64(buf.Len()), respErr
 }
@@ -21,9 +21,11 @@
 package httpext
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
+	"io"
 	"net"
 	"net/url"
 	"os"
@@ -46,6 +48,9 @@ const (
 	// non specific
 	defaultErrorCode          errCode = 1000
 	defaultNetNonTCPErrorCode errCode = 1010
+	requestTimeoutErrorCode   errCode = 1050
+	requestAbortedErrorCode   errCode = 1051
+	responseReadErrorCode     errCode = 1060
 	// DNS errors
 	defaultDNSErrorCode    errCode = 1100
 	dnsNoSuchHostErrorCode errCode = 1101
@@ -63,6 +68,7 @@ const (
 	defaultTLSErrorCode           errCode = 1300
 	x509UnknownAuthorityErrorCode errCode = 1310
 	x509HostnameErrorCode         errCode = 1311
+	x509CertificateInvalidCode    errCode = 1312
 
 	// HTTP2 errors
 	// defaultHTTP2ErrorCode errCode = 1600 // commented because of golint
@@ -84,6 +90,7 @@ const (
 )
 
 const (
+	requestAbortedErrorCodeMsg  = "request aborted"
 	tcpResetByPeerErrorCodeMsg  = "write: connection reset by peer"
 	tcpDialTimeoutErrorCodeMsg  = "dial: i/o timeout"
 	tcpDialRefusedErrorCodeMsg  = "dial: connection refused"
@@ -107,7 +114,15 @@ func http2ErrCodeOffset(code http2.ErrCode) errCode {
 
 // errorCodeForError returns the errorCode and a specific error message for given error.
 func errorCodeForError(err error) (errCode, string) {
-	switch e := errors.Cause(err).(type) {
+	cause := errors.Cause(err)
+	switch cause {
+	case context.DeadlineExceeded:
+		return requestTimeoutErrorCode, err.Error()
+	case io.ErrUnexpectedEOF:
+		return responseReadErrorCode, err.Error()
+	}
+
+	switch e := cause.(type) {
 	case K6Error:
 		return e.Code, e.Message
 	case *net.DNSError:
@@ -129,6 +144,9 @@ func errorCodeForError(err error) (errCode, string) {
 		return unknownHTTP2ConnectionErrorCode + http2ErrCodeOffset(http2.ErrCode(*e)),
 			fmt.Sprintf(http2ConnectionErrorCodeMsg, http2.ErrCode(*e))
 	case *net.OpError:
+		if e.Op == "remote error" { // an alert sent by the server during the TLS handshake
+			return defaultTLSErrorCode, err.Error()
+		}
 		if e.Net != "tcp" && e.Net != "tcp6" {
 			// TODO: figure out how this happens
 			return defaultNetNonTCPErrorCode, err.Error()
@@ -143,6 +161,11 @@ func errorCodeForError(err error) (errCode, string) {
 				}
 			}
 		}
+		if e.Op == "read" {
+			if sErr, ok := e.Err.(*os.SyscallError); ok && sErr.Err == syscall.ECONNRESET {
+				return tcpResetByPeerErrorCode, err.Error()
+			}
+		}
 		if e.Op == "dial" {
 			if e.Timeout() {
 				return tcpDialTimeoutErrorCode, tcpDialTimeoutErrorCodeMsg
@@ -175,6 +198,8 @@ func errorCodeForError(err error) (errCode, string) {
 		return x509UnknownAuthorityErrorCode, x509UnknownAuthority
 	case *x509.HostnameError:
 		return x509HostnameErrorCode, x509HostnameErrorCodeMsg
+	case x509.CertificateInvalidError:
+		return x509CertificateInvalidCode, err.Error()
 	case *tls.RecordHeaderError:
 		return defaultTLSErrorCode, err.Error()
 	case *url.Error:
@@ -21,9 +21,11 @@
 package httpext
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
+	"io"
 	"net"
 	"net/url"
 	"os"
@@ -42,6 +44,14 @@ func TestDefaultError(t *testing.T) {
 	testErrorCode(t, defaultErrorCode, fmt.Errorf("random error"))
 }
 
+func TestRequestErrors(t *testing.T) {
+	var testTable = map[errCode]error{
+		requestTimeoutErrorCode: context.DeadlineExceeded,
+		responseReadErrorCode:   io.ErrUnexpectedEOF,
+	}
+	testMapOfErrorCodes(t, testTable)
+}
+
 func TestHTTP2Errors(t *testing.T) {
 	var unknownErrorCode = 220
 	var connectionError = http2.ConnectionError(unknownErrorCode)
@@ -61,9 +71,16 @@ func TestTLSErrors(t *testing.T) {
 	var testTable = map[errCode]error{
 		x509UnknownAuthorityErrorCode: new(x509.UnknownAuthorityError),
 		x509HostnameErrorCode:         new(x509.HostnameError),
+		x509CertificateInvalidCode:    x509.CertificateInvalidError{Reason: x509.Expired},
 		defaultTLSErrorCode:           new(tls.RecordHeaderError),
 	}
 	testMapOfErrorCodes(t, testTable)
+
+	// TLS alerts sent by the server are wrapped in a *net.OpError without a network
+	testTable = map[errCode]error{
+		defaultTLSErrorCode: &net.OpError{Op: "remote error", Err: errors.New("tls: handshake failure")},
+	}
+	testMapOfErrorCodes(t, testTable)
 }
 
 func TestDNSErrors(t *testing.T) {
@@ -115,6 +132,7 @@ func TestTCPErrors(t *testing.T) {
 	var (
 		nonTCPError       = &net.OpError{Net: "something", Err: errors.New("non tcp error")}
 		econnreset        = &net.OpError{Net: "tcp", Op: "write", Err: &os.SyscallError{Err: syscall.ECONNRESET}}
+		readeconnreset    = &net.OpError{Net: "tcp", Op: "read", Err: &os.SyscallError{Err: syscall.ECONNRESET}}
 		epipeerror        = &net.OpError{Net: "tcp", Op: "write", Err: &os.SyscallError{Err: syscall.EPIPE}}
 		econnrefused      = &net.OpError{Net: "tcp", Op: "dial", Err: &os.SyscallError{Err: syscall.ECONNREFUSED}}
 		errnounknown      = &net.OpError{Net: "tcp", Op: "dial", Err: &os.SyscallError{Err: syscall.E2BIG}}
@@ -135,6 +153,10 @@ func TestTCPErrors(t *testing.T) {
 	}
 
 	testMapOfErrorCodes(t, testTable)
+
+	var errorCode, errorMsg = errorCodeForError(readeconnreset)
+	require.Equal(t, tcpResetByPeerErrorCode, errorCode)
+	require.Equal(t, readeconnreset.Error(), errorMsg)
 }
 
 func testErrorCode(t *testing.T, code errCode, err error) {
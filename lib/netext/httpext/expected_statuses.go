@@ -0,0 +1,65 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+package httpext
+
+import "fmt"
+
+// ExpectedStatuses is a response callback, created with http.expectedStatuses(),
+// which considers the responses with one of its statuses as expected and all
+// of the other ones as failed.
+type ExpectedStatuses struct {
+	ranges []statusRange
+}
+
+type statusRange struct {
+	min, max int
+}
+
+// AddRange adds the statuses from min to max, inclusive, to the expected ones.
+func (e *ExpectedStatuses) AddRange(min, max int) error {
+	if min < 100 || max > 599 || min > max {
+		return fmt.Errorf("invalid expected status range %d-%d, the statuses should be from 100 to 599", min, max)
+	}
+	e.ranges = append(e.ranges, statusRange{min: min, max: max})
+	return nil
+}
+
+// Expected reports whether the status is one of the expected ones.
+func (e *ExpectedStatuses) Expected(status int) bool {
+	for _, r := range e.ranges {
+		if status >= r.min && status <= r.max {
+			return true
+		}
+	}
+	return false
+}
+
+// isFailed reports whether a request failed, i.e. if it returned an error or
+// its response status wasn't expected by the response callback. Without a
+// callback, the 4xx and 5xx statuses are the unexpected ones.
+func isFailed(err error, status int, responseCallback func(status int) bool) bool {
+	if err != nil {
+		return true
+	}
+	if responseCallback == nil {
+		return status >= 400
+	}
+	return !responseCallback(status)
+}
@@ -0,0 +1,49 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+package httpext
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpectedStatuses(t *testing.T) {
+	t.Parallel()
+	expected := &ExpectedStatuses{}
+	require.NoError(t, expected.AddRange(200, 204))
+	require.NoError(t, expected.AddRange(404, 404))
+	assert.Error(t, expected.AddRange(300, 200))
+	assert.Error(t, expected.AddRange(99, 200))
+	assert.Error(t, expected.AddRange(500, 600))
+
+	for status, exp := range map[int]bool{200: true, 204: true, 206: false, 404: true, 500: false} {
+		assert.Equal(t, exp, expected.Expected(status), status)
+	}
+
+	assert.True(t, isFailed(errors.New("error"), 0, nil))
+	assert.True(t, isFailed(errors.New("error"), 0, expected.Expected))
+	assert.False(t, isFailed(nil, 399, nil))
+	assert.True(t, isFailed(nil, 400, nil))
+	assert.False(t, isFailed(nil, 404, expected.Expected))
+	assert.True(t, isFailed(nil, 206, expected.Expected))
+}
@@ -0,0 +1,172 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package httpext
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/loadimpact/k6/lib"
+	"github.com/loadimpact/k6/lib/metrics"
+	"github.com/loadimpact/k6/stats"
+)
+
+// MultipartPart is a single part of a streaming multipart response.
+type MultipartPart struct {
+	Headers map[string]string `json:"headers"`
+	Body    interface{}       `json:"body"`
+}
+
+// MultipartStream reads the parts of a streaming multipart response, i.e. one
+// with a multipart/x-mixed-replace content type, one by one as they arrive,
+// instead of waiting for the whole response body, which may never end.
+type MultipartStream struct {
+	ctx          context.Context
+	cancel       context.CancelFunc
+	body         io.ReadCloser
+	reader       *multipart.Reader
+	responseType ResponseType
+	tags         *stats.SampleTags // the same as the ones of the request metrics
+	done         bool
+}
+
+// newMultipartStream returns a MultipartStream for the response if it's a
+// streaming multipart response or nil otherwise. The stream takes ownership
+// of the response body and of the request context cancel function.
+func newMultipartStream(
+	ctx context.Context, cancel context.CancelFunc, res *http.Response,
+	responseType ResponseType,
+) *MultipartStream {
+	mediaType, params, err := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/x-mixed-replace" || params["boundary"] == "" {
+		return nil
+	}
+
+	return &MultipartStream{
+		ctx:          ctx,
+		cancel:       cancel,
+		body:         res.Body,
+		reader:       multipart.NewReader(res.Body, params["boundary"]),
+		responseType: responseType,
+	}
+}
+
+// NextPart blocks until the next part of the response has been received and
+// returns it. It returns nil once the response has ended or was closed.
+func (s *MultipartStream) NextPart() (*MultipartPart, error) {
+	if s.done {
+		return nil, nil
+	}
+
+	p, err := s.reader.NextPart()
+	if err == io.EOF {
+		return nil, s.Close()
+	}
+	if err != nil {
+		_ = s.Close()
+		return nil, err
+	}
+
+	part, err := readMultipartPart(p, s.responseType)
+	if err != nil {
+		_ = s.Close()
+		return nil, err
+	}
+
+	if state := lib.GetState(s.ctx); state != nil {
+		stats.PushIfNotDone(s.ctx, state.Samples, stats.Sample{
+			Time:   time.Now(),
+			Metric: metrics.MultipartPartsReceived,
+			Value:  1,
+			Tags:   s.tags,
+		})
+	}
+
+	return part, nil
+}
+
+// Close stops reading the response and releases the connection.
+func (s *MultipartStream) Close() error {
+	if s.done {
+		return nil
+	}
+	s.done = true
+	err := s.body.Close()
+	s.cancel()
+	return err
+}
+
+// ParseMultipartBody parses an already received multipart response body, e.g.
+// a multipart/form-data or multipart/mixed one, and returns its parts. The
+// boundary is taken from the contentType.
+func ParseMultipartBody(contentType string, body []byte, responseType ResponseType) ([]*MultipartPart, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid multipart response Content-Type '%s': %w", contentType, err)
+	}
+	if !strings.HasPrefix(mediaType, "multipart/") || params["boundary"] == "" {
+		return nil, fmt.Errorf("the response Content-Type '%s' isn't multipart with a boundary", contentType)
+	}
+
+	reader := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+	parts := []*MultipartPart{}
+	for {
+		p, err := reader.NextPart()
+		if err == io.EOF {
+			return parts, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		part, err := readMultipartPart(p, responseType)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+	}
+}
+
+// readMultipartPart reads the headers and the whole body of a multipart part.
+func readMultipartPart(p *multipart.Part, responseType ResponseType) (*MultipartPart, error) {
+	data, err := ioutil.ReadAll(p)
+	if err != nil {
+		return nil, err
+	}
+
+	part := &MultipartPart{Headers: make(map[string]string, len(p.Header))}
+	for k, vs := range p.Header {
+		part.Headers[k] = strings.Join(vs, ", ")
+	}
+	if responseType == ResponseTypeBinary {
+		part.Body = data
+	} else {
+		part.Body = string(data)
+	}
+	return part, nil
+}
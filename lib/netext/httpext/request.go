@@ -36,9 +36,12 @@ import (
 
 	"github.com/Azure/go-ntlmssp"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
 	"gopkg.in/guregu/null.v3"
 
 	"github.com/loadimpact/k6/lib"
+	"github.com/loadimpact/k6/lib/metrics"
+	"github.com/loadimpact/k6/lib/netext"
 	"github.com/loadimpact/k6/stats"
 )
 
@@ -115,6 +118,22 @@ type ParsedHTTPRequest struct {
 	ActiveJar    *cookiejar.Jar
 	Cookies      map[string]*HTTPRequestCookie
 	Tags         map[string]string
+	Balancer     *Balancer
+	BalancerRand float64 // drawn from the VU's random generator, see Balancer.pick()
+
+	// Decides which response statuses are expected, the state's
+	// ResponseCallback is used if it's nil
+	ResponseCallback func(status int) bool
+	Signal           *AbortSignal
+	IPVersion        string // overrides the ipVersion option
+
+	// The VU's mocks when the request was made, see lib.State.HTTPMocks
+	Mocks lib.HTTPMocks
+	// The VU's rate limit when the request was made, see lib.State.VURPSLimit
+	VURPSLimit *rate.Limiter
+
+	// Don't set the default Accept-Encoding header
+	DisableCompression bool
 }
 
 // Matches non-compliant io.Closer implementations (e.g. zstd.Decoder)
@@ -162,6 +181,8 @@ func updateK6Response(k6Response *Response, finishedReq *finishedRequest) {
 	k6Response.Timings = ResponseTimings{
 		Duration:       stats.D(trail.Duration),
 		Blocked:        stats.D(trail.Blocked),
+		DNSLookup:      stats.D(trail.LookingUp),
+		LookingUp:      stats.D(trail.LookingUp),
 		Connecting:     stats.D(trail.Connecting),
 		TLSHandshaking: stats.D(trail.TLSHandshaking),
 		Sending:        stats.D(trail.Sending),
@@ -215,6 +236,14 @@ func MakeRequest(ctx context.Context, preq *ParsedHTTPRequest) (*Response, error
 		preq.Req.Body, _ = preq.Req.GetBody()
 	}
 
+	if _, ok := preq.Req.Header["Accept-Encoding"]; !ok && !preq.DisableCompression {
+		preq.Req.Header.Set("Accept-Encoding", defaultAcceptEncoding)
+	}
+
+	if state.TraceID != "" {
+		setTraceContextHeaders(preq.Req.Header, state)
+	}
+
 	if contentLengthHeader := preq.Req.Header.Get("Content-Length"); contentLengthHeader != "" {
 		// The content-length header was set by the user, delete it (since Go
 		// will set it automatically) and warn if there were differences
@@ -243,13 +272,50 @@ func MakeRequest(ctx context.Context, preq *ParsedHTTPRequest) (*Response, error
 	}
 
 	// Check rate limit *after* we've prepared a request; no need to wait with that part.
-	if rpsLimit := state.RPSLimit; rpsLimit != nil {
+	// The VU's limit is waited for first, so that a VU that's throttled by it
+	// doesn't hold a token of the global limit that other VUs could use.
+	queueStart := time.Now()
+	for _, rpsLimit := range []*rate.Limiter{preq.VURPSLimit, state.RPSLimit} {
+		if rpsLimit == nil {
+			continue
+		}
 		if err := rpsLimit.Wait(ctx); err != nil {
 			return nil, err
 		}
 	}
+	rateLimited := state.RPSLimit != nil || preq.VURPSLimit != nil
+	queued := time.Since(queueStart)
+
+	var target string
+	if preq.Balancer != nil {
+		var done func()
+		target, done = preq.Balancer.pick(preq.BalancerRand)
+		defer done()
+		tags["target"] = target
+	}
 
 	tracerTransport := newTransport(ctx, state, tags)
+	tracerTransport.rateLimited, tracerTransport.queued = rateLimited, queued
+	tracerTransport.signal = preq.Signal
+	tracerTransport.mocks = preq.Mocks
+	responseCallback := preq.ResponseCallback
+	if responseCallback == nil {
+		responseCallback = state.ResponseCallback
+	}
+	tracerTransport.responseCallback = responseCallback
+	if t, ok := state.Transport.(*http.Transport); ok && target != "" {
+		tracerTransport.roundTripper = balancerTransport(state, t, preq.Req.URL, target)
+	}
+	if preq.IPVersion != "" {
+		// The idle connections of the VU's transport could be of the other IP
+		// version, so the request is sent with a transport for its IP version
+		ctx = lib.WithIPVersion(ctx, preq.IPVersion)
+		if t, ok := state.Transport.(*http.Transport); ok {
+			tracerTransport.roundTripper = state.IPVersionTransport(preq.IPVersion, func() http.RoundTripper {
+				return cloneTransport(t)
+			})
+		}
+	}
 	var transport http.RoundTripper = tracerTransport
 
 	if state.Options.HTTPDebug.String != "" {
@@ -266,7 +332,11 @@ func MakeRequest(ctx context.Context, preq *ParsedHTTPRequest) (*Response, error
 		transport = ntlmssp.Negotiator{RoundTripper: transport}
 	}
 
-	resp := &Response{ctx: ctx, URL: preq.URL.URL, Request: *respReq}
+	resp := &Response{
+		ctx: ctx, URL: preq.URL.URL, Request: *respReq,
+		// An empty list instead of null for the requests that didn't use TLS
+		TLSCertificates: []netext.Certificate{},
+	}
 	client := http.Client{
 		Transport: transport,
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
@@ -291,6 +361,7 @@ func MakeRequest(ctx context.Context, preq *ParsedHTTPRequest) (*Response, error
 						"Stopped after %d redirects and returned the redirection; pass { redirects: n }"+
 							" in request params or set global maxRedirects to silence this", l)
 				}
+				resp.RedirectsExceeded = true
 				return http.ErrUseLastResponse
 			}
 			return nil
@@ -298,7 +369,20 @@ func MakeRequest(ctx context.Context, preq *ParsedHTTPRequest) (*Response, error
 	}
 
 	reqCtx, cancelFunc := context.WithTimeout(ctx, preq.Timeout)
-	defer cancelFunc()
+	defer func() {
+		if resp.multipartStream == nil { // otherwise the stream cancels it when it's closed
+			cancelFunc()
+		}
+	}()
+	if preq.Signal != nil {
+		go func() {
+			select {
+			case <-preq.Signal.done:
+				cancelFunc()
+			case <-reqCtx.Done():
+			}
+		}()
+	}
 	mreq := preq.Req.WithContext(reqCtx)
 	res, resErr := client.Do(mreq)
 
@@ -311,10 +395,52 @@ func MakeRequest(ctx context.Context, preq *ParsedHTTPRequest) (*Response, error
 		return nil, fmt.Errorf("unsupported response status: %s", res.Status)
 	}
 
-	resp.Body, resErr = readResponseBody(state, preq.ResponseType, res, resErr)
+	if resErr == nil && preq.ResponseType != ResponseTypeNone {
+		resp.multipartStream = newMultipartStream(ctx, cancelFunc, res, preq.ResponseType)
+		if resp.multipartStream != nil {
+			state.CloseAtIterationEnd(resp.multipartStream)
+		}
+	}
+	var compressedBody *countingReadCloser
+	if resp.multipartStream == nil {
+		if resErr == nil && preq.ResponseType != ResponseTypeNone && res.Header.Get("Content-Encoding") != "" {
+			compressedBody = &countingReadCloser{ReadCloser: res.Body}
+			res.Body = compressedBody
+			resp.CompressionAlgorithm = strings.ToLower(strings.Join(res.Header.Values("Content-Encoding"), ", "))
+		}
+		resp.Body, resp.BodySize, resErr = readResponseBody(state, preq.ResponseType, res, resErr)
+		resp.CompressedSize, resp.DecompressedSize = resp.BodySize, resp.BodySize
+		if compressedBody != nil {
+			resp.CompressedSize = compressedBody.n
+		}
+	}
+	// The error of the round trip was already wrapped by the transport, but the
+	// request could have been aborted while its body was being read too
+	resErr = preq.Signal.wrapError(resErr)
 	finishedReq := tracerTransport.processLastSavedRequest(wrapDecompressionError(resErr))
 	if finishedReq != nil {
 		updateK6Response(resp, finishedReq)
+		resp.trail = finishedReq.trail
+		if resp.multipartStream != nil {
+			resp.multipartStream.tags = finishedReq.trail.Tags
+		}
+		if compressedBody != nil && resErr == nil {
+			stats.PushIfNotDone(ctx, state.Samples, stats.ConnectedSamples{
+				Samples: []stats.Sample{
+					{
+						Metric: metrics.HTTPRespCompressedBytes, Time: finishedReq.trail.EndTime,
+						Tags: finishedReq.trail.Tags, Value: float64(compressedBody.n),
+					},
+					{
+						Metric: metrics.HTTPRespUncompressedBytes, Time: finishedReq.trail.EndTime,
+						Tags: finishedReq.trail.Tags, Value: float64(resp.BodySize),
+					},
+				},
+				Tags: finishedReq.trail.Tags,
+				Time: finishedReq.trail.EndTime,
+			})
+			pushCompressionSamples(ctx, state, finishedReq.trail, resp)
+		}
 	}
 
 	if resErr == nil {
@@ -349,10 +475,22 @@ func MakeRequest(ctx context.Context, preq *ParsedHTTPRequest) (*Response, error
 				Secure:   c.Secure,
 				MaxAge:   c.MaxAge,
 				Expires:  c.Expires.UnixNano() / 1000000,
+				SameSite: SameSiteString(c.SameSite),
+				Priority: CookiePriority(c),
 			})
 		}
 	}
 
+	// Requests that were interrupted because the VU was stopped aren't counted.
+	// The rest are failed exactly when their http_req_failed sample says so.
+	if counter := lib.GetRequestCounter(ctx); counter != nil && ctx.Err() == nil {
+		if finishedReq != nil {
+			counter.Add(finishedReq.trail.Failed)
+		} else {
+			counter.Add(isFailed(resErr, resp.Status, responseCallback))
+		}
+	}
+
 	if resErr != nil {
 		if preq.Throw { // if we are going to throw, we shouldn't log it
 			return nil, resErr
@@ -369,6 +507,27 @@ func MakeRequest(ctx context.Context, preq *ParsedHTTPRequest) (*Response, error
 	return resp, nil
 }
 
+// pushCompressionSamples emits the compression ratio of a compressed response,
+// i.e. how many times larger its body is after decompressing it, and its
+// compression algorithm as the compression_algorithm tag of a counter.
+func pushCompressionSamples(ctx context.Context, state *lib.State, trail *Trail, resp *Response) {
+	algorithmTags := trail.Tags.CloneTags()
+	algorithmTags["compression_algorithm"] = resp.CompressionAlgorithm
+	samples := []stats.Sample{{
+		Metric: metrics.HTTPRespCompressionAlgorithm, Time: trail.EndTime,
+		Tags: stats.IntoSampleTags(&algorithmTags), Value: 1,
+	}}
+	if resp.CompressedSize > 0 {
+		samples = append(samples, stats.Sample{
+			Metric: metrics.HTTPRespCompressionRatio, Time: trail.EndTime,
+			Tags: trail.Tags, Value: float64(resp.DecompressedSize) / float64(resp.CompressedSize),
+		})
+	}
+	stats.PushIfNotDone(ctx, state.Samples, stats.ConnectedSamples{
+		Samples: samples, Tags: trail.Tags, Time: trail.EndTime,
+	})
+}
+
 // SetRequestCookies sets the cookies of the requests getting those cookies both from the jar and
 // from the reqCookies map. The Replace field of the HTTPRequestCookie will be taken into account
 func SetRequestCookies(req *http.Request, jar *cookiejar.Jar, reqCookies map[string]*HTTPRequestCookie) {
@@ -25,18 +25,24 @@ import (
 	"context"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/oxtoacart/bpool"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/loadimpact/k6/lib"
+	"github.com/loadimpact/k6/lib/metrics"
+	"github.com/loadimpact/k6/lib/netext"
 	"github.com/loadimpact/k6/stats"
 )
 
@@ -204,10 +210,10 @@ func TestMakeRequestTimeout(t *testing.T) {
 	assert.Len(t, samples, 1)
 	sampleCont := <-samples
 	allSamples := sampleCont.GetSamples()
-	require.Len(t, allSamples, 8)
+	require.Len(t, allSamples, 10) // including http_req_failed
 	expTags := map[string]string{
 		"error":      "context deadline exceeded",
-		"error_code": "1000",
+		"error_code": "1050",
 		"status":     "0",
 		"method":     "GET",
 		"url":        srv.URL,
@@ -218,6 +224,155 @@ func TestMakeRequestTimeout(t *testing.T) {
 	}
 }
 
+func TestMakeRequestConnPoolStats(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	poolStats := &lib.ConnPoolStats{}
+	dialer := netext.NewDialer(net.Dialer{})
+	transport := &http.Transport{DialContext: poolStats.WrapDialContext(dialer.DialContext)}
+	defer transport.CloseIdleConnections()
+	state := &lib.State{
+		Options: lib.Options{
+			RunTags:    &stats.SampleTags{},
+			SystemTags: &stats.DefaultSystemTagSet,
+		},
+		Transport:     transport,
+		Samples:       make(chan stats.SampleContainer, 10),
+		Logger:        logrus.New(),
+		BPool:         bpool.NewBufferPool(1),
+		ConnPoolStats: poolStats,
+	}
+	ctx = lib.WithState(ctx, state)
+
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest("GET", srv.URL, nil)
+		_, err := MakeRequest(ctx, &ParsedHTTPRequest{
+			Req:     req,
+			URL:     &URL{u: req.URL, URL: srv.URL},
+			Body:    new(bytes.Buffer),
+			Timeout: 10 * time.Second,
+		})
+		require.NoError(t, err)
+
+		// The connection is reused and back in the pool once the request is done
+		idle, active := poolStats.Get()
+		assert.Equal(t, int64(1), idle)
+		assert.Equal(t, int64(0), active)
+	}
+}
+
+func TestMakeRequestHeaderSizes(t *testing.T) {
+	bigHeader := strings.Repeat("x", 1000)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Big", bigHeader)
+	}))
+	defer srv.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	samples := make(chan stats.SampleContainer, 10)
+	state := &lib.State{
+		Options: lib.Options{
+			RunTags:    &stats.SampleTags{},
+			SystemTags: &stats.DefaultSystemTagSet,
+		},
+		Transport: srv.Client().Transport,
+		Samples:   samples,
+		Logger:    logrus.New(),
+		BPool:     bpool.NewBufferPool(1),
+	}
+	ctx = lib.WithState(ctx, state)
+	req, _ := http.NewRequest("GET", srv.URL+"/path?q=1", nil)
+	req.Header.Set("Cookie", bigHeader)
+	var preq = &ParsedHTTPRequest{
+		Req:     req,
+		URL:     &URL{u: req.URL, URL: srv.URL + "/path?q=1"},
+		Body:    new(bytes.Buffer),
+		Timeout: 10 * time.Second,
+	}
+
+	_, err := MakeRequest(ctx, preq)
+	require.NoError(t, err)
+	require.Len(t, samples, 1)
+
+	expReqSize := len("GET /path?q=1 HTTP/1.1\r\n") +
+		len("Host: "+strings.TrimPrefix(srv.URL, "http://")+"\r\n") +
+		len("User-Agent: Go-http-client/1.1\r\n") +
+		len("Cookie: "+bigHeader+"\r\n") +
+		len("Accept-Encoding: gzip, deflate, br, zstd\r\n") +
+		len("\r\n")
+	expRespSize := len("HTTP/1.1 200 OK\r\n") +
+		len("X-Big: "+bigHeader+"\r\n") +
+		len("Content-Length: 0\r\n") +
+		len("Date: Mon, 02 Jan 2006 15:04:05 GMT\r\n") +
+		len("\r\n")
+	seen := map[*stats.Metric]float64{}
+	for _, sample := range (<-samples).GetSamples() {
+		seen[sample.Metric] = sample.Value
+	}
+	assert.Equal(t, float64(expReqSize), seen[metrics.HTTPReqHeaderSize])
+	assert.Equal(t, float64(expRespSize), seen[metrics.HTTPRespHeaderSize])
+
+	t.Run("chunked", func(t *testing.T) {
+		res := &http.Response{
+			Proto:            "HTTP/1.1",
+			Status:           "404 Not Found",
+			Header:           http.Header{"A": []string{"1", "22"}},
+			TransferEncoding: []string{"chunked"},
+		}
+		exp := "HTTP/1.1 404 Not Found\r\nA: 1\r\nA: 22\r\nTransfer-Encoding: chunked\r\n\r\n"
+		assert.Equal(t, int64(len(exp)), responseHeaderSize(res))
+	})
+}
+
+func TestMakeRequestTLSCertExpiry(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	samples := make(chan stats.SampleContainer, 10)
+	state := &lib.State{
+		Options: lib.Options{
+			RunTags:    stats.IntoSampleTags(&map[string]string{"runtag": "yes"}),
+			SystemTags: &stats.DefaultSystemTagSet,
+		},
+		Transport:          srv.Client().Transport,
+		Samples:            samples,
+		Logger:             logrus.New(),
+		BPool:              bpool.NewBufferPool(1),
+		TLSCertExpiryHosts: new(sync.Map),
+	}
+	ctx = lib.WithState(ctx, state)
+
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest("GET", srv.URL, nil)
+		_, err := MakeRequest(ctx, &ParsedHTTPRequest{
+			Req:     req,
+			URL:     &URL{u: req.URL, URL: srv.URL},
+			Body:    new(bytes.Buffer),
+			Timeout: 10 * time.Second,
+		})
+		require.NoError(t, err)
+	}
+
+	var expiry []stats.Sample
+	for _, sc := range stats.GetBufferedSamples(samples) {
+		for _, sample := range sc.GetSamples() {
+			if sample.Metric == metrics.TLSCertExpirySeconds {
+				expiry = append(expiry, sample)
+			}
+		}
+	}
+	require.Len(t, expiry, 1, "the expiry should only be recorded once per host")
+	assert.Equal(t, map[string]string{"runtag": "yes", "host": "127.0.0.1"}, expiry[0].Tags.CloneTags())
+	expValue := srv.Certificate().NotAfter.Sub(expiry[0].Time).Seconds()
+	assert.InDelta(t, expValue, expiry[0].Value, 1)
+}
+
 func BenchmarkWrapDecompressionError(b *testing.B) {
 	err := errors.New("error")
 	b.ResetTimer()
@@ -25,6 +25,8 @@ import (
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/tidwall/gjson"
@@ -46,8 +48,9 @@ const (
 	// This is the default value for backwards-compatibility, unless the global
 	// discardResponseBodies option is enabled.
 	ResponseTypeText ResponseType = iota
-	// ResponseTypeBinary causes k6 to return the response body as a []byte, suitable
-	// for working with binary files without lost data and needless string conversions.
+	// ResponseTypeBinary causes k6 to return the response body as a []byte, which
+	// scripts get as an ArrayBuffer, suitable for working with binary files without
+	// lost data and needless string conversions.
 	ResponseTypeBinary
 	// ResponseTypeNone causes k6 to fully read the response body while immediately
 	// discarding the actual data - k6 would set the body of the returned HTTPResponse
@@ -72,7 +75,8 @@ func (j jsonError) Error() string {
 type ResponseTimings struct {
 	Duration       float64 `json:"duration"`
 	Blocked        float64 `json:"blocked"`
-	LookingUp      float64 `json:"looking_up"`
+	DNSLookup      float64 `json:"dns_lookup"`
+	LookingUp      float64 `json:"looking_up"` // Deprecated: the same as DNSLookup
 	Connecting     float64 `json:"connecting"`
 	TLSHandshaking float64 `json:"tls_handshaking"`
 	Sending        float64 `json:"sending"`
@@ -86,39 +90,110 @@ type HTTPCookie struct {
 	HTTPOnly, Secure          bool
 	MaxAge                    int
 	Expires                   int64
+	SameSite, Priority        string
+}
+
+// SameSiteString returns the value of the SameSite cookie attribute as a
+// lowercase string, or an empty string if it wasn't set.
+func SameSiteString(sameSite http.SameSite) string {
+	switch sameSite {
+	case http.SameSiteStrictMode:
+		return "strict"
+	case http.SameSiteLaxMode:
+		return "lax"
+	case http.SameSiteNoneMode:
+		return "none"
+	default:
+		return ""
+	}
+}
+
+// ParseSameSite is the inverse of SameSiteString(), it's case-insensitive.
+func ParseSameSite(sameSite string) (http.SameSite, error) {
+	switch strings.ToLower(sameSite) {
+	case "strict":
+		return http.SameSiteStrictMode, nil
+	case "lax":
+		return http.SameSiteLaxMode, nil
+	case "none":
+		return http.SameSiteNoneMode, nil
+	case "":
+		return http.SameSiteDefaultMode, nil
+	default:
+		return http.SameSiteDefaultMode, fmt.Errorf(
+			"invalid SameSite cookie attribute '%s', it should be one of strict, lax, none or empty", sameSite,
+		)
+	}
+}
+
+// CookiePriority returns the value of the non-standard Priority cookie
+// attribute in lowercase, since net/http leaves it in the unparsed attributes.
+func CookiePriority(c *http.Cookie) string {
+	for _, attr := range c.Unparsed {
+		kv := strings.SplitN(attr, "=", 2)
+		if len(kv) == 2 && strings.EqualFold(strings.TrimSpace(kv[0]), "priority") {
+			return strings.ToLower(strings.TrimSpace(kv[1]))
+		}
+	}
+	return ""
 }
 
 // Response is a representation of an HTTP response
 type Response struct {
 	ctx context.Context
 
-	RemoteIP       string                   `json:"remote_ip"`
-	RemotePort     int                      `json:"remote_port"`
-	URL            string                   `json:"url"`
-	Status         int                      `json:"status"`
-	Proto          string                   `json:"proto"`
-	Headers        map[string]string        `json:"headers"`
-	Cookies        map[string][]*HTTPCookie `json:"cookies"`
-	Body           interface{}              `json:"body"`
-	Timings        ResponseTimings          `json:"timings"`
-	TLSVersion     string                   `json:"tls_version"`
-	TLSCipherSuite string                   `json:"tls_cipher_suite"`
-	OCSP           netext.OCSP              `json:"ocsp"`
-	Error          string                   `json:"error"`
-	ErrorCode      int                      `json:"error_code"`
-	Request        Request                  `json:"request"`
-
-	cachedJSON    interface{}
-	validatedJSON bool
+	RemoteIP        string                   `json:"remote_ip"`
+	RemotePort      int                      `json:"remote_port"`
+	URL             string                   `json:"url"`
+	Status          int                      `json:"status"`
+	Proto           string                   `json:"proto"`
+	Headers         map[string]string        `json:"headers"`
+	Cookies         map[string][]*HTTPCookie `json:"cookies"`
+	Body            interface{}              `json:"body"`
+	BodySize        int64                    `json:"body_size"`
+	Timings         ResponseTimings          `json:"timings"`
+	TLSVersion      string                   `json:"tls_version"`
+	TLSCipherSuite  string                   `json:"tls_cipher_suite"`
+	TLSALPN         string                   `json:"tls_alpn" js:"tls_alpn"`
+	TLSCertificates []netext.Certificate     `json:"tls_certificates"`
+	OCSP            netext.OCSP              `json:"ocsp"`
+	Error           string                   `json:"error"`
+	ErrorCode       int                      `json:"error_code"`
+	Request         Request                  `json:"request"`
+
+	// Whether the redirect limit was hit, so this is the last redirect response
+	RedirectsExceeded bool `json:"redirects_exceeded"`
+
+	// Whether this is a 304 Not Modified response to a conditional request
+	Cached bool `json:"cached"`
+
+	// The sizes of the body before and after decompressing it, which are the
+	// same for uncompressed responses, and its Content-Encoding, if any
+	CompressedSize       int64  `json:"compressed_size"`
+	DecompressedSize     int64  `json:"decompressed_size"`
+	CompressionAlgorithm string `json:"compression_algorithm"`
+
+	cachedJSON      interface{}
+	validatedJSON   bool
+	multipartStream *MultipartStream
+	trail           *Trail // of the last request, nil if none was made
 }
 
 func (res *Response) setTLSInfo(tlsState *tls.ConnectionState) {
 	tlsInfo, oscp := netext.ParseTLSConnState(tlsState)
 	res.TLSVersion = tlsInfo.Version
 	res.TLSCipherSuite = tlsInfo.CipherSuite
+	res.TLSALPN = tlsInfo.ALPN
+	res.TLSCertificates = netext.ParseTLSCertificates(tlsState)
 	res.OCSP = oscp
 }
 
+// MultipartStream returns the stream of parts of a streaming multipart
+// response, or nil if the response wasn't one.
+func (res *Response) MultipartStream() *MultipartStream {
+	return res.multipartStream
+}
+
 // GetCtx return the response context
 func (res *Response) GetCtx() context.Context {
 	return res.ctx
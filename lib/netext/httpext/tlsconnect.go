@@ -0,0 +1,198 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package httpext
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http/httptrace"
+	"strconv"
+	"time"
+
+	"github.com/loadimpact/k6/lib"
+	"github.com/loadimpact/k6/lib/metrics"
+	"github.com/loadimpact/k6/lib/netext"
+	"github.com/loadimpact/k6/stats"
+)
+
+// TLSConnectParams contains the parameters of a TLS-only connection check.
+type TLSConnectParams struct {
+	Addr       string // host:port
+	ServerName string // the SNI value, the host from Addr by default
+	Timeout    time.Duration
+	Tags       map[string]string
+	Throw      bool
+}
+
+// TLSConnectTimings contains the timings of a TLS-only connection check.
+type TLSConnectTimings struct {
+	Duration       float64 `json:"duration"`
+	DNSLookup      float64 `json:"dns_lookup"`
+	Connecting     float64 `json:"connecting"`
+	TLSHandshaking float64 `json:"tls_handshaking"`
+}
+
+// TLSConnectResult is the result of a TLS-only connection check.
+type TLSConnectResult struct {
+	RemoteIP       string               `json:"remote_ip"`
+	RemotePort     int                  `json:"remote_port"`
+	ServerName     string               `json:"server_name"`
+	TLSVersion     string               `json:"tls_version"`
+	TLSCipherSuite string               `json:"tls_cipher_suite"`
+	TLSALPN        string               `json:"tls_alpn" js:"tls_alpn"`
+	OCSP           netext.OCSP          `json:"ocsp"`
+	Certificates   []netext.Certificate `json:"certificates"` // the chain, leaf first
+	Timings        TLSConnectTimings    `json:"timings"`
+	Error          string               `json:"error"`
+	ErrorCode      int                  `json:"error_code"`
+}
+
+// TLSConnect connects to the given address, completes the TLS handshake and
+// closes the connection, without sending anything else. The same dialer, TLS
+// config and timing hooks as for HTTP requests are used, and the connecting
+// and TLS handshaking durations are emitted as the http_req_connecting and
+// http_req_tls_handshaking metrics.
+func TLSConnect(ctx context.Context, params *TLSConnectParams) (*TLSConnectResult, error) {
+	state := lib.GetState(ctx)
+	if state == nil {
+		return nil, errors.New("TLS connections can't be made in the init context")
+	}
+
+	host, _, err := net.SplitHostPort(params.Addr)
+	if err != nil {
+		return nil, err
+	}
+	result := &TLSConnectResult{ServerName: params.ServerName}
+	if result.ServerName == "" {
+		result.ServerName = host
+	}
+
+	tracer := &Tracer{}
+	connCtx, cancel := context.WithTimeout(httptrace.WithClientTrace(ctx, tracer.Trace()), params.Timeout)
+	defer cancel()
+
+	startTime := time.Now()
+	tlsState, connErr := tlsHandshake(connCtx, state, tracer, params.Addr, result.ServerName)
+	trail := tracer.Done()
+	endTime := time.Now()
+
+	if trail.ConnRemoteAddr != nil {
+		if tcpAddr, ok := trail.ConnRemoteAddr.(*net.TCPAddr); ok {
+			result.RemoteIP, result.RemotePort = tcpAddr.IP.String(), tcpAddr.Port
+		}
+	}
+	result.Timings = TLSConnectTimings{
+		Duration:       stats.D(endTime.Sub(startTime)),
+		DNSLookup:      stats.D(trail.LookingUp),
+		Connecting:     stats.D(trail.Connecting),
+		TLSHandshaking: stats.D(trail.TLSHandshaking),
+	}
+
+	enabledTags := state.Options.SystemTags
+	tags := state.CloneTags()
+	for k, v := range params.Tags {
+		tags[k] = v
+	}
+	if _, ok := tags["name"]; !ok && enabledTags.Has(stats.TagName) {
+		tags["name"] = params.Addr
+	}
+	if enabledTags.Has(stats.TagProto) {
+		tags["proto"] = "tls"
+	}
+	if enabledTags.Has(stats.TagIP) && result.RemoteIP != "" {
+		tags["ip"] = result.RemoteIP
+	}
+
+	if connErr != nil {
+		code, msg := errorCodeForError(connErr)
+		result.Error, result.ErrorCode = msg, int(code)
+		if enabledTags.Has(stats.TagError) {
+			tags["error"] = msg
+		}
+		if enabledTags.Has(stats.TagErrorCode) {
+			tags["error_code"] = strconv.Itoa(int(code))
+		}
+	} else {
+		tlsInfo, ocsp := netext.ParseTLSConnState(tlsState)
+		result.TLSVersion, result.TLSCipherSuite, result.OCSP = tlsInfo.Version, tlsInfo.CipherSuite, ocsp
+		result.TLSALPN = tlsInfo.ALPN
+		result.Certificates = netext.ParseTLSCertificates(tlsState)
+		if enabledTags.Has(stats.TagTLSVersion) {
+			tags["tls_version"] = tlsInfo.Version
+		}
+		if enabledTags.Has(stats.TagOCSPStatus) {
+			tags["ocsp_status"] = ocsp.Status
+		}
+	}
+
+	sampleTags := stats.IntoSampleTags(&tags)
+	stats.PushIfNotDone(ctx, state.Samples, stats.ConnectedSamples{
+		Samples: []stats.Sample{
+			{Metric: metrics.HTTPReqConnecting, Time: endTime, Tags: sampleTags, Value: stats.D(trail.Connecting)},
+			{Metric: metrics.HTTPReqTLSHandshaking, Time: endTime, Tags: sampleTags, Value: stats.D(trail.TLSHandshaking)},
+		},
+		Tags: sampleTags,
+		Time: endTime,
+	})
+
+	if connErr != nil {
+		if params.Throw {
+			return nil, connErr
+		}
+		state.Logger.WithField("error", connErr).Warn("TLS connection failed")
+	}
+	return result, nil
+}
+
+// tlsHandshake dials the address and does the TLS handshake, calling the
+// tracer hooks that the net/http transport would have called.
+func tlsHandshake(
+	ctx context.Context, state *lib.State, tracer *Tracer, addr, serverName string,
+) (*tls.ConnectionState, error) {
+	tracer.GetConn(addr)
+	conn, err := state.Dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = conn.Close() }()
+	tracer.connRemoteAddr = conn.RemoteAddr()
+
+	tlsConfig := state.TLSConfig.Clone()
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{} //nolint:gosec
+	}
+	tlsConfig.ServerName = serverName
+
+	tlsConn := tls.Client(conn, tlsConfig)
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = tlsConn.SetDeadline(deadline)
+	}
+	tracer.TLSHandshakeStart()
+	err = tlsConn.Handshake()
+	tlsState := tlsConn.ConnectionState()
+	tracer.TLSHandshakeDone(tlsState, err)
+	if err != nil {
+		return nil, err
+	}
+	return &tlsState, nil
+}
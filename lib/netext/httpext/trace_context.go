@@ -0,0 +1,47 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package httpext
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/loadimpact/k6/lib"
+)
+
+// setTraceContextHeaders sets the headers of the configured propagator for the
+// trace of the current iteration, unless they were set by the script. The W3C
+// Trace Context ones get a new parent ID for the request.
+func setTraceContextHeaders(header http.Header, state *lib.State) {
+	if tc := state.Options.TraceContext; tc != nil && tc.Propagator.String == lib.TraceContextRequestID {
+		if header.Get("X-Request-ID") == "" {
+			header.Set("X-Request-ID", state.TraceID)
+		}
+		return
+	}
+
+	if header.Get("traceparent") == "" {
+		header.Set("traceparent", fmt.Sprintf("00-%s-%s-01", state.TraceID, lib.NewSpanID()))
+	}
+	if tc := state.Options.TraceContext; tc != nil && tc.TraceState.String != "" && header.Get("tracestate") == "" {
+		header.Set("tracestate", tc.TraceState.String)
+	}
+}
@@ -28,6 +28,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/loadimpact/k6/lib"
 	"github.com/loadimpact/k6/lib/metrics"
 	"github.com/loadimpact/k6/stats"
 )
@@ -45,12 +46,26 @@ type Trail struct {
 	Duration time.Duration
 
 	Blocked        time.Duration // Waiting to acquire a connection.
+	LookingUp      time.Duration // Looking up the DNS record of the remote host.
 	Connecting     time.Duration // Connecting to remote host.
 	TLSHandshaking time.Duration // Executing TLS handshake.
 	Sending        time.Duration // Writing request.
 	Waiting        time.Duration // Waiting for first byte.
 	Receiving      time.Duration // Receiving response.
 
+	// Waiting for the global and VU rate limits, if there are any.
+	RateLimited bool
+	Queued      time.Duration
+
+	// Whether the request failed with an error or a 4xx or 5xx status.
+	Failed bool
+
+	// The sizes of the request headers, including the request line, if they were
+	// written, and of the response headers, including the status line, if there
+	// was a response.
+	RequestHeaderSize  int64
+	ResponseHeaderSize int64
+
 	// Detailed connection information.
 	ConnReused     bool
 	ConnRemoteAddr net.Addr
@@ -75,6 +90,24 @@ func (tr *Trail) SaveSamples(tags *stats.SampleTags) {
 		{Metric: metrics.HTTPReqWaiting, Time: tr.EndTime, Tags: tags, Value: stats.D(tr.Waiting)},
 		{Metric: metrics.HTTPReqReceiving, Time: tr.EndTime, Tags: tags, Value: stats.D(tr.Receiving)},
 	}
+	if tr.RateLimited {
+		tr.Samples = append(tr.Samples,
+			stats.Sample{Metric: metrics.HTTPReqQueued, Time: tr.EndTime, Tags: tags, Value: stats.D(tr.Queued)})
+	}
+	if tr.Failed {
+		tr.Samples = append(tr.Samples,
+			stats.Sample{Metric: metrics.HTTPReqFailed, Time: tr.EndTime, Tags: tags, Value: 1})
+	}
+	if tr.RequestHeaderSize > 0 {
+		tr.Samples = append(tr.Samples, stats.Sample{
+			Metric: metrics.HTTPReqHeaderSize, Time: tr.EndTime, Tags: tags, Value: float64(tr.RequestHeaderSize),
+		})
+	}
+	if tr.ResponseHeaderSize > 0 {
+		tr.Samples = append(tr.Samples, stats.Sample{
+			Metric: metrics.HTTPRespHeaderSize, Time: tr.EndTime, Tags: tags, Value: float64(tr.ResponseHeaderSize),
+		})
+	}
 }
 
 // GetSamples implements the stats.SampleContainer interface.
@@ -95,6 +128,13 @@ func (tr *Trail) GetTime() time.Time {
 // Ensure that interfaces are implemented correctly
 var _ stats.ConnectedSampleContainer = &Trail{}
 
+// The states of the connection of a Tracer, for the connection pool statistics.
+const (
+	connNotAcquired int32 = iota
+	connAcquired
+	connReleased
+)
+
 // A Tracer wraps "net/http/httptrace" to collect granular timings for HTTP requests.
 // Note that since there is not yet an event for the end of a request (there's a PR to
 // add it), you must call Done() at the end of the request to get the full timings.
@@ -102,6 +142,8 @@ var _ stats.ConnectedSampleContainer = &Trail{}
 // Cheers, love, the cavalry's here.
 type Tracer struct {
 	getConn              int64
+	dnsStart             int64
+	dnsDone              int64
 	connectStart         int64
 	connectDone          int64
 	tlsHandshakeStart    int64
@@ -110,9 +152,19 @@ type Tracer struct {
 	wroteRequest         int64
 	gotFirstResponseByte int64
 
+	// The size of the header fields written so far for the current attempt
+	// and the total size of the headers of the last written request.
+	headerFieldsSize  int64
+	requestHeaderSize int64
+
 	connReused     bool
 	connRemoteAddr net.Addr
 
+	// If set, the connection is counted as active from GotConn() until Done().
+	// connPoolState is connNotAcquired, connAcquired or connReleased.
+	poolStats     *lib.ConnPoolStats
+	connPoolState int32
+
 	protoErrorsMutex sync.Mutex
 	protoErrors      []error
 }
@@ -121,11 +173,15 @@ type Tracer struct {
 func (t *Tracer) Trace() *httptrace.ClientTrace {
 	return &httptrace.ClientTrace{
 		GetConn:              t.GetConn,
+		DNSStart:             t.DNSStart,
+		DNSDone:              t.DNSDone,
 		ConnectStart:         t.ConnectStart,
 		ConnectDone:          t.ConnectDone,
 		TLSHandshakeStart:    t.TLSHandshakeStart,
 		TLSHandshakeDone:     t.TLSHandshakeDone,
 		GotConn:              t.GotConn,
+		WroteHeaderField:     t.WroteHeaderField,
+		WroteHeaders:         t.WroteHeaders,
 		WroteRequest:         t.WroteRequest,
 		GotFirstResponseByte: t.GotFirstResponseByte,
 	}
@@ -154,6 +210,26 @@ func (t *Tracer) GetConn(hostPort string) {
 	t.getConn = now()
 }
 
+// DNSStart is called when a DNS lookup begins. Since k6 does its own DNS
+// resolution, it's called by the netext.Dialer and not by the Go stdlib.
+//
+// If the connection is reused, or if the host is an IP address or a
+// configured host alias, this won't be called. Otherwise, it will be
+// called after GetConn() and before DNSDone().
+func (t *Tracer) DNSStart(info httptrace.DNSStartInfo) {
+	atomic.CompareAndSwapInt64(&t.dnsStart, 0, now())
+}
+
+// DNSDone is called when a DNS lookup ends. It will be called after
+// DNSStart() and before ConnectStart().
+func (t *Tracer) DNSDone(info httptrace.DNSDoneInfo) {
+	if info.Err == nil {
+		atomic.CompareAndSwapInt64(&t.dnsDone, 0, now())
+	} else {
+		t.addError(info.Err)
+	}
+}
+
 // ConnectStart is called when a new connection's Dial begins.
 // If net.Dialer.DualStack (IPv6 "Happy Eyeballs") support is
 // enabled, this may be called multiple times.
@@ -229,6 +305,10 @@ func (t *Tracer) GotConn(info httptrace.GotConnInfo) {
 	t.connReused = info.Reused
 	t.connRemoteAddr = info.Conn.RemoteAddr()
 
+	if t.poolStats != nil && atomic.CompareAndSwapInt32(&t.connPoolState, connNotAcquired, connAcquired) {
+		t.poolStats.ConnAcquired()
+	}
+
 	// The Go stdlib's http module can start connecting to a remote server, only
 	// to abandon that connection even before it was fully established and reuse
 	// a recently freed already existing connection.
@@ -260,6 +340,25 @@ func (t *Tracer) GotConn(info httptrace.GotConnInfo) {
 	}
 }
 
+// WroteHeaderField is called after the Transport has written each request
+// header, including the ones it adds itself, like Host and the cookies from the
+// jar. The size is counted as "Key: value\r\n" for every value, even for
+// HTTP/2, where the headers are compressed and pseudo-headers like :path are
+// also reported.
+func (t *Tracer) WroteHeaderField(key string, values []string) {
+	for _, value := range values {
+		atomic.AddInt64(&t.headerFieldsSize, int64(len(key)+2+len(value)+2))
+	}
+}
+
+// WroteHeaders is called after the Transport has written all of the request
+// headers and before the body. It may be called multiple times in the case of
+// retried requests, so only the size of the last attempt is kept.
+func (t *Tracer) WroteHeaders() {
+	// The empty line that ends the headers
+	atomic.StoreInt64(&t.requestHeaderSize, atomic.SwapInt64(&t.headerFieldsSize, 0)+2)
+}
+
 // WroteRequest is called with the result of writing the
 // request and any body. It may be called multiple times
 // in the case of retried requests.
@@ -283,9 +382,14 @@ func (t *Tracer) GotFirstResponseByte() {
 func (t *Tracer) Done() *Trail {
 	done := time.Now()
 
+	if t.poolStats != nil && atomic.SwapInt32(&t.connPoolState, connReleased) == connAcquired {
+		t.poolStats.ConnReleased()
+	}
+
 	trail := Trail{
-		ConnReused:     t.connReused,
-		ConnRemoteAddr: t.connRemoteAddr,
+		ConnReused:        t.connReused,
+		ConnRemoteAddr:    t.connRemoteAddr,
+		RequestHeaderSize: atomic.LoadInt64(&t.requestHeaderSize),
 	}
 
 	if t.gotConn != 0 && t.getConn != 0 && t.gotConn > t.getConn {
@@ -297,6 +401,8 @@ func (t *Tracer) Done() *Trail {
 	// already returned our result and we've called Done(). This happens
 	// mostly for cancelled requests, but we have to use atomics here as
 	// well (or use global Tracer locking) so we can avoid data races.
+	dnsStart := atomic.LoadInt64(&t.dnsStart)
+	dnsDone := atomic.LoadInt64(&t.dnsDone)
 	connectStart := atomic.LoadInt64(&t.connectStart)
 	connectDone := atomic.LoadInt64(&t.connectDone)
 	tlsHandshakeStart := atomic.LoadInt64(&t.tlsHandshakeStart)
@@ -305,6 +411,9 @@ func (t *Tracer) Done() *Trail {
 	wroteRequest := atomic.LoadInt64(&t.wroteRequest)
 	gotFirstResponseByte := atomic.LoadInt64(&t.gotFirstResponseByte)
 
+	if dnsDone != 0 && dnsStart != 0 {
+		trail.LookingUp = time.Duration(dnsDone - dnsStart)
+	}
 	if connectDone != 0 && connectStart != 0 {
 		trail.Connecting = time.Duration(connectDone - connectStart)
 	}
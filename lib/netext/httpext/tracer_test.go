@@ -100,7 +100,7 @@ func TestTracer(t *testing.T) {
 
 			assert.Equal(t, strings.TrimPrefix(srv.URL, "https://"), trail.ConnRemoteAddr.String())
 
-			assert.Len(t, samples, 8)
+			assert.Len(t, samples, 9)
 			seenMetrics := map[*stats.Metric]bool{}
 			for i, s := range samples {
 				assert.NotContains(t, seenMetrics, s.Metric)
@@ -119,7 +119,8 @@ func TestTracer(t *testing.T) {
 						break
 					}
 					fallthrough
-				case metrics.HTTPReqDuration, metrics.HTTPReqBlocked, metrics.HTTPReqSending, metrics.HTTPReqWaiting, metrics.HTTPReqReceiving:
+				case metrics.HTTPReqDuration, metrics.HTTPReqBlocked, metrics.HTTPReqSending, metrics.HTTPReqWaiting,
+					metrics.HTTPReqReceiving, metrics.HTTPReqHeaderSize:
 					assert.True(t, s.Value > 0.0, "%s is <= 0", s.Metric.Name)
 				default:
 					t.Errorf("unexpected metric: %s", s.Metric.Name)
@@ -129,6 +130,34 @@ func TestTracer(t *testing.T) {
 	}
 }
 
+func TestTracerDNSLookup(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(httpbin.New().Handler())
+	defer srv.Close()
+
+	transport, ok := srv.Client().Transport.(*http.Transport)
+	assert.True(t, ok)
+	transport.DialContext = netext.NewDialer(net.Dialer{}).DialContext
+
+	tracer := &Tracer{}
+	url := strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)
+	req, err := http.NewRequest("GET", url+"/get", nil)
+	require.NoError(t, err)
+
+	res, err := transport.RoundTrip(req.WithContext(httptrace.WithClientTrace(context.Background(), tracer.Trace())))
+	require.NoError(t, err)
+	_, err = io.Copy(ioutil.Discard, res.Body)
+	assert.NoError(t, err)
+	assert.NoError(t, res.Body.Close())
+	trail := tracer.Done()
+
+	assert.Empty(t, tracer.protoErrors)
+	assert.NotZero(t, tracer.dnsStart)
+	assert.True(t, tracer.dnsDone >= tracer.dnsStart)
+	assert.True(t, tracer.connectStart >= tracer.dnsDone)
+	assert.True(t, trail.LookingUp > 0)
+}
+
 type failingConn struct {
 	net.Conn
 }
@@ -22,15 +22,20 @@ package httpext
 
 import (
 	"context"
+	"crypto/tls"
 	"net"
 	"net/http"
 	"net/http/httptrace"
 	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/loadimpact/k6/lib"
+	"github.com/loadimpact/k6/lib/metrics"
 	"github.com/loadimpact/k6/lib/netext"
 	"github.com/loadimpact/k6/stats"
+	"golang.org/x/net/http2"
 )
 
 // transport is an implementation of http.RoundTripper that will measure and emit
@@ -40,6 +45,23 @@ type transport struct {
 	state *lib.State
 	tags  map[string]string
 
+	// The requests are sent with roundTripper, the state's Transport by default
+	roundTripper http.RoundTripper
+
+	// How long the request waited for the rate limits, if there are any. Only
+	// the first request is queued, not the redirects.
+	rateLimited bool
+	queued      time.Duration
+
+	// The errors of requests cancelled by signal are reported as aborted
+	signal *AbortSignal
+
+	// Decides which response statuses are expected, see isFailed()
+	responseCallback func(status int) bool
+
+	// The requests matching these mocks get their canned responses instead
+	mocks lib.HTTPMocks
+
 	lastRequest     *unfinishedRequest
 	lastRequestLock *sync.Mutex
 }
@@ -81,6 +103,7 @@ func newTransport(
 		ctx:             ctx,
 		state:           state,
 		tags:            tags,
+		roundTripper:    state.Transport,
 		lastRequestLock: new(sync.Mutex),
 	}
 }
@@ -89,6 +112,13 @@ func newTransport(
 // the metric samples for the supplied unfinished request.
 func (t *transport) measureAndEmitMetrics(unfReq *unfinishedRequest) *finishedRequest {
 	trail := unfReq.tracer.Done()
+	trail.RateLimited, trail.Queued = t.rateLimited, t.queued
+	t.queued = 0
+	if trail.RequestHeaderSize > 0 && (unfReq.response == nil || unfReq.response.ProtoMajor < 2) {
+		// HTTP/2 has pseudo-headers instead of the "GET /path HTTP/1.1\r\n" request line
+		trail.RequestHeaderSize += int64(len(unfReq.request.Method) + 1 +
+			len(unfReq.request.URL.RequestURI()) + len(" HTTP/1.1\r\n"))
+	}
 
 	tags := map[string]string{}
 	for k, v := range t.tags {
@@ -121,6 +151,11 @@ func (t *transport) measureAndEmitMetrics(unfReq *unfinishedRequest) *finishedRe
 		tags["method"] = unfReq.request.Method
 	}
 
+	status := 0
+	if unfReq.err == nil {
+		status = unfReq.response.StatusCode
+	}
+	trail.Failed = isFailed(unfReq.err, status, t.responseCallback)
 	if unfReq.err != nil {
 		result.errorCode, result.errorMsg = errorCodeForError(unfReq.err)
 		if enabledTags.Has(stats.TagError) {
@@ -135,6 +170,7 @@ func (t *transport) measureAndEmitMetrics(unfReq *unfinishedRequest) *finishedRe
 			tags["status"] = "0"
 		}
 	} else {
+		trail.ResponseHeaderSize = responseHeaderSize(unfReq.response)
 		if enabledTags.Has(stats.TagStatus) {
 			tags["status"] = strconv.Itoa(unfReq.response.StatusCode)
 		}
@@ -168,9 +204,54 @@ func (t *transport) measureAndEmitMetrics(unfReq *unfinishedRequest) *finishedRe
 	trail.SaveSamples(stats.IntoSampleTags(&tags))
 	stats.PushIfNotDone(t.ctx, t.state.Samples, trail)
 
+	if unfReq.err == nil && unfReq.response.TLS != nil {
+		t.recordTLSCertExpiry(unfReq.request.URL.Hostname(), unfReq.response.TLS, trail.EndTime)
+	}
+
 	return result
 }
 
+// recordTLSCertExpiry emits the seconds until the leaf certificate of the host
+// expires, if that wasn't already done by a VU during the test run.
+func (t *transport) recordTLSCertExpiry(host string, tlsState *tls.ConnectionState, now time.Time) {
+	if t.state.TLSCertExpiryHosts == nil || len(tlsState.PeerCertificates) == 0 {
+		return
+	}
+	if _, recorded := t.state.TLSCertExpiryHosts.LoadOrStore(host, true); recorded {
+		return
+	}
+
+	tags := t.state.Options.RunTags.CloneTags()
+	tags["host"] = host
+	stats.PushIfNotDone(t.ctx, t.state.Samples, stats.Sample{
+		Metric: metrics.TLSCertExpirySeconds,
+		Time:   now,
+		Tags:   stats.IntoSampleTags(&tags),
+		Value:  tlsState.PeerCertificates[0].NotAfter.Sub(now).Seconds(),
+	})
+}
+
+// responseHeaderSize returns the size of the status line and the header fields
+// of the response, as they would be written in HTTP/1.1, since the Go client
+// doesn't expose the raw bytes it read. For HTTP/2 responses this is the size
+// before the HPACK compression.
+func responseHeaderSize(res *http.Response) int64 {
+	// "HTTP/1.1 200 OK\r\n"
+	size := len(res.Proto) + 1 + len(res.Status) + 2
+	for key, values := range res.Header {
+		for _, value := range values {
+			// "Key: value\r\n"
+			size += len(key) + 2 + len(value) + 2
+		}
+	}
+	// The client removes the Transfer-Encoding header from the map
+	if len(res.TransferEncoding) > 0 {
+		size += len("Transfer-Encoding: ") + len(strings.Join(res.TransferEncoding, ", ")) + 2
+	}
+	// The empty line that ends the headers
+	return int64(size + 2)
+}
+
 func (t *transport) saveCurrentRequest(currentRequest *unfinishedRequest) {
 	t.lastRequestLock.Lock()
 	unprocessedRequest := t.lastRequest
@@ -208,9 +289,17 @@ func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
 	t.processLastSavedRequest(nil)
 
 	ctx := req.Context()
-	tracer := &Tracer{}
+	tracer := &Tracer{poolStats: t.state.ConnPoolStats}
 	reqWithTracer := req.WithContext(httptrace.WithClientTrace(ctx, tracer.Trace()))
-	resp, err := t.state.Transport.RoundTrip(reqWithTracer)
+
+	var resp *http.Response
+	var err error
+	if mock := t.mocks.For(req.URL.String()); mock != nil {
+		resp = mock.Response(req)
+	} else {
+		resp, err = t.roundTripper.RoundTrip(reqWithTracer)
+	}
+	err = t.signal.wrapError(err)
 
 	t.saveCurrentRequest(&unfinishedRequest{
 		ctx:      ctx,
@@ -222,3 +311,15 @@ func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
 
 	return resp, err
 }
+
+// cloneTransport returns a copy of the transport that doesn't share its
+// connections with it. Clone() would keep the HTTP/2 upgrade of the original
+// transport, so the HTTP/2 connections would still be pooled with its ones.
+func cloneTransport(t *http.Transport) *http.Transport {
+	clone := t.Clone()
+	if _, ok := t.TLSNextProto["h2"]; ok {
+		clone.TLSNextProto = nil
+		_ = http2.ConfigureTransport(clone)
+	}
+	return clone
+}
@@ -21,7 +21,10 @@
 package netext
 
 import (
+	"crypto/sha256"
 	"crypto/tls"
+	"encoding/hex"
+	"encoding/pem"
 
 	"golang.org/x/crypto/ocsp"
 
@@ -54,7 +57,22 @@ const (
 type TLSInfo struct {
 	Version     string
 	CipherSuite string
+	ALPN        string // the negotiated application protocol, e.g. "h2"
 }
+
+// Certificate is the information about one of the certificates in the chain
+// the server presented.
+type Certificate struct {
+	Subject      string   `json:"subject"`
+	Issuer       string   `json:"issuer"`
+	SAN          []string `json:"san" js:"san"`
+	ValidFrom    int64    `json:"valid_from"`
+	ValidTo      int64    `json:"valid_to"`
+	SerialNumber string   `json:"serial_number"`
+	Fingerprint  string   `json:"fingerprint"` // the hex-encoded SHA-256 hash of the certificate
+	PEM          string   `json:"pem" js:"pem"`
+}
+
 type OCSP struct {
 	ProducedAt       int64  `json:"produced_at"`
 	ThisUpdate       int64  `json:"this_update"`
@@ -80,6 +98,7 @@ func ParseTLSConnState(tlsState *tls.ConnectionState) (TLSInfo, OCSP) {
 	}
 
 	tlsInfo.CipherSuite = lib.SupportedTLSCipherSuitesToString[tlsState.CipherSuite]
+	tlsInfo.ALPN = tlsState.NegotiatedProtocol
 	ocspStapledRes := OCSP{Status: OCSP_STATUS_UNKNOWN}
 
 	if ocspRes, err := ocsp.ParseResponse(tlsState.OCSPResponse, nil); err == nil {
@@ -123,3 +142,33 @@ func ParseTLSConnState(tlsState *tls.ConnectionState) (TLSInfo, OCSP) {
 
 	return tlsInfo, ocspStapledRes
 }
+
+// ParseTLSCertificates returns the information about the peer certificates
+// of the connection, leaf first.
+func ParseTLSCertificates(tlsState *tls.ConnectionState) []Certificate {
+	certs := make([]Certificate, 0, len(tlsState.PeerCertificates))
+	for _, cert := range tlsState.PeerCertificates {
+		san := make([]string, 0, len(cert.DNSNames)+len(cert.IPAddresses)+len(cert.EmailAddresses)+len(cert.URIs))
+		san = append(san, cert.DNSNames...)
+		for _, ip := range cert.IPAddresses {
+			san = append(san, ip.String())
+		}
+		san = append(san, cert.EmailAddresses...)
+		for _, uri := range cert.URIs {
+			san = append(san, uri.String())
+		}
+
+		fingerprint := sha256.Sum256(cert.Raw)
+		certs = append(certs, Certificate{
+			Subject:      cert.Subject.String(),
+			Issuer:       cert.Issuer.String(),
+			SAN:          san,
+			ValidFrom:    cert.NotBefore.Unix(),
+			ValidTo:      cert.NotAfter.Unix(),
+			SerialNumber: cert.SerialNumber.Text(16),
+			Fingerprint:  hex.EncodeToString(fingerprint[:]),
+			PEM:          string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})),
+		})
+	}
+	return certs
+}
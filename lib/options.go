@@ -26,7 +26,9 @@ import (
 	"fmt"
 	"net"
 	"reflect"
+	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 	"gopkg.in/guregu/null.v3"
@@ -96,7 +98,11 @@ func (v *TLSVersions) isTLS13() bool {
 }
 
 // A list of TLS cipher suites.
-// Marshals and unmarshals from a list of names, eg. "TLS_ECDHE_RSA_WITH_RC4_128_SHA".
+// Marshals and unmarshals from a list of names, eg. "TLS_ECDHE_RSA_WITH_RC4_128_SHA",
+// or their hex IDs, eg. "0xc030". An empty list means Go's default secure set is used.
+//
+// The TLS 1.3 cipher suites can't be configured in Go, they are always enabled for
+// TLS 1.3 connections, so the list only affects TLS 1.0 - 1.2 connections.
 type TLSCipherSuites []uint16
 
 // MarshalJSON will return the JSON representation according to supported TLS cipher suites
@@ -121,11 +127,17 @@ func (s *TLSCipherSuites) UnmarshalJSON(data []byte) error {
 
 	var suiteIDs []uint16
 	for _, name := range suiteNames {
-		if suiteID, ok := SupportedTLSCipherSuites[name]; ok {
-			suiteIDs = append(suiteIDs, suiteID)
-		} else {
+		suiteID, ok := SupportedTLSCipherSuites[name]
+		if !ok && (strings.HasPrefix(name, "0x") || strings.HasPrefix(name, "0X")) {
+			if id, err := strconv.ParseUint(name[2:], 16, 16); err == nil {
+				_, ok = SupportedTLSCipherSuitesToString[uint16(id)]
+				suiteID = uint16(id)
+			}
+		}
+		if !ok {
 			return errors.New("Unknown cipher suite: " + name)
 		}
+		suiteIDs = append(suiteIDs, suiteID)
 	}
 
 	*s = suiteIDs
@@ -133,6 +145,18 @@ func (s *TLSCipherSuites) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// isTLS13CipherSuite returns whether the given ID is one of the fixed TLS 1.3 cipher suites.
+func isTLS13CipherSuite(id uint16) bool {
+	switch id {
+	case TLS13_CIPHER_SUITE_TLS_AES_128_GCM_SHA256,
+		TLS13_CIPHER_SUITE_TLS_AES_256_GCM_SHA384,
+		TLS13_CIPHER_SUITE_TLS_CHACHA20_POLY1305_SHA256:
+		return true
+	default:
+		return false
+	}
+}
+
 // Fields for TLSAuth. Unmarshalling hack.
 type TLSAuthFields struct {
 	// Certificate and key as a PEM-encoded string, including "-----BEGIN CERTIFICATE-----".
@@ -187,6 +211,25 @@ func (ipnet *IPNet) UnmarshalText(b []byte) error {
 	return nil
 }
 
+// The IP versions that the connections can be restricted to. With
+// IPVersionAny, both are raced with Happy Eyeballs (RFC 6555).
+const (
+	IPVersion4   = "ipv4"
+	IPVersion6   = "ipv6"
+	IPVersionAny = "any"
+)
+
+// ValidateIPVersion checks that the IP version is one of the known ones.
+func ValidateIPVersion(ipVersion string) error {
+	switch ipVersion {
+	case IPVersion4, IPVersion6, IPVersionAny:
+		return nil
+	default:
+		return fmt.Errorf("invalid ipVersion '%s', it should be %s, %s or %s",
+			ipVersion, IPVersion4, IPVersion6, IPVersionAny)
+	}
+}
+
 // HostAddress stores information about IP and port
 // for a host.
 type HostAddress net.TCPAddr
@@ -277,6 +320,90 @@ func ParseCIDR(s string) (*IPNet, error) {
 	return &parsedIPNet, nil
 }
 
+// TagRuleFields contains the fields of a TagRule. Unmarshalling hack.
+type TagRuleFields struct {
+	// Glob pattern the request URLs are matched against, "*" matches any
+	// sequence of characters. Exactly one of Pattern and Regex should be set.
+	Pattern string `json:"pattern,omitempty"`
+	// Regular expression the request URLs are matched against.
+	Regex string `json:"regex,omitempty"`
+
+	// Tags that are added to the metrics of the matching requests.
+	Tags map[string]string `json:"tags"`
+}
+
+// TagRule automatically tags the HTTP requests whose URLs match a pattern.
+type TagRule struct {
+	TagRuleFields
+	re *regexp.Regexp
+}
+
+// NewTagRule returns a new TagRule with the given glob pattern or regular
+// expression, or an error if neither or both of them are specified.
+func NewTagRule(pattern, regex string, tags map[string]string) (*TagRule, error) {
+	r := &TagRule{TagRuleFields: TagRuleFields{Pattern: pattern, Regex: regex, Tags: tags}}
+	if err := r.compile(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *TagRule) compile() (err error) {
+	switch {
+	case r.Pattern != "" && r.Regex != "":
+		return errors.New("tag rules should have either a pattern or a regex, not both")
+	case r.Pattern != "":
+		r.re, err = compileGlob(r.Pattern)
+	case r.Regex != "":
+		r.re, err = regexp.Compile(r.Regex)
+	default:
+		return errors.New("tag rules should have either a pattern or a regex")
+	}
+	return errors.Wrapf(err, "invalid tag rule")
+}
+
+// compileGlob returns an anchored regular expression for the given glob
+// pattern, in which "*" matches any sequence of characters.
+func compileGlob(pattern string) (*regexp.Regexp, error) {
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.Compile("^" + strings.Join(parts, ".*") + "$")
+}
+
+// UnmarshalJSON validates the rule and compiles its pattern.
+func (r *TagRule) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &r.TagRuleFields); err != nil {
+		return err
+	}
+	return r.compile()
+}
+
+// MarshalJSON only marshals the public fields of the rule.
+func (r *TagRule) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.TagRuleFields)
+}
+
+// Matches returns whether the given URL matches the rule's pattern.
+func (r *TagRule) Matches(url string) bool {
+	return r.re != nil && r.re.MatchString(url)
+}
+
+// TagRules is an ordered list of tag rules.
+type TagRules []*TagRule
+
+// TagsFor returns the tags of the first rule that matches the given URL, or
+// nil if no rule matches it.
+func (rs TagRules) TagsFor(url string) map[string]string {
+	for _, r := range rs {
+		if r.Matches(url) {
+			return r.Tags
+		}
+	}
+	return nil
+}
+
 type Options struct {
 	// Should the test start in a paused state?
 	Paused null.Bool `json:"paused" envconfig:"K6_PAUSED"`
@@ -310,7 +437,8 @@ type Options struct {
 	// How many HTTP redirects do we follow?
 	MaxRedirects null.Int `json:"maxRedirects" envconfig:"K6_MAX_REDIRECTS"`
 
-	// Default User Agent string for HTTP requests.
+	// Default User Agent string for HTTP requests. If it is set to an empty
+	// string, no User-Agent header is sent by default.
 	UserAgent null.String `json:"userAgent" envconfig:"K6_USER_AGENT"`
 
 	// How many batch requests are allowed in parallel, in total and per host?
@@ -323,6 +451,12 @@ type Options struct {
 	// Accept invalid or untrusted TLS certificates.
 	InsecureSkipTLSVerify null.Bool `json:"insecureSkipTLSVerify" envconfig:"K6_INSECURE_SKIP_TLS_VERIFY"`
 
+	// The paths of PEM files with CA certificates that are trusted in addition
+	// to the system ones, e.g. the CA of an intercepting proxy like Burp.
+	// Relative paths are resolved from the directory of the script, like the
+	// ones of open(), and the files are included in its archive.
+	TrustedProxyCAs []string `json:"trustedProxyCAs" envconfig:"K6_TRUSTED_PROXY_CAS"`
+
 	// Specify TLS versions and cipher suites, and present client certificates.
 	TLSCipherSuites *TLSCipherSuites `json:"tlsCipherSuites" envconfig:"K6_TLS_CIPHER_SUITES"`
 	TLSVersion      *TLSVersions     `json:"tlsVersion" ignored:"true"`
@@ -336,12 +470,31 @@ type Options struct {
 	// metric on a nonexistent metric named 'real_metric{tagA:valueA,tagB:valueB}'.
 	Thresholds map[string]stats.Thresholds `json:"thresholds" envconfig:"K6_THRESHOLDS"`
 
+	// Gracefully stop the test run as soon as more than this many HTTP requests have failed
+	AbortOnErrorCount null.Int `json:"abortOnErrorCount" envconfig:"K6_ABORT_ON_ERROR_COUNT"`
+
 	// Blacklist IP ranges that tests may not contact. Mainly useful in hosted setups.
 	BlacklistIPs []*IPNet `json:"blacklistIPs" envconfig:"K6_BLACKLIST_IPS"`
 
 	// Hosts overrides dns entries for given hosts
 	Hosts map[string]*HostAddress `json:"hosts" envconfig:"K6_HOSTS"`
 
+	// Tag HTTP requests based on their URLs, the first matching rule is used
+	TagRules TagRules `json:"tagRules" ignored:"true"`
+
+	// Propagate a trace context with the HTTP requests
+	TraceContext *TraceContext `json:"traceContext" ignored:"true"`
+
+	// Retry failed connection attempts, i.e. when the connection is refused, this many times,
+	// with an exponential backoff between the attempts. DNS errors are only retried if
+	// RetryDNSErrors is enabled, since they are usually persistent.
+	ConnectionRetries null.Int  `json:"connectionRetries" envconfig:"K6_CONNECTION_RETRIES"`
+	RetryDNSErrors    null.Bool `json:"retryDNSErrors" envconfig:"K6_RETRY_DNS_ERRORS"`
+
+	// Only connect to the IPv4 or the IPv6 addresses of the hosts, or race them with "any".
+	// By default, the first address that the host resolves to is used.
+	IPVersion null.String `json:"ipVersion" envconfig:"K6_IP_VERSION"`
+
 	// Disable keep-alive connections
 	NoConnectionReuse null.Bool `json:"noConnectionReuse" envconfig:"K6_NO_CONNECTION_REUSE"`
 
@@ -371,15 +524,38 @@ type Options struct {
 	// Tags to be applied to all samples for this running
 	RunTags *stats.SampleTags `json:"tags" envconfig:"K6_TAGS"`
 
+	// Whether to add the git_commit, git_branch and git_tag tags of the git
+	// repository in the current directory to the run tags
+	GitTags null.Bool `json:"gitTags" envconfig:"K6_GIT_TAGS"`
+
 	// Buffer size of the channel for metric samples; 0 means unbuffered
 	MetricSamplesBufferSize null.Int `json:"metricSamplesBufferSize" envconfig:"K6_METRIC_SAMPLES_BUFFER_SIZE"`
 
+	// How often the time-series outputs (InfluxDB, Kafka, StatsD, Datadog and
+	// the cloud) push the metrics, unless they have their own push interval set
+	MetricPushInterval types.NullDuration `json:"metricPushInterval" envconfig:"K6_METRIC_PUSH_INTERVAL"`
+
+	// The relative accuracy of the DDSketches in which the Trend metrics are
+	// stored, instead of keeping all of their values, e.g. 0.01 for 1%
+	TrendSketchAccuracy null.Float `json:"trendSketchAccuracy" envconfig:"K6_TREND_SKETCH_ACCURACY"`
+
 	// Do not reset cookies after a VU iteration
 	NoCookiesReset null.Bool `json:"noCookiesReset" envconfig:"K6_NO_COOKIES_RESET"`
 
 	// Discard Http Responses Body
 	DiscardResponseBodies null.Bool `json:"discardResponseBodies" envconfig:"K6_DISCARD_RESPONSE_BODIES"`
 
+	// Don't send the Accept-Encoding header with HTTP requests by default
+	DisableCompression null.Bool `json:"disableCompression" envconfig:"K6_DISABLE_COMPRESSION"`
+
+	// Send only one of the concurrent identical GET and HEAD requests without a
+	// body in a batch and share its response
+	RequestCoalescing null.Bool `json:"requestCoalescing" envconfig:"K6_REQUEST_COALESCING"`
+
+	// Seed for the random data generated by k6 modules like k6/faker, which makes it
+	// the same between test runs. It's combined with the VU number and iteration.
+	RandomSeed null.Int `json:"randomSeed" envconfig:"K6_RANDOM_SEED"`
+
 	// Redirect console logging to a file
 	ConsoleOutput null.String `json:"-" envconfig:"K6_CONSOLE_OUTPUT"`
 }
@@ -473,6 +649,9 @@ func (o Options) Apply(opts Options) Options {
 	if opts.InsecureSkipTLSVerify.Valid {
 		o.InsecureSkipTLSVerify = opts.InsecureSkipTLSVerify
 	}
+	if opts.TrustedProxyCAs != nil {
+		o.TrustedProxyCAs = opts.TrustedProxyCAs
+	}
 	if opts.TLSCipherSuites != nil {
 		o.TLSCipherSuites = opts.TLSCipherSuites
 	}
@@ -491,12 +670,30 @@ func (o Options) Apply(opts Options) Options {
 	if opts.Thresholds != nil {
 		o.Thresholds = opts.Thresholds
 	}
+	if opts.AbortOnErrorCount.Valid {
+		o.AbortOnErrorCount = opts.AbortOnErrorCount
+	}
 	if opts.BlacklistIPs != nil {
 		o.BlacklistIPs = opts.BlacklistIPs
 	}
 	if opts.Hosts != nil {
 		o.Hosts = opts.Hosts
 	}
+	if opts.TagRules != nil {
+		o.TagRules = opts.TagRules
+	}
+	if opts.TraceContext != nil {
+		o.TraceContext = opts.TraceContext
+	}
+	if opts.ConnectionRetries.Valid {
+		o.ConnectionRetries = opts.ConnectionRetries
+	}
+	if opts.RetryDNSErrors.Valid {
+		o.RetryDNSErrors = opts.RetryDNSErrors
+	}
+	if opts.IPVersion.Valid {
+		o.IPVersion = opts.IPVersion
+	}
 	if opts.NoConnectionReuse.Valid {
 		o.NoConnectionReuse = opts.NoConnectionReuse
 	}
@@ -524,12 +721,30 @@ func (o Options) Apply(opts Options) Options {
 	if !opts.RunTags.IsEmpty() {
 		o.RunTags = opts.RunTags
 	}
+	if opts.GitTags.Valid {
+		o.GitTags = opts.GitTags
+	}
 	if opts.MetricSamplesBufferSize.Valid {
 		o.MetricSamplesBufferSize = opts.MetricSamplesBufferSize
 	}
+	if opts.MetricPushInterval.Valid {
+		o.MetricPushInterval = opts.MetricPushInterval
+	}
+	if opts.TrendSketchAccuracy.Valid {
+		o.TrendSketchAccuracy = opts.TrendSketchAccuracy
+	}
 	if opts.DiscardResponseBodies.Valid {
 		o.DiscardResponseBodies = opts.DiscardResponseBodies
 	}
+	if opts.DisableCompression.Valid {
+		o.DisableCompression = opts.DisableCompression
+	}
+	if opts.RequestCoalescing.Valid {
+		o.RequestCoalescing = opts.RequestCoalescing
+	}
+	if opts.RandomSeed.Valid {
+		o.RandomSeed = opts.RandomSeed
+	}
 	if opts.ConsoleOutput.Valid {
 		o.ConsoleOutput = opts.ConsoleOutput
 	}
@@ -556,9 +771,70 @@ func (o Options) Validate() []error {
 					o.ExecutionSegment, o.ExecutionSegmentSequence))
 		}
 	}
+	if o.MetricPushInterval.Valid && o.MetricPushInterval.Duration <= 0 {
+		errors = append(errors, fmt.Errorf("the metricPushInterval should be more than 0"))
+	}
+	if o.ConnectionRetries.Valid && o.ConnectionRetries.Int64 < 0 {
+		errors = append(errors, fmt.Errorf("the connectionRetries can't be negative"))
+	}
+	if o.AbortOnErrorCount.Valid && o.AbortOnErrorCount.Int64 < 0 {
+		errors = append(errors, fmt.Errorf("the abortOnErrorCount can't be negative"))
+	}
+	if o.TraceContext != nil {
+		errors = append(errors, o.TraceContext.Validate()...)
+	}
+	if o.IPVersion.Valid {
+		if err := ValidateIPVersion(o.IPVersion.String); err != nil {
+			errors = append(errors, err)
+		}
+	}
+	if o.TrendSketchAccuracy.Valid && (o.TrendSketchAccuracy.Float64 <= 0 || o.TrendSketchAccuracy.Float64 >= 1) {
+		errors = append(errors, fmt.Errorf("the trendSketchAccuracy should be between 0 and 1"))
+	}
+	if o.TLSCipherSuites != nil && len(*o.TLSCipherSuites) > 0 && o.TLSVersion != nil &&
+		o.TLSVersion.Max != 0 && o.TLSVersion.Max < TLSVersion13 {
+		onlyTLS13 := true
+		for _, id := range *o.TLSCipherSuites {
+			onlyTLS13 = onlyTLS13 && isTLS13CipherSuite(id)
+		}
+		if onlyTLS13 {
+			errors = append(errors, fmt.Errorf(
+				"the tlsCipherSuites only contain TLS 1.3 cipher suites, but the tlsVersion doesn't allow TLS 1.3"))
+		}
+	}
 	return append(errors, o.Scenarios.Validate()...)
 }
 
+// TLSCipherSuitesWarnings returns warnings about the parts of the tlsCipherSuites
+// option that won't have an effect, since the TLS 1.3 cipher suites are fixed.
+func (o Options) TLSCipherSuitesWarnings() []string {
+	if o.TLSCipherSuites == nil || len(*o.TLSCipherSuites) == 0 {
+		return nil
+	}
+	var tls13Names []string
+	var hasOlder bool
+	for _, id := range *o.TLSCipherSuites {
+		if isTLS13CipherSuite(id) {
+			tls13Names = append(tls13Names, SupportedTLSCipherSuitesToString[id])
+		} else {
+			hasOlder = true
+		}
+	}
+
+	var warnings []string
+	if len(tls13Names) > 0 {
+		warnings = append(warnings, fmt.Sprintf(
+			"the TLS 1.3 cipher suites %s can't be configured, they are always enabled for TLS 1.3 connections",
+			strings.Join(tls13Names, ", ")))
+	}
+	if hasOlder && (o.TLSVersion == nil || o.TLSVersion.Max == 0 || o.TLSVersion.Max >= TLSVersion13) {
+		warnings = append(warnings, "the tlsCipherSuites only apply to TLS 1.0 - 1.2 connections, "+
+			"TLS 1.3 connections will use the fixed TLS 1.3 cipher suites; set the max tlsVersion "+
+			"to tls1.2 to only use the specified ones")
+	}
+	return warnings
+}
+
 // ForEachSpecified enumerates all struct fields and calls the supplied function with each
 // element that is valid. It panics for any unfamiliar or unexpected fields, so make sure
 // new fields in Options are accounted for.
@@ -120,6 +120,10 @@ func TestOptions(t *testing.T) {
 		assert.True(t, opts.InsecureSkipTLSVerify.Valid)
 		assert.True(t, opts.InsecureSkipTLSVerify.Bool)
 	})
+	t.Run("TrustedProxyCAs", func(t *testing.T) {
+		opts := Options{}.Apply(Options{TrustedProxyCAs: []string{"burp-ca.crt"}})
+		assert.Equal(t, []string{"burp-ca.crt"}, opts.TrustedProxyCAs)
+	})
 	t.Run("TLSCipherSuites", func(t *testing.T) {
 		for suiteName, suiteID := range SupportedTLSCipherSuites {
 			t.Run(suiteName, func(t *testing.T) {
@@ -157,6 +161,45 @@ func TestOptions(t *testing.T) {
 				jsonStr := `{"tlsCipherSuites":["foo"]}`
 				assert.Error(t, json.Unmarshal([]byte(jsonStr), &opts))
 			})
+			t.Run("Hex ID", func(t *testing.T) {
+				var opts Options
+				jsonStr := `{"tlsCipherSuites":["0xc030","0X1301"]}`
+				assert.NoError(t, json.Unmarshal([]byte(jsonStr), &opts))
+				assert.Equal(t, &TLSCipherSuites{tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+					TLS13_CIPHER_SUITE_TLS_AES_128_GCM_SHA256}, opts.TLSCipherSuites)
+
+				assert.Error(t, json.Unmarshal([]byte(`{"tlsCipherSuites":["0xffff"]}`), &opts))
+				assert.Error(t, json.Unmarshal([]byte(`{"tlsCipherSuites":["0xnope"]}`), &opts))
+			})
+			t.Run("Empty", func(t *testing.T) {
+				var opts Options
+				assert.NoError(t, json.Unmarshal([]byte(`{"tlsCipherSuites":[]}`), &opts))
+				assert.NotNil(t, opts.TLSCipherSuites)
+				assert.Empty(t, *opts.TLSCipherSuites)
+				assert.Empty(t, opts.TLSCipherSuitesWarnings())
+			})
+		})
+
+		t.Run("TLS 1.3", func(t *testing.T) {
+			tls12 := &TLSVersions{Min: tls.VersionTLS12, Max: tls.VersionTLS12}
+			tls13Suites := &TLSCipherSuites{TLS13_CIPHER_SUITE_TLS_AES_128_GCM_SHA256}
+			olderSuites := &TLSCipherSuites{tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384}
+
+			opts := Options{TLSCipherSuites: tls13Suites}
+			assert.Empty(t, opts.Validate())
+			assert.Equal(t, []string{"the TLS 1.3 cipher suites TLS_AES_128_GCM_SHA256 can't be configured, " +
+				"they are always enabled for TLS 1.3 connections"}, opts.TLSCipherSuitesWarnings())
+
+			opts = Options{TLSCipherSuites: tls13Suites, TLSVersion: tls12}
+			assert.Len(t, opts.Validate(), 1)
+
+			opts = Options{TLSCipherSuites: olderSuites}
+			assert.Empty(t, opts.Validate())
+			assert.Len(t, opts.TLSCipherSuitesWarnings(), 1)
+
+			opts = Options{TLSCipherSuites: olderSuites, TLSVersion: tls12}
+			assert.Empty(t, opts.Validate())
+			assert.Empty(t, opts.TLSCipherSuitesWarnings())
 		})
 	})
 	t.Run("TLSVersion", func(t *testing.T) {
@@ -285,6 +328,23 @@ func TestOptions(t *testing.T) {
 			assert.Error(t, json.Unmarshal([]byte(jsonStr), &opts))
 		})
 	})
+	t.Run("ConnectionRetries", func(t *testing.T) {
+		opts := Options{}.Apply(Options{ConnectionRetries: null.IntFrom(3), RetryDNSErrors: null.BoolFrom(true)})
+		assert.True(t, opts.ConnectionRetries.Valid)
+		assert.Equal(t, int64(3), opts.ConnectionRetries.Int64)
+		assert.True(t, opts.RetryDNSErrors.Valid)
+		assert.True(t, opts.RetryDNSErrors.Bool)
+		assert.Empty(t, opts.Validate())
+		opts = Options{}.Apply(Options{ConnectionRetries: null.IntFrom(-1)})
+		assert.Len(t, opts.Validate(), 1)
+	})
+	t.Run("IPVersion", func(t *testing.T) {
+		opts := Options{}.Apply(Options{IPVersion: null.StringFrom(IPVersion6)})
+		assert.True(t, opts.IPVersion.Valid)
+		assert.Equal(t, "ipv6", opts.IPVersion.String)
+		assert.Empty(t, opts.Validate())
+		assert.Len(t, Options{IPVersion: null.StringFrom("ipv5")}.Validate(), 1)
+	})
 	t.Run("NoConnectionReuse", func(t *testing.T) {
 		opts := Options{}.Apply(Options{NoConnectionReuse: null.BoolFrom(true)})
 		assert.True(t, opts.NoConnectionReuse.Valid)
@@ -327,6 +387,47 @@ func TestOptions(t *testing.T) {
 		assert.Equal(t, "192.0.2.1:80", opts.Hosts["test.loadimpact.com"].String())
 	})
 
+	t.Run("TagRules", func(t *testing.T) {
+		rule, err := NewTagRule("*/api/v2/users/*", "", map[string]string{"service": "users-api"})
+		require.NoError(t, err)
+
+		opts := Options{}.Apply(Options{TagRules: TagRules{rule}})
+		assert.Len(t, opts.TagRules, 1)
+		assert.Equal(t, map[string]string{"service": "users-api"}, opts.TagRules.TagsFor("https://example.com/api/v2/users/123"))
+		assert.Nil(t, opts.TagRules.TagsFor("https://example.com/api/v2/orders/123"))
+
+		t.Run("JSON", func(t *testing.T) {
+			var opts Options
+			jsonStr := `{"tagRules": [
+				{"regex": "/orders/\\d+$", "tags": {"service": "orders-api"}},
+				{"pattern": "https://example.com/*", "tags": {"service": "other"}}
+			]}`
+			require.NoError(t, json.Unmarshal([]byte(jsonStr), &opts))
+			assert.Equal(t, map[string]string{"service": "orders-api"}, opts.TagRules.TagsFor("https://example.com/orders/1"))
+			assert.Equal(t, map[string]string{"service": "other"}, opts.TagRules.TagsFor("https://example.com/orders/"))
+			assert.Nil(t, opts.TagRules.TagsFor("http://example.com/"))
+
+			t.Run("Roundtrip", func(t *testing.T) {
+				data, err := json.Marshal(opts.TagRules)
+				require.NoError(t, err)
+				var rules TagRules
+				require.NoError(t, json.Unmarshal(data, &rules))
+				assert.Equal(t, opts.TagRules, rules)
+			})
+		})
+
+		t.Run("Invalid", func(t *testing.T) {
+			for _, jsonStr := range []string{
+				`{"tagRules": [{"tags": {"a": "b"}}]}`,
+				`{"tagRules": [{"pattern": "*", "regex": ".*", "tags": {"a": "b"}}]}`,
+				`{"tagRules": [{"regex": "(", "tags": {"a": "b"}}]}`,
+			} {
+				var opts Options
+				assert.Error(t, json.Unmarshal([]byte(jsonStr), &opts), jsonStr)
+			}
+		})
+	})
+
 	t.Run("Throws", func(t *testing.T) {
 		opts := Options{}.Apply(Options{Throw: null.BoolFrom(true)})
 		assert.True(t, opts.Throw.Valid)
@@ -395,11 +496,63 @@ func TestOptions(t *testing.T) {
 		opts := Options{}.Apply(Options{RunTags: tags})
 		assert.Equal(t, tags, opts.RunTags)
 	})
+	t.Run("GitTags", func(t *testing.T) {
+		opts := Options{}.Apply(Options{GitTags: null.BoolFrom(true)})
+		assert.True(t, opts.GitTags.Valid)
+		assert.True(t, opts.GitTags.Bool)
+	})
 	t.Run("DiscardResponseBodies", func(t *testing.T) {
 		opts := Options{}.Apply(Options{DiscardResponseBodies: null.BoolFrom(true)})
 		assert.True(t, opts.DiscardResponseBodies.Valid)
 		assert.True(t, opts.DiscardResponseBodies.Bool)
 	})
+	t.Run("MetricPushInterval", func(t *testing.T) {
+		opts := Options{}.Apply(Options{MetricPushInterval: types.NullDurationFrom(5 * time.Second)})
+		assert.Equal(t, types.NullDurationFrom(5*time.Second), opts.MetricPushInterval)
+		assert.Empty(t, opts.Validate())
+		opts = Options{}.Apply(Options{MetricPushInterval: types.NullDurationFrom(0)})
+		assert.Len(t, opts.Validate(), 1)
+	})
+	t.Run("AbortOnErrorCount", func(t *testing.T) {
+		opts := Options{}.Apply(Options{AbortOnErrorCount: null.IntFrom(100)})
+		assert.Equal(t, null.IntFrom(100), opts.AbortOnErrorCount)
+		assert.Empty(t, opts.Validate())
+		opts = Options{}.Apply(Options{AbortOnErrorCount: null.IntFrom(-1)})
+		assert.Len(t, opts.Validate(), 1)
+	})
+	t.Run("TraceContext", func(t *testing.T) {
+		tc := &TraceContext{Propagator: null.StringFrom(TraceContextW3C), SampleRate: null.FloatFrom(0.1)}
+		opts := Options{}.Apply(Options{TraceContext: tc})
+		assert.Equal(t, tc, opts.TraceContext)
+		assert.Equal(t, 0.1, opts.TraceContext.GetSampleRate())
+		assert.Empty(t, opts.Validate())
+		opts = Options{}.Apply(Options{TraceContext: &TraceContext{Propagator: null.StringFrom(TraceContextRequestID)}})
+		assert.Empty(t, opts.Validate())
+		opts = Options{}.Apply(Options{TraceContext: &TraceContext{Propagator: null.StringFrom("b3")}})
+		assert.Len(t, opts.Validate(), 1)
+		opts = Options{}.Apply(Options{TraceContext: &TraceContext{SampleRate: null.FloatFrom(2)}})
+		assert.Len(t, opts.Validate(), 1)
+	})
+	t.Run("TrendSketchAccuracy", func(t *testing.T) {
+		opts := Options{}.Apply(Options{TrendSketchAccuracy: null.FloatFrom(0.01)})
+		assert.Equal(t, null.FloatFrom(0.01), opts.TrendSketchAccuracy)
+		assert.Empty(t, opts.Validate())
+		opts = Options{}.Apply(Options{TrendSketchAccuracy: null.FloatFrom(1)})
+		assert.Len(t, opts.Validate(), 1)
+	})
+	t.Run("DisableCompression", func(t *testing.T) {
+		opts := Options{}.Apply(Options{DisableCompression: null.BoolFrom(true)})
+		assert.True(t, opts.DisableCompression.Valid)
+		assert.True(t, opts.DisableCompression.Bool)
+	})
+	t.Run("RequestCoalescing", func(t *testing.T) {
+		opts := Options{}.Apply(Options{RequestCoalescing: null.BoolFrom(true)})
+		assert.Equal(t, null.BoolFrom(true), opts.RequestCoalescing)
+	})
+	t.Run("RandomSeed", func(t *testing.T) {
+		opts := Options{}.Apply(Options{RandomSeed: null.IntFrom(42)})
+		assert.Equal(t, null.IntFrom(42), opts.RandomSeed)
+	})
 }
 
 func TestOptionsEnv(t *testing.T) {
@@ -448,6 +601,18 @@ func TestOptionsEnv(t *testing.T) {
 		// TLSCipherSuites
 		// TLSVersion
 		// TLSAuth
+		{"ConnectionRetries", "K6_CONNECTION_RETRIES"}: {
+			"":  null.Int{},
+			"3": null.IntFrom(3),
+		},
+		{"IPVersion", "K6_IP_VERSION"}: {
+			"":     null.String{},
+			"ipv4": null.StringFrom("ipv4"),
+		},
+		{"RetryDNSErrors", "K6_RETRY_DNS_ERRORS"}: {
+			"":     null.Bool{},
+			"true": null.BoolFrom(true),
+		},
 		{"NoConnectionReuse", "K6_NO_CONNECTION_REUSE"}: {
 			"":      null.Bool{},
 			"true":  null.BoolFrom(true),
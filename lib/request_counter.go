@@ -0,0 +1,52 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package lib
+
+import "sync"
+
+// RequestCounter counts the HTTP requests that the VUs of a scenario make and
+// how many of them failed, i.e. returned an error or a 4xx or 5xx status, so
+// that the executor can react to the error rate. It's passed to the requests
+// through their context, see WithRequestCounter().
+type RequestCounter struct {
+	mu            sync.Mutex
+	total, failed uint64
+}
+
+// Add counts a request.
+func (rc *RequestCounter) Add(failed bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.total++
+	if failed {
+		rc.failed++
+	}
+}
+
+// Reset returns the number of requests and failed requests since the last
+// reset and zeroes them.
+func (rc *RequestCounter) Reset() (total, failed uint64) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	total, failed = rc.total, rc.failed
+	rc.total, rc.failed = 0, 0
+	return total, failed
+}
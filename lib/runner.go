@@ -22,6 +22,8 @@ package lib
 
 import (
 	"context"
+	"net/url"
+	"time"
 
 	"github.com/loadimpact/k6/stats"
 )
@@ -53,6 +55,22 @@ type VUActivationParams struct {
 	DeactivateCallback func(InitializedVU)
 	Env, Tags          map[string]string
 	Exec, Scenario     string
+	ExecProbabilities  []ExecProbability
+	ThinkTime          *ThinkTime
+	ProxyURL           *url.URL
+	MaxRPS             float64 // the rate limit of the VU, if it's positive
+
+	// The maximum random delay before the first iteration of the activation.
+	// It's sampled from the random number generator of the VU, so that it's
+	// reproducible with the randomSeed option.
+	StaggerJitter time.Duration
+}
+
+// ExecProbability is the probability with which a function is picked to be
+// executed at the start of each iteration, if a scenario has more than one.
+type ExecProbability struct {
+	Exec        string
+	Probability float64
 }
 
 // A Runner is a factory for VUs. It should precompute as much as possible upon
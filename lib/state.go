@@ -23,9 +23,13 @@ package lib
 import (
 	"context"
 	"crypto/tls"
+	"io"
+	"math/rand"
 	"net"
 	"net/http"
 	"net/http/cookiejar"
+	"net/url"
+	"sync"
 
 	"github.com/oxtoacart/bpool"
 	"github.com/sirupsen/logrus"
@@ -57,8 +61,36 @@ type State struct {
 	CookieJar *cookiejar.Jar
 	TLSConfig *tls.Config
 
-	// Rate limits.
-	RPSLimit *rate.Limiter
+	// Headers that are added to all HTTP requests made by the VU, unless they
+	// are overridden by the request params. They are set from the script.
+	GlobalHeaders http.Header
+
+	// Decides which HTTP response statuses are expected, i.e. which requests
+	// didn't fail, set from the script. If it's nil, the 4xx and 5xx statuses
+	// are the unexpected ones.
+	ResponseCallback func(status int) bool
+
+	// Canned responses for the HTTP requests made by the VU, set from the
+	// script. Matching requests never reach the network.
+	HTTPMocks HTTPMocks
+
+	// Handlers for the connections of the VU being established and closed,
+	// set from the script.
+	ConnHooks *ConnHooks
+
+	// The hosts whose TLS certificate expiry was already recorded, shared
+	// between all of the VUs of the test run. If it's nil, it isn't recorded.
+	TLSCertExpiryHosts *sync.Map
+
+	// The statistics of the HTTP connection pools, shared between all of the
+	// VUs of the test run. If it's nil, they aren't recorded.
+	ConnPoolStats *ConnPoolStats
+
+	// Rate limits, the global one and the one of the VU. The VU's one is
+	// replaced when the script changes it, so the requests use the one they
+	// were made with.
+	RPSLimit   *rate.Limiter
+	VURPSLimit *rate.Limiter
 
 	// Sample channel, possibly buffered
 	Samples chan<- stats.SampleContainer
@@ -67,8 +99,113 @@ type State struct {
 	// TODO: maybe use https://golang.org/pkg/sync/#Pool ?
 	BPool *bpool.BufferPool
 
+	// Random number generator for the data generated by k6 modules, like
+	// k6/faker. If the randomSeed option is set, it's reseeded at the start of
+	// every iteration, based on the seed, the VU number and the iteration.
+	Rand *rand.Rand
+
 	Vu, Iteration int64
 	Tags          map[string]string
+
+	// The name of the scenario that the VU is running.
+	Scenario string
+
+	// The trace ID of the current iteration, if it's sampled with the
+	// traceContext option
+	TraceID string
+
+	// The proxy of the scenario that the VU is running, if it has one.
+	ProxyURL *url.URL
+
+	// Resources that have to be released at the end of the current
+	// iteration, like the bodies of streaming responses that weren't read
+	// to the end by the script.
+	iterationClosers   []io.Closer
+	iterationClosersMu sync.Mutex
+
+	// The transports for the requests that have to use a specific IP
+	// version or that are balanced, see IPVersionTransport() and
+	// BalancerTransport()
+	transports   map[string]http.RoundTripper
+	transportsMu sync.Mutex
+}
+
+// IPVersionTransport returns the transport of the VU for the requests that
+// have to use the given IP version. It's created with newTransport the first
+// time and then reused, so that its connections are kept alive, but only
+// reused by the requests for the same IP version. It's safe for concurrent use.
+func (s *State) IPVersionTransport(ipVersion string, newTransport func() http.RoundTripper) http.RoundTripper {
+	return s.cachedTransport("ipVersion "+ipVersion, newTransport)
+}
+
+// BalancerTransport returns the transport of the VU for the balanced requests
+// with the given key, which identifies the address and the target the requests
+// are sent to. Like with IPVersionTransport(), it's created with newTransport
+// the first time and then reused.
+func (s *State) BalancerTransport(key string, newTransport func() http.RoundTripper) http.RoundTripper {
+	return s.cachedTransport("balancer "+key, newTransport)
+}
+
+func (s *State) cachedTransport(key string, newTransport func() http.RoundTripper) http.RoundTripper {
+	s.transportsMu.Lock()
+	defer s.transportsMu.Unlock()
+	if t, ok := s.transports[key]; ok {
+		return t
+	}
+	if s.transports == nil {
+		s.transports = make(map[string]http.RoundTripper)
+	}
+	t := newTransport()
+	s.transports[key] = t
+	return t
+}
+
+// CloseIdleConnections closes the connections of the transports of the VU that
+// aren't currently in use.
+func (s *State) CloseIdleConnections() {
+	type idleCloser interface{ CloseIdleConnections() }
+	if t, ok := s.Transport.(idleCloser); ok {
+		t.CloseIdleConnections()
+	}
+
+	s.transportsMu.Lock()
+	defer s.transportsMu.Unlock()
+	for _, t := range s.transports {
+		if t, ok := t.(idleCloser); ok {
+			t.CloseIdleConnections()
+		}
+	}
+}
+
+// CloseAtIterationEnd registers c to be closed at the end of the current
+// iteration. It's safe for concurrent use, e.g. by batch requests.
+func (s *State) CloseAtIterationEnd(c io.Closer) {
+	s.iterationClosersMu.Lock()
+	s.iterationClosers = append(s.iterationClosers, c)
+	s.iterationClosersMu.Unlock()
+}
+
+// CloseIterationResources closes everything registered with
+// CloseAtIterationEnd during the current iteration.
+func (s *State) CloseIterationResources() {
+	s.iterationClosersMu.Lock()
+	closers := s.iterationClosers
+	s.iterationClosers = nil
+	s.iterationClosersMu.Unlock()
+
+	for _, c := range closers {
+		_ = c.Close()
+	}
+}
+
+// ProxyFromState is the Proxy of the VU transports. It returns the proxy of the
+// scenario that the VU of the request is running, or the one from the
+// environment variables, if the scenario doesn't have one.
+func ProxyFromState(req *http.Request) (*url.URL, error) {
+	if state := GetState(req.Context()); state != nil && state.ProxyURL != nil {
+		return state.ProxyURL, nil
+	}
+	return http.ProxyFromEnvironment(req)
 }
 
 // CloneTags makes a copy of the tags map and returns it.
@@ -0,0 +1,71 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2019 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package lib
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateIPVersionTransport(t *testing.T) {
+	st := &State{}
+	created := 0
+	newTransport := func() http.RoundTripper {
+		created++
+		return &http.Transport{}
+	}
+
+	t4 := st.IPVersionTransport(IPVersion4, newTransport)
+	assert.True(t, t4 == st.IPVersionTransport(IPVersion4, newTransport))
+	t6 := st.IPVersionTransport(IPVersion6, newTransport)
+	assert.False(t, t4 == t6)
+	assert.Equal(t, 2, created)
+
+	st.CloseIdleConnections()
+}
+
+type idleClosingTransport struct {
+	http.RoundTripper
+	closed int
+}
+
+func (t *idleClosingTransport) CloseIdleConnections() {
+	t.closed++
+}
+
+func TestStateBalancerTransport(t *testing.T) {
+	st := &State{}
+	newTransport := func() http.RoundTripper {
+		return &idleClosingTransport{}
+	}
+
+	t1 := st.BalancerTransport("example.com:80 10.0.0.1", newTransport)
+	assert.True(t, t1 == st.BalancerTransport("example.com:80 10.0.0.1", newTransport))
+	t2 := st.BalancerTransport("example.com:80 10.0.0.2", newTransport)
+	assert.False(t, t1 == t2)
+	assert.False(t, t1 == st.IPVersionTransport("example.com:80 10.0.0.1", newTransport))
+
+	st.CloseIdleConnections()
+	assert.Equal(t, 1, t1.(*idleClosingTransport).closed)
+	assert.Equal(t, 1, t2.(*idleClosingTransport).closed)
+}
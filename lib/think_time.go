@@ -0,0 +1,137 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package lib
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/loadimpact/k6/lib/types"
+)
+
+// The distributions of the think time.
+const (
+	ThinkTimeUniform     = "uniform"
+	ThinkTimeNormal      = "normal"
+	ThinkTimeLognormal   = "lognormal"
+	ThinkTimeExponential = "exponential"
+)
+
+// ThinkTime is the configuration of the pause that the VUs of a scenario make
+// after each iteration, which is randomly sampled from a distribution and
+// clamped to the [min, max] range.
+//
+// The mean defaults to the middle of the range, and the stddev of the normal
+// and lognormal distributions to a sixth of it. The lambda of the exponential
+// distribution is its rate per second, and it defaults to 1/mean.
+type ThinkTime struct {
+	Min          types.NullDuration `json:"min"`
+	Max          types.NullDuration `json:"max"`
+	Distribution null.String        `json:"distribution"`
+	Mean         types.NullDuration `json:"mean"`
+	StdDev       types.NullDuration `json:"stddev"`
+	Lambda       null.Float         `json:"lambda"`
+}
+
+// Validate checks the think time config.
+func (tt ThinkTime) Validate() (errors []error) {
+	switch tt.Distribution.String {
+	case "", ThinkTimeUniform, ThinkTimeNormal, ThinkTimeLognormal, ThinkTimeExponential:
+	default:
+		errors = append(errors, fmt.Errorf(
+			"unknown think time distribution '%s', it should be one of %s, %s, %s or %s", tt.Distribution.String,
+			ThinkTimeUniform, ThinkTimeNormal, ThinkTimeLognormal, ThinkTimeExponential,
+		))
+	}
+	if !tt.Max.Valid {
+		errors = append(errors, fmt.Errorf("the think time max has to be specified"))
+	}
+	if tt.Min.Duration < 0 {
+		errors = append(errors, fmt.Errorf("the think time min can't be negative"))
+	}
+	if tt.Max.Duration < tt.Min.Duration {
+		errors = append(errors, fmt.Errorf("the think time max can't be less than its min"))
+	}
+	if tt.Mean.Valid && (tt.Mean.Duration < tt.Min.Duration || tt.Mean.Duration > tt.Max.Duration) {
+		errors = append(errors, fmt.Errorf("the think time mean should be between its min and max"))
+	}
+	if tt.StdDev.Duration < 0 {
+		errors = append(errors, fmt.Errorf("the think time stddev can't be negative"))
+	}
+	if tt.Lambda.Valid && tt.Lambda.Float64 <= 0 {
+		errors = append(errors, fmt.Errorf("the think time lambda should be positive"))
+	}
+	return errors
+}
+
+// GetDistribution returns the distribution of the think time, uniform by default.
+func (tt ThinkTime) GetDistribution() string {
+	if tt.Distribution.String == "" {
+		return ThinkTimeUniform
+	}
+	return tt.Distribution.String
+}
+
+// String returns a short description of the think time.
+func (tt ThinkTime) String() string {
+	return fmt.Sprintf("%s %s-%s", tt.GetDistribution(), tt.Min.Duration, tt.Max.Duration)
+}
+
+// Sample returns a random think time.
+func (tt ThinkTime) Sample(r *rand.Rand) time.Duration {
+	min, max := float64(tt.Min.Duration), float64(tt.Max.Duration)
+	mean := (min + max) / 2
+	if tt.Mean.Valid {
+		mean = float64(tt.Mean.Duration)
+	}
+	stddev := (max - min) / 6
+	if tt.StdDev.Valid {
+		stddev = float64(tt.StdDev.Duration)
+	}
+
+	var v float64
+	switch tt.GetDistribution() {
+	case ThinkTimeNormal:
+		v = mean + r.NormFloat64()*stddev
+	case ThinkTimeLognormal:
+		if mean <= 0 {
+			break
+		}
+		// The parameters of the underlying normal distribution, so that the
+		// think time has the configured mean and stddev
+		sigma2 := math.Log(1 + (stddev*stddev)/(mean*mean))
+		mu := math.Log(mean) - sigma2/2
+		v = math.Exp(mu + r.NormFloat64()*math.Sqrt(sigma2))
+	case ThinkTimeExponential:
+		lambda := float64(time.Second) / mean
+		if tt.Lambda.Valid {
+			lambda = tt.Lambda.Float64
+		}
+		v = r.ExpFloat64() / lambda * float64(time.Second)
+	default:
+		v = min + r.Float64()*(max-min)
+	}
+	return time.Duration(math.Min(math.Max(v, min), max))
+}
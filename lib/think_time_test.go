@@ -0,0 +1,79 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package lib
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v3"
+
+	"github.com/loadimpact/k6/lib/types"
+)
+
+func TestThinkTimeValidate(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		tt    ThinkTime
+		valid bool
+	}{
+		{ThinkTime{Max: types.NullDurationFrom(time.Second)}, true},
+		{ThinkTime{Min: types.NullDurationFrom(time.Second), Max: types.NullDurationFrom(2 * time.Second),
+			Distribution: null.StringFrom(ThinkTimeExponential), Lambda: null.FloatFrom(2)}, true},
+		{ThinkTime{}, false},
+		{ThinkTime{Max: types.NullDurationFrom(time.Second), Distribution: null.StringFrom("gamma")}, false},
+		{ThinkTime{Min: types.NullDurationFrom(2 * time.Second), Max: types.NullDurationFrom(time.Second)}, false},
+		{ThinkTime{Max: types.NullDurationFrom(time.Second), Mean: types.NullDurationFrom(2 * time.Second)}, false},
+		{ThinkTime{Max: types.NullDurationFrom(time.Second), Lambda: null.FloatFrom(0)}, false},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.valid, len(tc.tt.Validate()) == 0, "%#v", tc.tt)
+	}
+}
+
+func TestThinkTimeSample(t *testing.T) {
+	t.Parallel()
+	min, max := time.Second, 10*time.Second
+	for _, dist := range []string{ThinkTimeUniform, ThinkTimeNormal, ThinkTimeLognormal, ThinkTimeExponential} {
+		dist := dist
+		t.Run(dist, func(t *testing.T) {
+			t.Parallel()
+			tt := ThinkTime{
+				Min:          types.NullDurationFrom(min),
+				Max:          types.NullDurationFrom(max),
+				Distribution: null.StringFrom(dist),
+				Mean:         types.NullDurationFrom(3 * time.Second),
+			}
+			r := rand.New(rand.NewSource(1)) //nolint:gosec
+			var sum time.Duration
+			for i := 0; i < 10000; i++ {
+				d := tt.Sample(r)
+				assert.True(t, d >= min && d <= max, d)
+				sum += d
+			}
+			if dist != ThinkTimeUniform {
+				assert.InDelta(t, 3, (sum / 10000).Seconds(), 0.5)
+			}
+		})
+	}
+}
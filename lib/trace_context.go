@@ -0,0 +1,111 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package lib
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"gopkg.in/guregu/null.v3"
+)
+
+const (
+	// TraceContextW3C is the propagator of the W3C Trace Context headers, see
+	// https://www.w3.org/TR/trace-context/
+	TraceContextW3C = "w3c"
+	// TraceContextRequestID is the propagator that sends the trace ID of the
+	// iteration as the X-Request-ID header of all of its requests
+	TraceContextRequestID = "x-request-id"
+	// TraceContextNone disables the propagation of the trace context
+	TraceContextNone = "none"
+)
+
+// TraceContext configures the propagation of a trace context with the HTTP
+// requests. Each sampled iteration gets a new trace ID, which is sent with all
+// of its requests, with a new parent ID for each one with the w3c propagator.
+// It's propagated with the w3c one by default, unless the none propagator is
+// configured.
+type TraceContext struct {
+	Propagator null.String `json:"propagator"`
+	// The fraction of the iterations that are sampled, 1 by default
+	SampleRate null.Float `json:"sampleRate"`
+	// A tracestate header value that is sent along with the traceparent one
+	TraceState null.String `json:"traceState"`
+}
+
+// Validate checks the trace context config.
+func (tc TraceContext) Validate() (errors []error) {
+	switch tc.Propagator.String {
+	case "", TraceContextW3C, TraceContextRequestID, TraceContextNone:
+	default:
+		errors = append(errors, fmt.Errorf(
+			"unknown trace context propagator '%s', only %s, %s and %s are supported",
+			tc.Propagator.String, TraceContextW3C, TraceContextRequestID, TraceContextNone,
+		))
+	}
+	if tc.SampleRate.Valid && (tc.SampleRate.Float64 < 0 || tc.SampleRate.Float64 > 1) {
+		errors = append(errors, fmt.Errorf("the trace context sampleRate should be between 0 and 1"))
+	}
+	return errors
+}
+
+// IsEnabled returns whether the trace context is propagated, which it is if
+// it isn't configured at all.
+func (tc *TraceContext) IsEnabled() bool {
+	return tc == nil || tc.Propagator.String != TraceContextNone
+}
+
+// GetSampleRate returns the fraction of the iterations that are sampled.
+func (tc *TraceContext) GetSampleRate() float64 {
+	if tc == nil || !tc.SampleRate.Valid {
+		return 1
+	}
+	return tc.SampleRate.Float64
+}
+
+// NewTraceID returns a random W3C trace ID.
+func NewTraceID() string {
+	return randomHexID(16)
+}
+
+// NewSpanID returns a random W3C parent ID, which identifies a request.
+func NewSpanID() string {
+	return randomHexID(8)
+}
+
+func randomHexID(size int) string {
+	id := make([]byte, size)
+	// The all-zero IDs are invalid
+	for isZero(id) {
+		_, _ = rand.Read(id)
+	}
+	return hex.EncodeToString(id)
+}
+
+func isZero(b []byte) bool {
+	for _, v := range b {
+		if v != 0 {
+			return false
+		}
+	}
+	return true
+}
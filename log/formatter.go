@@ -0,0 +1,157 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/loadimpact/k6/lib"
+)
+
+// entryFields returns the fields of the entry, with the VU, iteration and
+// scenario of the VU state in its context, if it has one, e.g. for the
+// console logs of a VU.
+func entryFields(entry *logrus.Entry) logrus.Fields {
+	fields := make(logrus.Fields, len(entry.Data)+4)
+	if entry.Context != nil {
+		if state := lib.GetState(entry.Context); state != nil {
+			fields["vu"] = state.Vu
+			fields["iter"] = state.Iteration
+			if state.Scenario != "" {
+				fields["scenario"] = state.Scenario
+			}
+			if state.TraceID != "" {
+				fields["trace_id"] = state.TraceID
+			}
+		}
+	}
+	for k, v := range entry.Data {
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		fields[k] = v
+	}
+	return fields
+}
+
+func sortedKeys(fields logrus.Fields) []string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// LogfmtFormatter formats the log entries as logfmt lines, e.g.
+// `time="2020-10-15T10:00:00Z" level=info msg="some message" source=console vu=1`,
+// which can be parsed by Loki and the Heroku log drains.
+type LogfmtFormatter struct{}
+
+// Format renders a single log entry
+func (f LogfmtFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("time=")
+	writeLogfmtValue(&b, entry.Time.Format(time.RFC3339Nano))
+	b.WriteString(" level=")
+	b.WriteString(entry.Level.String())
+	b.WriteString(" msg=")
+	writeLogfmtValue(&b, entry.Message)
+
+	fields := entryFields(entry)
+	for _, k := range sortedKeys(fields) {
+		b.WriteByte(' ')
+		b.WriteString(k)
+		b.WriteByte('=')
+		writeLogfmtValue(&b, fmt.Sprint(fields[k]))
+	}
+	b.WriteByte('\n')
+	return b.Bytes(), nil
+}
+
+// writeLogfmtValue writes the value as it is, or quoted if it's empty or has
+// spaces, quotes, equals signs or non-printable characters.
+func writeLogfmtValue(b *bytes.Buffer, value string) {
+	if value == "" || strings.IndexFunc(value, func(r rune) bool {
+		return r <= ' ' || r == '=' || r == '"' || r == '\\' || !unicode.IsPrint(r)
+	}) >= 0 {
+		b.WriteString(strconv.Quote(value))
+		return
+	}
+	b.WriteString(value)
+}
+
+// gelfLevel returns the syslog severity of the logrus level.
+func gelfLevel(level logrus.Level) int {
+	switch level {
+	case logrus.PanicLevel:
+		return 0
+	case logrus.FatalLevel:
+		return 2
+	case logrus.ErrorLevel:
+		return 3
+	case logrus.WarnLevel:
+		return 4
+	case logrus.InfoLevel:
+		return 6
+	default:
+		return 7
+	}
+}
+
+// GELFFormatter formats the log entries as GELF 1.1 messages, one per line,
+// for Graylog. The fields of the entries are sent as additional fields.
+type GELFFormatter struct {
+	Host string
+}
+
+// Format renders a single log entry
+func (f GELFFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	msg := map[string]interface{}{
+		"version":       "1.1",
+		"host":          f.Host,
+		"short_message": entry.Message,
+		"timestamp":     math.Round(float64(entry.Time.UnixNano())/float64(time.Millisecond)) / 1000,
+		"level":         gelfLevel(entry.Level),
+	}
+	for k, v := range entryFields(entry) {
+		if k == "id" { // _id is reserved
+			k = "field_id"
+		}
+		msg["_"+k] = v
+	}
+
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the GELF message: %w", err)
+	}
+	return append(b, '\n'), nil
+}
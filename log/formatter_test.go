@@ -0,0 +1,87 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/loadimpact/k6/lib"
+)
+
+func newTestEntry() *logrus.Entry {
+	state := &lib.State{Vu: 3, Iteration: 7, Scenario: "default", TraceID: "4bf92f3577b34da6a3ce929d0e0e4736"}
+	entry := logrus.NewEntry(logrus.New()).
+		WithContext(lib.WithState(context.Background(), state)).
+		WithFields(logrus.Fields{"source": "console", "error": errors.New("some error")})
+	entry.Time = time.Date(2020, 10, 15, 10, 0, 0, 500000000, time.UTC)
+	entry.Level = logrus.WarnLevel
+	entry.Message = `a "quoted" message`
+	return entry
+}
+
+func TestLogfmtFormatter(t *testing.T) {
+	t.Parallel()
+	b, err := LogfmtFormatter{}.Format(newTestEntry())
+	require.NoError(t, err)
+	assert.Equal(t,
+		`time=2020-10-15T10:00:00.5Z level=warning msg="a \"quoted\" message" `+
+			`error="some error" iter=7 scenario=default source=console `+
+			`trace_id=4bf92f3577b34da6a3ce929d0e0e4736 vu=3`+"\n",
+		string(b))
+
+	b, err = LogfmtFormatter{}.Format(&logrus.Entry{Level: logrus.InfoLevel, Data: logrus.Fields{"empty": ""}})
+	require.NoError(t, err)
+	assert.Equal(t, `time=0001-01-01T00:00:00Z level=info msg="" empty=""`+"\n", string(b))
+}
+
+func TestGELFFormatter(t *testing.T) {
+	t.Parallel()
+	entry := newTestEntry()
+	entry.Data["id"] = "abc"
+	b, err := GELFFormatter{Host: "loadgen-1"}.Format(entry)
+	require.NoError(t, err)
+	assert.Equal(t, byte('\n'), b[len(b)-1])
+
+	var msg map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &msg))
+	assert.Equal(t, map[string]interface{}{
+		"version":       "1.1",
+		"host":          "loadgen-1",
+		"short_message": `a "quoted" message`,
+		"timestamp":     1602756000.5,
+		"level":         4.0,
+		"_source":       "console",
+		"_trace_id":     "4bf92f3577b34da6a3ce929d0e0e4736",
+		"_error":        "some error",
+		"_field_id":     "abc",
+		"_vu":           3.0,
+		"_iter":         7.0,
+		"_scenario":     "default",
+	}, msg)
+}
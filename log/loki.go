@@ -51,6 +51,7 @@ type lokiHook struct {
 	profile        bool
 	droppedLabels  map[string]string
 	droppedMsg     string
+	headers        http.Header
 }
 
 func getDefaultLoki() *lokiHook {
@@ -86,6 +87,44 @@ func LokiFromConfigLine(ctx context.Context, fallbackLogger logrus.FieldLogger,
 			return nil, err
 		}
 	}
+	h.start()
+
+	return h, nil
+}
+
+// LokiConfig configures a hook that is created with NewLokiHook.
+type LokiConfig struct {
+	Addr       string
+	Labels     map[string]string
+	Headers    http.Header // e.g. for authentication
+	Level      logrus.Level
+	PushPeriod time.Duration
+}
+
+// NewLokiHook returns a new logrus.Hook that pushes the entries that are at
+// least as severe as the configured level to Loki, or another service with the
+// same push API.
+func NewLokiHook(ctx context.Context, fallbackLogger logrus.FieldLogger, conf LokiConfig) logrus.Hook {
+	h := getDefaultLoki()
+	h.ctx = ctx
+	h.fallbackLogger = fallbackLogger
+	h.addr = conf.Addr
+	h.headers = conf.Headers
+	h.levels = logrus.AllLevels[:conf.Level+1]
+	if conf.PushPeriod > 0 {
+		h.pushPeriod = conf.PushPeriod
+	}
+	for k, v := range conf.Labels {
+		h.labels = append(h.labels, [2]string{k, v})
+	}
+	sort.Slice(h.labels, func(i, j int) bool { return h.labels[i][0] < h.labels[j][0] })
+
+	h.start()
+
+	return h
+}
+
+func (h *lokiHook) start() {
 	h.droppedLabels = make(map[string]string, 2+len(h.labels))
 	h.droppedLabels["level"] = logrus.WarnLevel.String()
 	for _, params := range h.labels {
@@ -97,8 +136,6 @@ func LokiFromConfigLine(ctx context.Context, fallbackLogger logrus.FieldLogger,
 	h.client = &http.Client{Timeout: h.pushPeriod}
 
 	go h.loop()
-
-	return h, nil
 }
 
 func (h *lokiHook) parseArgs(line string) error {
@@ -367,6 +404,9 @@ func (h *lokiHook) push(b bytes.Buffer) error {
 		return ioutil.NopCloser(bytes.NewBuffer(body)), nil
 	}
 
+	for k, v := range h.headers {
+		req.Header[k] = v
+	}
 	req.Header.Set("Content-Type", "application/json")
 
 	res, err := h.client.Do(req)
@@ -37,6 +37,7 @@ import (
 	"github.com/loadimpact/k6/lib/netext"
 	"github.com/loadimpact/k6/lib/netext/httpext"
 	"github.com/loadimpact/k6/loader"
+	"github.com/loadimpact/k6/log"
 	"github.com/loadimpact/k6/stats"
 )
 
@@ -79,7 +80,9 @@ type Collector struct {
 // Verify that Collector implements lib.Collector
 var _ lib.Collector = &Collector{}
 
-// MergeFromExternal merges three fields from json in a loadimact key of the provided external map
+// MergeFromExternal merges three fields from json in a loadimact key of the provided external map.
+// A ProjectID that is already set in conf, e.g. with --cloud-project-id or K6_CLOUD_PROJECT_ID,
+// takes precedence over the one in the script.
 func MergeFromExternal(external map[string]json.RawMessage, conf *Config) error {
 	if val, ok := external["loadimpact"]; ok {
 		// TODO: Important! Separate configs and fix the whole 2 configs mess!
@@ -88,7 +91,7 @@ func MergeFromExternal(external map[string]json.RawMessage, conf *Config) error
 			return err
 		}
 		// Only take out the ProjectID, Name and Token from the options.ext.loadimpact map:
-		if tmpConfig.ProjectID.Valid {
+		if tmpConfig.ProjectID.Valid && !conf.ProjectID.Valid {
 			conf.ProjectID = tmpConfig.ProjectID
 		}
 		if tmpConfig.Name.Valid {
@@ -213,6 +216,29 @@ func (c *Collector) Link() string {
 	return URLForResults(c.referenceID, c.config)
 }
 
+// NewLogsHook returns a logrus hook that pushes the log entries that are at
+// least as severe as the level to the cloud logs of the test run, batched
+// every second. It can only be used after Init() and with a token.
+func (c *Collector) NewLogsHook(
+	ctx context.Context, fallbackLogger logrus.FieldLogger, level logrus.Level,
+) (logrus.Hook, error) {
+	if c.anonymous || c.referenceID == "" {
+		return nil, errors.New(`the logs can only be sent to the cloud when logged in, please use "k6 login cloud"`)
+	}
+	if !c.config.LogsPushURL.Valid || c.config.LogsPushURL.String == "" {
+		return nil, errors.New("the URL for pushing the logs to the cloud has to be set with K6_CLOUD_LOGS_PUSH_URL")
+	}
+	headers := make(http.Header)
+	headers.Set("Authorization", "Token "+c.config.Token.String)
+	return log.NewLokiHook(ctx, fallbackLogger, log.LokiConfig{
+		Addr:       c.config.LogsPushURL.String,
+		Labels:     map[string]string{"test_run_id": c.referenceID},
+		Headers:    headers,
+		Level:      level,
+		PushPeriod: time.Second,
+	}), nil
+}
+
 // Run is called in a goroutine and starts the collector. Should commit samples to the backend
 // at regular intervals and when the context is terminated.
 func (c *Collector) Run(ctx context.Context) {
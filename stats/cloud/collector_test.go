@@ -33,6 +33,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/guregu/null.v3"
@@ -689,6 +690,64 @@ func TestCloudCollectorRecvIterLIAllIterations(t *testing.T) {
 	require.True(t, gotIterations)
 }
 
+func TestCloudCollectorLogsHook(t *testing.T) {
+	t.Parallel()
+	tb := httpmultibin.NewHTTPMultiBin(t)
+	defer tb.Cleanup()
+	pushes := make(chan string, 10)
+	tb.Mux.HandleFunc("/logs/push", func(_ http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, "Token 123abc", r.Header.Get("Authorization"))
+		pushes <- string(body)
+	})
+
+	script := &loader.SourceData{Data: []byte(""), URL: &url.URL{Path: "/script.js"}}
+	config := NewConfig().Apply(Config{
+		Host:        null.StringFrom(tb.ServerHTTP.URL),
+		LogsPushURL: null.StringFrom(tb.ServerHTTP.URL + "/logs/push"),
+	})
+	config.PushRefID = null.StringFrom("123")
+
+	anonymousCollector, err := New(testutils.NewLogger(t), config, script, lib.Options{}, nil, "1.0")
+	require.NoError(t, err)
+	require.NoError(t, anonymousCollector.Init())
+	_, err = anonymousCollector.NewLogsHook(context.Background(), testutils.NewLogger(t), logrus.InfoLevel)
+	require.Error(t, err)
+
+	config.Token = null.StringFrom("123abc")
+	noURLConfig := config
+	noURLConfig.LogsPushURL = null.String{}
+	noURLCollector, err := New(testutils.NewLogger(t), noURLConfig, script, lib.Options{}, nil, "1.0")
+	require.NoError(t, err)
+	require.NoError(t, noURLCollector.Init())
+	_, err = noURLCollector.NewLogsHook(context.Background(), testutils.NewLogger(t), logrus.InfoLevel)
+	require.EqualError(t, err, "the URL for pushing the logs to the cloud has to be set with K6_CLOUD_LOGS_PUSH_URL")
+
+	collector, err := New(testutils.NewLogger(t), config, script, lib.Options{}, nil, "1.0")
+	require.NoError(t, err)
+	require.NoError(t, collector.Init())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	hook, err := collector.NewLogsHook(ctx, testutils.NewLogger(t), logrus.WarnLevel)
+	require.NoError(t, err)
+
+	logger := logrus.New()
+	logger.SetOutput(ioutil.Discard)
+	logger.AddHook(hook)
+	logger.Info("not sent")
+	logger.Warn("sent")
+
+	select {
+	case body := <-pushes:
+		assert.Contains(t, body, `"test_run_id":"123"`)
+		assert.Contains(t, body, `"sent"`)
+		assert.NotContains(t, body, "not sent")
+	case <-time.After(5 * time.Second):
+		t.Fatal("the logs weren't pushed")
+	}
+}
+
 func TestNewName(t *testing.T) {
 	t.Parallel()
 	mustParse := func(u string) *url.URL {
@@ -736,3 +795,20 @@ func TestNewName(t *testing.T) {
 		})
 	}
 }
+
+func TestMergeFromExternal(t *testing.T) {
+	external := map[string]json.RawMessage{
+		"loadimpact": json.RawMessage(`{"projectID": 123, "name": "script name", "token": "script token"}`),
+	}
+
+	conf := NewConfig()
+	require.NoError(t, MergeFromExternal(external, &conf))
+	assert.Equal(t, null.IntFrom(123), conf.ProjectID)
+	assert.Equal(t, null.StringFrom("script name"), conf.Name)
+	assert.Equal(t, null.StringFrom("script token"), conf.Token)
+
+	// A project ID from the CLI flags or the environment isn't overridden
+	conf = NewConfig().Apply(Config{ProjectID: null.IntFrom(456)})
+	require.NoError(t, MergeFromExternal(external, &conf))
+	assert.Equal(t, null.IntFrom(456), conf.ProjectID)
+}
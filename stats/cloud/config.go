@@ -39,6 +39,7 @@ type Config struct {
 
 	Host        null.String `json:"host" envconfig:"K6_CLOUD_HOST"`
 	LogsTailURL null.String `json:"-" envconfig:"K6_CLOUD_LOGS_TAIL_URL"`
+	LogsPushURL null.String `json:"-" envconfig:"K6_CLOUD_LOGS_PUSH_URL"`
 	PushRefID   null.String `json:"pushRefID" envconfig:"K6_CLOUD_PUSH_REF_ID"`
 	WebAppURL   null.String `json:"webAppURL" envconfig:"K6_CLOUD_WEB_APP_URL"`
 	NoCompress  null.Bool   `json:"noCompress" envconfig:"K6_CLOUD_NO_COMPRESS"`
@@ -195,6 +196,9 @@ func (c Config) Apply(cfg Config) Config {
 	if cfg.LogsTailURL.Valid && cfg.LogsTailURL.String != "" {
 		c.LogsTailURL = cfg.LogsTailURL
 	}
+	if cfg.LogsPushURL.Valid && cfg.LogsPushURL.String != "" {
+		c.LogsPushURL = cfg.LogsPushURL
+	}
 	if cfg.WebAppURL.Valid {
 		c.WebAppURL = cfg.WebAppURL
 	}
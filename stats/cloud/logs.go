@@ -26,6 +26,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -90,26 +91,42 @@ func labelsToLogrusFields(labels map[string]string) logrus.Fields {
 	return fields
 }
 
-func (c *Config) getRequest(referenceID string, start time.Duration) (*url.URL, error) {
+// levelSelector returns the label matcher for the levels that are at least as
+// severe as the given one, or an empty string if all of them are.
+func levelSelector(level logrus.Level) string {
+	if level >= logrus.DebugLevel {
+		return ""
+	}
+	levels := make([]string, 0, level+2)
+	for _, l := range logrus.AllLevels[:level+1] {
+		levels = append(levels, l.String())
+		if l == logrus.WarnLevel {
+			levels = append(levels, "warn")
+		}
+	}
+	return fmt.Sprintf(`,level=~"%s"`, strings.Join(levels, "|"))
+}
+
+func (c *Config) getRequest(referenceID string, start time.Duration, level logrus.Level) (*url.URL, error) {
 	u, err := url.Parse(c.LogsTailURL.String)
 	if err != nil {
 		return nil, fmt.Errorf("couldn't parse cloud logs host %w", err)
 	}
 
-	u.RawQuery = fmt.Sprintf(`query={test_run_id="%s"}&start=%d`,
-		referenceID,
-		time.Now().Add(-start).UnixNano(),
-	)
+	u.RawQuery = url.Values{
+		"query": {fmt.Sprintf(`{test_run_id="%s"%s}`, referenceID, levelSelector(level))},
+		"start": {strconv.FormatInt(time.Now().Add(-start).UnixNano(), 10)},
+	}.Encode()
 
 	return u, nil
 }
 
 // StreamLogsToLogger streams the logs for the configured test to the provided logger until ctx is
-// Done or an error occurs.
+// Done or an error occurs. Only the logs that are at least as severe as level are requested.
 func (c *Config) StreamLogsToLogger(
-	ctx context.Context, logger logrus.FieldLogger, referenceID string, start time.Duration,
+	ctx context.Context, logger logrus.FieldLogger, referenceID string, start time.Duration, level logrus.Level,
 ) error {
-	u, err := c.getRequest(referenceID, start)
+	u, err := c.getRequest(referenceID, start, level)
 	if err != nil {
 		return err
 	}
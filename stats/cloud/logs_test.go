@@ -21,10 +21,17 @@
 package cloud
 
 import (
+	"context"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/gorilla/websocket"
+	"gopkg.in/guregu/null.v3"
+
 	"github.com/loadimpact/k6/lib/testutils"
 	"github.com/mailru/easyjson"
 	"github.com/sirupsen/logrus"
@@ -122,3 +129,53 @@ func TestMSGLog(t *testing.T) {
 		require.Equal(t, expectTime, entry.Time)
 	}
 }
+
+func TestStreamLogsToLogger(t *testing.T) {
+	queries := make(chan string, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries <- r.URL.Query().Get("query")
+		upgrader := websocket.Upgrader{Subprotocols: []string{r.Header.Get("Sec-WebSocket-Protocol")}}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"streams": [{"stream": {"level": "error"},
+			"values": [["1598282752000000000", "something failed"]]}]}`))
+		_, _, _ = conn.ReadMessage() // wait for the client to close the connection
+	}))
+	defer srv.Close()
+
+	config := NewConfig()
+	config.Token = null.StringFrom("token")
+	config.LogsTailURL = null.StringFrom("ws" + strings.TrimPrefix(srv.URL, "http"))
+
+	logger := logrus.New()
+	logger.Out = ioutil.Discard
+	hook := &testutils.SimpleLogrusHook{HookedLevels: logrus.AllLevels}
+	logger.AddHook(hook)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errC := make(chan error, 1)
+	go func() { errC <- config.StreamLogsToLogger(ctx, logger, "1234", 0, logrus.WarnLevel) }()
+
+	assert.Equal(t, `{test_run_id="1234",level=~"panic|fatal|error|warning|warn"}`, <-queries)
+	var lines []logrus.Entry
+	for deadline := time.Now().Add(time.Second); len(lines) == 0 && time.Now().Before(deadline); {
+		time.Sleep(10 * time.Millisecond)
+		lines = append(lines, hook.Drain()...)
+	}
+	cancel()
+	require.NoError(t, <-errC)
+
+	lines = append(lines, hook.Drain()...)
+	require.Len(t, lines, 1)
+	assert.Equal(t, logrus.ErrorLevel, lines[0].Level)
+	assert.Equal(t, "something failed", lines[0].Message)
+}
+
+func TestLevelSelector(t *testing.T) {
+	assert.Equal(t, `,level=~"panic|fatal|error"`, levelSelector(logrus.ErrorLevel))
+	assert.Equal(t, `,level=~"panic|fatal|error|warning|warn|info"`, levelSelector(logrus.InfoLevel))
+	assert.Equal(t, "", levelSelector(logrus.DebugLevel))
+}
@@ -0,0 +1,125 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package stats
+
+import "math"
+
+// The values that are closer to 0 than this are counted as zeros, so the
+// number of buckets stays reasonable.
+const minSketchValue = 1e-9
+
+// DDSketch is a quantile sketch with a bounded relative error, see
+// https://arxiv.org/abs/1908.10693. The values are counted in logarithmically
+// sized buckets, so its memory usage only depends on the range of the values,
+// and not on their number.
+type DDSketch struct {
+	RelativeAccuracy float64
+
+	gamma, logGamma float64
+	positive        sketchStore
+	negative        sketchStore
+	zeros           uint64
+	count           uint64
+}
+
+// sketchStore is a dense store of the bucket counts, starting with the bucket
+// with the offset index.
+type sketchStore struct {
+	counts []uint64
+	offset int
+}
+
+func (s *sketchStore) add(index int) {
+	switch {
+	case len(s.counts) == 0:
+		s.counts = []uint64{0}
+		s.offset = index
+	case index < s.offset:
+		counts := make([]uint64, len(s.counts)+s.offset-index)
+		copy(counts[s.offset-index:], s.counts)
+		s.counts, s.offset = counts, index
+	case index >= s.offset+len(s.counts):
+		s.counts = append(s.counts, make([]uint64, index-s.offset-len(s.counts)+1)...)
+	}
+	s.counts[index-s.offset]++
+}
+
+// NewDDSketch returns a new DDSketch with the given relative accuracy, which
+// has to be between 0 and 1, e.g. 0.01 for 1%.
+func NewDDSketch(relativeAccuracy float64) *DDSketch {
+	gamma := (1 + relativeAccuracy) / (1 - relativeAccuracy)
+	return &DDSketch{RelativeAccuracy: relativeAccuracy, gamma: gamma, logGamma: math.Log(gamma)}
+}
+
+// Add adds a value to the sketch.
+func (d *DDSketch) Add(v float64) {
+	d.count++
+	switch {
+	case v >= minSketchValue:
+		d.positive.add(d.index(v))
+	case v <= -minSketchValue:
+		d.negative.add(d.index(-v))
+	default:
+		d.zeros++
+	}
+}
+
+// Count returns the number of values in the sketch.
+func (d *DDSketch) Count() uint64 {
+	return d.count
+}
+
+// Quantile returns the estimate of the given quantile, between 0 and 1, of
+// the values in the sketch, or 0 if it's empty.
+func (d *DDSketch) Quantile(q float64) float64 {
+	if d.count == 0 {
+		return 0
+	}
+	rank := uint64(q * float64(d.count-1))
+
+	var n uint64
+	// The negative values are from the largest bucket index to the smallest
+	for i := len(d.negative.counts) - 1; i >= 0; i-- {
+		if n += d.negative.counts[i]; n > rank {
+			return -d.value(i + d.negative.offset)
+		}
+	}
+	if n += d.zeros; n > rank {
+		return 0
+	}
+	for i, c := range d.positive.counts {
+		if n += c; n > rank {
+			return d.value(i + d.positive.offset)
+		}
+	}
+	return d.value(d.positive.offset + len(d.positive.counts) - 1)
+}
+
+// index returns the index of the bucket of a positive value.
+func (d *DDSketch) index(v float64) int {
+	return int(math.Ceil(math.Log(v) / d.logGamma))
+}
+
+// value returns the estimate for the values in the bucket with the given
+// index, which is within the relative accuracy from all of them.
+func (d *DDSketch) value(index int) float64 {
+	return 2 * math.Pow(d.gamma, float64(index)) / (d.gamma + 1)
+}
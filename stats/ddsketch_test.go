@@ -0,0 +1,84 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package stats
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDDSketch(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+		d := NewDDSketch(0.01)
+		assert.Equal(t, 0.0, d.Quantile(0.5))
+		assert.Equal(t, uint64(0), d.Count())
+	})
+
+	t.Run("relative accuracy", func(t *testing.T) {
+		t.Parallel()
+		r := rand.New(rand.NewSource(1)) //nolint:gosec
+		for _, accuracy := range []float64{0.05, 0.01, 0.001} {
+			d := NewDDSketch(accuracy)
+			values := make([]float64, 100000)
+			for i := range values {
+				// Spans several orders of magnitude, like response times
+				values[i] = math.Exp(r.NormFloat64()*2 + 5)
+				d.Add(values[i])
+			}
+			sort.Float64s(values)
+			assert.Equal(t, uint64(len(values)), d.Count())
+			for _, q := range []float64{0, 0.1, 0.5, 0.9, 0.95, 0.99, 0.999, 1} {
+				exact := values[int(q*float64(len(values)-1))]
+				assert.InEpsilon(t, exact, d.Quantile(q), accuracy, "q=%f accuracy=%f", q, accuracy)
+			}
+		}
+	})
+
+	t.Run("negative values and zeros", func(t *testing.T) {
+		t.Parallel()
+		d := NewDDSketch(0.01)
+		for _, v := range []float64{-100, -10, 0, 0, 10, 100, 1000} {
+			d.Add(v)
+		}
+		assert.InEpsilon(t, -100, d.Quantile(0), 0.01)
+		assert.InEpsilon(t, -10, d.Quantile(1.0/6), 0.01)
+		assert.Equal(t, 0.0, d.Quantile(0.5))
+		assert.InEpsilon(t, 100, d.Quantile(5.0/6), 0.01)
+		assert.InEpsilon(t, 1000, d.Quantile(1), 0.01)
+	})
+
+	t.Run("bounded memory", func(t *testing.T) {
+		t.Parallel()
+		d := NewDDSketch(0.01)
+		for i := 0; i < 1000000; i++ {
+			d.Add(float64(i%60000) + 0.5) // up to a minute, in milliseconds
+		}
+		// log(60000)/log(1.01/0.99) buckets
+		assert.True(t, len(d.positive.counts) < 600, len(d.positive.counts))
+	})
+}
@@ -0,0 +1,177 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package json
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/loadimpact/k6/lib/types"
+	"github.com/loadimpact/k6/stats"
+)
+
+// TimeBound is a bound of the time range of the samples that are read, either
+// an absolute time or an offset from the first sample in the file.
+type TimeBound struct {
+	Time   time.Time
+	Offset types.NullDuration
+}
+
+// ParseTimeBound parses an RFC 3339 time or a duration offset, e.g. "1m30s".
+// An empty string is an unset bound.
+func ParseTimeBound(s string) (TimeBound, error) {
+	if s == "" {
+		return TimeBound{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return TimeBound{Time: t}, nil
+	}
+	d, err := types.ParseExtendedDuration(s)
+	if err != nil {
+		return TimeBound{}, fmt.Errorf("invalid time %q, it should be an RFC 3339 time or a duration", s)
+	}
+	return TimeBound{Offset: types.NullDurationFrom(d)}, nil
+}
+
+// resolve returns the absolute time of the bound, or the zero time if it's unset.
+func (b TimeBound) resolve(start time.Time) time.Time {
+	if b.Offset.Valid {
+		return start.Add(time.Duration(b.Offset.Duration))
+	}
+	return b.Time
+}
+
+// ReadConfig configures ReadMetrics.
+type ReadConfig struct {
+	From, To TimeBound
+	// The names of the submetrics to aggregate in addition to the ones that
+	// were defined in the test, e.g. "http_req_duration{status:200}"
+	Submetrics []string
+}
+
+// Results are the metrics that were aggregated from a JSON output file.
+type Results struct {
+	Metrics map[string]*stats.Metric
+	// The time range of the aggregated samples
+	Start, End time.Time
+}
+
+// Duration returns the duration of the time range of the aggregated samples.
+func (r *Results) Duration() time.Duration {
+	return r.End.Sub(r.Start)
+}
+
+// metricData is the data of a "Metric" envelope, with the parts of the metric
+// that are needed for aggregating its samples.
+type metricData struct {
+	Name       string            `json:"name"`
+	Type       stats.MetricType  `json:"type"`
+	Contains   stats.ValueType   `json:"contains"`
+	Thresholds stats.Thresholds  `json:"thresholds"`
+	Submetrics []stats.Submetric `json:"submetrics"`
+}
+
+// ReadMetrics reads the envelopes that the JSON output writes and aggregates
+// the samples into metrics, like the engine does during the test run. The
+// recorded thresholds of the metrics are restored, but they aren't evaluated.
+func ReadMetrics(r io.Reader, conf ReadConfig) (*Results, error) {
+	results := &Results{Metrics: make(map[string]*stats.Metric)}
+	extraSubmetrics := make(map[string][]string)
+	for _, name := range conf.Submetrics {
+		parent, _ := stats.NewSubmetric(name)
+		extraSubmetrics[parent] = append(extraSubmetrics[parent], name)
+	}
+
+	var from, to, first time.Time
+	decoder := json.NewDecoder(bufio.NewReader(r))
+	for line := 1; ; line++ {
+		var envelope struct {
+			Type   string          `json:"type"`
+			Metric string          `json:"metric"`
+			Data   json.RawMessage `json:"data"`
+		}
+		if err := decoder.Decode(&envelope); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("invalid envelope %d: %w", line, err)
+		}
+
+		switch envelope.Type {
+		case "Metric":
+			var data metricData
+			if err := json.Unmarshal(envelope.Data, &data); err != nil {
+				return nil, fmt.Errorf("invalid metric %s: %w", envelope.Metric, err)
+			}
+			m := stats.New(data.Name, data.Type, data.Contains)
+			m.Thresholds = data.Thresholds
+			names := extraSubmetrics[data.Name]
+			for _, sm := range data.Submetrics {
+				names = append(names, sm.Name)
+			}
+			for _, name := range names {
+				if _, ok := results.Metrics[name]; ok {
+					continue
+				}
+				_, sm := stats.NewSubmetric(name)
+				sm.Metric = stats.New(name, data.Type, data.Contains)
+				sm.Metric.Sub = *sm
+				m.Submetrics = append(m.Submetrics, sm)
+				results.Metrics[name] = sm.Metric
+			}
+			results.Metrics[data.Name] = m
+		case "Point":
+			m, ok := results.Metrics[envelope.Metric]
+			if !ok {
+				return nil, fmt.Errorf("the sample in envelope %d is for the unknown metric %s", line, envelope.Metric)
+			}
+			var sample JSONSample
+			if err := json.Unmarshal(envelope.Data, &sample); err != nil {
+				return nil, fmt.Errorf("invalid sample in envelope %d: %w", line, err)
+			}
+
+			if first.IsZero() {
+				first = sample.Time
+				from, to = conf.From.resolve(first), conf.To.resolve(first)
+			}
+			if (!from.IsZero() && sample.Time.Before(from)) || (!to.IsZero() && sample.Time.After(to)) {
+				continue
+			}
+			if results.Start.IsZero() || sample.Time.Before(results.Start) {
+				results.Start = sample.Time
+			}
+			if sample.Time.After(results.End) {
+				results.End = sample.Time
+			}
+
+			s := stats.Sample{Metric: m, Time: sample.Time, Tags: sample.Tags, Value: sample.Value}
+			m.Sink.Add(s)
+			for _, sm := range m.Submetrics {
+				if sample.Tags.Contains(sm.Tags) {
+					sm.Metric.Sink.Add(s)
+				}
+			}
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,107 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/loadimpact/k6/lib/types"
+	"github.com/loadimpact/k6/stats"
+)
+
+func TestReadMetrics(t *testing.T) {
+	t.Parallel()
+	trend := stats.New("my_trend", stats.Trend, stats.Time)
+	trend.Thresholds, _ = stats.NewThresholds([]string{"p(95)<100"})
+	counter := stats.New("my_counter", stats.Counter)
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	require.NoError(t, encoder.Encode(WrapMetric(trend)))
+	require.NoError(t, encoder.Encode(WrapMetric(counter)))
+	for i := 0; i < 10; i++ {
+		status := "200"
+		if i%2 == 1 {
+			status = "500"
+		}
+		tags := stats.IntoSampleTags(&map[string]string{"status": status})
+		sampleTime := start.Add(time.Duration(i) * time.Second)
+		require.NoError(t, encoder.Encode(WrapSample(&stats.Sample{
+			Metric: trend, Time: sampleTime, Tags: tags, Value: float64(i),
+		})))
+		require.NoError(t, encoder.Encode(WrapSample(&stats.Sample{
+			Metric: counter, Time: sampleTime, Tags: tags, Value: 1,
+		})))
+	}
+
+	t.Run("all", func(t *testing.T) {
+		results, err := ReadMetrics(bytes.NewReader(buf.Bytes()), ReadConfig{
+			Submetrics: []string{"my_trend{status:500}"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, start, results.Start)
+		assert.Equal(t, 9*time.Second, results.Duration())
+
+		require.Len(t, results.Metrics, 3)
+		trendSink := results.Metrics["my_trend"].Sink.(*stats.TrendSink)
+		assert.Equal(t, uint64(10), trendSink.Count)
+		assert.Equal(t, 9.0, trendSink.Max)
+		assert.Len(t, results.Metrics["my_trend"].Thresholds.Thresholds, 1)
+		assert.Equal(t, 10.0, results.Metrics["my_counter"].Sink.(*stats.CounterSink).Value)
+
+		subSink := results.Metrics["my_trend{status:500}"].Sink.(*stats.TrendSink)
+		assert.Equal(t, uint64(5), subSink.Count)
+		assert.Equal(t, 1.0, subSink.Min)
+	})
+
+	t.Run("time range", func(t *testing.T) {
+		from, err := ParseTimeBound("2s")
+		require.NoError(t, err)
+		to, err := ParseTimeBound(start.Add(5 * time.Second).Format(time.RFC3339))
+		require.NoError(t, err)
+		assert.Equal(t, TimeBound{Offset: types.NullDurationFrom(2 * time.Second)}, from)
+
+		results, err := ReadMetrics(bytes.NewReader(buf.Bytes()), ReadConfig{From: from, To: to})
+		require.NoError(t, err)
+		assert.Equal(t, start.Add(2*time.Second), results.Start)
+		assert.Equal(t, start.Add(5*time.Second), results.End)
+		assert.Equal(t, 4.0, results.Metrics["my_counter"].Sink.(*stats.CounterSink).Value)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := ParseTimeBound("yesterday")
+		assert.EqualError(t, err, `invalid time "yesterday", it should be an RFC 3339 time or a duration`)
+
+		_, err = ReadMetrics(strings.NewReader(`{"type":"Point","data":{"value":1},"metric":"nope"}`), ReadConfig{})
+		assert.EqualError(t, err, "the sample in envelope 1 is for the unknown metric nope")
+
+		_, err = ReadMetrics(strings.NewReader(`{"type":`), ReadConfig{})
+		assert.Error(t, err)
+	})
+}
@@ -93,10 +93,24 @@ type TrendSink struct {
 	Min, Max float64
 	Sum, Avg float64
 	Med      float64
+
+	// If it's set, the values are added to the sketch instead of Values, and
+	// the median and the percentiles are estimated from it.
+	Sketch *DDSketch
+}
+
+// NewSketchTrendSink returns a TrendSink that stores its values in a DDSketch
+// with the given relative accuracy, so it uses a bounded amount of memory.
+func NewSketchTrendSink(relativeAccuracy float64) *TrendSink {
+	return &TrendSink{Sketch: NewDDSketch(relativeAccuracy)}
 }
 
 func (t *TrendSink) Add(s Sample) {
-	t.Values = append(t.Values, s.Value)
+	if t.Sketch != nil {
+		t.Sketch.Add(s.Value)
+	} else {
+		t.Values = append(t.Values, s.Value)
+	}
 	t.jumbled = true
 	t.Count += 1
 	t.Sum += s.Value
@@ -112,10 +126,13 @@ func (t *TrendSink) Add(s Sample) {
 
 // P calculates the given percentile from sink values.
 func (t *TrendSink) P(pct float64) float64 {
-	switch t.Count {
-	case 0:
+	switch {
+	case t.Sketch != nil:
+		// The estimates can be slightly out of the range of the values
+		return math.Min(math.Max(t.Sketch.Quantile(pct), t.Min), t.Max)
+	case t.Count == 0:
 		return 0
-	case 1:
+	case t.Count == 1:
 		return t.Values[0]
 	default:
 		// If percentile falls on a value in Values slice, we return that value.
@@ -135,8 +152,12 @@ func (t *TrendSink) Calc() {
 		return
 	}
 
-	sort.Float64s(t.Values)
 	t.jumbled = false
+	if t.Sketch != nil {
+		t.Med = t.P(0.5)
+		return
+	}
+	sort.Float64s(t.Values)
 
 	// The median of an even number of values is the average of the middle two.
 	if (t.Count & 0x01) == 0 {
@@ -269,3 +269,22 @@ func TestDummySinkCalcDoesNothing(t *testing.T) {
 func TestDummySinkFormatReturnsItself(t *testing.T) {
 	assert.Equal(t, map[string]float64{"a": 1}, DummySink{"a": 1}.Format(0))
 }
+
+func TestSketchTrendSink(t *testing.T) {
+	sink := NewSketchTrendSink(0.01)
+	for i := 1; i <= 1000; i++ {
+		sink.Add(Sample{Metric: &Metric{}, Value: float64(i)})
+	}
+	assert.Empty(t, sink.Values)
+	assert.Equal(t, uint64(1000), sink.Count)
+	assert.Equal(t, 1.0, sink.Min)
+	assert.Equal(t, 1000.0, sink.Max)
+	assert.Equal(t, 500.5, sink.Avg)
+
+	sink.Calc()
+	assert.InEpsilon(t, 500.5, sink.Med, 0.01)
+	assert.InEpsilon(t, 900.1, sink.P(0.90), 0.01)
+	assert.Equal(t, 1.0, sink.P(0))
+	assert.Equal(t, 1000.0, sink.P(1))
+	assert.InEpsilon(t, 950.05, sink.Format(0)["p(95)"], 0.01)
+}
@@ -22,6 +22,8 @@ package stats
 
 import (
 	"encoding/json"
+	"fmt"
+	"regexp"
 	"time"
 
 	"github.com/dop251/goja"
@@ -38,6 +40,9 @@ function p(pct) {
 
 var jsEnv *goja.Program
 
+// metricReferenceRegex matches the metric("name") references to other metrics.
+var metricReferenceRegex = regexp.MustCompile(`\bmetric\(\s*(?:"([^"]+)"|'([^']+)')\s*\)`)
+
 func init() {
 	pgm, err := goja.Compile("__env__", jsEnvSrc, true)
 	if err != nil {
@@ -152,12 +157,45 @@ func newThresholdsWithConfig(configs []thresholdConfig) (Thresholds, error) {
 	return Thresholds{rt, ts, false}, nil
 }
 
-func (ts *Thresholds) updateVM(sink Sink, t time.Duration) error {
+// MetricSinkLookup returns the sink of the metric or submetric with the given
+// name, or nil if there isn't one.
+type MetricSinkLookup func(name string) Sink
+
+// MetricReferences returns the names of the other metrics that the thresholds
+// reference with metric("name").
+func (ts Thresholds) MetricReferences() []string {
+	var names []string
+	for _, th := range ts.Thresholds {
+		for _, match := range metricReferenceRegex.FindAllStringSubmatch(th.Source, -1) {
+			names = append(names, match[1]+match[2])
+		}
+	}
+	return names
+}
+
+func (ts *Thresholds) updateVM(sink Sink, t time.Duration, lookup MetricSinkLookup) error {
 	ts.Runtime.Set("__sink__", sink)
 	f := sink.Format(t)
 	for k, v := range f {
 		ts.Runtime.Set(k, v)
 	}
+	ts.Runtime.Set("metric", func(name string) (map[string]interface{}, error) {
+		var sink Sink
+		if lookup != nil {
+			sink = lookup(name)
+		}
+		if sink == nil {
+			return nil, fmt.Errorf("the referenced metric %s has no samples", name)
+		}
+		values := make(map[string]interface{})
+		for k, v := range sink.Format(t) {
+			values[k] = v
+		}
+		if ps, ok := sink.(interface{ P(pct float64) float64 }); ok {
+			values["p"] = func(pct float64) float64 { return ps.P(pct / 100.0) }
+		}
+		return values, nil
+	})
 	return nil
 }
 
@@ -185,7 +223,13 @@ func (ts *Thresholds) runAll(t time.Duration) (bool, error) {
 // Run processes all the thresholds with the provided Sink at the provided time and returns if any
 // of them fails
 func (ts *Thresholds) Run(sink Sink, t time.Duration) (bool, error) {
-	if err := ts.updateVM(sink, t); err != nil {
+	return ts.RunWithMetrics(sink, t, nil)
+}
+
+// RunWithMetrics is like Run, but the thresholds can also reference the values of other metrics,
+// which are looked up by name, e.g. `p(95) < metric("http_req_duration{scenario:baseline}").p(95) * 1.1`
+func (ts *Thresholds) RunWithMetrics(sink Sink, t time.Duration, lookup MetricSinkLookup) (bool, error) {
+	if err := ts.updateVM(sink, t, lookup); err != nil {
 		return false, err
 	}
 	return ts.runAll(t)
@@ -135,7 +135,7 @@ func TestNewThresholdsWithConfig(t *testing.T) {
 func TestThresholdsUpdateVM(t *testing.T) {
 	ts, err := NewThresholds(nil)
 	assert.NoError(t, err)
-	assert.NoError(t, ts.updateVM(DummySink{"a": 1234.5}, 0))
+	assert.NoError(t, ts.updateVM(DummySink{"a": 1234.5}, 0, nil))
 	assert.Equal(t, 1234.5, ts.Runtime.Get("a").ToFloat())
 }
 
@@ -218,6 +218,56 @@ func TestThresholdsRun(t *testing.T) {
 	})
 }
 
+func TestThresholdsRunWithMetrics(t *testing.T) {
+	ts, err := NewThresholds([]string{
+		`a > 0 && a < metric("b").a * 1.1`,
+		`p(95) <= metric('trend{tag:value}').p(95)`,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b", "trend{tag:value}"}, ts.MetricReferences())
+
+	trend := &TrendSink{}
+	for _, v := range []float64{1, 2, 3} {
+		trend.Add(Sample{Value: v})
+	}
+	sinks := map[string]Sink{"b": DummySink{"a": 100}, "trend{tag:value}": trend}
+	lookup := func(name string) Sink { return sinks[name] }
+
+	t.Run("relative", func(t *testing.T) {
+		ts, err := NewThresholds([]string{`a < metric("b").a * 1.1`})
+		assert.NoError(t, err)
+		b, err := ts.RunWithMetrics(DummySink{"a": 105}, 0, lookup)
+		assert.NoError(t, err)
+		assert.True(t, b)
+		b, err = ts.RunWithMetrics(DummySink{"a": 115}, 0, lookup)
+		assert.NoError(t, err)
+		assert.False(t, b)
+	})
+
+	t.Run("percentiles", func(t *testing.T) {
+		ts, err := NewThresholds([]string{`p(95) <= metric("trend{tag:value}").p(95)`})
+		assert.NoError(t, err)
+		sink := &TrendSink{}
+		sink.Add(Sample{Value: 2})
+		b, err := ts.RunWithMetrics(sink, 0, lookup)
+		assert.NoError(t, err)
+		assert.True(t, b)
+		sink.Add(Sample{Value: 10})
+		b, err = ts.RunWithMetrics(sink, 0, lookup)
+		assert.NoError(t, err)
+		assert.False(t, b)
+	})
+
+	t.Run("unknown metric", func(t *testing.T) {
+		ts, err := NewThresholds([]string{`a < metric("c").a`})
+		assert.NoError(t, err)
+		_, err = ts.RunWithMetrics(DummySink{"a": 1}, 0, lookup)
+		assert.Error(t, err)
+		_, err = ts.Run(DummySink{"a": 1}, 0)
+		assert.Error(t, err)
+	})
+}
+
 func TestThresholdsJSON(t *testing.T) {
 	var testdata = []struct {
 		JSON        string
@@ -57,6 +57,9 @@ var (
 		"max":   func(s *stats.TrendSink) interface{} { return s.Max },
 		"count": func(s *stats.TrendSink) interface{} { return s.Count },
 	}
+	// The factors for converting the values of time metrics, which are in
+	// milliseconds, to the supported summary time units.
+	timeUnitFactors = map[string]float64{"s": 1e-3, "ms": 1, "us": 1e3}
 )
 
 // ErrInvalidStat represents an invalid trend column stat
@@ -78,7 +81,10 @@ type Summary struct {
 // NewSummary returns a new Summary instance, used for writing a
 // summary/report of the test metrics data.
 func NewSummary(cols []string) *Summary {
-	s := Summary{trendColumns: cols, trendValueResolvers: staticResolvers}
+	s := Summary{trendColumns: cols, trendValueResolvers: make(map[string]func(s *stats.TrendSink) interface{})}
+	for name, res := range staticResolvers {
+		s.trendValueResolvers[name] = res
+	}
 
 	customResolvers := s.generateCustomTrendValueResolvers(cols)
 	for name, res := range customResolvers {
@@ -244,7 +250,16 @@ func nonTrendMetricValueForSum(t time.Duration, timeUnit string, m *stats.Metric
 	}
 }
 
-func nonTrendMetricValueForSumJSON(t time.Duration, m *stats.Metric) map[string]interface{} {
+// scaleTimeValue converts v to the given time unit if m is a time metric, and
+// returns it as it is otherwise.
+func scaleTimeValue(v float64, timeUnit string, m *stats.Metric) float64 {
+	if factor, ok := timeUnitFactors[timeUnit]; ok && m.Contains == stats.Time && m.Type != stats.Rate {
+		return v * factor
+	}
+	return v
+}
+
+func nonTrendMetricValueForSumJSON(t time.Duration, timeUnit string, m *stats.Metric) map[string]interface{} {
 	data := make(map[string]interface{})
 	switch sink := m.Sink.(type) {
 	case *stats.CounterSink:
@@ -252,10 +267,10 @@ func nonTrendMetricValueForSumJSON(t time.Duration, m *stats.Metric) map[string]
 		if t > 0 {
 			rate = sink.Value / (float64(t) / float64(time.Second))
 		}
-		data["rate"] = rate
+		data["rate"] = scaleTimeValue(rate, timeUnit, m)
 	case *stats.GaugeSink:
-		data["min"] = sink.Min
-		data["max"] = sink.Max
+		data["min"] = scaleTimeValue(sink.Min, timeUnit, m)
+		data["max"] = scaleTimeValue(sink.Max, timeUnit, m)
 	case *stats.RateSink:
 		data["passes"] = sink.Trues
 		data["fails"] = sink.Total - sink.Trues
@@ -403,10 +418,33 @@ func (s *Summary) SummarizeMetrics(w io.Writer, indent string, data SummaryData)
 		summarizeGroup(w, indent+"    ", data.RootGroup)
 	}
 
+	if _, ok := timeUnitFactors[data.TimeUnit]; ok {
+		_, _ = fmt.Fprintf(w, "%s  time values are in %s\n\n", indent, data.TimeUnit)
+	}
 	s.summarizeMetrics(w, indent+"  ", data.Time, data.TimeUnit, data.Metrics)
 }
 
-// SummarizeMetricsJSON summarizes a dataset in JSON format.
+// trendSinkData returns the values of the summary trend columns for the sink.
+func (s *Summary) trendSinkData(sink *stats.TrendSink, timeUnit string, m *stats.Metric) map[string]float64 {
+	data := make(map[string]float64, len(s.trendColumns))
+	for _, tc := range s.trendColumns {
+		resolver, ok := s.trendValueResolvers[tc]
+		if !ok {
+			continue
+		}
+		switch v := resolver(sink).(type) {
+		case float64:
+			data[tc] = scaleTimeValue(v, timeUnit, m)
+		case uint64:
+			data[tc] = float64(v)
+		}
+	}
+	return data
+}
+
+// SummarizeMetricsJSON summarizes a dataset in JSON format, with the summary
+// trend columns for the trend metrics. The values of the time metrics are in
+// the summary time unit, or in milliseconds by default.
 func (s *Summary) SummarizeMetricsJSON(w io.Writer, data SummaryData) error {
 	m := make(map[string]interface{})
 	m["root_group"] = data.RootGroup
@@ -415,7 +453,15 @@ func (s *Summary) SummarizeMetricsJSON(w io.Writer, data SummaryData) error {
 	for name, m := range data.Metrics {
 		m.Sink.Calc()
 
-		sinkData := m.Sink.Format(data.Time)
+		var sinkData map[string]float64
+		if sink, ok := m.Sink.(*stats.TrendSink); ok {
+			sinkData = s.trendSinkData(sink, data.TimeUnit, m)
+		} else {
+			sinkData = m.Sink.Format(data.Time)
+			for k, v := range sinkData {
+				sinkData[k] = scaleTimeValue(v, data.TimeUnit, m)
+			}
+		}
 		metricsData[name] = sinkData
 
 		var thresholds map[string]interface{}
@@ -436,7 +482,7 @@ func (s *Summary) SummarizeMetricsJSON(w io.Writer, data SummaryData) error {
 			continue
 		}
 
-		extra := nonTrendMetricValueForSumJSON(data.Time, m)
+		extra := nonTrendMetricValueForSumJSON(data.Time, data.TimeUnit, m)
 		if len(extra) > 1 {
 			extraData := make(map[string]interface{})
 			extraData["value"] = sinkData["value"]
@@ -22,6 +22,7 @@ package ui
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"testing"
 	"time"
@@ -77,6 +78,19 @@ func TestSummary(t *testing.T) {
 				assert.Equal(t, tc.expected, w.String())
 			})
 		}
+
+		t.Run("TimeUnit", func(t *testing.T) {
+			var w bytes.Buffer
+			s := NewSummary([]string{"avg", "max"})
+			s.SummarizeMetrics(&w, " ", SummaryData{
+				Metrics:  metrics,
+				Time:     time.Second,
+				TimeUnit: "us",
+			})
+			assert.Equal(t, "   time values are in us\n\n"+
+				"   ✓ checks......: 100.00% ✓ 3   ✗ 0  \n"+countOut+
+				"   ✗ my_trend....: avg=15000.00µs max=20000.00µs\n"+gaugeOut, w.String())
+		})
 	})
 
 	t.Run("generateCustomTrendValueResolvers", func(t *testing.T) {
@@ -229,6 +243,7 @@ func TestSummarizeMetricsJSON(t *testing.T) {
             "min": 10,
             "p(90)": 19,
             "p(95)": 19.5,
+            "p(99.9)": 19.990000000000002,
             "thresholds": {
                 "my_trend<1000": true
             }
@@ -262,3 +277,27 @@ func TestSummarizeMetricsJSON(t *testing.T) {
 	require.Contains(t, w.String(), "<")
 	require.JSONEq(t, expected, w.String())
 }
+
+func TestSummarizeMetricsJSONTimeUnit(t *testing.T) {
+	metrics := createTestMetrics()
+	timeGauge := stats.New("my_gauge", stats.Gauge, stats.Time)
+	timeGauge.Sink.Add(stats.Sample{Value: 1500})
+	metrics["my_gauge"] = timeGauge
+
+	s := NewSummary([]string{"avg", "max", "count"})
+	var w bytes.Buffer
+	require.NoError(t, s.SummarizeMetricsJSON(&w, SummaryData{Metrics: metrics, Time: time.Second, TimeUnit: "s"}))
+
+	var result struct {
+		Metrics map[string]map[string]interface{} `json:"metrics"`
+	}
+	require.NoError(t, json.Unmarshal(w.Bytes(), &result))
+	assert.Equal(t, 0.015, result.Metrics["my_trend"]["avg"])
+	assert.Equal(t, 0.02, result.Metrics["my_trend"]["max"])
+	assert.Equal(t, 3.0, result.Metrics["my_trend"]["count"])
+	assert.NotContains(t, result.Metrics["my_trend"], "med")
+	assert.Equal(t, map[string]interface{}{"value": 1.5, "min": 1.5, "max": 1.5}, result.Metrics["my_gauge"])
+	// Only the time metrics are converted
+	assert.Equal(t, 3.0, result.Metrics["http_reqs"]["count"])
+	assert.Equal(t, 1.0, result.Metrics["vus"]["value"])
+}
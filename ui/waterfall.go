@@ -0,0 +1,174 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ui
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/loadimpact/k6/lib/netext/httpext"
+)
+
+const (
+	// The number of columns of the bars of the waterfall chart
+	waterfallWidth = 60
+	// The max width of the request labels, longer ones are truncated
+	waterfallLabelWidth = 40
+)
+
+// waterfallPhase is a phase of the HTTP requests in the waterfall chart.
+type waterfallPhase struct {
+	char     byte
+	name     string
+	duration func(*httpext.Trail) time.Duration
+}
+
+//nolint:gochecknoglobals
+var waterfallPhases = []waterfallPhase{
+	{'.', "blocked", func(t *httpext.Trail) time.Duration {
+		// The connection is established while the request is blocked
+		if blocked := t.Blocked - t.LookingUp - t.Connecting - t.TLSHandshaking; blocked > 0 {
+			return blocked
+		}
+		return 0
+	}},
+	{'d', "DNS lookup", func(t *httpext.Trail) time.Duration { return t.LookingUp }},
+	{'c', "connecting", func(t *httpext.Trail) time.Duration { return t.Connecting }},
+	{'t', "TLS handshaking", func(t *httpext.Trail) time.Duration { return t.TLSHandshaking }},
+	{'s', "sending", func(t *httpext.Trail) time.Duration { return t.Sending }},
+	{'w', "waiting", func(t *httpext.Trail) time.Duration { return t.Waiting }},
+	{'r', "receiving", func(t *httpext.Trail) time.Duration { return t.Receiving }},
+}
+
+// waterfallRow is a request in the waterfall chart, with the end offsets of
+// its phases from its start.
+type waterfallRow struct {
+	label     string
+	start     time.Time
+	phaseEnds []time.Duration
+}
+
+func newWaterfallRow(trail *httpext.Trail) waterfallRow {
+	row := waterfallRow{phaseEnds: make([]time.Duration, len(waterfallPhases))}
+	var total time.Duration
+	for i, phase := range waterfallPhases {
+		total += phase.duration(trail)
+		row.phaseEnds[i] = total
+	}
+	row.start = trail.EndTime.Add(-total)
+
+	if trail.Tags != nil {
+		method, _ := trail.Tags.Get("method")
+		name, _ := trail.Tags.Get("name")
+		row.label = strings.TrimSpace(method + " " + name)
+	}
+	if len(row.label) > waterfallLabelWidth {
+		row.label = row.label[:waterfallLabelWidth-3] + "..."
+	}
+	return row
+}
+
+func (row waterfallRow) duration() time.Duration {
+	return row.phaseEnds[len(row.phaseEnds)-1]
+}
+
+// bar draws the phases of the request in the columns that their times fall
+// in. A request that is shorter than a column is drawn with its longest phase.
+func (row waterfallRow) bar(chartStart time.Time, column time.Duration) string {
+	bar := []byte(strings.Repeat(" ", waterfallWidth))
+	offset := row.start.Sub(chartStart)
+	drawn := false
+	for col := range bar {
+		t := time.Duration(col)*column + column/2 - offset
+		for i, end := range row.phaseEnds {
+			if t >= 0 && t < end {
+				bar[col], drawn = waterfallPhases[i].char, true
+				break
+			}
+		}
+	}
+	if !drawn {
+		longest := 0
+		for i := range row.phaseEnds {
+			if row.phase(i) > row.phase(longest) {
+				longest = i
+			}
+		}
+		col := int(offset / column)
+		if col >= waterfallWidth {
+			col = waterfallWidth - 1
+		}
+		bar[col] = waterfallPhases[longest].char
+	}
+	return string(bar)
+}
+
+// phase returns the duration of the i-th phase of the request.
+func (row waterfallRow) phase(i int) time.Duration {
+	if i == 0 {
+		return row.phaseEnds[0]
+	}
+	return row.phaseEnds[i] - row.phaseEnds[i-1]
+}
+
+// SummarizeWaterfall writes an ASCII waterfall chart of the given HTTP
+// requests to w, with a row for each request and the time that it spent in
+// each phase, e.g. connecting or waiting for the response, drawn to scale.
+func SummarizeWaterfall(w io.Writer, indent string, trails []*httpext.Trail) {
+	if len(trails) == 0 {
+		return
+	}
+	rows := make([]waterfallRow, len(trails))
+	for i, trail := range trails {
+		rows[i] = newWaterfallRow(trail)
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].start.Before(rows[j].start) })
+
+	chartStart, chartEnd := rows[0].start, rows[0].start
+	labelWidth := 0
+	for _, row := range rows {
+		if end := row.start.Add(row.duration()); end.After(chartEnd) {
+			chartEnd = end
+		}
+		if len(row.label) > labelWidth {
+			labelWidth = len(row.label)
+		}
+	}
+	column := chartEnd.Sub(chartStart) / waterfallWidth
+	if column <= 0 {
+		column = 1
+	}
+
+	_, _ = fmt.Fprintf(w, "%swaterfall of the first %d HTTP requests (each column is %s):\n\n",
+		indent, len(rows), column)
+	for _, row := range rows {
+		_, _ = fmt.Fprintf(w, "%s%-*s |%s| %s\n",
+			indent, labelWidth, row.label, row.bar(chartStart, column), row.duration())
+	}
+	legend := make([]string, len(waterfallPhases))
+	for i, phase := range waterfallPhases {
+		legend[i] = fmt.Sprintf("%c %s", phase.char, phase.name)
+	}
+	_, _ = fmt.Fprintf(w, "\n%s%s\n", indent, strings.Join(legend, "  "))
+}
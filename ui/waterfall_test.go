@@ -0,0 +1,72 @@
+/*
+ *
+ * k6 - a next-generation load testing tool
+ * Copyright (C) 2020 Load Impact
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as
+ * published by the Free Software Foundation, either version 3 of the
+ * License, or (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ *
+ */
+
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/loadimpact/k6/lib/netext/httpext"
+	"github.com/loadimpact/k6/stats"
+)
+
+func TestSummarizeWaterfall(t *testing.T) {
+	start := time.Now()
+	ms := time.Millisecond
+	tags := func(method, name string) *stats.SampleTags {
+		return stats.IntoSampleTags(&map[string]string{"method": method, "name": name})
+	}
+	trails := []*httpext.Trail{
+		{
+			EndTime: start.Add(120 * ms), Waiting: 50 * ms, Receiving: 10 * ms,
+			Tags: tags("POST", "http://example.com/a/very/long/path/that/is/truncated"),
+		},
+		{
+			EndTime: start.Add(100 * ms), Blocked: 30 * ms, LookingUp: 10 * ms, Connecting: 10 * ms,
+			Sending: 10 * ms, Waiting: 40 * ms, Receiving: 20 * ms,
+			Tags: tags("GET", "http://example.com/"),
+		},
+		{EndTime: start.Add(111 * ms), Waiting: 1 * ms, Tags: tags("GET", "http://example.com/tiny")},
+	}
+
+	var buf bytes.Buffer
+	SummarizeWaterfall(&buf, "  ", trails)
+	assert.Equal(t, strings.Join([]string{
+		"  waterfall of the first 3 HTTP requests (each column is 2ms):",
+		"",
+		"  GET http://example.com/                  |.....dddddcccccsssss" + strings.Repeat("w", 20) +
+			strings.Repeat("r", 10) + strings.Repeat(" ", 10) + "| 100ms",
+		"  POST http://example.com/a/very/long/p... |" + strings.Repeat(" ", 30) + strings.Repeat("w", 25) +
+			"rrrrr| 60ms",
+		"  GET http://example.com/tiny              |" + strings.Repeat(" ", 55) + "w    | 1ms",
+		"",
+		"  . blocked  d DNS lookup  c connecting  t TLS handshaking  s sending  w waiting  r receiving",
+		"",
+	}, "\n"), buf.String())
+
+	buf.Reset()
+	SummarizeWaterfall(&buf, "  ", nil)
+	assert.Empty(t, buf.String())
+}